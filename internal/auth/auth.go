@@ -0,0 +1,230 @@
+// Package auth - аутентификация запросов по JWT с HMAC подписью и статическим API ключам.
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+)
+
+// HeaderAPIKey - заголовок со статическим API ключом. Ключ также принимается в Authorization: Bearer.
+const HeaderAPIKey = "X-API-Key"
+
+// minSecret - минимальная длина секрета JWT: не короче выхода HS256.
+const minSecret = 32
+
+var algorithms = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+// Authenticator - определяет пользователя запроса по JWT или API ключу.
+type Authenticator struct {
+	secret   []byte
+	issuer   string
+	audience string
+	leeway   time.Duration
+	keys     map[[sha256.Size]byte]int64 // хеш ключа -> user_id
+	now      func() time.Time
+}
+
+// New - конструктор аутентификатора. Без секрета и ключей аутентификация отключена (см. Enabled).
+func New(cfg config.AuthConfig) (*Authenticator, error) {
+	if cfg.JWTSecret != "" && len(cfg.JWTSecret) < minSecret {
+		return nil, errShortSecret
+	}
+
+	keys := make(map[[sha256.Size]byte]int64, len(cfg.APIKeys))
+	for key, userID := range cfg.APIKeys {
+		if userID <= 0 || key == "" {
+			return nil, errKeyUser
+		}
+		keys[sha256.Sum256([]byte(key))] = userID
+	}
+
+	return &Authenticator{
+		secret:   []byte(cfg.JWTSecret),
+		issuer:   cfg.JWTIssuer,
+		audience: cfg.JWTAudience,
+		leeway:   cfg.Leeway,
+		keys:     keys,
+		now:      time.Now,
+	}, nil
+}
+
+// Enabled - настроен ли хотя бы один способ аутентификации.
+func (a *Authenticator) Enabled() bool {
+	return len(a.secret) > 0 || len(a.keys) > 0
+}
+
+// Authenticate - user_id вызывающего из заголовка X-API-Key или Authorization: Bearer (JWT или API ключ).
+func (a *Authenticator) Authenticate(r *http.Request) (int64, error) {
+	if key := strings.TrimSpace(r.Header.Get(HeaderAPIKey)); key != "" {
+		return a.apiKey(key)
+	}
+
+	scheme, token, ok := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return 0, errNoCredentials
+	}
+
+	token = strings.TrimSpace(token)
+	if strings.Count(token, ".") == 2 {
+		return a.jwt(token)
+	}
+
+	return a.apiKey(token)
+}
+
+// Issue - подписывает HS256 JWT для пользователя на срок ttl.
+func (a *Authenticator) Issue(userID int64, ttl time.Duration) (string, error) {
+	if len(a.secret) == 0 {
+		return "", errNoSecret
+	}
+
+	now := a.now()
+	claims := map[string]any{
+		"sub": strconv.FormatInt(userID, 10),
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+	if a.issuer != "" {
+		claims["iss"] = a.issuer
+	}
+	if a.audience != "" {
+		claims["aud"] = a.audience
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := encode(header) + "." + encode(payload)
+	return signed + "." + encode(sign(sha256.New, a.secret, signed)), nil
+}
+
+func (a *Authenticator) apiKey(key string) (int64, error) {
+	userID, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return 0, errUnknownKey
+	}
+
+	return userID, nil
+}
+
+// claims - проверяемые поля JWT. sub - user_id строкой или числом, aud - строка или массив.
+type claims struct {
+	Sub json.RawMessage `json:"sub"`
+	Exp *int64          `json:"exp"`
+	Nbf *int64          `json:"nbf"`
+	Iss string          `json:"iss"`
+	Aud json.RawMessage `json:"aud"`
+}
+
+func (a *Authenticator) jwt(token string) (int64, error) {
+	if len(a.secret) == 0 {
+		return 0, errUnknownKey
+	}
+
+	parts := strings.Split(token, ".")
+	headerJSON, err := decode(parts[0])
+	if err != nil {
+		return 0, errMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return 0, errMalformed
+	}
+	newHash, ok := algorithms[header.Alg]
+	if !ok {
+		return 0, errAlgorithm
+	}
+
+	signature, err := decode(parts[2])
+	if err != nil {
+		return 0, errMalformed
+	}
+	if !hmac.Equal(signature, sign(newHash, a.secret, parts[0]+"."+parts[1])) {
+		return 0, errSignature
+	}
+
+	payload, err := decode(parts[1])
+	if err != nil {
+		return 0, errMalformed
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return 0, errMalformed
+	}
+
+	return a.validate(c)
+}
+
+// validate - проверяет срок действия, издателя и получателя, возвращает user_id из sub.
+// exp обязателен: бессрочный токен нельзя отозвать, не сменив секрет.
+func (a *Authenticator) validate(c claims) (int64, error) {
+	now := a.now()
+	if c.Exp == nil || now.After(time.Unix(*c.Exp, 0).Add(a.leeway)) {
+		return 0, errExpired
+	}
+	if c.Nbf != nil && now.Add(a.leeway).Before(time.Unix(*c.Nbf, 0)) {
+		return 0, errNotYetValid
+	}
+	if a.issuer != "" && c.Iss != a.issuer {
+		return 0, errIssuer
+	}
+	if a.audience != "" && !hasAudience(c.Aud, a.audience) {
+		return 0, errAudience
+	}
+
+	sub := string(bytes.Trim(c.Sub, `"`))
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, errSubject
+	}
+
+	return userID, nil
+}
+
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var one string
+	if json.Unmarshal(raw, &one) == nil {
+		return one == audience
+	}
+
+	var many []string
+	return json.Unmarshal(raw, &many) == nil && slices.Contains(many, audience)
+}
+
+func sign(newHash func() hash.Hash, secret []byte, signed string) []byte {
+	mac := hmac.New(newHash, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func newAuth(t *testing.T) *Authenticator {
+	t.Helper()
+
+	a, err := New(config.AuthConfig{
+		JWTSecret:   secret,
+		JWTIssuer:   "calendar",
+		JWTAudience: "api",
+		APIKeys:     map[string]int64{"key-7": 7},
+		Leeway:      time.Minute,
+	})
+	require.NoError(t, err)
+	a.now = func() time.Time { return time.Unix(1_700_000_000, 0) }
+
+	return a
+}
+
+// token - подписывает JWT с произвольными заголовком и claims.
+func token(t *testing.T, alg string, key string, claims map[string]any) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := encode(header) + "." + encode(payload)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(signed))
+
+	return signed + "." + encode(mac.Sum(nil))
+}
+
+func authenticate(a *Authenticator, header, value string) (int64, error) {
+	r := httptest.NewRequest("GET", "/events", nil)
+	if header != "" {
+		r.Header.Set(header, value)
+	}
+	return a.Authenticate(r)
+}
+
+func TestIssueRoundTrip(t *testing.T) {
+	a := newAuth(t)
+
+	tok, err := a.Issue(42, time.Hour)
+	require.NoError(t, err)
+
+	uid, err := authenticate(a, "Authorization", "Bearer "+tok)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), uid)
+}
+
+func TestJWTClaims(t *testing.T) {
+	a := newAuth(t)
+	now := a.now().Unix()
+	valid := func() map[string]any {
+		return map[string]any{"sub": "5", "exp": now + 60, "iss": "calendar", "aud": []string{"web", "api"}}
+	}
+
+	uid, err := authenticate(a, "Authorization", "Bearer "+token(t, "HS256", secret, valid()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), uid)
+
+	numeric := valid()
+	numeric["sub"] = 5
+	_, err = authenticate(a, "Authorization", "Bearer "+token(t, "HS256", secret, numeric))
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		edit func(map[string]any)
+		want error
+	}{
+		"expired":        {func(c map[string]any) { c["exp"] = now - 120 }, errExpired},
+		"no exp":         {func(c map[string]any) { delete(c, "exp") }, errExpired},
+		"not yet valid":  {func(c map[string]any) { c["nbf"] = now + 120 }, errNotYetValid},
+		"issuer":         {func(c map[string]any) { c["iss"] = "other" }, errIssuer},
+		"audience":       {func(c map[string]any) { c["aud"] = "web" }, errAudience},
+		"subject":        {func(c map[string]any) { c["sub"] = "alice" }, errSubject},
+		"within leeway":  {func(c map[string]any) { c["exp"] = now - 30 }, nil},
+		"nbf in leeway":  {func(c map[string]any) { c["nbf"] = now + 30 }, nil},
+		"single aud str": {func(c map[string]any) { c["aud"] = "api" }, nil},
+	}
+	for name, tc := range cases {
+		claims := valid()
+		tc.edit(claims)
+		_, err := authenticate(a, "Authorization", "Bearer "+token(t, "HS256", secret, claims))
+		assert.ErrorIs(t, err, tc.want, name)
+	}
+}
+
+func TestJWTSignature(t *testing.T) {
+	a := newAuth(t)
+	claims := map[string]any{"sub": "5", "exp": a.now().Unix() + 60, "iss": "calendar", "aud": "api"}
+
+	_, err := authenticate(a, "Authorization", "Bearer "+token(t, "HS256", "wrong-secret-wrong-secret-wrong!!", claims))
+	assert.ErrorIs(t, err, errSignature)
+
+	// Заголовок alg=none не отключает проверку подписи.
+	_, err = authenticate(a, "Authorization", "Bearer "+token(t, "none", secret, claims))
+	assert.ErrorIs(t, err, errAlgorithm)
+
+	// Подпись HS256 не подходит к заголовку HS512.
+	_, err = authenticate(a, "Authorization", "Bearer "+token(t, "HS512", secret, claims))
+	assert.ErrorIs(t, err, errSignature)
+
+	_, err = authenticate(a, "Authorization", "Bearer a.b.c")
+	assert.ErrorIs(t, err, errMalformed)
+}
+
+func TestAPIKey(t *testing.T) {
+	a := newAuth(t)
+
+	uid, err := authenticate(a, HeaderAPIKey, "key-7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), uid)
+
+	uid, err = authenticate(a, "Authorization", "Bearer key-7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), uid)
+
+	_, err = authenticate(a, HeaderAPIKey, "key-8")
+	assert.ErrorIs(t, err, errUnknownKey)
+
+	_, err = authenticate(a, "Authorization", "Basic key-7")
+	assert.ErrorIs(t, err, errNoCredentials)
+
+	_, err = authenticate(a, "", "")
+	assert.ErrorIs(t, err, errNoCredentials)
+}
+
+func TestNew(t *testing.T) {
+	a, err := New(config.AuthConfig{})
+	require.NoError(t, err)
+	assert.False(t, a.Enabled())
+
+	_, err = New(config.AuthConfig{JWTSecret: "short"})
+	assert.ErrorIs(t, err, errShortSecret)
+
+	_, err = New(config.AuthConfig{APIKeys: map[string]int64{"key": 0}})
+	assert.ErrorIs(t, err, errKeyUser)
+}
+
+func TestAuthorize(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, Authorize(ctx, 1))
+
+	ctx = WithUser(ctx, 1)
+	require.NoError(t, Authorize(ctx, 1))
+	assert.ErrorIs(t, Authorize(ctx, 2), ErrForbidden)
+}
@@ -0,0 +1,26 @@
+package auth
+
+import "context"
+
+type userKey struct{}
+
+// WithUser - контекст запроса, выполняемого от имени пользователя userID.
+func WithUser(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// UserFrom - пользователь, от имени которого выполняется запрос.
+// false - запрос не аутентифицирован (аутентификация отключена или это фоновый сервис).
+func UserFrom(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userKey{}).(int64)
+	return userID, ok
+}
+
+// Authorize - запрещает аутентифицированному вызывающему действовать от имени другого пользователя.
+func Authorize(ctx context.Context, userID int64) error {
+	if caller, ok := UserFrom(ctx); ok && caller != userID {
+		return ErrForbidden
+	}
+
+	return nil
+}
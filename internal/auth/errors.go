@@ -0,0 +1,22 @@
+package auth
+
+import "errors"
+
+// ErrForbidden - у вызывающего нет прав на данные другого пользователя.
+var ErrForbidden = errors.New("доступ запрещен: нет прав на данные другого пользователя")
+
+var (
+	errNoCredentials = errors.New("не переданы учетные данные")
+	errUnknownKey    = errors.New("неизвестный API ключ")
+	errMalformed     = errors.New("некорректный JWT")
+	errAlgorithm     = errors.New("неподдерживаемый алгоритм подписи JWT")
+	errSignature     = errors.New("неверная подпись JWT")
+	errExpired       = errors.New("срок действия JWT истек")
+	errNotYetValid   = errors.New("JWT еще не действует")
+	errIssuer        = errors.New("неверный издатель JWT")
+	errAudience      = errors.New("JWT выпущен для другого получателя")
+	errSubject       = errors.New("некорректный sub в JWT, ожидается user_id")
+	errShortSecret   = errors.New("секрет JWT должен быть не короче 32 байт")
+	errKeyUser       = errors.New("некорректный user_id у API ключа")
+	errNoSecret      = errors.New("секрет JWT не задан")
+)
@@ -3,12 +3,17 @@ package config
 import "time"
 
 type Config struct {
-	HTTPPort    string        `default:"8080"  envconfig:"HTTP_PORT"`
-	HTTPTimeout time.Duration `default:"20s"   envconfig:"HTTP_TIMEOUT"`
-	LoggerCfg   LoggerConfig  `envconfig:"LOG"`
+	HTTPPort    string         `default:"8080"  envconfig:"HTTP_PORT"`
+	HTTPTimeout time.Duration  `default:"20s"   envconfig:"HTTP_TIMEOUT"`
+	LoggerCfg   LoggerConfig   `envconfig:"LOG"`
+	DBCfg       DatabaseConfig `envconfig:"DB"`
 
+	BrokerCfg   BrokerConfig   `envconfig:"BROKER"`
 	ReminderCfg ReminderConfig `envconfig:"REMINDER"`
 	ArchiveCfg  ArchiverConfig `envconfig:"ARCHIVE"`
+	NotifyCfg   NotifyConfig   `envconfig:"NOTIFY"`
+	WebhookCfg  WebhookConfig  `envconfig:"WEBHOOK"`
+	AuthCfg     AuthConfig     `envconfig:"AUTH"`
 }
 
 type LoggerConfig struct {
@@ -16,11 +21,76 @@ type LoggerConfig struct {
 	ChanSize int    `default:"100"  envconfig:"CHAN_SIZE"`
 }
 
+type DatabaseConfig struct {
+	Driver string `default:"inmem"       envconfig:"DRIVER"` // inmem | sqlite | postgres
+	DSN    string `default:"calendar.db" envconfig:"DSN"`
+}
+
+type BrokerConfig struct {
+	Driver      string        `default:"inmem"  envconfig:"DRIVER"` // inmem | disk | redis
+	Dir         string        `default:"broker" envconfig:"DIR"`
+	MaxAttempts int           `default:"5"      envconfig:"MAX_ATTEMPTS"`
+	RetryBase   time.Duration `default:"1s"     envconfig:"RETRY_BASE"`
+	RetryMax    time.Duration `default:"5m"     envconfig:"RETRY_MAX"`
+	Sync        bool          `default:"true"   envconfig:"SYNC"`        // fsync после каждой записи
+	CompactMin  int           `default:"10000"  envconfig:"COMPACT_MIN"` // записей журнала, до которых он не сжимается во время работы
+	Redis       RedisConfig   `envconfig:"REDIS"`
+}
+
+type RedisConfig struct {
+	Addr      string        `default:"localhost:6379" envconfig:"ADDR"`
+	Password  string        `envconfig:"PASSWORD"`
+	DB        int           `default:"0"              envconfig:"DB"`
+	Prefix    string        `default:"calendar:"      envconfig:"PREFIX"`     // префикс ключей потоков
+	MaxLen    int64         `default:"100000"         envconfig:"MAX_LEN"`    // примерная длина потока топика
+	Consumer  string        `envconfig:"CONSUMER"`                            // пусто - hostname-pid
+	ClaimIdle time.Duration `default:"1m"             envconfig:"CLAIM_IDLE"` // простой, после которого сообщение забирает другая реплика
+	Block     time.Duration `default:"1s"             envconfig:"BLOCK"`      // ожидание новых сообщений в XREADGROUP
+	Batch     int64         `default:"100"            envconfig:"BATCH"`
+}
+
 type ReminderConfig struct {
-	ChanSize int           `default:"100" envconfig:"CHAN_SIZE"`
-	Interval time.Duration `default:"2s"  envconfig:"INTERVAL"`
+	ChanSize    int           `default:"100" envconfig:"CHAN_SIZE"`
+	Interval    time.Duration `default:"2s"  envconfig:"INTERVAL"`
+	MaxAttempts int           `default:"4"   envconfig:"MAX_ATTEMPTS"` // попыток доставки по каналу, включая первую
+	RetryBase   time.Duration `default:"30s" envconfig:"RETRY_BASE"`
+	RetryMax    time.Duration `default:"10m" envconfig:"RETRY_MAX"`
 }
 
 type ArchiverConfig struct {
 	Interval time.Duration `default:"10s" envconfig:"INTERVAL"`
 }
+
+type NotifyConfig struct {
+	Channels            []string      `default:"log" envconfig:"CHANNELS"` // log | email | webhook
+	LogPath             string        `envconfig:"LOG_PATH"`               // пусто - stdout
+	WebhookTimeout      time.Duration `default:"5s"  envconfig:"WEBHOOK_TIMEOUT"`
+	WebhookAllowPrivate bool          `envconfig:"WEBHOOK_ALLOW_PRIVATE"` // разрешить webhook_url на внутренние адреса
+	SMTP                SMTPConfig    `envconfig:"SMTP"`
+}
+
+type WebhookConfig struct {
+	Timeout      time.Duration `default:"5s" envconfig:"TIMEOUT"`
+	MaxAttempts  int           `default:"5"  envconfig:"MAX_ATTEMPTS"`
+	RetryBase    time.Duration `default:"1s" envconfig:"RETRY_BASE"`
+	RetryMax     time.Duration `default:"5m" envconfig:"RETRY_MAX"`
+	AllowPrivate bool          `envconfig:"ALLOW_PRIVATE"` // разрешить вебхуки на внутренние адреса
+}
+
+// AuthConfig - аутентификация запросов. Без секрета JWT и API ключей аутентификация отключена.
+type AuthConfig struct {
+	JWTSecret   string           `envconfig:"JWT_SECRET"`          // HMAC секрет JWT (HS256/HS384/HS512), от 32 байт
+	JWTIssuer   string           `envconfig:"JWT_ISSUER"`          // пусто - iss не проверяется
+	JWTAudience string           `envconfig:"JWT_AUDIENCE"`        // пусто - aud не проверяется
+	APIKeys     map[string]int64 `envconfig:"API_KEYS"`            // ключ:user_id через запятую
+	Leeway      time.Duration    `default:"1m" envconfig:"LEEWAY"` // допустимое расхождение часов для exp/nbf
+}
+
+type SMTPConfig struct {
+	Host     string        `default:"localhost"          envconfig:"HOST"`
+	Port     string        `default:"25"                 envconfig:"PORT"`
+	From     string        `default:"calendar@localhost" envconfig:"FROM"`
+	Username string        `envconfig:"USERNAME"`
+	Password string        `envconfig:"PASSWORD"`
+	Timeout  time.Duration `default:"10s"                envconfig:"TIMEOUT"` // подключение и отправка одного письма
+}
@@ -3,6 +3,8 @@ package entrypoint
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -12,15 +14,24 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
 	"github.com/sunr3d/simple-http-calendar/internal/config"
 	httphandlers "github.com/sunr3d/simple-http-calendar/internal/handlers/http"
+	"github.com/sunr3d/simple-http-calendar/internal/infra/diskbroker"
 	"github.com/sunr3d/simple-http-calendar/internal/infra/inmembroker"
 	"github.com/sunr3d/simple-http-calendar/internal/infra/inmemdb"
+	"github.com/sunr3d/simple-http-calendar/internal/infra/notifier"
+	"github.com/sunr3d/simple-http-calendar/internal/infra/redisbroker"
+	"github.com/sunr3d/simple-http-calendar/internal/infra/sqldb"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
 	"github.com/sunr3d/simple-http-calendar/internal/middleware"
+	"github.com/sunr3d/simple-http-calendar/internal/netguard"
 	"github.com/sunr3d/simple-http-calendar/internal/server"
 	"github.com/sunr3d/simple-http-calendar/internal/services/archiversvc"
 	"github.com/sunr3d/simple-http-calendar/internal/services/calendarsvc"
 	"github.com/sunr3d/simple-http-calendar/internal/services/remindersvc"
+	"github.com/sunr3d/simple-http-calendar/internal/services/webhooksvc"
+	"github.com/sunr3d/simple-http-calendar/models"
 )
 
 func Run(cfg *config.Config, logger *zap.Logger) error {
@@ -37,23 +48,62 @@ func Run(cfg *config.Config, logger *zap.Logger) error {
 	}()
 
 	/// Инфра слой
-	repo := inmemdb.New(logger)
-	broker := inmembroker.New(cfg.ReminderCfg.ChanSize, logger)
+	store, err := newStorage(appCtx, cfg.DBCfg, logger)
+	if err != nil {
+		return fmt.Errorf("newStorage: %w", err)
+	}
+	defer func() {
+		if err := store.close(); err != nil {
+			log.Printf("ошибка при закрытии хранилища: %v\n", err)
+		}
+	}()
+	repo := store.events
+	broker, closeBroker, err := newBroker(cfg.BrokerCfg, cfg.ReminderCfg, logger)
+	if err != nil {
+		return fmt.Errorf("newBroker: %w", err)
+	}
+	defer func() {
+		if err := closeBroker(); err != nil {
+			log.Printf("ошибка при закрытии брокера: %v\n", err)
+		}
+	}()
+	notifiers, defaultChannels, closeNotifiers, err := newNotifiers(cfg.NotifyCfg, logger)
+	if err != nil {
+		return fmt.Errorf("newNotifiers: %w", err)
+	}
+	defer func() {
+		if err := closeNotifiers(); err != nil {
+			log.Printf("ошибка при закрытии канала напоминаний: %v\n", err)
+		}
+	}()
 
 	/// Сервисный слой
-	calSvc := calendarsvc.New(repo, broker, logger)
-	remSvc := remindersvc.New(repo, broker, logger)
-	archSvc := archiversvc.New(repo, logger, cfg.ArchiveCfg)
+	recipients := &netguard.Guard{AllowPrivate: cfg.NotifyCfg.WebhookAllowPrivate}
+	calSvc := calendarsvc.New(repo, store.users, store.shares, store.calendars, store.resources, broker, recipients, logger)
+	remSvc := remindersvc.New(repo, store.users, broker, notifiers, defaultChannels, logger, cfg.ReminderCfg)
+	archSvc := archiversvc.New(repo, broker, logger, cfg.ArchiveCfg)
+	hookSvc := webhooksvc.New(store.webhooks, broker, logger, cfg.WebhookCfg)
 
 	/// HTTP слой
-	controller := httphandlers.New(calSvc, logger)
+	controller := httphandlers.New(calSvc, hookSvc, logger)
 	mux := http.NewServeMux()
 	controller.RegisterCalendarHandlers(mux)
+	controller.RegisterCalendarV2Handlers(mux)
+	controller.RegisterWebhookHandlers(mux)
 
 	// Middleware
+	authn, err := auth.New(cfg.AuthCfg)
+	if err != nil {
+		return fmt.Errorf("auth.New: %w", err)
+	}
+	if !authn.Enabled() {
+		logger.Warn("аутентификация отключена: AUTH_JWT_SECRET и AUTH_API_KEYS не заданы")
+	}
 	handler := middleware.Recovery(logger)(
 		middleware.ReqLogger(logger)(
-			middleware.JSONValidator(logger)(mux),
+			middleware.Auth(authn, logger)(
+				middleware.JSONValidator(logger)(mux),
+			),
 		),
 	)
 
@@ -75,6 +125,110 @@ func Run(cfg *config.Config, logger *zap.Logger) error {
 			}
 		}
 	}()
+	go func() {
+		if err := hookSvc.Start(appCtx); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				log.Printf("ошибка в работе сервиса вебхуков: %v\n", err)
+			}
+		}
+	}()
 
 	return srv.Start(appCtx)
 }
+
+// storage - хранилища приложения, работающие поверх одного соединения.
+type storage struct {
+	events    infra.Database
+	users     infra.UserRepo
+	webhooks  infra.WebhookRepo
+	shares    infra.ShareRepo
+	calendars infra.CalendarRepo
+	resources infra.ResourceRepo
+	close     func() error
+}
+
+// newStorage - выбирает реализацию хранилищ по конфигурации.
+func newStorage(ctx context.Context, cfg config.DatabaseConfig, logger *zap.Logger) (*storage, error) {
+	switch cfg.Driver {
+	case "", "inmem":
+		resources := inmemdb.NewResourceRepo(logger)
+		return &storage{
+			events:    inmemdb.New(logger, resources),
+			users:     inmemdb.NewUserRepo(logger),
+			webhooks:  inmemdb.NewWebhookRepo(logger),
+			shares:    inmemdb.NewShareRepo(logger),
+			calendars: inmemdb.NewCalendarRepo(logger),
+			resources: resources,
+			close:     func() error { return nil },
+		}, nil
+	default:
+		db, err := sqldb.Open(ctx, cfg, logger)
+		if err != nil {
+			return nil, fmt.Errorf("sqldb.Open: %w", err)
+		}
+		return &storage{
+			events:    sqldb.New(db),
+			users:     sqldb.NewUserRepo(db),
+			webhooks:  sqldb.NewWebhookRepo(db),
+			shares:    sqldb.NewShareRepo(db),
+			calendars: sqldb.NewCalendarRepo(db),
+			resources: sqldb.NewResourceRepo(db),
+			close:     db.Close,
+		}, nil
+	}
+}
+
+// newBroker - выбирает реализацию брокера по конфигурации.
+func newBroker(cfg config.BrokerConfig, reminderCfg config.ReminderConfig, logger *zap.Logger) (infra.Broker, func() error, error) {
+	switch cfg.Driver {
+	case "", "inmem":
+		return inmembroker.New(reminderCfg.ChanSize, logger), func() error { return nil }, nil
+	case "disk":
+		b, err := diskbroker.Open(cfg, logger)
+		if err != nil {
+			return nil, nil, fmt.Errorf("diskbroker.Open: %w", err)
+		}
+		return b, b.Close, nil
+	case "redis":
+		b, err := redisbroker.Open(cfg, logger)
+		if err != nil {
+			return nil, nil, fmt.Errorf("redisbroker.Open: %w", err)
+		}
+		return b, b.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("неизвестный драйвер брокера: %s", cfg.Driver)
+	}
+}
+
+// newNotifiers - каналы доставки напоминаний и каналы по умолчанию из конфигурации.
+// Канал log пишет в файл NOTIFY_LOG_PATH или в stdout.
+func newNotifiers(cfg config.NotifyConfig, logger *zap.Logger) ([]infra.Notifier, []models.Channel, func() error, error) {
+	defaults := make([]models.Channel, 0, len(cfg.Channels))
+	for _, name := range cfg.Channels {
+		ch := models.Channel(strings.TrimSpace(name))
+		if !ch.Valid() {
+			return nil, nil, nil, fmt.Errorf("неизвестный канал напоминаний %q", name)
+		}
+		defaults = append(defaults, ch)
+	}
+
+	var (
+		out     io.Writer = os.Stdout
+		closeFn           = func() error { return nil }
+	)
+	if cfg.LogPath != "" {
+		f, err := os.OpenFile(cfg.LogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("os.OpenFile: %w", err)
+		}
+		out, closeFn = f, f.Close
+	}
+
+	notifiers := []infra.Notifier{
+		notifier.NewWriter(out, logger),
+		notifier.NewWebhook(cfg.WebhookTimeout, &netguard.Guard{AllowPrivate: cfg.WebhookAllowPrivate}, logger),
+		notifier.NewSMTP(cfg.SMTP, logger),
+	}
+
+	return notifiers, defaults, closeFn, nil
+}
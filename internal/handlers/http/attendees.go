@@ -0,0 +1,88 @@
+package httphandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/handlers/validators"
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func (h *Handler) inviteAttendees(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "InviteAttendees"))
+
+	logger.Info("получен запрос на приглашение участников")
+
+	var req inviteReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	eventID := strings.TrimSpace(req.EventID)
+	if eventID == "" {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadEventID.Error())
+		return
+	}
+	if _, err := attendeesOf(req.Attendees); err != nil || len(req.Attendees) == 0 {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadAttendees.Error())
+		return
+	}
+
+	if err := h.svc.InviteAttendees(r.Context(), eventID, req.Attendees); err != nil {
+		logger.Warn("ошибка при приглашении участников", zap.String("event_id", eventID), zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	logger.Info("участники приглашены", zap.String("event_id", eventID), zap.Int64s("attendees", req.Attendees))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
+
+func (h *Handler) respondEvent(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "RespondEvent"))
+
+	logger.Info("получен ответ на приглашение")
+
+	var req respondReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	eventID := strings.TrimSpace(req.EventID)
+	if eventID == "" {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadEventID.Error())
+		return
+	}
+	userID := bindUser(r, req.UserID)
+	if userID <= 0 {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadUserID.Error())
+		return
+	}
+	status := models.AttendeeStatus(strings.TrimSpace(req.Status))
+	if !status.Valid() {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadRSVP.Error())
+		return
+	}
+
+	if err := h.svc.RespondEvent(r.Context(), eventID, userID, status); err != nil {
+		logger.Warn("ошибка при ответе на приглашение", zap.String("event_id", eventID), zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	logger.Info("ответ на приглашение сохранен",
+		zap.String("event_id", eventID),
+		zap.Int64("user_id", userID),
+		zap.String("status", string(status)),
+	)
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
@@ -28,29 +28,27 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	day, err := time.ParseInLocation("2006-01-02T15:04:05", strings.TrimSpace(req.Date), time.Local)
-	if err != nil {
-		logger.Warn("некорректная дата", zap.Error(err))
-		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректная дата, ожидается YYYY-MM-DDTHH:MM:SS")
+	req.UserID = bindUser(r, req.UserID)
+
+	event, ok := h.newEvent(w, r, logger, req)
+	if !ok {
 		return
 	}
 
-	event := models.Event{UserID: req.UserID, Date: day, Text: req.Event, Reminder: req.Reminder}
-	if err := validators.ValidateCreatePayload(event); err != nil {
-		logger.Warn("некорректные данные события", zap.Error(err))
-		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+	conflicts, ok := h.checkConflicts(w, r, logger, event, req.OnConflict)
+	if !ok {
 		return
 	}
 
 	id, err := h.svc.CreateEvent(r.Context(), event)
 	if err != nil {
 		logger.Warn("ошибка при создании события", zap.Error(err))
-		_ = httpx.HTTPError(w, http.StatusServiceUnavailable, "Сервис недоступен")
+		serviceError(w, err)
 		return
 	}
 
 	logger.Info("событие успешно создано", zap.String("event_id", id))
-	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": id})
+	_ = httpx.WriteJSON(w, http.StatusOK, withConflicts(map[string]any{"result": id}, conflicts))
 }
 
 func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
@@ -66,28 +64,211 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	day, err := time.ParseInLocation("2006-01-02T15:04:05", strings.TrimSpace(req.Date), time.Local)
-	if err != nil {
-		logger.Warn("некорректная дата", zap.Error(err))
-		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректная дата, ожидается YYYY-MM-DDTHH:MM:SS")
+	req.UserID = bindUser(r, req.UserID)
+
+	event, ok := h.changedEvent(w, r, logger, req)
+	if !ok {
 		return
 	}
 
-	event := models.Event{ID: req.EventID, UserID: req.UserID, Date: day, Text: req.Event, Reminder: req.Reminder}
-	if err := validators.ValidateUpdate(event); err != nil {
-		logger.Warn("некорректные данные события", zap.Error(err))
+	scope := models.EditScope(req.Scope)
+	if err := validators.ValidateScope(scope); err != nil {
+		logger.Warn("некорректная область изменения", zap.Error(err))
 		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if err := h.svc.UpdateEvent(r.Context(), event); err != nil {
+	conflicts, ok := h.checkConflicts(w, r, logger, event, req.OnConflict)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.UpdateEvent(r.Context(), event, scope); err != nil {
 		logger.Warn("ошибка при обновлении события", zap.String("event_id", req.EventID), zap.Error(err))
-		_ = httpx.HTTPError(w, http.StatusServiceUnavailable, "Сервис недоступен")
+		serviceError(w, err)
 		return
 	}
 
 	logger.Info("событие успешно обновлено", zap.String("event_id", req.EventID))
-	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+	_ = httpx.WriteJSON(w, http.StatusOK, withConflicts(map[string]any{"result": "ok"}, conflicts))
+}
+
+// newEvent - событие из запроса на создание, проверенное валидатором.
+// При ошибке пишет ответ 400 (или ошибку сервиса) и возвращает false.
+func (h *Handler) newEvent(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger *zap.Logger,
+	req createEventReq,
+) (models.Event, bool) {
+	loc, ok := h.location(w, r, logger, req.UserID, req.TZ)
+	if !ok {
+		return models.Event{}, false
+	}
+
+	channels, err := parseChannels(req.Channels)
+	if err != nil {
+		logger.Warn("некорректные каналы напоминаний", zap.Strings("channels", req.Channels))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	reminders, err := parseReminders(req.Reminders)
+	if err != nil {
+		logger.Warn("некорректные напоминания", zap.Strings("reminders", req.Reminders))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	day, end, err := parseEventTimes(req.Date, req.End, req.Duration, req.AllDay, loc)
+	if err != nil {
+		logger.Warn("некорректная дата", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	exdates, err := parseDates(req.ExDates, loc)
+	if err != nil {
+		logger.Warn("некорректные даты исключений", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректные даты исключений, ожидается YYYY-MM-DDTHH:MM:SS или RFC 3339")
+		return models.Event{}, false
+	}
+
+	attendees, err := attendeesOf(req.Attendees)
+	if err != nil {
+		logger.Warn("некорректные участники", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	event := models.Event{
+		UserID:     req.UserID,
+		CalendarID: req.CalendarID,
+		Date:       day,
+		End:        end,
+		AllDay:     req.AllDay,
+		TimeZone:   req.TZ,
+		Text:       req.Event,
+		Reminders:  reminders,
+		Channels:   channels,
+		RRule:      req.RRule,
+		ExDates:    exdates,
+		Attendees:  attendees,
+		Resources:  resourcesOf(req.Resources),
+	}
+	if err := validators.ValidateCreatePayload(event); err != nil {
+		logger.Warn("некорректные данные события", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	return event, true
+}
+
+// changedEvent - изменение события из запроса на обновление, проверенное валидатором.
+// При ошибке пишет ответ 400 (или ошибку сервиса) и возвращает false.
+func (h *Handler) changedEvent(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger *zap.Logger,
+	req updateEventReq,
+) (models.Event, bool) {
+	loc, ok := h.location(w, r, logger, req.UserID, req.TZ)
+	if !ok {
+		return models.Event{}, false
+	}
+
+	channels, err := parseChannels(req.Channels)
+	if err != nil {
+		logger.Warn("некорректные каналы напоминаний", zap.Strings("channels", req.Channels))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	reminders, err := parseReminders(req.Reminders)
+	if err != nil {
+		logger.Warn("некорректные напоминания", zap.Strings("reminders", req.Reminders))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	day, end, err := parseEventTimes(req.Date, req.End, req.Duration, req.AllDay, loc)
+	if err != nil {
+		logger.Warn("некорректная дата", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	attendees, err := attendeesOf(req.Attendees)
+	if err != nil {
+		logger.Warn("некорректные участники", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	event := models.Event{
+		ID:         req.EventID,
+		UserID:     req.UserID,
+		CalendarID: req.CalendarID,
+		Date:       day,
+		End:        end,
+		AllDay:     req.AllDay,
+		TimeZone:   req.TZ,
+		Text:       req.Event,
+		Reminders:  reminders,
+		Channels:   channels,
+		RRule:      req.RRule,
+		Attendees:  attendees,
+		Resources:  resourcesOf(req.Resources),
+	}
+	if err := validators.ValidateUpdate(event); err != nil {
+		logger.Warn("некорректные данные события", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Event{}, false
+	}
+
+	return event, true
+}
+
+// checkConflicts - события пользователя, пересекающиеся с сохраняемым событием. При on_conflict=reject
+// и найденных пересечениях отвечает 409 со списком событий и возвращает false.
+func (h *Handler) checkConflicts(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger *zap.Logger,
+	event models.Event,
+	onConflict string,
+) ([]string, bool) {
+	policy := models.ConflictPolicy(strings.TrimSpace(onConflict))
+	if err := validators.ValidateConflictPolicy(policy); err != nil {
+		logger.Warn("некорректная реакция на пересечение", zap.String("on_conflict", onConflict))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	conflicts, err := h.svc.CheckConflicts(r.Context(), event)
+	if err != nil {
+		logger.Warn("ошибка при проверке пересечений", zap.Error(err))
+		serviceError(w, err)
+		return nil, false
+	}
+	if len(conflicts) > 0 && policy == models.ConflictReject {
+		logger.Info("событие пересекается с другими событиями", zap.Strings("conflicts", conflicts))
+		conflictError(w, conflicts)
+		return nil, false
+	}
+
+	return conflicts, true
+}
+
+// withConflicts - добавляет к ответу предупреждение о пересечениях, если они есть.
+func withConflicts(resp map[string]any, conflicts []string) map[string]any {
+	if len(conflicts) > 0 {
+		resp["warning"] = "событие пересекается с другими событиями"
+		resp["conflicts"] = conflicts
+	}
+
+	return resp
 }
 
 func (h *Handler) deleteEvent(w http.ResponseWriter, r *http.Request) {
@@ -109,9 +290,16 @@ func (h *Handler) deleteEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.DeleteEvent(r.Context(), req.EventID); err != nil {
+	scope := models.EditScope(req.Scope)
+	if err := validators.ValidateScope(scope); err != nil {
+		logger.Warn("некорректная область удаления", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.DeleteEvent(r.Context(), req.EventID, scope); err != nil {
 		logger.Warn("ошибка при удалении события", zap.String("event_id", req.EventID), zap.Error(err))
-		_ = httpx.HTTPError(w, http.StatusServiceUnavailable, "Сервис недоступен")
+		serviceError(w, err)
 		return
 	}
 
@@ -135,11 +323,23 @@ func (h *Handler) getEvents(
 	w http.ResponseWriter,
 	r *http.Request,
 	op string,
-	eventsFunc func(context.Context, int64, time.Time) ([]models.Event, error),
+	eventsFunc func(context.Context, int64, time.Time, ...string) ([]models.Event, error),
 ) {
 	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", op))
 
-	filter, ok := parseQuery(r)
+	uid, tz, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loc, ok := h.location(w, r, logger, uid, tz)
+	if !ok {
+		return
+	}
+
+	filter, ok := parseQuery(r, loc)
 	if !ok {
 		logger.Warn("некорректный запрос")
 		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректный запрос")
@@ -152,13 +352,119 @@ func (h *Handler) getEvents(
 			filter.UserID),
 	)
 
-	events, err := eventsFunc(r.Context(), filter.UserID, filter.Day)
+	events, err := eventsFunc(r.Context(), filter.UserID, filter.Day, parseCalendarIDs(r)...)
 	if err != nil {
 		logger.Warn("ошибка при получении событий", zap.Error(err))
-		_ = httpx.HTTPError(w, http.StatusServiceUnavailable, "Сервис недоступен")
+		serviceError(w, err)
 		return
 	}
 
 	logger.Info("события успешно получены", zap.String("user_id", strconv.FormatInt(filter.UserID, 10)))
 	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": events})
 }
+
+// defaultEvents - размер страницы выборки событий, если limit не задан.
+const defaultEvents = 50
+
+func (h *Handler) listEvents(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "ListEvents"))
+
+	uid, tz, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.listRange(w, r, logger, uid, tz)
+}
+
+// listRange - отвечает страницей событий пользователя uid за период из строки запроса
+// (см. parseRangeQuery) и cursor следующей страницы, если она есть.
+func (h *Handler) listRange(w http.ResponseWriter, r *http.Request, logger *zap.Logger, uid int64, tz string) {
+	loc, ok := h.location(w, r, logger, uid, tz)
+	if !ok {
+		return
+	}
+
+	query, err := parseRangeQuery(r, uid, loc)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("получен запрос на получение событий за период для пользователя %d", uid))
+
+	page, err := h.svc.ListEvents(r.Context(), query)
+	if err != nil {
+		logger.Warn("ошибка при получении событий", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	resp := map[string]any{"result": page.Events}
+	if page.More {
+		resp["next_cursor"] = encodeCursor(query, page.Events[len(page.Events)-1])
+	}
+	_ = httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) searchEvents(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "SearchEvents"))
+
+	uid, tz, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loc, ok := h.location(w, r, logger, uid, tz)
+	if !ok {
+		return
+	}
+
+	search, err := parseSearchQuery(r, uid, loc)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("получен запрос на поиск событий для пользователя %d", uid))
+
+	events, err := h.svc.SearchEvents(r.Context(), search)
+	if err != nil {
+		logger.Warn("ошибка при поиске событий", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": events})
+}
+
+// location - часовой пояс запроса: tz, иначе пояс из настроек пользователя, иначе пояс сервера.
+// При ошибке пишет ответ и возвращает false.
+func (h *Handler) location(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger *zap.Logger,
+	userID int64,
+	tz string,
+) (*time.Location, bool) {
+	if err := validators.ValidateTimeZone(tz); err != nil {
+		logger.Warn("некорректный часовой пояс", zap.String("tz", tz))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	loc, err := h.svc.Location(r.Context(), userID, tz)
+	if err != nil {
+		logger.Warn("ошибка при определении часового пояса", zap.Error(err))
+		serviceError(w, err)
+		return nil, false
+	}
+
+	return loc, true
+}
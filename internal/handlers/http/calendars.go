@@ -0,0 +1,156 @@
+package httphandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/handlers/validators"
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func (h *Handler) createCalendar(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "CreateCalendar"))
+
+	logger.Info("получен запрос на создание календаря")
+
+	cal, ok := decodeCalendar(w, r, logger)
+	if !ok {
+		return
+	}
+
+	created, err := h.svc.CreateCalendar(r.Context(), cal)
+	if err != nil {
+		logger.Warn("ошибка при создании календаря", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	logger.Info("календарь создан", zap.String("calendar_id", created.ID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": created})
+}
+
+func (h *Handler) updateCalendar(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "UpdateCalendar"))
+
+	logger.Info("получен запрос на изменение календаря")
+
+	cal, ok := decodeCalendar(w, r, logger)
+	if !ok {
+		return
+	}
+	if cal.ID == "" {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadCalendarID.Error())
+		return
+	}
+
+	found, err := h.svc.UpdateCalendar(r.Context(), cal)
+	if err != nil {
+		logger.Warn("ошибка при изменении календаря", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+	if !found {
+		_ = httpx.HTTPError(w, http.StatusNotFound, "Календарь не найден")
+		return
+	}
+
+	logger.Info("календарь изменен", zap.String("calendar_id", cal.ID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
+
+func (h *Handler) deleteCalendar(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "DeleteCalendar"))
+
+	logger.Info("получен запрос на удаление календаря")
+
+	var req deleteCalendarReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	userID := bindUser(r, req.UserID)
+	if userID <= 0 {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadUserID.Error())
+		return
+	}
+	if strings.TrimSpace(req.CalendarID) == "" {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadCalendarID.Error())
+		return
+	}
+
+	found, err := h.svc.DeleteCalendar(r.Context(), userID, strings.TrimSpace(req.CalendarID))
+	if err != nil {
+		logger.Warn("ошибка при удалении календаря", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+	if !found {
+		_ = httpx.HTTPError(w, http.StatusNotFound, "Календарь не найден")
+		return
+	}
+
+	logger.Info("календарь удален", zap.String("calendar_id", req.CalendarID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
+
+func (h *Handler) getCalendars(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "GetCalendars"))
+
+	uid, _, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	calendars, err := h.svc.ListCalendars(r.Context(), uid)
+	if err != nil {
+		logger.Warn("ошибка при получении календарей", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": calendars})
+}
+
+// decodeCalendar - разбирает и проверяет тело запроса на создание или изменение календаря.
+// При ошибке ответ уже отправлен.
+func decodeCalendar(w http.ResponseWriter, r *http.Request, logger *zap.Logger) (models.Calendar, bool) {
+	var req calendarReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return models.Calendar{}, false
+	}
+
+	reminders, err := parseReminders(req.Reminders)
+	if err != nil {
+		logger.Warn("некорректные напоминания", zap.Strings("reminders", req.Reminders))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Calendar{}, false
+	}
+
+	cal := models.Calendar{
+		ID:         strings.TrimSpace(req.CalendarID),
+		UserID:     bindUser(r, req.UserID),
+		Name:       strings.TrimSpace(req.Name),
+		Color:      req.Color,
+		Reminders:  reminders,
+		Visibility: models.CalendarVisibility(strings.TrimSpace(req.Visibility)),
+		NoOverlap:  req.NoOverlap,
+	}
+	if err := validators.ValidateCalendar(cal); err != nil {
+		logger.Warn("некорректный календарь", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Calendar{}, false
+	}
+
+	return cal, true
+}
@@ -0,0 +1,43 @@
+package httphandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
+)
+
+func (h *Handler) getFreeBusy(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "FreeBusy"))
+
+	// Границы рабочего дня считаются в поясе вызывающего: tz или его настройки.
+	tz := strings.TrimSpace(r.URL.Query().Get("tz"))
+	loc, ok := h.location(w, r, logger, bindUser(r, 0), tz)
+	if !ok {
+		return
+	}
+
+	query, err := parseFreeBusyQuery(r, loc)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info("получен запрос занятости пользователей", zap.Int64s("user_ids", query.UserIDs))
+
+	fb, err := h.svc.FreeBusy(r.Context(), query)
+	if err != nil {
+		logger.Warn("ошибка при расчете занятости", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	resp := map[string]any{"result": fb.Users}
+	if query.SlotDuration > 0 {
+		resp["free"] = fb.Free
+	}
+	_ = httpx.WriteJSON(w, http.StatusOK, resp)
+}
@@ -9,19 +9,59 @@ import (
 )
 
 type Handler struct {
-	svc    services.CalendarService
-	logger *zap.Logger
+	svc      services.CalendarService
+	webhooks services.WebhookService
+	logger   *zap.Logger
 }
 
-func New(svc services.CalendarService, logger *zap.Logger) *Handler {
-	return &Handler{svc: svc, logger: logger}
+func New(svc services.CalendarService, webhooks services.WebhookService, logger *zap.Logger) *Handler {
+	return &Handler{svc: svc, webhooks: webhooks, logger: logger}
 }
 
 func (h *Handler) RegisterCalendarHandlers(mux *http.ServeMux) {
 	mux.HandleFunc("POST /create_event", h.createEvent)
 	mux.HandleFunc("POST /update_event", h.updateEvent)
 	mux.HandleFunc("POST /delete_event", h.deleteEvent)
+	mux.HandleFunc("POST /invite_attendees", h.inviteAttendees)
+	mux.HandleFunc("POST /respond_event", h.respondEvent)
 	mux.HandleFunc("GET /events_for_day", h.getDayEvents)
 	mux.HandleFunc("GET /events_for_week", h.getWeekEvents)
 	mux.HandleFunc("GET /events_for_month", h.getMonthEvents)
+	mux.HandleFunc("GET /events", h.listEvents)
+	mux.HandleFunc("GET /events/search", h.searchEvents)
+	mux.HandleFunc("GET /freebusy", h.getFreeBusy)
+	mux.HandleFunc("GET /calendar.ics", h.exportICal)
+	mux.HandleFunc("POST /import", h.importICal)
+	mux.HandleFunc("GET /user_settings", h.getUserSettings)
+	mux.HandleFunc("POST /user_settings", h.saveUserSettings)
+	mux.HandleFunc("POST /grant_share", h.grantShare)
+	mux.HandleFunc("POST /revoke_share", h.revokeShare)
+	mux.HandleFunc("GET /shares", h.getShares)
+	mux.HandleFunc("POST /create_calendar", h.createCalendar)
+	mux.HandleFunc("POST /update_calendar", h.updateCalendar)
+	mux.HandleFunc("POST /delete_calendar", h.deleteCalendar)
+	mux.HandleFunc("GET /calendars", h.getCalendars)
+	mux.HandleFunc("POST /create_resource", h.createResource)
+	mux.HandleFunc("POST /update_resource", h.updateResource)
+	mux.HandleFunc("POST /delete_resource", h.deleteResource)
+	mux.HandleFunc("GET /resources", h.getResources)
+	mux.HandleFunc("GET /resource_bookings", h.getResourceBookings)
+}
+
+// RegisterCalendarV2Handlers - REST API событий: событие адресуется путем, действие задается методом.
+// Работает параллельно с RegisterCalendarHandlers и принимает только JSON.
+func (h *Handler) RegisterCalendarV2Handlers(mux *http.ServeMux) {
+	mux.HandleFunc("POST /v2/users/{uid}/events", h.createEventV2)
+	mux.HandleFunc("GET /v2/users/{uid}/events", h.listEventsV2)
+	mux.HandleFunc("GET /v2/events/{id}", h.getEventV2)
+	mux.HandleFunc("PUT /v2/events/{id}", h.putEventV2)
+	mux.HandleFunc("PATCH /v2/events/{id}", h.patchEventV2)
+	mux.HandleFunc("DELETE /v2/events/{id}", h.deleteEventV2)
+}
+
+func (h *Handler) RegisterWebhookHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("POST /create_webhook", h.createWebhook)
+	mux.HandleFunc("POST /delete_webhook", h.deleteWebhook)
+	mux.HandleFunc("GET /webhooks", h.getWebhooks)
+	mux.HandleFunc("GET /webhook_deliveries", h.getWebhookDeliveries)
 }
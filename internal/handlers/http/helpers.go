@@ -1,14 +1,20 @@
 package httphandlers
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
 	"github.com/sunr3d/simple-http-calendar/internal/handlers/validators"
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
 	"github.com/sunr3d/simple-http-calendar/models"
 )
 
@@ -23,18 +29,95 @@ func decodeBody(r *http.Request, dst any) error {
 		case *createEventReq:
 			uid, _ := strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
 			payload.UserID = uid
+			payload.CalendarID = strings.TrimSpace(r.Form.Get("calendar_id"))
 			payload.Date = strings.TrimSpace(r.Form.Get("date"))
+			payload.End = strings.TrimSpace(r.Form.Get("end"))
+			payload.Duration = strings.TrimSpace(r.Form.Get("duration"))
+			payload.AllDay = r.Form.Get("all_day") == "true"
+			payload.TZ = strings.TrimSpace(r.Form.Get("tz"))
 			payload.Event = r.Form.Get("event")
-			payload.Reminder = r.Form.Get("reminder") == "true"
+			payload.Reminders = r.Form["reminders"]
+			payload.Channels = r.Form["channels"]
+			payload.RRule = strings.TrimSpace(r.Form.Get("rrule"))
+			payload.ExDates = r.Form["exdates"]
+			payload.Attendees = formIDs(r.Form["attendees"])
+			payload.Resources = r.Form["resources"]
+			payload.OnConflict = strings.TrimSpace(r.Form.Get("on_conflict"))
 		case *updateEventReq:
 			uid, _ := strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
 			payload.EventID = strings.TrimSpace(r.Form.Get("event_id"))
 			payload.UserID = uid
+			payload.CalendarID = strings.TrimSpace(r.Form.Get("calendar_id"))
 			payload.Date = strings.TrimSpace(r.Form.Get("date"))
+			payload.End = strings.TrimSpace(r.Form.Get("end"))
+			payload.Duration = strings.TrimSpace(r.Form.Get("duration"))
+			payload.AllDay = r.Form.Get("all_day") == "true"
+			payload.TZ = strings.TrimSpace(r.Form.Get("tz"))
 			payload.Event = r.Form.Get("event")
-			payload.Reminder = r.Form.Get("reminder") == "true"
+			payload.Reminders = r.Form["reminders"]
+			payload.Channels = r.Form["channels"]
+			payload.RRule = strings.TrimSpace(r.Form.Get("rrule"))
+			payload.Scope = strings.TrimSpace(r.Form.Get("scope"))
+			payload.Attendees = formIDs(r.Form["attendees"])
+			payload.Resources = r.Form["resources"]
+			payload.OnConflict = strings.TrimSpace(r.Form.Get("on_conflict"))
+		case *inviteReq:
+			payload.EventID = strings.TrimSpace(r.Form.Get("event_id"))
+			payload.Attendees = formIDs(r.Form["attendees"])
+		case *respondReq:
+			payload.EventID = strings.TrimSpace(r.Form.Get("event_id"))
+			payload.UserID, _ = strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
+			payload.Status = strings.TrimSpace(r.Form.Get("status"))
 		case *deleteEventReq:
 			payload.EventID = strings.TrimSpace(r.Form.Get("event_id"))
+			payload.Scope = strings.TrimSpace(r.Form.Get("scope"))
+		case *userSettingsReq:
+			uid, _ := strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
+			payload.UserID = uid
+			payload.TZ = strings.TrimSpace(r.Form.Get("tz"))
+			payload.Channels = r.Form["channels"]
+			payload.Email = strings.TrimSpace(r.Form.Get("email"))
+			payload.WebhookURL = strings.TrimSpace(r.Form.Get("webhook_url"))
+		case *createWebhookReq:
+			uid, _ := strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
+			payload.UserID = uid
+			payload.URL = strings.TrimSpace(r.Form.Get("url"))
+			payload.Secret = r.Form.Get("secret")
+			payload.Types = r.Form["types"]
+		case *deleteWebhookReq:
+			uid, _ := strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
+			payload.UserID = uid
+			payload.WebhookID = strings.TrimSpace(r.Form.Get("webhook_id"))
+		case *calendarReq:
+			payload.CalendarID = strings.TrimSpace(r.Form.Get("calendar_id"))
+			payload.UserID, _ = strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
+			payload.Name = r.Form.Get("name")
+			payload.Color = strings.TrimSpace(r.Form.Get("color"))
+			payload.Reminders = r.Form["reminders"]
+			payload.Visibility = strings.TrimSpace(r.Form.Get("visibility"))
+			payload.NoOverlap = r.Form.Get("no_overlap") == "true"
+		case *deleteCalendarReq:
+			payload.UserID, _ = strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
+			payload.CalendarID = strings.TrimSpace(r.Form.Get("calendar_id"))
+		case *resourceReq:
+			payload.ResourceID = strings.TrimSpace(r.Form.Get("resource_id"))
+			payload.UserID, _ = strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
+			payload.Name = r.Form.Get("name")
+			payload.Kind = strings.TrimSpace(r.Form.Get("kind"))
+			payload.Capacity, _ = strconv.Atoi(strings.TrimSpace(r.Form.Get("capacity")))
+			payload.Location = r.Form.Get("location")
+			payload.MaxDuration = strings.TrimSpace(r.Form.Get("max_duration"))
+			payload.Horizon = strings.TrimSpace(r.Form.Get("horizon"))
+		case *deleteResourceReq:
+			payload.UserID, _ = strconv.ParseInt(strings.TrimSpace(r.Form.Get("user_id")), 10, 64)
+			payload.ResourceID = strings.TrimSpace(r.Form.Get("resource_id"))
+		case *grantShareReq:
+			payload.OwnerID, _ = strconv.ParseInt(strings.TrimSpace(r.Form.Get("owner_id")), 10, 64)
+			payload.GranteeID, _ = strconv.ParseInt(strings.TrimSpace(r.Form.Get("grantee_id")), 10, 64)
+			payload.Role = strings.TrimSpace(r.Form.Get("role"))
+		case *revokeShareReq:
+			payload.OwnerID, _ = strconv.ParseInt(strings.TrimSpace(r.Form.Get("owner_id")), 10, 64)
+			payload.GranteeID, _ = strconv.ParseInt(strings.TrimSpace(r.Form.Get("grantee_id")), 10, 64)
 		default:
 			return fmt.Errorf("неподдерживаемый payload")
 		}
@@ -48,20 +131,138 @@ func decodeBody(r *http.Request, dst any) error {
 	return decoder.Decode(dst)
 }
 
-func parseQuery(r *http.Request) (models.EventsByDay, bool) {
-	uidStr := strings.TrimSpace(r.URL.Query().Get("user_id"))
-	dateStr := strings.TrimSpace(r.URL.Query().Get("date"))
+// formIDs - user_id из повторяющегося поля формы; нечисловые значения становятся 0
+// и отклоняются валидацией. nil - поле не передано.
+func formIDs(values []string) []int64 {
+	if values == nil {
+		return nil
+	}
 
-	uid, err := strconv.ParseInt(uidStr, 10, 64)
-	if err != nil || uid <= 0 {
-		return models.EventsByDay{}, false
+	res := make([]int64, 0, len(values))
+	for _, v := range values {
+		id, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		res = append(res, id)
+	}
+
+	return res
+}
+
+// attendeesOf - участники по списку user_id; nil сохраняется, чтобы отличать "не передан" от пустого списка.
+func attendeesOf(ids []int64) ([]models.Attendee, error) {
+	if ids == nil {
+		return nil, nil
+	}
+
+	res := make([]models.Attendee, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, models.Attendee{UserID: id})
+	}
+
+	return res, validators.ValidateAttendees(res)
+}
+
+// resourcesOf - ID ресурсов без пробелов; nil сохраняется, чтобы отличать "не передан" от пустого списка.
+// Пустые значения отбрасываются: resources= в форме освобождает все ресурсы события.
+func resourcesOf(ids []string) []string {
+	if ids == nil {
+		return nil
+	}
+
+	res := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id = strings.TrimSpace(id); id != "" {
+			res = append(res, id)
+		}
+	}
+
+	return res
+}
+
+// parseUserQuery - разбирает user_id и tz из строки запроса.
+// Для аутентифицированного запроса user_id можно не передавать: берется пользователь из токена.
+func parseUserQuery(r *http.Request) (int64, string, error) {
+	var uid int64
+	raw := strings.TrimSpace(r.URL.Query().Get("user_id"))
+	if _, ok := auth.UserFrom(r.Context()); raw != "" || !ok {
+		var err error
+		uid, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || uid <= 0 {
+			return 0, "", validators.ErrBadUserID
+		}
+	}
+
+	tz := strings.TrimSpace(r.URL.Query().Get("tz"))
+	if err := validators.ValidateTimeZone(tz); err != nil {
+		return 0, "", err
+	}
+
+	return bindUser(r, uid), tz, nil
+}
+
+// bindUser - user_id запроса; если он не передан (0), - аутентифицированный пользователь.
+// Права на чужой user_id (доступ к календарю, владение подпиской) проверяют сервисы.
+func bindUser(r *http.Request, userID int64) int64 {
+	if caller, ok := auth.UserFrom(r.Context()); ok && userID == 0 {
+		return caller
+	}
+
+	return userID
+}
+
+// serviceError - ответ на ошибку сервиса: 400 с текстом ошибки, если сервис отклонил запрос
+// (models.ErrInvalid), 403 при отсутствии доступа, 409 со списком пересекающихся событий
+// при запрещенном пересечении или занятом ресурсе, 422 при брони вне правил ресурса,
+// иначе (хранилище или брокер недоступны) 503.
+func serviceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrInvalid) {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, auth.ErrForbidden) {
+		_ = httpx.HTTPError(w, http.StatusForbidden, auth.ErrForbidden.Error())
+		return
+	}
+	var conflict *models.ConflictError
+	if errors.As(err, &conflict) {
+		conflictError(w, conflict.EventIDs)
+		return
 	}
+	var booking *models.BookingError
+	if errors.As(err, &booking) {
+		_ = httpx.HTTPError(w, http.StatusUnprocessableEntity, booking.Error())
+		return
+	}
+	_ = httpx.HTTPError(w, http.StatusServiceUnavailable, "Сервис недоступен")
+}
+
+// conflictError - ответ 409 со списком событий, с которыми пересекается сохраняемое событие.
+func conflictError(w http.ResponseWriter, eventIDs []string) {
+	_ = httpx.WriteJSON(w, http.StatusConflict, map[string]any{
+		"error":     "событие пересекается с другими событиями",
+		"conflicts": eventIDs,
+	})
+}
 
-	date, err := time.ParseInLocation("2006-01-02", dateStr, time.UTC)
+// parseQuery - разбирает фильтр выборки. Дата YYYY-MM-DD трактуется в часовом поясе loc,
+// дата в RFC 3339 задает пояс своим смещением, если tz не передан явно.
+func parseQuery(r *http.Request, loc *time.Location) (models.EventsByDay, bool) {
+	uid, tz, err := parseUserQuery(r)
 	if err != nil {
 		return models.EventsByDay{}, false
 	}
 
+	dateStr := strings.TrimSpace(r.URL.Query().Get("date"))
+	date, err := time.ParseInLocation("2006-01-02", dateStr, loc)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, dateStr)
+		if err != nil {
+			return models.EventsByDay{}, false
+		}
+		if tz != "" {
+			date = date.In(loc)
+		}
+	}
+
 	filter := models.EventsByDay{UserID: uid, Day: date}
 	if err := validators.ValidateFilter(filter); err != nil {
 		return models.EventsByDay{}, false
@@ -69,3 +270,327 @@ func parseQuery(r *http.Request) (models.EventsByDay, bool) {
 
 	return filter, true
 }
+
+// defaultCalendar - значение calendar_id в запросах, выбирающее календарь по умолчанию.
+const defaultCalendar = "default"
+
+// parseCalendarIDs - календари выборки из параметров calendar_id (повторяющихся или через запятую).
+// nil - параметр не передан, выбираются все календари.
+func parseCalendarIDs(r *http.Request) []string {
+	values, ok := r.URL.Query()["calendar_id"]
+	if !ok {
+		return nil
+	}
+
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		for _, id := range strings.Split(v, ",") {
+			id = strings.TrimSpace(id)
+			if id == defaultCalendar {
+				id = ""
+			} else if id == "" {
+				continue
+			}
+			if !slices.Contains(res, id) {
+				res = append(res, id)
+			}
+		}
+	}
+
+	return res
+}
+
+// parseDates - разбирает список дат в формате YYYY-MM-DDTHH:MM:SS (в поясе loc) или RFC 3339.
+func parseDates(values []string, loc *time.Location) ([]time.Time, error) {
+	res := make([]time.Time, 0, len(values))
+	for _, v := range values {
+		t, err := parseEventTime(v, false, loc)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+
+	return res, nil
+}
+
+// parseEventTimes - разбирает начало и окончание события.
+// Окончание задается либо датой end, либо длительностью duration (90m, 1h30m, 2d).
+// Для событий на весь день допускаются даты без времени, end - последний день включительно.
+// Время без смещения трактуется в часовом поясе loc.
+func parseEventTimes(dateStr, endStr, durationStr string, allDay bool, loc *time.Location) (time.Time, time.Time, error) {
+	if endStr != "" && durationStr != "" {
+		return time.Time{}, time.Time{}, validators.ErrBadEnd
+	}
+
+	start, err := parseEventTime(dateStr, allDay, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, validators.ErrBadDateTime
+	}
+
+	var end time.Time
+	switch {
+	case endStr != "":
+		end, err = parseEventTime(endStr, allDay, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, validators.ErrBadEnd
+		}
+		if allDay {
+			end = end.AddDate(0, 0, 1)
+		}
+	case durationStr != "":
+		d, err := models.ParseDuration(durationStr)
+		if err != nil || d < 0 {
+			return time.Time{}, time.Time{}, validators.ErrBadDuration
+		}
+		end = start.Add(d)
+	}
+
+	return start, end, nil
+}
+
+// parseChannels - разбирает список каналов напоминаний.
+func parseChannels(values []string) ([]models.Channel, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	res := make([]models.Channel, 0, len(values))
+	for _, v := range values {
+		res = append(res, models.Channel(strings.TrimSpace(v)))
+	}
+
+	return res, validators.ValidateChannels(res)
+}
+
+// parseReminders - разбирает смещения напоминаний (-1d, -15m).
+// nil - напоминания не переданы; пустые значения дают пустой список, который удаляет напоминания.
+func parseReminders(values []string) ([]models.Reminder, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	res := make([]models.Reminder, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		d, err := models.ParseDuration(v)
+		if err != nil {
+			return nil, validators.ErrBadReminders
+		}
+		res = append(res, models.Reminder{Offset: models.Offset(d)})
+	}
+
+	return res, validators.ValidateReminders(res)
+}
+
+func parseEventTime(s string, allDay bool, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if allDay {
+		if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation("2006-01-02T15:04:05", s, loc)
+}
+
+// parseRangeQuery - разбирает постраничную выборку событий: from/to (YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS
+// в поясе loc или RFC 3339), sort, limit и cursor предыдущей страницы.
+func parseRangeQuery(r *http.Request, userID int64, loc *time.Location) (models.EventsRange, error) {
+	q := r.URL.Query()
+
+	from, err := parseEventTime(q.Get("from"), true, loc)
+	if err != nil {
+		return models.EventsRange{}, validators.ErrBadRange
+	}
+	to, err := parseEventTime(q.Get("to"), true, loc)
+	if err != nil {
+		return models.EventsRange{}, validators.ErrBadRange
+	}
+
+	query := models.EventsRange{
+		UserID:      userID,
+		CalendarIDs: parseCalendarIDs(r),
+		From:        from,
+		To:          to,
+		Sort:        models.SortOrder(strings.TrimSpace(q.Get("sort"))),
+		Limit:       defaultEvents,
+	}
+	if query.Sort == "" {
+		query.Sort = models.SortAsc
+	}
+	if s := strings.TrimSpace(q.Get("limit")); s != "" {
+		if query.Limit, err = strconv.Atoi(s); err != nil {
+			return models.EventsRange{}, validators.ErrBadLimit
+		}
+	}
+	if err := validators.ValidateEventsRange(query); err != nil {
+		return models.EventsRange{}, err
+	}
+
+	if s := strings.TrimSpace(q.Get("cursor")); s != "" {
+		cursor, err := decodeCursor(s)
+		if err != nil || cursor.Filter != rangeFilter(query) {
+			return models.EventsRange{}, validators.ErrBadCursor
+		}
+		query.After = &models.EventKey{Date: time.Unix(0, cursor.Date), ID: cursor.ID}
+	}
+
+	return query, nil
+}
+
+// parseSearchQuery - разбирает полнотекстовый поиск: q, необязательные from/to (как в parseRangeQuery), archived и limit.
+func parseSearchQuery(r *http.Request, userID int64, loc *time.Location) (models.EventsSearch, error) {
+	q := r.URL.Query()
+
+	search := models.EventsSearch{
+		UserID:      userID,
+		CalendarIDs: parseCalendarIDs(r),
+		Query:       strings.TrimSpace(q.Get("q")),
+		Limit:       defaultEvents,
+	}
+
+	var err error
+	if s := strings.TrimSpace(q.Get("from")); s != "" {
+		if search.From, err = parseEventTime(s, true, loc); err != nil {
+			return models.EventsSearch{}, validators.ErrBadRange
+		}
+	}
+	if s := strings.TrimSpace(q.Get("to")); s != "" {
+		if search.To, err = parseEventTime(s, true, loc); err != nil {
+			return models.EventsSearch{}, validators.ErrBadRange
+		}
+	}
+	if s := strings.TrimSpace(q.Get("archived")); s != "" {
+		if search.Archived, err = strconv.ParseBool(s); err != nil {
+			return models.EventsSearch{}, validators.ErrBadArchived
+		}
+	}
+	if s := strings.TrimSpace(q.Get("limit")); s != "" {
+		if search.Limit, err = strconv.Atoi(s); err != nil {
+			return models.EventsSearch{}, validators.ErrBadLimit
+		}
+	}
+
+	return search, validators.ValidateEventsSearch(search)
+}
+
+// Рабочие часы поиска свободных промежутков, если work_start/work_end не заданы.
+const (
+	defaultWorkStart = 9 * time.Hour
+	defaultWorkEnd   = 18 * time.Hour
+)
+
+// parseFreeBusyQuery - разбирает запрос занятости: user_ids (повторяющийся параметр или через запятую),
+// from/to (как в parseRangeQuery) и для поиска свободных промежутков duration, work_start/work_end (HH:MM)
+// и weekends.
+func parseFreeBusyQuery(r *http.Request, loc *time.Location) (models.FreeBusyQuery, error) {
+	q := r.URL.Query()
+
+	query := models.FreeBusyQuery{WorkStart: defaultWorkStart, WorkEnd: defaultWorkEnd}
+	for _, v := range q["user_ids"] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return models.FreeBusyQuery{}, validators.ErrBadUserIDs
+			}
+			query.UserIDs = append(query.UserIDs, id)
+		}
+	}
+
+	var err error
+	if query.From, err = parseEventTime(q.Get("from"), true, loc); err != nil {
+		return models.FreeBusyQuery{}, validators.ErrBadRange
+	}
+	if query.To, err = parseEventTime(q.Get("to"), true, loc); err != nil {
+		return models.FreeBusyQuery{}, validators.ErrBadRange
+	}
+
+	if s := strings.TrimSpace(q.Get("duration")); s != "" {
+		if query.SlotDuration, err = models.ParseDuration(s); err != nil || query.SlotDuration <= 0 {
+			return models.FreeBusyQuery{}, validators.ErrBadSlot
+		}
+	}
+	if s := strings.TrimSpace(q.Get("work_start")); s != "" {
+		if query.WorkStart, err = parseClock(s); err != nil {
+			return models.FreeBusyQuery{}, validators.ErrBadWorkHours
+		}
+	}
+	if s := strings.TrimSpace(q.Get("work_end")); s != "" {
+		if query.WorkEnd, err = parseClock(s); err != nil {
+			return models.FreeBusyQuery{}, validators.ErrBadWorkHours
+		}
+	}
+	if s := strings.TrimSpace(q.Get("weekends")); s != "" {
+		if query.Weekends, err = strconv.ParseBool(s); err != nil {
+			return models.FreeBusyQuery{}, validators.ErrBadWorkHours
+		}
+	}
+
+	return query, validators.ValidateFreeBusy(query)
+}
+
+// parseClock - время суток HH:MM как смещение от полуночи; 24:00 - конец суток.
+func parseClock(s string) (time.Duration, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	if !ok || len(mm) != 2 {
+		return 0, validators.ErrBadWorkHours
+	}
+	h, err := strconv.Atoi(hh)
+	if err != nil {
+		return 0, validators.ErrBadWorkHours
+	}
+	m, err := strconv.Atoi(mm)
+	if err != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, validators.ErrBadWorkHours
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// encodeCursor - cursor страницы выборки query, следующей за событием last.
+func encodeCursor(query models.EventsRange, last models.Event) string {
+	data, _ := json.Marshal(eventsCursor{
+		Date:   last.Date.UnixNano(),
+		ID:     last.ID,
+		Filter: rangeFilter(query),
+	})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// rangeFilter - отпечаток выборки: пользователь, календари, период и сортировка.
+// Порядок календарей в запросе на выборку не влияет.
+func rangeFilter(query models.EventsRange) string {
+	calendars := slices.Clone(query.CalendarIDs)
+	slices.Sort(calendars)
+
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d|%t|%q|%d|%d|%s",
+		query.UserID, calendars == nil, calendars, query.From.UnixNano(), query.To.UnixNano(), query.Sort))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func decodeCursor(s string) (eventsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return eventsCursor{}, err
+	}
+
+	var cursor eventsCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return eventsCursor{}, err
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,114 @@
+package httphandlers
+
+import (
+	"bytes"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
+	"github.com/sunr3d/simple-http-calendar/internal/ical"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// maxImportBytes - ограничение размера импортируемого файла.
+const maxImportBytes = 10 << 20
+
+func (h *Handler) exportICal(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "ExportICal"))
+
+	uid, _, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info("получен запрос на выгрузку календаря", zap.Int64("user_id", uid))
+
+	events, err := h.svc.GetAllEvents(r.Context(), uid)
+	if err != nil {
+		logger.Warn("ошибка при получении событий", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(events); err != nil {
+		logger.Warn("ошибка при сериализации календаря", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+ical.Filename(uid)+`"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logger.Warn("не удалось записать тело ответа", zap.Error(err))
+		return
+	}
+
+	logger.Info("календарь успешно выгружен", zap.Int64("user_id", uid), zap.Int("events", len(events)))
+}
+
+func (h *Handler) importICal(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "ImportICal"))
+
+	uid, _, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info("получен запрос на импорт календаря", zap.Int64("user_id", uid))
+
+	items, err := ical.Decode(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil && len(items) == 0 {
+		logger.Warn("некорректный iCalendar", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректный iCalendar: "+err.Error())
+		return
+	}
+
+	results := make([]models.ImportResult, len(items))
+	events := make([]models.Event, 0, len(items))
+	for i, item := range items {
+		if item.Err != nil {
+			results[i] = models.ImportResult{UID: item.UID, Status: models.ImportFailed, Error: item.Err.Error()}
+			continue
+		}
+		events = append(events, item.Event)
+	}
+
+	imported, err := h.svc.ImportEvents(r.Context(), uid, events)
+	if err != nil {
+		logger.Warn("ошибка при импорте событий", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	summary := map[models.ImportStatus]int{}
+	next := 0
+	for i, item := range items {
+		if item.Err == nil {
+			results[i] = imported[next]
+			next++
+		}
+		summary[results[i].Status]++
+	}
+
+	logger.Info("импорт календаря завершен",
+		zap.Int64("user_id", uid),
+		zap.Int("created", summary[models.ImportCreated]),
+		zap.Int("updated", summary[models.ImportUpdated]),
+		zap.Int("duplicates", summary[models.ImportDuplicate]),
+		zap.Int("failed", summary[models.ImportFailed]),
+	)
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": map[string]any{
+		"created":    summary[models.ImportCreated],
+		"updated":    summary[models.ImportUpdated],
+		"duplicates": summary[models.ImportDuplicate],
+		"failed":     summary[models.ImportFailed],
+		"items":      results,
+	}})
+}
@@ -1,20 +1,152 @@
 package httphandlers
 
+import "github.com/sunr3d/simple-http-calendar/models"
+
 type createEventReq struct {
-	UserID   int64  `json:"user_id"`
-	Date     string `json:"date"`
-	Event    string `json:"event"`
-	Reminder bool   `json:"reminder"`
+	UserID     int64    `json:"user_id"`
+	CalendarID string   `json:"calendar_id,omitempty"`
+	Date       string   `json:"date"`
+	End        string   `json:"end,omitempty"`
+	Duration   string   `json:"duration,omitempty"`
+	AllDay     bool     `json:"all_day,omitempty"`
+	TZ         string   `json:"tz,omitempty"`
+	Event      string   `json:"event"`
+	Reminders  []string `json:"reminders,omitempty"`
+	Channels   []string `json:"channels,omitempty"`
+	RRule      string   `json:"rrule,omitempty"`
+	ExDates    []string `json:"exdates,omitempty"`
+	Attendees  []int64  `json:"attendees,omitempty"`
+	Resources  []string `json:"resources,omitempty"`
+	OnConflict string   `json:"on_conflict,omitempty"`
 }
 
 type updateEventReq struct {
-	EventID  string `json:"event_id"`
-	UserID   int64  `json:"user_id"`
-	Date     string `json:"date"`
-	Event    string `json:"event"`
-	Reminder bool   `json:"reminder"`
+	EventID    string   `json:"event_id"`
+	UserID     int64    `json:"user_id"`
+	CalendarID string   `json:"calendar_id,omitempty"`
+	Date       string   `json:"date"`
+	End        string   `json:"end,omitempty"`
+	Duration   string   `json:"duration,omitempty"`
+	AllDay     bool     `json:"all_day,omitempty"`
+	TZ         string   `json:"tz,omitempty"`
+	Event      string   `json:"event"`
+	Reminders  []string `json:"reminders,omitempty"`
+	Channels   []string `json:"channels,omitempty"`
+	RRule      string   `json:"rrule,omitempty"`
+	Scope      string   `json:"scope,omitempty"`
+	Attendees  []int64  `json:"attendees,omitempty"`
+	Resources  []string `json:"resources,omitempty"`
+	OnConflict string   `json:"on_conflict,omitempty"`
+}
+
+// eventV2Req - тело запросов v2 API: создания, замены (PUT) и частичного изменения (PATCH) события.
+// Пользователь и событие задаются путем, scope и on_conflict - параметрами строки запроса.
+type eventV2Req struct {
+	CalendarID string   `json:"calendar_id,omitempty"`
+	Date       string   `json:"date"`
+	End        string   `json:"end,omitempty"`
+	Duration   string   `json:"duration,omitempty"`
+	AllDay     bool     `json:"all_day,omitempty"`
+	TZ         string   `json:"tz,omitempty"`
+	Event      string   `json:"event"`
+	Reminders  []string `json:"reminders,omitempty"`
+	Channels   []string `json:"channels,omitempty"`
+	RRule      string   `json:"rrule,omitempty"`
+	ExDates    []string `json:"exdates,omitempty"`
+	Attendees  []int64  `json:"attendees,omitempty"`
+	Resources  []string `json:"resources,omitempty"`
+}
+
+type inviteReq struct {
+	EventID   string  `json:"event_id"`
+	Attendees []int64 `json:"attendees"`
+}
+
+type respondReq struct {
+	EventID string `json:"event_id"`
+	UserID  int64  `json:"user_id"`
+	Status  string `json:"status"`
 }
 
 type deleteEventReq struct {
 	EventID string `json:"event_id"`
+	Scope   string `json:"scope,omitempty"`
+}
+
+type userSettingsReq struct {
+	UserID     int64    `json:"user_id"`
+	TZ         string   `json:"tz"`
+	Channels   []string `json:"channels,omitempty"`
+	Email      string   `json:"email,omitempty"`
+	WebhookURL string   `json:"webhook_url,omitempty"`
+}
+
+type createWebhookReq struct {
+	UserID int64    `json:"user_id"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Types  []string `json:"types,omitempty"`
+}
+
+type deleteWebhookReq struct {
+	UserID    int64  `json:"user_id"`
+	WebhookID string `json:"webhook_id"`
+}
+
+type grantShareReq struct {
+	OwnerID   int64  `json:"owner_id"`
+	GranteeID int64  `json:"grantee_id"`
+	Role      string `json:"role"`
+}
+
+type revokeShareReq struct {
+	OwnerID   int64 `json:"owner_id"`
+	GranteeID int64 `json:"grantee_id"`
+}
+
+// calendarReq - создание (без calendar_id) или изменение календаря.
+type calendarReq struct {
+	CalendarID string   `json:"calendar_id,omitempty"`
+	UserID     int64    `json:"user_id"`
+	Name       string   `json:"name"`
+	Color      string   `json:"color,omitempty"`
+	Reminders  []string `json:"reminders,omitempty"`
+	Visibility string   `json:"visibility,omitempty"`
+	NoOverlap  bool     `json:"no_overlap,omitempty"`
+}
+
+type deleteCalendarReq struct {
+	UserID     int64  `json:"user_id"`
+	CalendarID string `json:"calendar_id"`
+}
+
+// resourceReq - создание (без resource_id) или изменение ресурса.
+type resourceReq struct {
+	ResourceID  string `json:"resource_id,omitempty"`
+	UserID      int64  `json:"user_id"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind,omitempty"`
+	Capacity    int    `json:"capacity,omitempty"`
+	Location    string `json:"location,omitempty"`
+	MaxDuration string `json:"max_duration,omitempty"`
+	Horizon     string `json:"horizon,omitempty"`
+}
+
+type deleteResourceReq struct {
+	UserID     int64  `json:"user_id"`
+	ResourceID string `json:"resource_id"`
+}
+
+// createWebhookResp - созданная подписка. Секрет отдается только в этом ответе.
+type createWebhookResp struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// eventsCursor - позиция последнего события страницы, зашифрованная в непрозрачный cursor.
+// Filter - отпечаток выборки (см. rangeFilter): cursor нельзя применить к другой выборке.
+type eventsCursor struct {
+	Date   int64  `json:"d"`
+	ID     string `json:"i"`
+	Filter string `json:"h"`
 }
@@ -0,0 +1,194 @@
+package httphandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/handlers/validators"
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func (h *Handler) createResource(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "CreateResource"))
+
+	logger.Info("получен запрос на создание ресурса")
+
+	res, ok := decodeResource(w, r, logger)
+	if !ok {
+		return
+	}
+
+	created, err := h.svc.CreateResource(r.Context(), res)
+	if err != nil {
+		logger.Warn("ошибка при создании ресурса", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	logger.Info("ресурс создан", zap.String("resource_id", created.ID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": created})
+}
+
+func (h *Handler) updateResource(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "UpdateResource"))
+
+	logger.Info("получен запрос на изменение ресурса")
+
+	res, ok := decodeResource(w, r, logger)
+	if !ok {
+		return
+	}
+	if res.ID == "" {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadResourceID.Error())
+		return
+	}
+
+	found, err := h.svc.UpdateResource(r.Context(), res)
+	if err != nil {
+		logger.Warn("ошибка при изменении ресурса", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+	if !found {
+		_ = httpx.HTTPError(w, http.StatusNotFound, "Ресурс не найден")
+		return
+	}
+
+	logger.Info("ресурс изменен", zap.String("resource_id", res.ID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
+
+func (h *Handler) deleteResource(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "DeleteResource"))
+
+	logger.Info("получен запрос на удаление ресурса")
+
+	var req deleteResourceReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	userID := bindUser(r, req.UserID)
+	if userID <= 0 {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadUserID.Error())
+		return
+	}
+	resourceID := strings.TrimSpace(req.ResourceID)
+	if resourceID == "" {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadResourceID.Error())
+		return
+	}
+
+	found, err := h.svc.DeleteResource(r.Context(), userID, resourceID)
+	if err != nil {
+		logger.Warn("ошибка при удалении ресурса", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+	if !found {
+		_ = httpx.HTTPError(w, http.StatusNotFound, "Ресурс не найден")
+		return
+	}
+
+	logger.Info("ресурс удален", zap.String("resource_id", resourceID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
+
+func (h *Handler) getResources(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "GetResources"))
+
+	resources, err := h.svc.ListResources(r.Context())
+	if err != nil {
+		logger.Warn("ошибка при получении ресурсов", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": resources})
+}
+
+// getResourceBookings - календарь ресурса: брони за период from/to в поясе вызывающего.
+func (h *Handler) getResourceBookings(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "ResourceBookings"))
+
+	q := r.URL.Query()
+	tz := strings.TrimSpace(q.Get("tz"))
+	loc, ok := h.location(w, r, logger, bindUser(r, 0), tz)
+	if !ok {
+		return
+	}
+
+	resourceID := strings.TrimSpace(q.Get("resource_id"))
+	if resourceID == "" {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadResourceID.Error())
+		return
+	}
+	from, errFrom := parseEventTime(q.Get("from"), true, loc)
+	to, errTo := parseEventTime(q.Get("to"), true, loc)
+	if errFrom != nil || errTo != nil || !from.Before(to) || to.Sub(from) > validators.MaxRange {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadRange.Error())
+		return
+	}
+
+	bookings, err := h.svc.ResourceBookings(r.Context(), resourceID, from, to)
+	if err != nil {
+		logger.Warn("ошибка при получении броней ресурса", zap.String("resource_id", resourceID), zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": bookings})
+}
+
+// decodeResource - разбирает и проверяет тело запроса на создание или изменение ресурса.
+// При ошибке ответ уже отправлен.
+func decodeResource(w http.ResponseWriter, r *http.Request, logger *zap.Logger) (models.Resource, bool) {
+	var req resourceReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return models.Resource{}, false
+	}
+
+	var policy models.BookingPolicy
+	if req.MaxDuration != "" {
+		d, err := models.ParseDuration(req.MaxDuration)
+		if err != nil {
+			_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadBookingPolicy.Error())
+			return models.Resource{}, false
+		}
+		policy.MaxDuration = models.Offset(d)
+	}
+	if req.Horizon != "" {
+		d, err := models.ParseDuration(req.Horizon)
+		if err != nil {
+			_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadBookingPolicy.Error())
+			return models.Resource{}, false
+		}
+		policy.Horizon = models.Offset(d)
+	}
+
+	res := models.Resource{
+		ID:       strings.TrimSpace(req.ResourceID),
+		UserID:   bindUser(r, req.UserID),
+		Name:     strings.TrimSpace(req.Name),
+		Kind:     models.ResourceKind(strings.TrimSpace(req.Kind)),
+		Capacity: req.Capacity,
+		Location: strings.TrimSpace(req.Location),
+		Policy:   policy,
+	}
+	if err := validators.ValidateResource(res); err != nil {
+		logger.Warn("некорректный ресурс", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return models.Resource{}, false
+	}
+
+	return res, true
+}
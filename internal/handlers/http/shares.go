@@ -0,0 +1,108 @@
+package httphandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/handlers/validators"
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func (h *Handler) grantShare(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "GrantShare"))
+
+	logger.Info("получен запрос на выдачу доступа к календарю")
+
+	var req grantShareReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	share := models.Share{
+		OwnerID:   bindUser(r, req.OwnerID),
+		GranteeID: req.GranteeID,
+		Role:      models.ShareRole(strings.TrimSpace(req.Role)),
+	}
+	if err := validators.ValidateShare(share); err != nil {
+		logger.Warn("некорректный доступ к календарю", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.GrantShare(r.Context(), share); err != nil {
+		logger.Warn("ошибка при выдаче доступа к календарю", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	logger.Info("доступ к календарю выдан",
+		zap.Int64("owner_id", share.OwnerID),
+		zap.Int64("grantee_id", share.GranteeID),
+		zap.String("role", string(share.Role)),
+	)
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
+
+func (h *Handler) getShares(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "GetShares"))
+
+	uid, _, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	shares, err := h.svc.ListShares(r.Context(), uid)
+	if err != nil {
+		logger.Warn("ошибка при получении доступов к календарю", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": shares})
+}
+
+func (h *Handler) revokeShare(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "RevokeShare"))
+
+	logger.Info("получен запрос на отзыв доступа к календарю")
+
+	var req revokeShareReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	ownerID := bindUser(r, req.OwnerID)
+	if ownerID <= 0 {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadUserID.Error())
+		return
+	}
+	if req.GranteeID <= 0 {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadGrantee.Error())
+		return
+	}
+
+	ok, err := h.svc.RevokeShare(r.Context(), ownerID, req.GranteeID)
+	if err != nil {
+		logger.Warn("ошибка при отзыве доступа к календарю", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+	if !ok {
+		_ = httpx.HTTPError(w, http.StatusNotFound, "Доступ не найден")
+		return
+	}
+
+	logger.Info("доступ к календарю отозван", zap.Int64("owner_id", ownerID), zap.Int64("grantee_id", req.GranteeID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
@@ -0,0 +1,75 @@
+package httphandlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/handlers/validators"
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func (h *Handler) getUserSettings(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "GetUserSettings"))
+
+	uid, _, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	settings, err := h.svc.GetUserSettings(r.Context(), uid)
+	if err != nil {
+		logger.Warn("ошибка при получении настроек", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": settings})
+}
+
+func (h *Handler) saveUserSettings(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "SaveUserSettings"))
+
+	logger.Info("получен запрос на сохранение настроек пользователя")
+
+	var req userSettingsReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	req.UserID = bindUser(r, req.UserID)
+
+	channels, err := parseChannels(req.Channels)
+	if err != nil {
+		logger.Warn("некорректные каналы напоминаний", zap.Strings("channels", req.Channels))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	settings := models.UserSettings{
+		UserID:     req.UserID,
+		TimeZone:   req.TZ,
+		Channels:   channels,
+		Email:      req.Email,
+		WebhookURL: req.WebhookURL,
+	}
+	if err := validators.ValidateUserSettings(settings); err != nil {
+		logger.Warn("некорректные настройки пользователя", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.svc.SaveUserSettings(r.Context(), settings); err != nil {
+		logger.Warn("ошибка при сохранении настроек", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	logger.Info("настройки пользователя сохранены", zap.Int64("user_id", req.UserID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
@@ -0,0 +1,352 @@
+package httphandlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/handlers/validators"
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func (h *Handler) createEventV2(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "CreateEventV2"))
+
+	logger.Info("получен запрос на создание события")
+
+	uid, err := pathUser(r)
+	if err != nil {
+		logger.Warn("некорректный user_id в пути", zap.String("uid", r.PathValue("uid")))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var body eventV2Req
+	if err := decodeBody(r, &body); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	event, ok := h.newEvent(w, r, logger, body.create(uid))
+	if !ok {
+		return
+	}
+
+	conflicts, ok := h.checkConflicts(w, r, logger, event, r.URL.Query().Get("on_conflict"))
+	if !ok {
+		return
+	}
+
+	id, err := h.svc.CreateEvent(r.Context(), event)
+	if err != nil {
+		logger.Warn("ошибка при создании события", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	created, err := h.svc.GetEvent(r.Context(), id)
+	if err != nil {
+		logger.Warn("ошибка при чтении созданного события", zap.String("event_id", id), zap.Error(err))
+		v2Error(w, err)
+		return
+	}
+
+	logger.Info("событие успешно создано", zap.String("event_id", id))
+	w.Header().Set("Location", eventLocation(id))
+	_ = httpx.WriteJSON(w, http.StatusCreated, withConflicts(map[string]any{"result": created}, conflicts))
+}
+
+func (h *Handler) getEventV2(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "GetEventV2"))
+
+	event, ok := h.currentEvent(w, r, logger)
+	if !ok {
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": event})
+}
+
+// putEventV2 - заменяет событие целиком: не переданные напоминания, каналы, участники
+// и ресурсы удаляются. Календарь, часовой пояс и правило повторения без значения не меняются.
+func (h *Handler) putEventV2(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "PutEventV2"))
+
+	logger.Info("получен запрос на замену события")
+
+	current, ok := h.currentEvent(w, r, logger)
+	if !ok {
+		return
+	}
+
+	var body eventV2Req
+	if err := decodeBody(r, &body); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+	if body.Reminders == nil {
+		body.Reminders = []string{}
+	}
+	if body.Attendees == nil {
+		body.Attendees = []int64{}
+	}
+	if body.Resources == nil {
+		body.Resources = []string{}
+	}
+
+	h.updateEventV2(w, r, logger, current, body, true)
+}
+
+// patchEventV2 - изменяет только переданные поля события. Перенос начала без end и duration
+// сохраняет длительность события; списки заменяются, если переданы.
+func (h *Handler) patchEventV2(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "PatchEventV2"))
+
+	logger.Info("получен запрос на частичное изменение события")
+
+	current, ok := h.currentEvent(w, r, logger)
+	if !ok {
+		return
+	}
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Warn("ошибка при чтении тела запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	body := patchBase(current)
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+	// Явно переданное окончание заменяет длительность из текущего события.
+	if _, ok := fields["end"]; ok {
+		if _, ok := fields["duration"]; !ok {
+			body.Duration = ""
+		}
+	}
+
+	h.updateEventV2(w, r, logger, current, body, false)
+}
+
+// updateEventV2 - сохраняет изменение события из тела v2 запроса и отвечает событием после изменения.
+// replace - запрос заменяет событие целиком (PUT), и не переданные каналы напоминаний удаляются.
+// Если изменение вынесло вхождение из серии (scope=following или all), событие по этому ID
+// больше не существует, и ответ - 204 без тела.
+func (h *Handler) updateEventV2(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger *zap.Logger,
+	current models.Event,
+	body eventV2Req,
+	replace bool,
+) {
+	id := r.PathValue("id")
+	if body.ExDates != nil {
+		logger.Warn("исключения серии в запросе на изменение", zap.String("event_id", id))
+		_ = httpx.HTTPError(w, http.StatusBadRequest,
+			"exdates задаются только при создании серии, вхождение удаляется через DELETE /v2/events/{id}")
+		return
+	}
+
+	event, ok := h.changedEvent(w, r, logger, body.update(id, current.UserID))
+	if !ok {
+		return
+	}
+	if replace && event.Channels == nil {
+		event.Channels = []models.Channel{}
+	}
+
+	scope := models.EditScope(strings.TrimSpace(r.URL.Query().Get("scope")))
+	if err := validators.ValidateScope(scope); err != nil {
+		logger.Warn("некорректная область изменения", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	conflicts, ok := h.checkConflicts(w, r, logger, event, r.URL.Query().Get("on_conflict"))
+	if !ok {
+		return
+	}
+
+	if err := h.svc.UpdateEvent(r.Context(), event, scope); err != nil {
+		logger.Warn("ошибка при обновлении события", zap.String("event_id", id), zap.Error(err))
+		v2Error(w, err)
+		return
+	}
+
+	logger.Info("событие успешно обновлено", zap.String("event_id", id))
+
+	updated, err := h.svc.GetEvent(r.Context(), id)
+	if errors.Is(err, models.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		logger.Warn("ошибка при чтении измененного события", zap.String("event_id", id), zap.Error(err))
+		v2Error(w, err)
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, withConflicts(map[string]any{"result": updated}, conflicts))
+}
+
+func (h *Handler) deleteEventV2(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "DeleteEventV2"))
+
+	logger.Info("получен запрос на удаление события")
+
+	if _, ok := h.currentEvent(w, r, logger); !ok {
+		return
+	}
+
+	scope := models.EditScope(strings.TrimSpace(r.URL.Query().Get("scope")))
+	if err := validators.ValidateScope(scope); err != nil {
+		logger.Warn("некорректная область удаления", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := h.svc.DeleteEvent(r.Context(), id, scope); err != nil {
+		logger.Warn("ошибка при удалении события", zap.String("event_id", id), zap.Error(err))
+		v2Error(w, err)
+		return
+	}
+
+	logger.Info("событие успешно удалено", zap.String("event_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) listEventsV2(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "ListEventsV2"))
+
+	uid, err := pathUser(r)
+	if err != nil {
+		logger.Warn("некорректный user_id в пути", zap.String("uid", r.PathValue("uid")))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.listRange(w, r, logger, uid, strings.TrimSpace(r.URL.Query().Get("tz")))
+}
+
+// currentEvent - событие по ID из пути запроса. При ошибке пишет ответ (404, если события нет)
+// и возвращает false.
+func (h *Handler) currentEvent(w http.ResponseWriter, r *http.Request, logger *zap.Logger) (models.Event, bool) {
+	id := r.PathValue("id")
+	event, err := h.svc.GetEvent(r.Context(), id)
+	if err != nil {
+		logger.Warn("ошибка при получении события", zap.String("event_id", id), zap.Error(err))
+		v2Error(w, err)
+		return models.Event{}, false
+	}
+
+	return event, true
+}
+
+// create - запрос на создание события пользователя uid.
+func (req eventV2Req) create(uid int64) createEventReq {
+	return createEventReq{
+		UserID:     uid,
+		CalendarID: req.CalendarID,
+		Date:       req.Date,
+		End:        req.End,
+		Duration:   req.Duration,
+		AllDay:     req.AllDay,
+		TZ:         req.TZ,
+		Event:      req.Event,
+		Reminders:  req.Reminders,
+		Channels:   req.Channels,
+		RRule:      req.RRule,
+		ExDates:    req.ExDates,
+		Attendees:  req.Attendees,
+		Resources:  req.Resources,
+	}
+}
+
+// update - запрос на изменение события eventID владельца uid.
+func (req eventV2Req) update(eventID string, uid int64) updateEventReq {
+	return updateEventReq{
+		EventID:    eventID,
+		UserID:     uid,
+		CalendarID: req.CalendarID,
+		Date:       req.Date,
+		End:        req.End,
+		Duration:   req.Duration,
+		AllDay:     req.AllDay,
+		TZ:         req.TZ,
+		Event:      req.Event,
+		Reminders:  req.Reminders,
+		Channels:   req.Channels,
+		RRule:      req.RRule,
+		Attendees:  req.Attendees,
+		Resources:  req.Resources,
+	}
+}
+
+// patchBase - текущее событие в виде тела запроса, поверх которого применяется PATCH.
+// Окончание задается длительностью, чтобы перенос начала ее сохранял; списки остаются nil,
+// поэтому не переданные в PATCH напоминания, каналы, участники и ресурсы не меняются.
+func patchBase(event models.Event) eventV2Req {
+	base := eventV2Req{
+		CalendarID: event.CalendarID,
+		Date:       event.Date.Format(time.RFC3339),
+		AllDay:     event.AllDay,
+		TZ:         event.TimeZone,
+		Event:      event.Text,
+	}
+	if d := event.Duration(); d > 0 {
+		base.Duration = models.FormatDuration(d)
+	}
+
+	return base
+}
+
+// pathUser - user_id из пути запроса.
+func pathUser(r *http.Request) (int64, error) {
+	uid, err := strconv.ParseInt(r.PathValue("uid"), 10, 64)
+	if err != nil || uid <= 0 {
+		return 0, validators.ErrBadUserID
+	}
+
+	return uid, nil
+}
+
+// eventLocation - адрес события в v2 API для заголовка Location.
+func eventLocation(eventID string) string {
+	return "/v2/events/" + url.PathEscape(eventID)
+}
+
+// v2Error - ответ на ошибку сервиса в v2 API: 404, если события или вхождения серии нет,
+// иначе как serviceError.
+func v2Error(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrNotFound) {
+		_ = httpx.HTTPError(w, http.StatusNotFound, "Событие не найдено")
+		return
+	}
+	serviceError(w, err)
+}
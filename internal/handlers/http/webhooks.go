@@ -0,0 +1,156 @@
+package httphandlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/handlers/validators"
+	"github.com/sunr3d/simple-http-calendar/internal/httpx"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// defaultDeliveries - число записей журнала доставок, если limit не задан.
+const defaultDeliveries = 50
+
+func (h *Handler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "CreateWebhook"))
+
+	logger.Info("получен запрос на создание подписки на вебхук")
+
+	var req createWebhookReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	req.UserID = bindUser(r, req.UserID)
+
+	webhook := models.Webhook{
+		UserID: req.UserID,
+		URL:    strings.TrimSpace(req.URL),
+		Secret: req.Secret,
+	}
+	for _, typ := range req.Types {
+		webhook.Types = append(webhook.Types, models.EnvelopeType(strings.TrimSpace(typ)))
+	}
+	if err := validators.ValidateWebhook(webhook); err != nil {
+		logger.Warn("некорректная подписка на вебхук", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.webhooks.CreateWebhook(r.Context(), webhook)
+	if err != nil {
+		logger.Warn("ошибка при создании подписки на вебхук", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	logger.Info("подписка на вебхук создана", zap.String("webhook_id", created.ID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"result": createWebhookResp{Webhook: created, Secret: created.Secret},
+	})
+}
+
+func (h *Handler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "DeleteWebhook"))
+
+	logger.Info("получен запрос на удаление подписки на вебхук")
+
+	var req deleteWebhookReq
+
+	if err := decodeBody(r, &req); err != nil {
+		logger.Warn("некорректное тело запроса", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	req.UserID = bindUser(r, req.UserID)
+
+	if req.UserID <= 0 {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadUserID.Error())
+		return
+	}
+	if err := validators.ValidateWebhookID(req.WebhookID); err != nil {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ok, err := h.webhooks.DeleteWebhook(r.Context(), req.UserID, req.WebhookID)
+	if err != nil {
+		logger.Warn("ошибка при удалении подписки на вебхук", zap.String("webhook_id", req.WebhookID), zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+	if !ok {
+		_ = httpx.HTTPError(w, http.StatusNotFound, "Подписка не найдена")
+		return
+	}
+
+	logger.Info("подписка на вебхук удалена", zap.String("webhook_id", req.WebhookID))
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": "ok"})
+}
+
+func (h *Handler) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "GetWebhooks"))
+
+	uid, _, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webhooks, err := h.webhooks.ListWebhooks(r.Context(), uid)
+	if err != nil {
+		logger.Warn("ошибка при получении подписок на вебхуки", zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": webhooks})
+}
+
+func (h *Handler) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("component", "handler"), zap.String("op", "GetWebhookDeliveries"))
+
+	uid, _, err := parseUserQuery(r)
+	if err != nil {
+		logger.Warn("некорректный запрос", zap.Error(err))
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webhookID := strings.TrimSpace(r.URL.Query().Get("webhook_id"))
+	if err := validators.ValidateWebhookID(webhookID); err != nil {
+		_ = httpx.HTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := defaultDeliveries
+	if s := strings.TrimSpace(r.URL.Query().Get("limit")); s != "" {
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > validators.MaxDeliveries {
+			_ = httpx.HTTPError(w, http.StatusBadRequest, validators.ErrBadLimit.Error())
+			return
+		}
+	}
+
+	deliveries, ok, err := h.webhooks.ListDeliveries(r.Context(), uid, webhookID, limit)
+	if err != nil {
+		logger.Warn("ошибка при получении журнала доставок", zap.String("webhook_id", webhookID), zap.Error(err))
+		serviceError(w, err)
+		return
+	}
+	if !ok {
+		_ = httpx.HTTPError(w, http.StatusNotFound, "Подписка не найдена")
+		return
+	}
+
+	_ = httpx.WriteJSON(w, http.StatusOK, map[string]any{"result": deliveries})
+}
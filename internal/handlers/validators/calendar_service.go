@@ -1,7 +1,14 @@
 package validators
 
 import (
+	"fmt"
+	"net/mail"
+	"net/url"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sunr3d/simple-http-calendar/internal/rrule"
 
 	"github.com/sunr3d/simple-http-calendar/models"
 )
@@ -16,8 +23,16 @@ func ValidateCreatePayload(payload models.Event) error {
 	if payload.Date.IsZero() {
 		return ErrBadDate
 	}
+	if !payload.End.IsZero() && payload.End.Before(payload.Date) {
+		return ErrBadEnd
+	}
+	if payload.RRule != "" {
+		if _, err := rrule.Parse(payload.RRule); err != nil {
+			return fmt.Errorf("%w: %v", ErrBadRRule, err)
+		}
+	}
 
-	return nil
+	return ValidateBooking(payload)
 }
 
 func ValidateUpdate(payload models.Event) error {
@@ -39,6 +54,88 @@ func ValidateFilter(filter models.EventsByDay) error {
 	return nil
 }
 
+// MaxRange - максимальная длина периода выборки событий: серии разворачиваются целиком в его пределах.
+const MaxRange = 366 * 24 * time.Hour
+
+// MaxEvents - максимальный размер страницы выборки событий.
+const MaxEvents = 500
+
+// ValidateEventsRange - проверяет постраничную выборку событий.
+func ValidateEventsRange(query models.EventsRange) error {
+	if query.UserID <= 0 {
+		return ErrBadUserID
+	}
+	if query.From.IsZero() || query.To.IsZero() || !query.From.Before(query.To) || query.To.Sub(query.From) > MaxRange {
+		return ErrBadRange
+	}
+	if query.Sort != models.SortAsc && query.Sort != models.SortDesc {
+		return ErrBadSort
+	}
+	if query.Limit <= 0 || query.Limit > MaxEvents {
+		return ErrBadLimit
+	}
+
+	return nil
+}
+
+// ValidateConflictPolicy - проверяет реакцию на пересечение событий; пустая - allow.
+func ValidateConflictPolicy(policy models.ConflictPolicy) error {
+	switch policy {
+	case "", models.ConflictAllow, models.ConflictReject:
+		return nil
+	default:
+		return ErrBadConflict
+	}
+}
+
+// MaxFreeBusyUsers - максимальное число пользователей в запросе занятости.
+const MaxFreeBusyUsers = 50
+
+// ValidateFreeBusy - проверяет запрос занятости: пользователи, период как у выборки событий
+// и, при поиске свободных промежутков, рабочие часы в пределах суток.
+func ValidateFreeBusy(query models.FreeBusyQuery) error {
+	if len(query.UserIDs) == 0 || len(query.UserIDs) > MaxFreeBusyUsers {
+		return ErrBadUserIDs
+	}
+	for _, id := range query.UserIDs {
+		if id <= 0 {
+			return ErrBadUserIDs
+		}
+	}
+	if query.From.IsZero() || query.To.IsZero() || !query.From.Before(query.To) || query.To.Sub(query.From) > MaxRange {
+		return ErrBadRange
+	}
+	if query.SlotDuration < 0 {
+		return ErrBadSlot
+	}
+	if query.SlotDuration > 0 && (query.WorkStart < 0 || query.WorkEnd > 24*time.Hour || query.WorkStart >= query.WorkEnd) {
+		return ErrBadWorkHours
+	}
+
+	return nil
+}
+
+// MaxQuery - максимальная длина поискового запроса в символах.
+const MaxQuery = 200
+
+// ValidateEventsSearch - проверяет полнотекстовый поиск событий. Период необязателен.
+func ValidateEventsSearch(search models.EventsSearch) error {
+	if search.UserID <= 0 {
+		return ErrBadUserID
+	}
+	if q := strings.TrimSpace(search.Query); q == "" || utf8.RuneCountInString(q) > MaxQuery {
+		return ErrBadQuery
+	}
+	if !search.From.IsZero() && !search.To.IsZero() && !search.From.Before(search.To) {
+		return ErrBadRange
+	}
+	if search.Limit <= 0 || search.Limit > MaxEvents {
+		return ErrBadLimit
+	}
+
+	return nil
+}
+
 func ValidateDelete(id string) error {
 	if strings.TrimSpace(id) == "" {
 		return ErrBadEventID
@@ -46,3 +143,103 @@ func ValidateDelete(id string) error {
 
 	return nil
 }
+
+func ValidateScope(scope models.EditScope) error {
+	switch scope {
+	case models.EditScopeDefault, models.EditScopeThis, models.EditScopeFollowing, models.EditScopeAll:
+		return nil
+	default:
+		return ErrBadScope
+	}
+}
+
+// ValidateTimeZone - проверяет IANA часовой пояс. Пустое значение допустимо.
+func ValidateTimeZone(tz string) error {
+	if tz == "" {
+		return nil
+	}
+	if tz == "Local" {
+		return ErrBadTimeZone
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return ErrBadTimeZone
+	}
+
+	return nil
+}
+
+// ValidateChannels - проверяет каналы напоминаний.
+func ValidateChannels(channels []models.Channel) error {
+	for _, ch := range channels {
+		if !ch.Valid() {
+			return ErrBadChannel
+		}
+	}
+
+	return nil
+}
+
+// MaxReminders - максимальное число напоминаний у события.
+const MaxReminders = 10
+
+// ValidateReminders - проверяет напоминания события.
+func ValidateReminders(reminders []models.Reminder) error {
+	if len(reminders) > MaxReminders {
+		return ErrBadReminders
+	}
+
+	return nil
+}
+
+// ValidateUserSettings - проверяет пользовательские настройки.
+// Для выбранных каналов email и webhook должен быть задан адрес доставки.
+func ValidateUserSettings(settings models.UserSettings) error {
+	if settings.UserID <= 0 {
+		return ErrBadUserID
+	}
+	if err := ValidateTimeZone(settings.TimeZone); err != nil {
+		return err
+	}
+	if err := ValidateChannels(settings.Channels); err != nil {
+		return err
+	}
+	if settings.Email != "" {
+		if _, err := mail.ParseAddress(settings.Email); err != nil {
+			return ErrBadEmail
+		}
+	}
+	if settings.WebhookURL != "" {
+		u, err := url.Parse(settings.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrBadWebhook
+		}
+	}
+
+	for _, ch := range settings.Channels {
+		switch {
+		case ch == models.ChannelEmail && settings.Email == "":
+			return ErrBadEmail
+		case ch == models.ChannelWebhook && settings.WebhookURL == "":
+			return ErrBadWebhook
+		}
+	}
+
+	return nil
+}
+
+// MaxAttendees - максимальное число участников события.
+const MaxAttendees = 100
+
+// ValidateAttendees - проверяет список участников: user_id положительные, не больше MaxAttendees.
+func ValidateAttendees(attendees []models.Attendee) error {
+	if len(attendees) > MaxAttendees {
+		return ErrBadAttendees
+	}
+	for _, a := range attendees {
+		if a.UserID <= 0 {
+			return ErrBadAttendees
+		}
+	}
+
+	return nil
+}
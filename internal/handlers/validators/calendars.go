@@ -0,0 +1,33 @@
+package validators
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// MaxCalendarName - максимальная длина названия календаря в символах.
+const MaxCalendarName = 100
+
+var colorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ValidateCalendar - проверяет создаваемый или изменяемый календарь; пустая видимость допустима (shared).
+func ValidateCalendar(cal models.Calendar) error {
+	if cal.UserID <= 0 {
+		return ErrBadUserID
+	}
+	name := strings.TrimSpace(cal.Name)
+	if name == "" || utf8.RuneCountInString(name) > MaxCalendarName {
+		return ErrBadCalendarName
+	}
+	if cal.Color != "" && !colorRe.MatchString(cal.Color) {
+		return ErrBadColor
+	}
+	if cal.Visibility != "" && !cal.Visibility.Valid() {
+		return ErrBadVisibility
+	}
+
+	return ValidateReminders(cal.Reminders)
+}
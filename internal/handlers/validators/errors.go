@@ -7,4 +7,47 @@ var (
 	ErrBadEventID   = errors.New("некорректный event_id")
 	ErrBadDate      = errors.New("некорректная дата, ожидается YYYY-MM-DD")
 	ErrBadEventText = errors.New("текст события не может быть пустым")
+	ErrBadRRule     = errors.New("некорректное правило повторения RRULE")
+	ErrBadScope     = errors.New("некорректная область изменения, ожидается this, following или all")
+	ErrBadDateTime  = errors.New("некорректная дата, ожидается YYYY-MM-DDTHH:MM:SS, RFC 3339 или YYYY-MM-DD для all_day")
+	ErrBadEnd       = errors.New("некорректное окончание события, ожидается YYYY-MM-DDTHH:MM:SS или RFC 3339 не раньше начала")
+	ErrBadDuration  = errors.New("некорректная длительность события, ожидается например 90m, 1h30m или 2d")
+	ErrBadTimeZone  = errors.New("некорректный часовой пояс, ожидается имя IANA, например Europe/Moscow")
+	ErrBadChannel   = errors.New("некорректный канал напоминаний, ожидается log, email или webhook")
+	ErrBadEmail     = errors.New("некорректный email")
+	ErrBadWebhook   = errors.New("некорректный webhook_url, ожидается http(s) URL")
+	ErrBadReminders = errors.New("некорректные напоминания, ожидается до 10 смещений, например -1d или -15m")
+	ErrBadRange     = errors.New("некорректный период, ожидается from раньше to и не длиннее 366 дней")
+	ErrBadSort      = errors.New("некорректная сортировка, ожидается asc или desc")
+	ErrBadCursor    = errors.New("некорректный cursor")
+	ErrBadQuery     = errors.New("некорректный поисковый запрос, ожидается непустая строка до 200 символов")
+	ErrBadArchived  = errors.New("некорректный archived, ожидается true или false")
+	ErrBadAttendees = errors.New("некорректные участники, ожидается до 100 user_id других пользователей")
+	ErrBadRSVP      = errors.New("некорректный ответ на приглашение, ожидается needs-action, accepted, declined или tentative")
+	ErrBadUserIDs   = errors.New("некорректные user_ids, ожидается от 1 до 50 user_id через запятую")
+	ErrBadSlot      = errors.New("некорректная длительность свободного промежутка, ожидается например 30m или 1h")
+	ErrBadWorkHours = errors.New("некорректные рабочие часы, ожидается HH:MM, work_start раньше work_end")
+	ErrBadConflict  = errors.New("некорректный on_conflict, ожидается allow или reject")
+
+	ErrBadWebhookID     = errors.New("некорректный webhook_id")
+	ErrBadWebhookURL    = errors.New("некорректный url вебхука, ожидается http(s) URL")
+	ErrBadWebhookTypes  = errors.New("некорректные типы изменений, ожидается event.created, event.updated, event.deleted или event.archived")
+	ErrBadWebhookSecret = errors.New("секрет вебхука должен быть не короче 16 символов")
+	ErrBadLimit         = errors.New("некорректный limit, ожидается число от 1 до 500")
+
+	ErrBadGrantee   = errors.New("некорректный grantee_id, ожидается user_id другого пользователя")
+	ErrBadShareRole = errors.New("некорректная роль, ожидается viewer, editor или free_busy")
+
+	ErrBadCalendarID   = errors.New("некорректный calendar_id")
+	ErrBadCalendarName = errors.New("некорректное название календаря, ожидается непустая строка до 100 символов")
+	ErrBadColor        = errors.New("некорректный цвет календаря, ожидается #RRGGBB")
+	ErrBadVisibility   = errors.New("некорректная видимость календаря, ожидается shared, free_busy или private")
+
+	ErrBadResourceID    = errors.New("некорректный resource_id")
+	ErrBadResourceName  = errors.New("некорректное название ресурса, ожидается непустая строка до 100 символов")
+	ErrBadResourceKind  = errors.New("некорректный вид ресурса, ожидается room или equipment")
+	ErrBadCapacity      = errors.New("некорректная вместимость, ожидается неотрицательное число")
+	ErrBadBookingPolicy = errors.New("некорректные правила бронирования, ожидается длительность, например 4h или 30d")
+	ErrBadResources     = errors.New("некорректные ресурсы, ожидается до 10 resource_id")
+	ErrBadBooking       = errors.New("ресурсы бронируются только одиночным событием с окончанием")
 )
@@ -0,0 +1,54 @@
+package validators
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// MaxResourceName - максимальная длина названия ресурса в символах.
+const MaxResourceName = 100
+
+// MaxEventResources - максимальное число ресурсов, которые занимает одно событие.
+const MaxEventResources = 10
+
+// ValidateResource - проверяет создаваемый или изменяемый ресурс; пустой вид допустим (room).
+func ValidateResource(res models.Resource) error {
+	if res.UserID <= 0 {
+		return ErrBadUserID
+	}
+	name := strings.TrimSpace(res.Name)
+	if name == "" || utf8.RuneCountInString(name) > MaxResourceName {
+		return ErrBadResourceName
+	}
+	if res.Kind != "" && !res.Kind.Valid() {
+		return ErrBadResourceKind
+	}
+	if res.Capacity < 0 {
+		return ErrBadCapacity
+	}
+	if res.Policy.MaxDuration < 0 || res.Policy.Horizon < 0 {
+		return ErrBadBookingPolicy
+	}
+
+	return nil
+}
+
+// ValidateBooking - проверяет ресурсы события: не больше MaxEventResources, занимает их
+// только одиночное событие с окончанием.
+func ValidateBooking(event models.Event) error {
+	if len(event.Resources) > MaxEventResources {
+		return ErrBadResources
+	}
+	for _, id := range event.Resources {
+		if strings.TrimSpace(id) == "" {
+			return ErrBadResources
+		}
+	}
+	if len(event.Resources) > 0 && (event.RRule != "" || !event.End.After(event.Date)) {
+		return ErrBadBooking
+	}
+
+	return nil
+}
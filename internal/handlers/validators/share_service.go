@@ -0,0 +1,18 @@
+package validators
+
+import "github.com/sunr3d/simple-http-calendar/models"
+
+// ValidateShare - проверяет выдачу доступа к календарю.
+func ValidateShare(share models.Share) error {
+	if share.OwnerID <= 0 {
+		return ErrBadUserID
+	}
+	if share.GranteeID <= 0 || share.GranteeID == share.OwnerID {
+		return ErrBadGrantee
+	}
+	if !share.Role.Valid() {
+		return ErrBadShareRole
+	}
+
+	return nil
+}
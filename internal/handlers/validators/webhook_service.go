@@ -0,0 +1,44 @@
+package validators
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// MinWebhookSecret - минимальная длина секрета, заданного пользователем.
+const MinWebhookSecret = 16
+
+// MaxDeliveries - максимальное число записей журнала доставок в одном ответе.
+const MaxDeliveries = 500
+
+// ValidateWebhook - проверяет подписку на вебхук. Пустой секрет будет сгенерирован сервисом.
+func ValidateWebhook(webhook models.Webhook) error {
+	if webhook.UserID <= 0 {
+		return ErrBadUserID
+	}
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrBadWebhookURL
+	}
+	for _, typ := range webhook.Types {
+		if !slices.Contains(models.WebhookTypes, typ) {
+			return ErrBadWebhookTypes
+		}
+	}
+	if webhook.Secret != "" && len(webhook.Secret) < MinWebhookSecret {
+		return ErrBadWebhookSecret
+	}
+
+	return nil
+}
+
+func ValidateWebhookID(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return ErrBadWebhookID
+	}
+
+	return nil
+}
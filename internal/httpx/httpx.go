@@ -16,6 +16,15 @@ func IsJSON(ct string) bool {
 	return strings.HasPrefix(ct, "application/json;")
 }
 
+func IsICal(ct string) bool {
+	ct = strings.ToLower(strings.TrimSpace(ct))
+	if ct == "text/calendar" {
+		return true
+	}
+
+	return strings.HasPrefix(ct, "text/calendar;")
+}
+
 func WriteJSON(w http.ResponseWriter, code int, v any) error {
 	buff, err := json.Marshal(v)
 
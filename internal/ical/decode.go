@@ -0,0 +1,438 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// Item - результат разбора одного VEVENT.
+// Ошибка разбора относится только к этому VEVENT и не прерывает разбор остальных.
+type Item struct {
+	UID   string
+	Event models.Event
+	Err   error
+
+	duration time.Duration
+	alarms   []trigger
+}
+
+// trigger - TRIGGER компонента VALARM: смещение от начала (или окончания) события либо абсолютное время.
+type trigger struct {
+	offset  time.Duration
+	fromEnd bool
+	at      *time.Time
+	ok      bool
+}
+
+type contentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode - разбирает поток iCalendar и возвращает все найденные VEVENT.
+func Decode(r io.Reader) ([]Item, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		items      []Item
+		inCalendar bool
+		sawCal     bool
+		stack      []string
+		current    *Item
+	)
+
+	for n, raw := range lines {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		cl, err := parseLine(raw)
+		if err != nil {
+			if current != nil && current.Err == nil {
+				current.Err = fmt.Errorf("строка %d: %w", n+1, err)
+			}
+			continue
+		}
+
+		switch cl.name {
+		case "BEGIN":
+			comp := strings.ToUpper(cl.value)
+			stack = append(stack, comp)
+			switch {
+			case comp == "VCALENDAR":
+				inCalendar, sawCal = true, true
+			case comp == "VEVENT" && inCalendar && len(stack) == 2:
+				current = &Item{}
+			case comp == "VALARM" && current != nil && len(stack) == 3:
+				current.alarms = append(current.alarms, trigger{})
+			}
+			continue
+		case "END":
+			comp := strings.ToUpper(cl.value)
+			if len(stack) == 0 || stack[len(stack)-1] != comp {
+				return items, fmt.Errorf("%w: END:%s", ErrBadLine, comp)
+			}
+			stack = stack[:len(stack)-1]
+			switch comp {
+			case "VCALENDAR":
+				inCalendar = false
+			case "VEVENT":
+				if current != nil {
+					if current.Err == nil && current.Event.Date.IsZero() {
+						current.Err = ErrNoDTStart
+					}
+					if current.Event.End.IsZero() && current.duration > 0 {
+						current.Event.End = current.Event.Date.Add(current.duration)
+					}
+					current.Event.Reminders = current.reminders()
+					items = append(items, *current)
+					current = nil
+				}
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+		if len(stack) == 3 && stack[2] == "VALARM" && cl.name == "TRIGGER" && len(current.alarms) > 0 {
+			current.alarms[len(current.alarms)-1] = parseTrigger(cl)
+			continue
+		}
+		if len(stack) != 2 {
+			continue
+		}
+
+		applyProperty(current, cl)
+	}
+
+	if !sawCal {
+		return nil, ErrNoCalendar
+	}
+	if len(stack) > 0 {
+		return items, fmt.Errorf("%w: %s", ErrUnterminated, stack[len(stack)-1])
+	}
+
+	return items, nil
+}
+
+func applyProperty(item *Item, cl contentLine) {
+	setErr := func(err error) {
+		if item.Err == nil {
+			item.Err = fmt.Errorf("%s: %w", cl.name, err)
+		}
+	}
+
+	switch cl.name {
+	case "UID":
+		item.UID = unescapeText(cl.value)
+		item.Event.ICalUID = item.UID
+	case "SUMMARY":
+		item.Event.Text = unescapeText(cl.value)
+	case "DTSTART":
+		t, err := parseDateTime(cl.value, cl.params)
+		if err != nil {
+			setErr(err)
+			return
+		}
+		item.Event.Date = t
+		item.Event.AllDay = isDate(cl.value, cl.params)
+		item.Event.TimeZone = cl.params["TZID"]
+	case "DTEND":
+		t, err := parseDateTime(cl.value, cl.params)
+		if err != nil {
+			setErr(err)
+			return
+		}
+		item.Event.End = t
+	case "DURATION":
+		d, err := parseDuration(cl.value)
+		if err != nil {
+			setErr(err)
+			return
+		}
+		item.duration = d
+	case "RRULE":
+		item.Event.RRule = cl.value
+	case "EXDATE":
+		for _, v := range strings.Split(cl.value, ",") {
+			t, err := parseDateTime(v, cl.params)
+			if err != nil {
+				setErr(err)
+				return
+			}
+			item.Event.ExDates = append(item.Event.ExDates, t)
+		}
+	case "RECURRENCE-ID":
+		t, err := parseDateTime(cl.value, cl.params)
+		if err != nil {
+			setErr(err)
+			return
+		}
+		item.Event.RecurrenceID = &t
+	}
+}
+
+// unfold - читает поток и склеивает свернутые строки (RFC 5545, 3.1).
+func unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("sc.Scan: %w", err)
+	}
+
+	return lines, nil
+}
+
+// parseLine - разбирает строку "NAME;PARAM=VALUE;...:VALUE" с учетом кавычек в параметрах.
+func parseLine(line string) (contentLine, error) {
+	cl := contentLine{params: map[string]string{}}
+
+	inQuotes := false
+	colon := -1
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ':':
+			if !inQuotes {
+				colon = i
+			}
+		}
+		if colon >= 0 {
+			break
+		}
+	}
+	if colon <= 0 {
+		return cl, fmt.Errorf("%w: %q", ErrBadLine, line)
+	}
+
+	head := line[:colon]
+	cl.value = line[colon+1:]
+
+	parts := splitParams(head)
+	cl.name = strings.ToUpper(parts[0])
+	for _, p := range parts[1:] {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok {
+			return cl, fmt.Errorf("%w: %q", ErrBadLine, line)
+		}
+		cl.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+
+	return cl, nil
+}
+
+func splitParams(head string) []string {
+	var (
+		parts    []string
+		start    int
+		inQuotes bool
+	)
+	for i := 0; i < len(head); i++ {
+		switch head[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ';':
+			if !inQuotes {
+				parts = append(parts, head[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, head[start:])
+}
+
+// parseDateTime - разбирает DATE-TIME (UTC, с TZID или плавающее) и DATE.
+// Плавающее время и даты без времени трактуются в time.Local.
+func parseDateTime(value string, params map[string]string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrUnsupportedTZ, tzid)
+		}
+		loc = l
+	}
+
+	if isDate(value, params) {
+		t, err := time.ParseInLocation(dateLayout, value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrBadDateTime, value)
+		}
+		return t, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.ParseInLocation(dateTimeLayout, value, time.UTC)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrBadDateTime, value)
+		}
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(localDateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrBadDateTime, value)
+	}
+
+	return t, nil
+}
+
+// isDate - задано ли значение как DATE (без времени).
+func isDate(value string, params map[string]string) bool {
+	return strings.EqualFold(params["VALUE"], "DATE") || len(strings.TrimSpace(value)) == len(dateLayout)
+}
+
+// parseTrigger - разбирает TRIGGER. Некорректный TRIGGER пропускается вместе с напоминанием.
+func parseTrigger(cl contentLine) trigger {
+	if strings.EqualFold(cl.params["VALUE"], "DATE-TIME") {
+		t, err := parseDateTime(cl.value, cl.params)
+		if err != nil {
+			return trigger{}
+		}
+		return trigger{at: &t, ok: true}
+	}
+
+	d, err := parseDuration(cl.value)
+	if err != nil {
+		return trigger{}
+	}
+
+	return trigger{offset: d, fromEnd: strings.EqualFold(cl.params["RELATED"], "END"), ok: true}
+}
+
+// reminders - напоминания события со смещениями относительно его начала.
+func (item *Item) reminders() []models.Reminder {
+	var res []models.Reminder
+	for _, a := range item.alarms {
+		if !a.ok || item.Event.Date.IsZero() {
+			continue
+		}
+
+		offset := a.offset
+		switch {
+		case a.at != nil:
+			offset = a.at.Sub(item.Event.Date)
+		case a.fromEnd:
+			offset += item.Event.Duration()
+		}
+		res = append(res, models.Reminder{Offset: models.Offset(offset)})
+	}
+
+	return res
+}
+
+// parseDuration - разбирает длительность ISO 8601 из RFC 5545 (PT1H30M, P1D, -PT15M, P1W).
+func parseDuration(value string) (time.Duration, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("%w: %q", ErrBadDuration, value)
+	}
+	s = s[1:]
+
+	var (
+		total  time.Duration
+		num    int
+		digits bool
+		inTime bool
+	)
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num = num*10 + int(r-'0')
+			digits = true
+			continue
+		case r == 'T':
+			if digits || inTime {
+				return 0, fmt.Errorf("%w: %q", ErrBadDuration, value)
+			}
+			inTime = true
+			continue
+		}
+
+		if !digits {
+			return 0, fmt.Errorf("%w: %q", ErrBadDuration, value)
+		}
+
+		var unit time.Duration
+		switch {
+		case r == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case r == 'H' && inTime:
+			unit = time.Hour
+		case r == 'M' && inTime:
+			unit = time.Minute
+		case r == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("%w: %q", ErrBadDuration, value)
+		}
+
+		total += time.Duration(num) * unit
+		num, digits = 0, false
+	}
+	if digits {
+		return 0, fmt.Errorf("%w: %q", ErrBadDuration, value)
+	}
+
+	return sign * total, nil
+}
+
+// unescapeText - обратное преобразование к escapeText.
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,156 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+const sample = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//test//EN\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Moscow\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:series-1@example.com\r\n" +
+	"DTSTART;TZID=Europe/Moscow:20250106T100000\r\n" +
+	"DURATION:PT30M\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO\r\n" +
+	"EXDATE;TZID=Europe/Moscow:20250113T100000,20250120T100000\r\n" +
+	"SUMMARY:Стендап\\, команда\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:series-1@example.com\r\n" +
+	"RECURRENCE-ID:20250127T070000Z\r\n" +
+	"DTSTART:20250127T090000Z\r\n" +
+	"DTEND:20250127T100000Z\r\n" +
+	"SUMMARY:Перенесенный стендап\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:broken@example.com\r\n" +
+	"SUMMARY:no start\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:long@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20250201\r\n" +
+	"SUMMARY:очень длинное описание которое было свернуто по правил\r\n" +
+	" ам RFC 5545\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestDecode(t *testing.T) {
+	items, err := Decode(strings.NewReader(sample))
+	require.NoError(t, err)
+	require.Len(t, items, 4)
+
+	msk, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	series := items[0]
+	require.NoError(t, series.Err)
+	assert.Equal(t, "series-1@example.com", series.UID)
+	assert.Equal(t, "series-1@example.com", series.Event.ICalUID)
+	assert.Equal(t, "Стендап, команда", series.Event.Text)
+	assert.True(t, series.Event.Date.Equal(time.Date(2025, 1, 6, 10, 0, 0, 0, msk)))
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", series.Event.RRule)
+	assert.Len(t, series.Event.ExDates, 2)
+	assert.Equal(t, []models.Reminder{{Offset: models.Offset(-15 * time.Minute)}}, series.Event.Reminders)
+	assert.Equal(t, 30*time.Minute, series.Event.Duration())
+	assert.False(t, series.Event.AllDay)
+
+	exception := items[1]
+	require.NoError(t, exception.Err)
+	require.NotNil(t, exception.Event.RecurrenceID)
+	assert.True(t, exception.Event.RecurrenceID.Equal(time.Date(2025, 1, 27, 7, 0, 0, 0, time.UTC)))
+	assert.Empty(t, exception.Event.Reminders)
+	assert.Equal(t, time.Hour, exception.Event.Duration())
+
+	assert.ErrorIs(t, items[2].Err, ErrNoDTStart)
+
+	long := items[3]
+	require.NoError(t, long.Err)
+	assert.Equal(t, "очень длинное описание которое было свернуто по правилам RFC 5545", long.Event.Text)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local), long.Event.Date)
+	assert.True(t, long.Event.AllDay)
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P1D":     24 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+		"P1W":     7 * 24 * time.Hour,
+		"P1DT2H":  26 * time.Hour,
+	}
+	for in, want := range cases {
+		got, err := parseDuration(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, bad := range []string{"", "P", "PT", "1H", "PT1D", "P1H", "PT1"} {
+		_, err := parseDuration(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := Decode(strings.NewReader("hello"))
+	require.ErrorIs(t, err, ErrNoCalendar)
+
+	_, err = Decode(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n"))
+	require.ErrorIs(t, err, ErrUnterminated)
+
+	items, err := Decode(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\n" +
+		"DTSTART;TZID=Nowhere/City:20250101T100000\r\nSUMMARY:x\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.ErrorIs(t, items[0].Err, ErrUnsupportedTZ)
+}
+
+func TestRoundTrip(t *testing.T) {
+	events := []models.Event{{
+		ID:        "e-1",
+		Date:      time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC),
+		Text:      strings.Repeat("Текст; с запятыми, и \\ слешами\n", 5),
+		Reminders: []models.Reminder{{Offset: models.Offset(-time.Hour)}, {Offset: 0}},
+		RRule:     "FREQ=DAILY;COUNT=3",
+	}, {
+		ID:     "e-2",
+		Date:   time.Date(2025, 3, 5, 0, 0, 0, 0, time.Local),
+		End:    time.Date(2025, 3, 7, 0, 0, 0, 0, time.Local),
+		AllDay: true,
+		Text:   "отпуск",
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEncoder(&buf).Encode(events))
+
+	items, err := Decode(&buf)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.NoError(t, items[0].Err)
+
+	vacation := items[1].Event
+	assert.True(t, vacation.AllDay)
+	assert.Equal(t, events[1].Date, vacation.Date)
+	assert.Equal(t, events[1].End, vacation.End)
+
+	got := items[0].Event
+	assert.Equal(t, "e-1", got.ICalUID)
+	assert.Equal(t, events[0].Text, got.Text)
+	assert.True(t, events[0].Date.Equal(got.Date))
+	assert.Equal(t, events[0].RRule, got.RRule)
+	assert.Equal(t, events[0].Reminders, got.Reminders)
+}
@@ -0,0 +1,231 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+const (
+	// ProdID - идентификатор продукта в VCALENDAR.
+	ProdID = "-//sunr3d//simple-http-calendar//RU"
+	// ContentType - MIME-тип потока iCalendar.
+	ContentType = "text/calendar; charset=utf-8"
+
+	dateTimeLayout      = "20060102T150405Z"
+	localDateTimeLayout = "20060102T150405"
+	dateLayout          = "20060102"
+	// maxLineOctets - максимальная длина строки контента без CRLF (RFC 5545, 3.1).
+	maxLineOctets = 75
+)
+
+// Encoder - сериализует события в поток iCalendar (RFC 5545).
+type Encoder struct {
+	w   *bufio.Writer
+	now func() time.Time
+	err error
+}
+
+// NewEncoder - конструктор энкодера iCalendar.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w), now: time.Now}
+}
+
+// Encode - записывает VCALENDAR со всеми событиями.
+// Повторяющиеся серии выгружаются правилом RRULE, исключения - отдельными VEVENT с RECURRENCE-ID.
+// Для каждого часового пояса, на который ссылается TZID, записывается VTIMEZONE.
+func (e *Encoder) Encode(events []models.Event) error {
+	stamp := e.now().UTC().Format(dateTimeLayout)
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", ProdID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+
+	for _, z := range zones(events) {
+		e.timezone(z)
+	}
+	for _, event := range events {
+		e.event(event, stamp)
+	}
+
+	e.line("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.w.Flush()
+}
+
+func (e *Encoder) event(event models.Event, stamp string) {
+	uid := event.ID
+	switch {
+	case event.ICalUID != "":
+		uid = event.ICalUID
+	case event.SeriesID != "":
+		uid = event.SeriesID
+	}
+
+	e.line("BEGIN", "VEVENT")
+	e.line("UID", escapeText(uid))
+	e.line("DTSTAMP", stamp)
+	if event.AllDay {
+		e.line("DTSTART;VALUE=DATE", formatDate(event.Date))
+		e.line("DTEND;VALUE=DATE", formatDate(event.EndTime()))
+	} else {
+		e.dateTime("DTSTART", event.Date, event.TimeZone)
+		if event.End.After(event.Date) {
+			e.dateTime("DTEND", event.End, event.TimeZone)
+		}
+	}
+	if event.RecurrenceID != nil {
+		e.instant(event, "RECURRENCE-ID", *event.RecurrenceID)
+	}
+	if event.RRule != "" && event.SeriesID == "" {
+		e.line("RRULE", event.RRule)
+		for _, ex := range event.ExDates {
+			e.instant(event, "EXDATE", ex)
+		}
+	}
+	e.line("SUMMARY", escapeText(event.Text))
+	for _, r := range event.Reminders {
+		e.line("BEGIN", "VALARM")
+		e.line("ACTION", "DISPLAY")
+		e.line("DESCRIPTION", escapeText(event.Text))
+		e.line("TRIGGER", formatDuration(r.Offset.Duration()))
+		e.line("END", "VALARM")
+	}
+	e.line("END", "VEVENT")
+}
+
+// line - записывает строку контента "NAME:VALUE" со сверткой длинных строк.
+func (e *Encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+
+	_, e.err = e.w.WriteString(fold(name + ":" + value))
+}
+
+// instant - записывает вхождение серии (RECURRENCE-ID, EXDATE) с тем же типом значения, что и DTSTART:
+// у событий на весь день - DATE в поясе начала события, иначе DATE-TIME в UTC.
+func (e *Encoder) instant(event models.Event, name string, t time.Time) {
+	if event.AllDay {
+		e.line(name+";VALUE=DATE", formatDate(t.In(event.Date.Location())))
+		return
+	}
+
+	e.line(name, formatDateTime(t))
+}
+
+// dateTime - записывает DATE-TIME в UTC, а при известном часовом поясе события -
+// локальное время с TZID, чтобы клиенты разворачивали повторения с учетом перехода на летнее время.
+// Определение пояса (VTIMEZONE) записывает Encode, см. zones.
+func (e *Encoder) dateTime(name string, t time.Time, tz string) {
+	if tz == "" {
+		e.line(name, formatDateTime(t))
+		return
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		e.line(name, formatDateTime(t))
+		return
+	}
+
+	e.line(name+";TZID="+tz, t.In(loc).Format(localDateTimeLayout))
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout)
+}
+
+// formatDuration - длительность в формате RFC 5545 (-PT15M, -P1D, PT0S).
+func formatDuration(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+
+	const day = 24 * time.Hour
+	if days := d / day; days > 0 {
+		b.WriteString(strconv.FormatInt(int64(days), 10) + "D")
+		d -= days * day
+	}
+	if d == 0 && b.Len() > 2 {
+		return b.String()
+	}
+
+	b.WriteByte('T')
+	h, m, sec := d/time.Hour, (d%time.Hour)/time.Minute, (d%time.Minute)/time.Second
+	if h > 0 {
+		b.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+	}
+	if m > 0 {
+		b.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+	}
+	if sec > 0 || (h == 0 && m == 0) {
+		b.WriteString(strconv.FormatInt(int64(sec), 10) + "S")
+	}
+
+	return b.String()
+}
+
+func formatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// escapeText - экранирование значения типа TEXT (RFC 5545, 3.3.11).
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+
+	return r.Replace(s)
+}
+
+// fold - сворачивает строку по 75 октетов, не разрывая многобайтные символы UTF-8.
+// Строки продолжения начинаются с пробела, все строки заканчиваются CRLF.
+func fold(line string) string {
+	if len(line) <= maxLineOctets {
+		return line + "\r\n"
+	}
+
+	var sb strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n ")
+		line = line[cut:]
+		// Пробел в начале строки продолжения занимает один октет.
+		limit = maxLineOctets - 1
+	}
+	sb.WriteString(line)
+	sb.WriteString("\r\n")
+
+	return sb.String()
+}
+
+// Filename - имя файла выгрузки для пользователя.
+func Filename(userID int64) string {
+	return fmt.Sprintf("calendar-%d.ics", userID)
+}
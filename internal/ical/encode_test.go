@@ -0,0 +1,205 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func TestEncode(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	err := enc.Encode([]models.Event{
+		{
+			ID:     "e-1",
+			UserID: 1,
+			Date:   time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC),
+			Text:   "Созвон; план, итоги\nвторая строка",
+			Reminders: []models.Reminder{
+				{Offset: models.Offset(-24 * time.Hour)},
+				{Offset: models.Offset(-15 * time.Minute)},
+			},
+		},
+		{
+			ID:      "s-1",
+			UserID:  1,
+			Date:    time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC),
+			Text:    "standup",
+			RRule:   "FREQ=WEEKLY",
+			ExDates: []time.Time{time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC)},
+		},
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "UID:e-1\r\n")
+	assert.Contains(t, out, "DTSTART:20250106T100000Z\r\n")
+	assert.Contains(t, out, `SUMMARY:Созвон\; план\, итоги\nвторая строка`+"\r\n")
+	assert.Contains(t, out, "BEGIN:VALARM\r\nACTION:DISPLAY\r\n")
+	assert.Contains(t, out, "TRIGGER:-P1D\r\n")
+	assert.Contains(t, out, "TRIGGER:-PT15M\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=WEEKLY\r\n")
+	assert.Contains(t, out, "EXDATE:20250113T090000Z\r\n")
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VALARM"))
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+}
+
+func TestEncodeTimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = NewEncoder(&buf).Encode([]models.Event{{
+		ID:       "e-1",
+		UserID:   1,
+		Date:     time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC),
+		End:      time.Date(2025, 1, 6, 11, 0, 0, 0, time.UTC),
+		Text:     "standup",
+		TimeZone: "Europe/Moscow",
+	}})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "DTSTART;TZID=Europe/Moscow:20250106T130000\r\n")
+	assert.Contains(t, buf.String(), "DTEND;TZID=Europe/Moscow:20250106T140000\r\n")
+
+	items, err := Decode(&buf)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Europe/Moscow", items[0].Event.TimeZone)
+	assert.Equal(t, loc, items[0].Event.Date.Location())
+	assert.True(t, items[0].Event.Date.Equal(time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)))
+}
+
+func TestEncodeAllDaySeries(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	day := time.Date(2025, 6, 2, 0, 0, 0, 0, loc)
+	moved := day.AddDate(0, 0, 7)
+
+	var buf bytes.Buffer
+	err = NewEncoder(&buf).Encode([]models.Event{
+		{
+			ID: "s-1", Date: day, End: day.AddDate(0, 0, 1), Text: "дежурство", AllDay: true,
+			RRule: "FREQ=WEEKLY", ExDates: []time.Time{day.AddDate(0, 0, 14).UTC()},
+		},
+		{
+			ID: "s-1#1", SeriesID: "s-1", Date: moved.AddDate(0, 0, 1), End: moved.AddDate(0, 0, 2), Text: "дежурство", AllDay: true,
+			RecurrenceID: &moved,
+		},
+	})
+	require.NoError(t, err)
+
+	// Тип значения совпадает с DTSTART, дата берется в поясе события, а не в UTC.
+	out := buf.String()
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20250602\r\n")
+	assert.Contains(t, out, "EXDATE;VALUE=DATE:20250616\r\n")
+	assert.Contains(t, out, "RECURRENCE-ID;VALUE=DATE:20250609\r\n")
+
+	items, err := Decode(&buf)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Len(t, items[0].Event.ExDates, 1)
+	assert.Equal(t, "20250616", formatDate(items[0].Event.ExDates[0]))
+	require.NotNil(t, items[1].Event.RecurrenceID)
+	assert.Equal(t, "20250609", formatDate(*items[1].Event.RecurrenceID))
+}
+
+func TestEncodeVTimeZone(t *testing.T) {
+	var buf bytes.Buffer
+	err := NewEncoder(&buf).Encode([]models.Event{
+		{ID: "s-1", Date: time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), Text: "standup", TimeZone: "Europe/Berlin", RRule: "FREQ=WEEKLY"},
+		{ID: "e-1", Date: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC), Text: "ретро", TimeZone: "Europe/Berlin"},
+		{ID: "e-2", Date: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC), Text: "x", TimeZone: "Europe/Moscow"},
+		{ID: "e-3", Date: time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), Text: "x", TimeZone: "Asia/Tokyo", AllDay: true},
+		{ID: "e-4", Date: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC), Text: "x"},
+	})
+	require.NoError(t, err)
+	out := buf.String()
+
+	// Определение есть у каждого TZID, и только у них.
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VTIMEZONE"))
+	assert.Equal(t, 1, strings.Count(out, "TZID:Europe/Berlin\r\n"))
+	assert.Contains(t, out, "TZID:Europe/Moscow\r\nBEGIN:STANDARD\r\nDTSTART:20250101T000000\r\nTZOFFSETFROM:+0300\r\nTZOFFSETTO:+0300\r\nTZNAME:MSK\r\n")
+	assert.NotContains(t, out, "Asia/Tokyo")
+	assert.Less(t, strings.Index(out, "END:VTIMEZONE"), strings.Index(out, "BEGIN:VEVENT"))
+
+	// Переходы первых лет разовые, последнего - правилом, чтобы серия продолжалась по нему.
+	assert.Contains(t, out, "BEGIN:DAYLIGHT\r\nDTSTART:20240331T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\n")
+	assert.Contains(t, out, "BEGIN:DAYLIGHT\r\nDTSTART:20250330T020000\r\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\n")
+	assert.Contains(t, out, "BEGIN:STANDARD\r\nDTSTART:20251026T030000\r\nRRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\n")
+	assert.Contains(t, out, "DTSTART;TZID=Europe/Berlin:20250602T110000\r\n")
+
+	items, err := Decode(&buf)
+	require.NoError(t, err)
+	assert.Len(t, items, 5)
+}
+
+func TestYearlyRule(t *testing.T) {
+	for tz, want := range map[string][]string{
+		"America/New_York": {"FREQ=YEARLY;BYMONTH=3;BYDAY=2SU", "FREQ=YEARLY;BYMONTH=11;BYDAY=1SU"},
+		"Australia/Sydney": {"FREQ=YEARLY;BYMONTH=4;BYDAY=1SU", "FREQ=YEARLY;BYMONTH=10;BYDAY=1SU"},
+		"Europe/Moscow":    nil,
+	} {
+		loc, err := time.LoadLocation(tz)
+		require.NoError(t, err)
+
+		var rules []string
+		for _, o := range transitions(loc, time.Date(2025, 1, 1, 0, 0, 0, 0, loc), time.Date(2026, 1, 1, 0, 0, 0, 0, loc)) {
+			rules = append(rules, yearlyRule(loc, o))
+		}
+		assert.Equal(t, want, rules, tz)
+	}
+
+	assert.Equal(t, "+0530", formatOffset(5*3600+30*60))
+	assert.Equal(t, "-0330", formatOffset(-(3*3600 + 30*60)))
+	assert.Equal(t, "+023017", formatOffset(2*3600+30*60+17))
+}
+
+func TestFormatDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                                "PT0S",
+		-15 * time.Minute:                "-PT15M",
+		-24 * time.Hour:                  "-P1D",
+		-(26*time.Hour + 30*time.Minute): "-P1DT2H30M",
+		90 * time.Second:                 "PT1M30S",
+	}
+	for d, want := range cases {
+		assert.Equal(t, want, formatDuration(d), d.String())
+
+		got, err := parseDuration(want)
+		require.NoError(t, err)
+		assert.Equal(t, d, got)
+	}
+}
+
+func TestFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("Длинное описание события ", 10)
+	folded := fold(line)
+
+	lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	require.Greater(t, len(lines), 1)
+	for i, l := range lines {
+		assert.LessOrEqual(t, len(l), maxLineOctets)
+		assert.True(t, utf8.ValidString(l))
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(l, " "))
+		}
+	}
+
+	unfolded := strings.ReplaceAll(folded, "\r\n ", "")
+	assert.Equal(t, line+"\r\n", unfolded)
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne`, escapeText("a\\b;c,d\r\ne"))
+}
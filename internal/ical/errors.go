@@ -0,0 +1,13 @@
+package ical
+
+import "errors"
+
+var (
+	ErrNoCalendar    = errors.New("поток не содержит VCALENDAR")
+	ErrBadLine       = errors.New("некорректная строка контента")
+	ErrUnterminated  = errors.New("компонент не закрыт END")
+	ErrNoDTStart     = errors.New("у VEVENT отсутствует DTSTART")
+	ErrBadDateTime   = errors.New("некорректная дата-время")
+	ErrUnsupportedTZ = errors.New("неизвестный часовой пояс TZID")
+	ErrBadDuration   = errors.New("некорректная длительность DURATION")
+)
@@ -0,0 +1,170 @@
+package ical
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// weekdays - коды дней недели RFC 5545 в порядке time.Weekday.
+var weekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// zone - часовой пояс, в котором выгружены события, и годы их дат.
+type zone struct {
+	tzid     string
+	loc      *time.Location
+	from, to int
+}
+
+// observance - период часового пояса (STANDARD или DAYLIGHT в VTIMEZONE), начинающийся в момент at.
+type observance struct {
+	at       time.Time
+	from, to int // смещения от UTC до и после начала периода, в секундах
+	name     string
+	dst      bool
+}
+
+// zones - часовые пояса событий, даты которых выгружаются с TZID (см. Encoder.dateTime), по TZID.
+func zones(events []models.Event) []zone {
+	byID := make(map[string]*zone)
+	for _, event := range events {
+		if event.AllDay || event.TimeZone == "" {
+			continue
+		}
+
+		z, ok := byID[event.TimeZone]
+		if !ok {
+			loc, err := time.LoadLocation(event.TimeZone)
+			if err != nil {
+				continue
+			}
+			year := event.Date.In(loc).Year()
+			z = &zone{tzid: event.TimeZone, loc: loc, from: year, to: year}
+			byID[event.TimeZone] = z
+		}
+
+		z.from = min(z.from, event.Date.In(z.loc).Year())
+		z.to = max(z.to, event.Date.In(z.loc).Year(), event.EndTime().In(z.loc).Year())
+	}
+
+	res := make([]zone, 0, len(byID))
+	for _, z := range byID {
+		res = append(res, *z)
+	}
+	slices.SortFunc(res, func(a, b zone) int { return strings.Compare(a.tzid, b.tzid) })
+
+	return res
+}
+
+// timezone - записывает VTIMEZONE: смещение на начало первого года событий и переходы до конца последнего.
+// Переходы последнего года записываются правилом RRULE, если оно совпадает с переходами следующего года,
+// чтобы клиенты продолжали их для повторений серий за пределами выгруженных лет.
+func (e *Encoder) timezone(z zone) {
+	start := time.Date(z.from, 1, 1, 0, 0, 0, 0, z.loc)
+	last := time.Date(z.to, 1, 1, 0, 0, 0, 0, z.loc)
+	end := time.Date(z.to+1, 1, 1, 0, 0, 0, 0, z.loc)
+
+	e.line("BEGIN", "VTIMEZONE")
+	e.line("TZID", z.tzid)
+
+	name, offset := start.Zone()
+	e.observance(observance{at: start, from: offset, to: offset, name: name, dst: start.IsDST()}, "")
+	for _, o := range transitions(z.loc, start, end) {
+		var rule string
+		if !o.at.Before(last) {
+			rule = yearlyRule(z.loc, o)
+		}
+		e.observance(o, rule)
+	}
+
+	e.line("END", "VTIMEZONE")
+}
+
+// observance - записывает период часового пояса. DTSTART - местное время до перехода (RFC 5545, 3.6.5).
+func (e *Encoder) observance(o observance, rule string) {
+	kind := "STANDARD"
+	if o.dst {
+		kind = "DAYLIGHT"
+	}
+
+	e.line("BEGIN", kind)
+	e.line("DTSTART", o.at.In(time.FixedZone("", o.from)).Format(localDateTimeLayout))
+	if rule != "" {
+		e.line("RRULE", rule)
+	}
+	e.line("TZOFFSETFROM", formatOffset(o.from))
+	e.line("TZOFFSETTO", formatOffset(o.to))
+	if o.name != "" {
+		e.line("TZNAME", escapeText(o.name))
+	}
+	e.line("END", kind)
+}
+
+// transitions - переходы часового пояса loc в [from; to).
+func transitions(loc *time.Location, from, to time.Time) []observance {
+	var res []observance
+	for t := from; ; {
+		_, next := t.In(loc).ZoneBounds()
+		if next.IsZero() || !next.Before(to) {
+			return res
+		}
+
+		_, before := next.Add(-time.Second).In(loc).Zone()
+		name, after := next.In(loc).Zone()
+		res = append(res, observance{at: next, from: before, to: after, name: name, dst: next.In(loc).IsDST()})
+		t = next
+	}
+}
+
+// yearlyRule - RRULE перехода o вида "n-й (или последний) день недели месяца", если по нему же
+// происходит переход следующего года. Иначе "" - переход записывается разовым.
+func yearlyRule(loc *time.Location, o observance) string {
+	local := o.at.In(time.FixedZone("", o.from))
+	n := (local.Day()-1)/7 + 1
+	if local.AddDate(0, 0, 7).Month() != local.Month() {
+		n = -1
+	}
+
+	year := local.Year() + 1
+	day := nthWeekday(year, local.Month(), n, local.Weekday())
+	want := time.Date(year, local.Month(), day, local.Hour(), local.Minute(), local.Second(), 0, time.UTC)
+
+	next := transitions(loc, time.Date(year, 1, 1, 0, 0, 0, 0, loc), time.Date(year+1, 1, 1, 0, 0, 0, 0, loc))
+	for _, t := range next {
+		clock := t.at.In(time.FixedZone("", t.from))
+		if t.from == o.from && t.to == o.to && clock.Format(localDateTimeLayout) == want.Format(localDateTimeLayout) {
+			return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", local.Month(), n, weekdays[local.Weekday()])
+		}
+	}
+
+	return ""
+}
+
+// nthWeekday - число месяца, на которое приходится n-й день недели wd (n == -1 - последний).
+func nthWeekday(year int, month time.Month, n int, wd time.Weekday) int {
+	if n < 0 {
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		return last.Day() - (int(last.Weekday())-int(wd)+7)%7
+	}
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return 1 + (int(wd)-int(first.Weekday())+7)%7 + 7*(n-1)
+}
+
+// formatOffset - смещение от UTC в формате UTC-OFFSET (+0300, -0430, +053328).
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign, seconds = '-', -seconds
+	}
+
+	res := fmt.Sprintf("%c%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if s := seconds % 60; s != 0 {
+		res += fmt.Sprintf("%02d", s)
+	}
+
+	return res
+}
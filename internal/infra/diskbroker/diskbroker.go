@@ -0,0 +1,615 @@
+// Package diskbroker - брокер с топиками и журналом на диске, подтверждением обработки,
+// повторными попытками с экспоненциальной задержкой и очередью недоставленных сообщений (DLQ).
+package diskbroker
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/internal/scheduler"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.Broker = (*Broker)(nil)
+
+const (
+	logFile  = "broker.log"
+	deadFile = "dead.jsonl"
+
+	opSubscribe = "sub"
+	opPublish   = "pub"
+	opAck       = "ack"
+	opDead      = "dead"
+	opSeq       = "seq"
+
+	// compactRatio - журнал сжимается во время работы, когда записей в нем в compactRatio раз больше,
+	// чем останется после сжатия (и не меньше BrokerConfig.CompactMin).
+	compactRatio = 4
+)
+
+// record - строка журнала: регистрация подписчика, публикация сообщения,
+// подтверждение обработки подписчиком, перенос в DLQ или последний выданный seq
+// (пишется при сжатии, чтобы номера сообщений не повторялись после перезапуска).
+type record struct {
+	Op         string           `json:"op"`
+	Seq        uint64           `json:"seq,omitempty"`
+	Topic      models.Topic     `json:"topic,omitempty"`
+	Subscriber string           `json:"sub,omitempty"`
+	Envelope   *models.Envelope `json:"env,omitempty"`
+}
+
+// DeadLetter - сообщение, которое подписчик не смог обработать за MaxAttempts попыток.
+type DeadLetter struct {
+	Seq        uint64          `json:"seq"`
+	Topic      models.Topic    `json:"topic"`
+	Subscriber string          `json:"subscriber"`
+	Envelope   models.Envelope `json:"envelope"`
+	Attempts   int             `json:"attempts"`
+	Error      string          `json:"error"`
+	FailedAt   time.Time       `json:"failed_at"`
+}
+
+// stored - сообщение в журнале и подписчики, которые его еще не подтвердили.
+type stored struct {
+	seq     uint64
+	topic   models.Topic
+	env     models.Envelope
+	pending map[string]struct{}
+}
+
+// subscription - активная подписка со своей очередью доставки.
+type subscription struct {
+	topic   models.Topic
+	name    string
+	handler infra.Handler
+	sched   *scheduler.Scheduler[*delivery]
+}
+
+// delivery - доставка сообщения одному подписчику.
+type delivery struct {
+	seq      uint64
+	env      models.Envelope
+	attempts int
+}
+
+// Broker - брокер с журналом на диске.
+// Подписчик получает сообщения топика, опубликованные после его первой подписки,
+// в том числе пока он не запущен. Сообщение хранится в журнале, пока все такие подписчики
+// его не подтвердят (обработчик вернул nil). При ошибке обработка повторяется с задержкой
+// RetryBase * 2^(n-1) (не больше RetryMax), после MaxAttempts попыток сообщение
+// для этого подписчика переносится в dead.jsonl. Журнал сжимается при открытии
+// и во время работы, когда в нем накапливаются записи о завершенных сообщениях.
+type Broker struct {
+	cfg    config.BrokerConfig
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	log    *os.File
+	dead   *os.File
+	seq    uint64
+	msgs   map[uint64]*stored
+	known  map[models.Topic]map[string]struct{}
+	active map[models.Topic]map[string]*subscription
+	closed bool
+
+	// records - записей в журнале, compactAt - при скольких записях проверить, не пора ли его сжать.
+	records   int
+	compactAt int
+}
+
+// Open - открывает журнал брокера в cfg.Dir, восстанавливает неподтвержденные сообщения
+// и сжимает журнал, оставляя в нем только их.
+func Open(cfg config.BrokerConfig, logger *zap.Logger) (*Broker, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	b := &Broker{
+		cfg:    cfg,
+		logger: logger,
+		msgs:   make(map[uint64]*stored),
+		known:  make(map[models.Topic]map[string]struct{}),
+		active: make(map[models.Topic]map[string]*subscription),
+	}
+
+	if err := b.replay(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if b.log, err = b.compact(); err != nil {
+		return nil, err
+	}
+	b.dead, err = os.OpenFile(b.path(deadFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		_ = b.log.Close()
+		return nil, fmt.Errorf("os.OpenFile: %w", err)
+	}
+
+	logger.Info("журнал брокера открыт",
+		zap.String("service", "diskbroker"),
+		zap.String("dir", cfg.Dir),
+		zap.Int("pending", b.Pending()),
+	)
+
+	return b, nil
+}
+
+// Publish - записывает конверт в журнал и ставит его в очереди подписчиков топика.
+// Если у топика еще не было подписчиков, сообщение некому доставлять и оно не сохраняется.
+func (b *Broker) Publish(ctx context.Context, topic models.Topic, env models.Envelope) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errClosed
+	}
+	if len(b.known[topic]) == 0 {
+		return nil
+	}
+
+	b.seq++
+	if err := b.append(record{Op: opPublish, Seq: b.seq, Topic: topic, Envelope: &env}); err != nil {
+		return fmt.Errorf("запись в журнал брокера: %w", err)
+	}
+	b.store(b.seq, topic, env)
+
+	now := time.Now()
+	for _, sub := range b.active[topic] {
+		sub.sched.Schedule(key(b.seq), now, &delivery{seq: b.seq, env: env})
+	}
+
+	return nil
+}
+
+// Subscribe - подписывает обработчик на топик под именем subscriber.
+// Подписчик получает неподтвержденные им сообщения из журнала и новые сообщения топика.
+// Обработчик вызывается последовательно; nil подтверждает сообщение, ошибка или паника - повтор.
+func (b *Broker) Subscribe(
+	ctx context.Context,
+	topic models.Topic,
+	subscriber string,
+	handler infra.Handler,
+) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errClosed
+	}
+	if _, ok := b.active[topic][subscriber]; ok {
+		return errSubscribed
+	}
+	if _, ok := b.known[topic][subscriber]; !ok {
+		if err := b.append(record{Op: opSubscribe, Topic: topic, Subscriber: subscriber}); err != nil {
+			return fmt.Errorf("запись в журнал брокера: %w", err)
+		}
+		b.addKnown(topic, subscriber)
+	}
+
+	sub := &subscription{topic: topic, name: subscriber, handler: handler}
+	sub.sched = scheduler.New(func(ctx context.Context, _ string, d *delivery) {
+		b.deliver(ctx, sub, d)
+	})
+	if b.active[topic] == nil {
+		b.active[topic] = make(map[string]*subscription)
+	}
+	b.active[topic][subscriber] = sub
+
+	now := time.Now()
+	for _, msg := range b.sorted() {
+		if _, ok := msg.pending[subscriber]; ok && msg.topic == topic {
+			sub.sched.Schedule(key(msg.seq), now, &delivery{seq: msg.seq, env: msg.env})
+		}
+	}
+
+	b.logger.Info("запуск подписки на события брокера",
+		zap.String("service", "diskbroker"),
+		zap.String("op", "Subscribe"),
+		zap.String("topic", string(topic)),
+		zap.String("subscriber", subscriber),
+		zap.Int("pending", sub.sched.Len()),
+	)
+
+	go func() {
+		_ = sub.sched.Run(ctx)
+
+		b.mu.Lock()
+		delete(b.active[topic], subscriber)
+		b.mu.Unlock()
+	}()
+
+	return nil
+}
+
+// DeadLetters - сообщения из очереди недоставленных (dead.jsonl).
+func (b *Broker) DeadLetters() ([]DeadLetter, error) {
+	f, err := os.Open(b.path(deadFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer f.Close()
+
+	var res []DeadLetter
+	dec := json.NewDecoder(f)
+	for {
+		var dl DeadLetter
+		if err := dec.Decode(&dl); err != nil {
+			if errors.Is(err, io.EOF) {
+				return res, nil
+			}
+			return res, fmt.Errorf("разбор dead.jsonl: %w", err)
+		}
+		res = append(res, dl)
+	}
+}
+
+// Pending - число неподтвержденных доставок (сообщение x подписчик).
+func (b *Broker) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := 0
+	for _, msg := range b.msgs {
+		n += len(msg.pending)
+	}
+
+	return n
+}
+
+// Close - закрывает файлы журнала. Неподтвержденные сообщения будут доставлены после следующего Open.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	return errors.Join(b.log.Close(), b.dead.Close())
+}
+
+// deliver - обработчик планировщика подписки: вызывает подписчика и подтверждает сообщение
+// либо планирует повтор или переносит сообщение в DLQ.
+func (b *Broker) deliver(ctx context.Context, sub *subscription, d *delivery) {
+	logger := b.logger.With(
+		zap.String("service", "diskbroker"),
+		zap.String("op", "deliver"),
+		zap.String("topic", string(sub.topic)),
+		zap.String("subscriber", sub.name),
+		zap.Uint64("seq", d.seq),
+		zap.String("type", string(d.env.Type)),
+	)
+
+	err := b.call(ctx, sub.handler, d.env)
+	if ctx.Err() != nil {
+		// Сообщение остается в журнале и будет доставлено после перезапуска.
+		return
+	}
+	d.attempts++
+
+	if err == nil {
+		if err := b.done(record{Op: opAck, Seq: d.seq, Subscriber: sub.name}, nil); err != nil {
+			logger.Error("ошибка при подтверждении сообщения", zap.Error(err))
+		}
+		return
+	}
+
+	if d.attempts < b.cfg.MaxAttempts {
+		delay := b.backoff(d.attempts)
+		logger.Warn("ошибка при обработке сообщения, повтор",
+			zap.Error(err),
+			zap.Int("attempt", d.attempts),
+			zap.Duration("delay", delay),
+		)
+		sub.sched.Schedule(key(d.seq), time.Now().Add(delay), d)
+		return
+	}
+
+	logger.Error("сообщение перенесено в очередь недоставленных",
+		zap.Error(err),
+		zap.Int("attempts", d.attempts),
+	)
+	dl := &DeadLetter{
+		Seq:        d.seq,
+		Topic:      sub.topic,
+		Subscriber: sub.name,
+		Envelope:   d.env,
+		Attempts:   d.attempts,
+		Error:      err.Error(),
+		FailedAt:   time.Now(),
+	}
+	if err := b.done(record{Op: opDead, Seq: d.seq, Subscriber: sub.name}, dl); err != nil {
+		logger.Error("ошибка при записи в очередь недоставленных", zap.Error(err))
+	}
+}
+
+// call - вызывает обработчик, превращая панику в ошибку.
+func (b *Broker) call(ctx context.Context, handler infra.Handler, env models.Envelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("паника в обработчике брокера",
+				zap.String("service", "diskbroker"),
+				zap.Any("rec", r),
+				zap.String("stack", string(debug.Stack())),
+			)
+			err = fmt.Errorf("%w: %v", errPanic, r)
+		}
+	}()
+
+	return handler(ctx, env)
+}
+
+// backoff - задержка перед попыткой attempt+1: RetryBase * 2^(attempt-1), не больше RetryMax.
+func (b *Broker) backoff(attempt int) time.Duration {
+	delay := b.cfg.RetryBase
+	for i := 1; i < attempt && delay < b.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+
+	return min(delay, b.cfg.RetryMax)
+}
+
+// done - отмечает в журнале, что подписчик закончил с сообщением (ack или dead),
+// и забывает сообщение, когда с ним закончили все подписчики. dl != nil - запись в dead.jsonl.
+func (b *Broker) done(rec record, dl *DeadLetter) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errClosed
+	}
+
+	if dl != nil {
+		data, err := json.Marshal(dl)
+		if err != nil {
+			return err
+		}
+		if _, err := b.dead.Write(append(data, '\n')); err != nil {
+			return err
+		}
+		if b.cfg.Sync {
+			if err := b.dead.Sync(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := b.append(rec); err != nil {
+		return err
+	}
+	b.forget(rec.Seq, rec.Subscriber)
+	b.maybeCompact()
+
+	return nil
+}
+
+// maybeCompact - сжимает журнал во время работы, если записей в нем в compactRatio раз больше,
+// чем останется после сжатия. Иначе откладывает проверку на max(CompactMin, live) записей,
+// так что построение снимка окупается записями, накопленными между проверками.
+// Вызывается под b.mu.
+func (b *Broker) maybeCompact() {
+	if b.records < b.compactAt {
+		return
+	}
+	if live := len(b.snapshot()); b.records < compactRatio*live {
+		b.compactAt = b.records + max(b.cfg.CompactMin, live)
+		return
+	}
+
+	f, err := b.compact()
+	if err != nil {
+		// Журнал остается прежним, сжатие повторится, когда он вырастет вдвое.
+		b.compactAt = 2 * b.records
+		b.logger.Warn("ошибка при сжатии журнала брокера",
+			zap.String("service", "diskbroker"),
+			zap.Error(err),
+		)
+		return
+	}
+
+	old := b.log
+	b.log = f
+	if err := old.Close(); err != nil {
+		b.logger.Warn("ошибка при закрытии журнала брокера",
+			zap.String("service", "diskbroker"),
+			zap.Error(err),
+		)
+	}
+}
+
+// append - дописывает запись в журнал. Вызывается под b.mu.
+func (b *Broker) append(rec record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := b.log.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	b.records++
+	if b.cfg.Sync {
+		return b.log.Sync()
+	}
+
+	return nil
+}
+
+// replay - восстанавливает по журналу подписчиков и неподтвержденные сообщения.
+// Оборванная последняя строка (сбой во время записи) пропускается.
+func (b *Broker) replay() error {
+	f, err := os.Open(b.path(logFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("os.Open: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			b.logger.Warn("пропущена поврежденная запись журнала брокера",
+				zap.String("service", "diskbroker"),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		b.seq = max(b.seq, rec.Seq)
+		switch rec.Op {
+		case opSubscribe:
+			b.addKnown(rec.Topic, rec.Subscriber)
+		case opPublish:
+			if rec.Envelope != nil {
+				b.store(rec.Seq, rec.Topic, *rec.Envelope)
+			}
+		case opAck, opDead:
+			b.forget(rec.Seq, rec.Subscriber)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("чтение журнала брокера: %w", err)
+	}
+
+	return nil
+}
+
+// snapshot - записи сжатого журнала: последний seq, подписчики, неподтвержденные сообщения
+// и подтверждения тех подписчиков, которые с ними уже закончили.
+func (b *Broker) snapshot() []record {
+	recs := []record{{Op: opSeq, Seq: b.seq}}
+	for topic, subs := range b.known {
+		for name := range subs {
+			recs = append(recs, record{Op: opSubscribe, Topic: topic, Subscriber: name})
+		}
+	}
+	for _, msg := range b.sorted() {
+		recs = append(recs, record{Op: opPublish, Seq: msg.seq, Topic: msg.topic, Envelope: &msg.env})
+		for name := range b.known[msg.topic] {
+			if _, ok := msg.pending[name]; !ok {
+				recs = append(recs, record{Op: opAck, Seq: msg.seq, Subscriber: name})
+			}
+		}
+	}
+
+	return recs
+}
+
+// compact - перезаписывает журнал снимком состояния (см. snapshot) через временный файл
+// и возвращает новый журнал, открытый на дозапись. Вызывается под b.mu.
+func (b *Broker) compact() (*os.File, error) {
+	recs := b.snapshot()
+
+	tmp := b.path(logFile + ".tmp")
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("os.OpenFile: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range recs {
+		if err := enc.Encode(rec); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("сжатие журнала брокера: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("сжатие журнала брокера: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("сжатие журнала брокера: %w", err)
+	}
+
+	if err := os.Rename(tmp, b.path(logFile)); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("os.Rename: %w", err)
+	}
+
+	b.records = len(recs)
+	b.compactAt = max(b.cfg.CompactMin, compactRatio*len(recs))
+
+	return f, nil
+}
+
+// store - сохраняет опубликованное сообщение для всех известных подписчиков топика.
+func (b *Broker) store(seq uint64, topic models.Topic, env models.Envelope) {
+	pending := make(map[string]struct{}, len(b.known[topic]))
+	for name := range b.known[topic] {
+		pending[name] = struct{}{}
+	}
+	if len(pending) > 0 {
+		b.msgs[seq] = &stored{seq: seq, topic: topic, env: env, pending: pending}
+	}
+}
+
+// forget - подписчик закончил с сообщением; сообщение без ожидающих подписчиков удаляется.
+func (b *Broker) forget(seq uint64, subscriber string) {
+	msg, ok := b.msgs[seq]
+	if !ok {
+		return
+	}
+
+	delete(msg.pending, subscriber)
+	if len(msg.pending) == 0 {
+		delete(b.msgs, seq)
+	}
+}
+
+func (b *Broker) addKnown(topic models.Topic, subscriber string) {
+	if b.known[topic] == nil {
+		b.known[topic] = make(map[string]struct{})
+	}
+	b.known[topic][subscriber] = struct{}{}
+}
+
+// sorted - сохраненные сообщения в порядке публикации.
+func (b *Broker) sorted() []*stored {
+	res := make([]*stored, 0, len(b.msgs))
+	for _, msg := range b.msgs {
+		res = append(res, msg)
+	}
+	slices.SortFunc(res, func(x, y *stored) int {
+		return cmp.Compare(x.seq, y.seq)
+	})
+
+	return res
+}
+
+func (b *Broker) path(name string) string {
+	return filepath.Join(b.cfg.Dir, name)
+}
+
+func key(seq uint64) string {
+	return strconv.FormatUint(seq, 10)
+}
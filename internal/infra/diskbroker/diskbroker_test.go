@@ -0,0 +1,363 @@
+package diskbroker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func testConfig(t *testing.T) config.BrokerConfig {
+	t.Helper()
+
+	return config.BrokerConfig{
+		Driver:      "disk",
+		Dir:         t.TempDir(),
+		MaxAttempts: 3,
+		RetryBase:   time.Millisecond,
+		RetryMax:    10 * time.Millisecond,
+	}
+}
+
+func open(t *testing.T, cfg config.BrokerConfig) *Broker {
+	t.Helper()
+
+	b, err := Open(cfg, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.Close() })
+
+	return b
+}
+
+// collector - обработчик, запоминающий ID событий из полученных конвертов.
+type collector struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (c *collector) handle(_ context.Context, env models.Envelope) error {
+	var event models.Event
+	if err := env.Decode(&event); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ids = append(c.ids, event.ID)
+	return nil
+}
+
+func (c *collector) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.ids...)
+}
+
+const topic = models.TopicEvents
+
+func envelope(t *testing.T, event models.Event) models.Envelope {
+	t.Helper()
+
+	env, err := models.NewEnvelope(models.EventCreated, event)
+	require.NoError(t, err)
+
+	return env
+}
+
+// publish - публикует событие в топик теста.
+func publish(t *testing.T, b *Broker, event models.Event) error {
+	t.Helper()
+
+	return b.Publish(context.Background(), topic, envelope(t, event))
+}
+
+func TestDeliverAndAck(t *testing.T) {
+	cfg := testConfig(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := open(t, cfg)
+	c := &collector{}
+	require.NoError(t, b.Subscribe(ctx, topic, "test", c.handle))
+	require.ErrorIs(t, b.Subscribe(ctx, topic, "test", c.handle), errSubscribed)
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, publish(t, b, models.Event{ID: id}))
+	}
+
+	require.Eventually(t, func() bool { return len(c.received()) == 3 }, time.Second, 5*time.Millisecond)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, c.received())
+	require.Eventually(t, func() bool { return b.Pending() == 0 }, time.Second, 5*time.Millisecond)
+
+	// Подтвержденные сообщения после перезапуска не доставляются.
+	cancel()
+	require.NoError(t, b.Close())
+	reopened := open(t, cfg)
+	assert.Equal(t, 0, reopened.Pending())
+}
+
+func TestRedeliverAfterRestart(t *testing.T) {
+	cfg := testConfig(t)
+	ctx := context.Background()
+
+	b := open(t, cfg)
+	// Подписчик зарегистрирован в прошлом запуске, сейчас не активен.
+	subCtx, cancelSub := context.WithCancel(ctx)
+	require.NoError(t, b.Subscribe(subCtx, topic, "test", (&collector{}).handle))
+	cancelSub()
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.active[topic]) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, publish(t, b, models.Event{ID: "a", Text: "сохранено"}))
+	require.NoError(t, publish(t, b, models.Event{ID: "b"}))
+	require.NoError(t, b.Close())
+	require.ErrorIs(t, publish(t, b, models.Event{ID: "c"}), errClosed)
+
+	// Оборванная запись в конце журнала не мешает восстановлению.
+	f, err := os.OpenFile(b.path(logFile), os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"pub","seq":3,"topic":"calendar.ev`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reopened := open(t, cfg)
+	require.Equal(t, 2, reopened.Pending())
+
+	c := &collector{}
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	require.NoError(t, reopened.Subscribe(subCtx, topic, "test", c.handle))
+	require.Eventually(t, func() bool { return len(c.received()) == 2 }, time.Second, 5*time.Millisecond)
+	assert.ElementsMatch(t, []string{"a", "b"}, c.received())
+
+	// Новые сообщения продолжают нумерацию после восстановленных.
+	require.NoError(t, publish(t, reopened, models.Event{ID: "c"}))
+	require.Eventually(t, func() bool { return len(c.received()) == 3 }, time.Second, 5*time.Millisecond)
+}
+
+func TestReplayInPublishOrder(t *testing.T) {
+	cfg := testConfig(t)
+	b := open(t, cfg)
+	// Подписчик только регистрируется: сообщения копятся до следующего запуска.
+	subCtx, cancelSub := context.WithCancel(context.Background())
+	require.NoError(t, b.Subscribe(subCtx, topic, "test", (&collector{}).handle))
+	cancelSub()
+	require.NoError(t, b.Close())
+
+	var ids []string
+	b = open(t, cfg)
+	for i := range 50 {
+		ids = append(ids, strconv.Itoa(i))
+		require.NoError(t, publish(t, b, models.Event{ID: ids[i]}))
+	}
+	require.NoError(t, b.Close())
+
+	reopened := open(t, cfg)
+	c := &collector{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, reopened.Subscribe(ctx, topic, "test", c.handle))
+	require.Eventually(t, func() bool { return len(c.received()) == len(ids) }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, ids, c.received())
+}
+
+func TestCompactAtRuntime(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.CompactMin = 8
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := open(t, cfg)
+	c := &collector{}
+	require.NoError(t, b.Subscribe(ctx, topic, "test", c.handle))
+	for i := range 50 {
+		require.NoError(t, publish(t, b, models.Event{ID: strconv.Itoa(i)}))
+		require.Eventually(t, func() bool { return b.Pending() == 0 }, time.Second, time.Millisecond)
+	}
+
+	// Без сжатия в журнале была бы 101 запись: подписчик, 50 публикаций и 50 подтверждений.
+	data, err := os.ReadFile(b.path(logFile))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, strings.Count(string(data), "\n"), cfg.CompactMin)
+
+	// Запись после сжатия попадает в новый журнал.
+	require.NoError(t, publish(t, b, models.Event{ID: "last"}))
+	require.NoError(t, b.Close())
+	reopened := open(t, cfg)
+	assert.Equal(t, 1, reopened.Pending())
+}
+
+func TestSeqAfterAllAcked(t *testing.T) {
+	cfg := testConfig(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := open(t, cfg)
+	require.NoError(t, b.Subscribe(ctx, topic, "test", (&collector{}).handle))
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, publish(t, b, models.Event{ID: id}))
+	}
+	require.Eventually(t, func() bool { return b.Pending() == 0 }, time.Second, 5*time.Millisecond)
+	cancel()
+	require.NoError(t, b.Close())
+
+	// Сжатие при открытии оставляет только последний seq: нумерация не начинается заново.
+	reopened := open(t, cfg)
+	require.NoError(t, reopened.Close())
+	reopened = open(t, cfg)
+	require.NoError(t, publish(t, reopened, models.Event{ID: "d"}))
+	assert.Equal(t, uint64(4), reopened.seq)
+}
+
+func TestRetryThenAck(t *testing.T) {
+	cfg := testConfig(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := open(t, cfg)
+
+	var mu sync.Mutex
+	calls := 0
+	require.NoError(t, b.Subscribe(ctx, topic, "test", func(context.Context, models.Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if calls < 3 {
+			return errors.New("временная ошибка")
+		}
+		return nil
+	}))
+	require.NoError(t, publish(t, b, models.Event{ID: "a"}))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls == 3 && b.Pending() == 0
+	}, time.Second, 5*time.Millisecond)
+
+	dead, err := b.DeadLetters()
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+}
+
+func TestDeadLetters(t *testing.T) {
+	cfg := testConfig(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := open(t, cfg)
+	require.NoError(t, b.Subscribe(ctx, topic, "test", func(_ context.Context, env models.Envelope) error {
+		var event models.Event
+		require.NoError(t, env.Decode(&event))
+		if event.ID == "panic" {
+			panic("сбой")
+		}
+		return errors.New("постоянная ошибка")
+	}))
+	require.NoError(t, publish(t, b, models.Event{ID: "bad", Text: "не доставится"}))
+	require.NoError(t, publish(t, b, models.Event{ID: "panic"}))
+
+	var dead []DeadLetter
+	require.Eventually(t, func() bool {
+		var err error
+		dead, err = b.DeadLetters()
+		return err == nil && len(dead) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	byID := map[string]DeadLetter{}
+	texts := map[string]string{}
+	for _, dl := range dead {
+		var event models.Event
+		require.NoError(t, dl.Envelope.Decode(&event))
+		byID[event.ID] = dl
+		texts[event.ID] = event.Text
+	}
+	assert.Equal(t, 3, byID["bad"].Attempts)
+	assert.Equal(t, "постоянная ошибка", byID["bad"].Error)
+	assert.Equal(t, "test", byID["bad"].Subscriber)
+	assert.Equal(t, topic, byID["bad"].Topic)
+	assert.Equal(t, "не доставится", texts["bad"])
+	assert.Contains(t, byID["panic"].Error, errPanic.Error())
+	assert.Equal(t, 0, b.Pending())
+
+	// Сообщения из DLQ не доставляются повторно после перезапуска.
+	cancel()
+	require.NoError(t, b.Close())
+	reopened := open(t, cfg)
+	assert.Equal(t, 0, reopened.Pending())
+	dead, err := reopened.DeadLetters()
+	require.NoError(t, err)
+	assert.Len(t, dead, 2)
+}
+
+func TestPublishDoesNotDrop(t *testing.T) {
+	cfg := testConfig(t)
+	b := open(t, cfg)
+
+	// Без подписчиков сообщение некому доставлять.
+	require.NoError(t, publish(t, b, models.Event{ID: "lost"}))
+	assert.Equal(t, 0, b.Pending())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, b.Subscribe(ctx, topic, "test", (&collector{}).handle))
+
+	const n = 5000
+	for i := 0; i < n; i++ {
+		require.NoError(t, publish(t, b, models.Event{ID: "e"}))
+	}
+	assert.Equal(t, n, b.Pending())
+}
+
+func TestIndependentSubscribers(t *testing.T) {
+	cfg := testConfig(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := open(t, cfg)
+	ok := &collector{}
+	require.NoError(t, b.Subscribe(ctx, topic, "ok", ok.handle))
+	require.NoError(t, b.Subscribe(ctx, topic, "failing", func(context.Context, models.Envelope) error {
+		return errors.New("ошибка")
+	}))
+	other := &collector{}
+	require.NoError(t, b.Subscribe(ctx, models.TopicReminders, "ok", other.handle))
+
+	require.NoError(t, publish(t, b, models.Event{ID: "a"}))
+
+	// Сбой одного подписчика не мешает другому; другой топик сообщение не получает.
+	require.Eventually(t, func() bool { return len(ok.received()) == 1 }, time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool {
+		dead, err := b.DeadLetters()
+		return err == nil && len(dead) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Empty(t, other.received())
+	assert.Equal(t, 0, b.Pending())
+}
+
+func TestBackoff(t *testing.T) {
+	b := &Broker{cfg: config.BrokerConfig{RetryBase: time.Second, RetryMax: 10 * time.Second}}
+
+	assert.Equal(t, time.Second, b.backoff(1))
+	assert.Equal(t, 2*time.Second, b.backoff(2))
+	assert.Equal(t, 8*time.Second, b.backoff(4))
+	assert.Equal(t, 10*time.Second, b.backoff(5))
+	assert.Equal(t, 10*time.Second, b.backoff(60))
+}
@@ -0,0 +1,9 @@
+package diskbroker
+
+import "errors"
+
+var (
+	errClosed     = errors.New("брокер закрыт")
+	errSubscribed = errors.New("у брокера уже есть подписчик")
+	errPanic      = errors.New("паника в обработчике")
+)
@@ -2,7 +2,9 @@ package inmembroker
 
 import (
 	"context"
+	"errors"
 	"runtime/debug"
+	"sync"
 
 	"go.uber.org/zap"
 
@@ -12,53 +14,93 @@ import (
 
 var _ infra.Broker = (*inmemBroker)(nil)
 
+var errSubscribed = errors.New("подписчик с таким именем уже подписан на топик")
+
 type inmemBroker struct {
-	eventChan chan *models.Event
-	logger    *zap.Logger
+	chanSize int
+	logger   *zap.Logger
+
+	mu     sync.RWMutex
+	topics map[models.Topic]map[string]chan models.Envelope
 }
 
+// New - конструктор in-memory брокера. chanSize - размер очереди каждого подписчика.
 func New(chanSize int, logger *zap.Logger) infra.Broker {
 	return &inmemBroker{
-		eventChan: make(chan *models.Event, chanSize),
-		logger:    logger,
+		chanSize: chanSize,
+		logger:   logger,
+		topics:   make(map[models.Topic]map[string]chan models.Envelope),
 	}
 }
 
-func (b *inmemBroker) Publish(ctx context.Context, event *models.Event) error {
+// Publish - раздает конверт в очереди всех подписчиков топика.
+// Если очередь подписчика переполнена, сообщение для него теряется.
+func (b *inmemBroker) Publish(ctx context.Context, topic models.Topic, env models.Envelope) error {
 	logger := b.logger.With(
 		zap.String("service", "inmembroker"),
 		zap.String("op", "Publish"),
+		zap.String("topic", string(topic)),
+		zap.String("type", string(env.Type)),
 	)
 
-	select {
-	case b.eventChan <- event:
-		logger.Info("событие успешно отправлено в брокер",
-			zap.String("event_id", event.ID),
-			zap.Int64("user_id", event.UserID),
-			zap.String("event", event.Text),
-			zap.Time("date", event.Date),
-		)
-		return nil
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-		logger.Warn("брокер переполнен, событие не может быть отправлено")
-		return nil
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for name, ch := range b.topics[topic] {
+		select {
+		case ch <- env:
+		default:
+			logger.Warn("очередь подписчика переполнена, событие не может быть отправлено",
+				zap.String("subscriber", name),
+			)
+		}
+	}
+	logger.Debug("событие отправлено в брокер", zap.String("id", env.ID))
+
+	return nil
 }
 
+// Subscribe - подписывает обработчик на топик. Обработчик вызывается последовательно;
+// ошибка обработчика только логируется.
 func (b *inmemBroker) Subscribe(
 	ctx context.Context,
-	handler func(ctx context.Context, event *models.Event) error,
+	topic models.Topic,
+	subscriber string,
+	handler infra.Handler,
 ) error {
 	logger := b.logger.With(
 		zap.String("service", "inmembroker"),
 		zap.String("op", "Subscribe"),
+		zap.String("topic", string(topic)),
+		zap.String("subscriber", subscriber),
 	)
+
+	b.mu.Lock()
+	subs, ok := b.topics[topic]
+	if !ok {
+		subs = make(map[string]chan models.Envelope)
+		b.topics[topic] = subs
+	}
+	if _, ok := subs[subscriber]; ok {
+		b.mu.Unlock()
+		return errSubscribed
+	}
+	ch := make(chan models.Envelope, b.chanSize)
+	subs[subscriber] = ch
+	b.mu.Unlock()
+
 	logger.Info("запуск подписки на события брокера")
 
 	go func() {
 		defer func() {
+			b.mu.Lock()
+			delete(b.topics[topic], subscriber)
+			b.mu.Unlock()
+
 			if r := recover(); r != nil {
 				logger.Error("паника в горутине подписки на события брокера",
 					zap.Any("rec", r),
@@ -69,14 +111,12 @@ func (b *inmemBroker) Subscribe(
 
 		for {
 			select {
-			case event := <-b.eventChan:
-				logger.Info("получено событие из брокера",
-					zap.String("event_id", event.ID),
-				)
-				if err := handler(ctx, event); err != nil {
+			case env := <-ch:
+				if err := handler(ctx, env); err != nil {
 					logger.Error("ошибка при обработке события",
 						zap.Error(err),
-						zap.String("event_id", event.ID),
+						zap.String("id", env.ID),
+						zap.String("type", string(env.Type)),
 					)
 				}
 			case <-ctx.Done():
@@ -0,0 +1,110 @@
+package inmemdb
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.CalendarRepo = (*inmemCalendarRepo)(nil)
+
+type inmemCalendarRepo struct {
+	data   map[string]models.Calendar
+	logger *zap.Logger
+	mu     sync.RWMutex
+}
+
+// NewCalendarRepo - конструктор in-memory хранилища календарей.
+func NewCalendarRepo(log *zap.Logger) infra.CalendarRepo {
+	return &inmemCalendarRepo{
+		data:   make(map[string]models.Calendar),
+		logger: log,
+	}
+}
+
+func (db *inmemCalendarRepo) Create(_ context.Context, calendar *models.Calendar) error {
+	if calendar == nil {
+		return errNilCalendar
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.data[calendar.ID]; exists {
+		return errDuplicate
+	}
+
+	db.data[calendar.ID] = cloneCalendar(*calendar)
+	return nil
+}
+
+func (db *inmemCalendarRepo) Get(_ context.Context, calendarID string) (*models.Calendar, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	calendar, exists := db.data[calendarID]
+	if !exists {
+		return nil, nil
+	}
+
+	calendar = cloneCalendar(calendar)
+	return &calendar, nil
+}
+
+func (db *inmemCalendarRepo) List(_ context.Context, userID int64) ([]models.Calendar, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	res := make([]models.Calendar, 0)
+	for _, calendar := range db.data {
+		if calendar.UserID == userID {
+			res = append(res, cloneCalendar(calendar))
+		}
+	}
+	slices.SortFunc(res, func(a, b models.Calendar) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return res, nil
+}
+
+func (db *inmemCalendarRepo) Update(_ context.Context, calendar *models.Calendar) error {
+	if calendar == nil {
+		return errNilCalendar
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.data[calendar.ID]; !exists {
+		return errNotFound
+	}
+
+	db.data[calendar.ID] = cloneCalendar(*calendar)
+	return nil
+}
+
+func (db *inmemCalendarRepo) Delete(_ context.Context, calendarID string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.data[calendarID]; !exists {
+		return false, nil
+	}
+	delete(db.data, calendarID)
+
+	return true, nil
+}
+
+func cloneCalendar(calendar models.Calendar) models.Calendar {
+	if calendar.Reminders != nil {
+		calendar.Reminders = append([]models.Reminder(nil), calendar.Reminders...)
+	}
+	return calendar
+}
@@ -0,0 +1,223 @@
+package inmemdb
+
+import (
+	"cmp"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/internal/skiplist"
+	"github.com/sunr3d/simple-http-calendar/internal/textsearch"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// dateKey - ключ упорядоченных индексов: начало события, при равенстве - ID.
+type dateKey struct {
+	at int64
+	id string
+}
+
+func compareKeys(a, b dateKey) int {
+	return cmp.Or(cmp.Compare(a.at, b.at), cmp.Compare(a.id, b.id))
+}
+
+func keyOf(evnt models.Event) dateKey {
+	return dateKey{at: evnt.Date.UnixNano(), id: evnt.ID}
+}
+
+type idSet map[string]struct{}
+
+// indexes - вторичные индексы хранилища.
+// Упорядоченные индексы содержат только одиночные события: вхождения серий разворачивает сервис,
+// поэтому серия попадает в выборку по периоду независимо от своего начала и хранится отдельно.
+type indexes struct {
+	byDate *skiplist.List[dateKey]
+	active *skiplist.List[dateKey] // неархивные
+	byUser map[int64]*skiplist.List[dateKey]
+
+	series     idSet
+	userSeries map[int64]idSet
+	bySeries   map[string]idSet            // события с SeriesID: исключения и продолжения серии
+	pending    idSet                       // с неотправленными напоминаниями
+	invited    map[int64]idSet             // события по приглашению участника, включая серии
+	claims     map[string]idSet            // события, занявшие ресурс
+	text       map[int64]*textsearch.Index // полнотекстовый индекс по пользователю
+
+	// maxDuration - наибольшая длительность одиночного события. Не уменьшается при удалении:
+	// выборка по периоду начинается с From - maxDuration, чтобы не пропустить начавшиеся раньше события.
+	maxDuration time.Duration
+}
+
+func newIndexes() indexes {
+	return indexes{
+		byDate:     skiplist.New(compareKeys),
+		active:     skiplist.New(compareKeys),
+		byUser:     make(map[int64]*skiplist.List[dateKey]),
+		series:     make(idSet),
+		userSeries: make(map[int64]idSet),
+		bySeries:   make(map[string]idSet),
+		pending:    make(idSet),
+		invited:    make(map[int64]idSet),
+		claims:     make(map[string]idSet),
+		text:       make(map[int64]*textsearch.Index),
+	}
+}
+
+func (ix *indexes) add(evnt models.Event) {
+	if evnt.RRule != "" {
+		ix.series[evnt.ID] = struct{}{}
+		addTo(ix.userSeries, evnt.UserID, evnt.ID)
+	} else {
+		key := keyOf(evnt)
+		ix.byDate.Insert(key)
+		if !evnt.Archived {
+			ix.active.Insert(key)
+		}
+		user, ok := ix.byUser[evnt.UserID]
+		if !ok {
+			user = skiplist.New(compareKeys)
+			ix.byUser[evnt.UserID] = user
+		}
+		user.Insert(key)
+		ix.maxDuration = max(ix.maxDuration, evnt.Duration())
+	}
+
+	if evnt.SeriesID != "" {
+		addTo(ix.bySeries, evnt.SeriesID, evnt.ID)
+	}
+	if evnt.ReminderPending() {
+		ix.pending[evnt.ID] = struct{}{}
+	}
+	for _, a := range evnt.Attendees {
+		if a.UserID != evnt.UserID {
+			addTo(ix.invited, a.UserID, evnt.ID)
+		}
+	}
+	for _, resourceID := range evnt.Resources {
+		addTo(ix.claims, resourceID, evnt.ID)
+	}
+
+	text, ok := ix.text[evnt.UserID]
+	if !ok {
+		text = textsearch.New()
+		ix.text[evnt.UserID] = text
+	}
+	text.Add(evnt.ID, evnt.Text)
+}
+
+func (ix *indexes) remove(evnt models.Event) {
+	if evnt.RRule != "" {
+		delete(ix.series, evnt.ID)
+		removeFrom(ix.userSeries, evnt.UserID, evnt.ID)
+	} else {
+		key := keyOf(evnt)
+		ix.byDate.Delete(key)
+		ix.active.Delete(key)
+		if user, ok := ix.byUser[evnt.UserID]; ok {
+			user.Delete(key)
+			if user.Len() == 0 {
+				delete(ix.byUser, evnt.UserID)
+			}
+		}
+	}
+
+	if evnt.SeriesID != "" {
+		removeFrom(ix.bySeries, evnt.SeriesID, evnt.ID)
+	}
+	delete(ix.pending, evnt.ID)
+	for _, a := range evnt.Attendees {
+		removeFrom(ix.invited, a.UserID, evnt.ID)
+	}
+	for _, resourceID := range evnt.Resources {
+		removeFrom(ix.claims, resourceID, evnt.ID)
+	}
+
+	if text, ok := ix.text[evnt.UserID]; ok {
+		text.Remove(evnt.ID)
+		if text.Len() == 0 {
+			delete(ix.text, evnt.UserID)
+		}
+	}
+}
+
+// candidates - ID событий, среди которых лежит выборка opts, из самого узкого подходящего индекса.
+// Результат еще нужно отфильтровать matchesFilter.
+func (ix *indexes) candidates(opts *infra.ListOptions, yield func(id string)) {
+	switch {
+	case opts.SeriesID != nil:
+		for id := range ix.bySeries[*opts.SeriesID] {
+			yield(id)
+		}
+		return
+	case opts.ReminderPending != nil && *opts.ReminderPending:
+		for id := range ix.pending {
+			yield(id)
+		}
+		return
+	case opts.ResourceID != nil:
+		for id := range ix.claims[*opts.ResourceID] {
+			yield(id)
+		}
+		return
+	}
+
+	dates, series := ix.byDate, ix.series
+	switch {
+	case opts.UserID != nil:
+		dates, series = ix.byUser[*opts.UserID], ix.userSeries[*opts.UserID]
+	case opts.Participant != nil:
+		dates, series = ix.byUser[*opts.Participant], ix.userSeries[*opts.Participant]
+		// Приглашений у пользователя немного, они перебираются целиком и отсекаются matchesFilter.
+		for id := range ix.invited[*opts.Participant] {
+			yield(id)
+		}
+	case opts.Archived != nil && !*opts.Archived:
+		dates = ix.active
+	}
+
+	if opts.Recurring == nil || !*opts.Recurring {
+		ix.scan(dates, opts, yield)
+	}
+	if opts.Recurring == nil || *opts.Recurring {
+		for id := range series {
+			yield(id)
+		}
+	}
+}
+
+// scan - одиночные события индекса, которые могут пересекаться с [From; To).
+func (ix *indexes) scan(dates *skiplist.List[dateKey], opts *infra.ListOptions, yield func(id string)) {
+	if dates == nil {
+		return
+	}
+
+	seq := dates.All()
+	if opts.From != nil {
+		seq = dates.From(dateKey{at: opts.From.Add(-ix.maxDuration).UnixNano()})
+	}
+	for key := range seq {
+		if opts.To != nil && key.at >= opts.To.UnixNano() {
+			return
+		}
+		yield(key.id)
+	}
+}
+
+func addTo[K comparable](sets map[K]idSet, key K, id string) {
+	set, ok := sets[key]
+	if !ok {
+		set = make(idSet)
+		sets[key] = set
+	}
+	set[id] = struct{}{}
+}
+
+func removeFrom[K comparable](sets map[K]idSet, key K, id string) {
+	set, ok := sets[key]
+	if !ok {
+		return
+	}
+	delete(set, id)
+	if len(set) == 0 {
+		delete(sets, key)
+	}
+}
@@ -1,7 +1,10 @@
 package inmemdb
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -13,20 +16,28 @@ import (
 
 var _ infra.Database = (*inmemRepo)(nil)
 
+// inmemRepo - in-memory хранилище событий со вторичными индексами (см. indexes),
+// благодаря которым выборки по периоду, напоминаниям и архивации не обходят все события.
 type inmemRepo struct {
-	data   map[string]models.Event
-	logger *zap.Logger
-	mu     sync.RWMutex
+	data      map[string]models.Event
+	index     indexes
+	resources infra.ResourceRepo
+	logger    *zap.Logger
+	mu        sync.RWMutex
 }
 
-func New(log *zap.Logger) infra.Database {
+// New - конструктор in-memory хранилища событий. По resources проверяется вместимость
+// занимаемых событиями ресурсов; nil - вместимость не проверяется.
+func New(log *zap.Logger, resources infra.ResourceRepo) infra.Database {
 	return &inmemRepo{
-		data:   make(map[string]models.Event),
-		logger: log,
+		data:      make(map[string]models.Event),
+		index:     newIndexes(),
+		resources: resources,
+		logger:    log,
 	}
 }
 
-func (db *inmemRepo) Create(_ context.Context, event *models.Event) error {
+func (db *inmemRepo) Create(ctx context.Context, event *models.Event) error {
 	if event == nil {
 		return errNilEvent
 	}
@@ -37,8 +48,12 @@ func (db *inmemRepo) Create(_ context.Context, event *models.Event) error {
 	if _, exists := db.data[event.ID]; exists {
 		return errDuplicate
 	}
+	if err := db.claim(ctx, event, nil); err != nil {
+		return err
+	}
 
-	db.data[event.ID] = *event
+	db.data[event.ID] = cloneEvent(*event)
+	db.index.add(*event)
 	return nil
 }
 
@@ -51,10 +66,11 @@ func (db *inmemRepo) Read(_ context.Context, eventID string) (*models.Event, err
 		return nil, errNotFound
 	}
 
+	evnt = cloneEvent(evnt)
 	return &evnt, nil
 }
 
-func (db *inmemRepo) Update(_ context.Context, event *models.Event) error {
+func (db *inmemRepo) Update(ctx context.Context, event *models.Event) error {
 	if event == nil {
 		return errNilEvent
 	}
@@ -62,45 +78,233 @@ func (db *inmemRepo) Update(_ context.Context, event *models.Event) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	if _, exists := db.data[event.ID]; !exists {
+	current, exists := db.data[event.ID]
+	if !exists {
 		return errNotFound
 	}
+	if err := db.claim(ctx, event, &current); err != nil {
+		return err
+	}
 
-	db.data[event.ID] = *event
+	db.index.remove(current)
+	db.data[event.ID] = cloneEvent(*event)
+	db.index.add(*event)
 
 	return nil
 }
 
+func (db *inmemRepo) UpdateAttendees(
+	ctx context.Context,
+	eventID string,
+	change func(event *models.Event) error,
+) (*models.Event, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current, exists := db.data[eventID]
+	if !exists {
+		return nil, errNotFound
+	}
+
+	changed := cloneEvent(current)
+	if err := change(&changed); err != nil {
+		return nil, err
+	}
+	event := cloneEvent(current)
+	event.Attendees = changed.Attendees
+	if err := db.claim(ctx, &event, &current); err != nil {
+		return nil, err
+	}
+
+	db.index.remove(current)
+	db.data[eventID] = cloneEvent(event)
+	db.index.add(event)
+
+	return &event, nil
+}
+
 func (db *inmemRepo) Delete(_ context.Context, eventID string) (bool, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	_, exists := db.data[eventID]
+	current, exists := db.data[eventID]
 	if !exists {
 		return false, errNotFound
 	}
 
 	delete(db.data, eventID)
+	db.index.remove(current)
+
+	return true, nil
+}
+
+func (db *inmemRepo) MarkReminderSent(
+	_ context.Context,
+	eventID string,
+	offset models.Offset,
+	occurrence, sentAt time.Time,
+) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current, exists := db.data[eventID]
+	if !exists {
+		return false, errNotFound
+	}
+
+	event := cloneEvent(current)
+	if !event.MarkReminderSent(offset, occurrence, sentAt) {
+		return false, nil
+	}
+
+	db.index.remove(current)
+	db.data[eventID] = event
+	db.index.add(event)
+
+	return true, nil
+}
+
+func (db *inmemRepo) Archive(_ context.Context, eventID string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current, exists := db.data[eventID]
+	if !exists {
+		return false, errNotFound
+	}
+	if current.Archived {
+		return false, nil
+	}
+
+	event := cloneEvent(current)
+	event.Archived = true
+	db.index.remove(current)
+	db.data[eventID] = event
+	db.index.add(event)
 
 	return true, nil
 }
 
 func (db *inmemRepo) List(_ context.Context, opts *infra.ListOptions) ([]models.Event, error) {
+	if opts == nil {
+		opts = &infra.ListOptions{}
+	}
+
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
-	res := make([]models.Event, 0, len(db.data))
-	for _, evnt := range db.data {
-		if !db.matchesFilter(evnt, opts) {
-			continue
+	res := make([]models.Event, 0)
+	db.index.candidates(opts, func(id string) {
+		if evnt := db.data[id]; db.matchesFilter(evnt, opts) {
+			res = append(res, cloneEvent(evnt))
+		}
+	})
+
+	slices.SortFunc(res, func(a, b models.Event) int {
+		c := cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.ID, b.ID))
+		if opts.Order == infra.OrderDesc {
+			return -c
 		}
+		return c
+	})
+
+	return page(res, opts.Offset, opts.Limit), nil
+}
+
+func (db *inmemRepo) Search(_ context.Context, opts infra.SearchOptions) ([]models.Event, error) {
+	filter := &infra.ListOptions{
+		UserID:      &opts.UserID,
+		CalendarIDs: opts.CalendarIDs,
+		From:        opts.From,
+		To:          opts.To,
+		Archived:    opts.Archived,
+	}
 
-		res = append(res, evnt)
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	res := make([]models.Event, 0)
+	text, ok := db.index.text[opts.UserID]
+	if !ok {
+		return res, nil
+	}
+
+	for _, hit := range text.Search(opts.Query) {
+		if evnt := db.data[hit.ID]; db.matchesFilter(evnt, filter) {
+			res = append(res, cloneEvent(evnt))
+			if len(res) == opts.Limit {
+				break
+			}
+		}
 	}
 
 	return res, nil
 }
 
+// claim - проверяет, что событие может занять свои ресурсы: они не заняты пересекающимися событиями
+// (иначе *models.ConflictError) и вмещают его участников (иначе *models.BookingError, см. models.Resource.CheckCapacity).
+// prev - сохраненное состояние события, nil - новое событие. Вызывается под блокировкой записи,
+// поэтому проверки и сохранение брони атомарны: конкурентные приглашения не переполнят ресурс.
+func (db *inmemRepo) claim(ctx context.Context, event, prev *models.Event) error {
+	if ids := db.claimedBy(event); len(ids) > 0 {
+		return &models.ConflictError{EventIDs: ids}
+	}
+	if db.resources == nil {
+		return nil
+	}
+
+	for _, id := range event.Resources {
+		res, err := db.resources.Get(ctx, id)
+		if err != nil {
+			return fmt.Errorf("resources.Get: %w", err)
+		}
+		if res == nil {
+			continue
+		}
+		if err := res.CheckCapacity(event, prev); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// claimedBy - ID других событий, занявших ресурсы события на пересекающееся время, по началу.
+func (db *inmemRepo) claimedBy(event *models.Event) []string {
+	var busy []models.Event
+	for _, resourceID := range event.Resources {
+		for id := range db.index.claims[resourceID] {
+			other := db.data[id]
+			if id == event.ID || slices.ContainsFunc(busy, func(e models.Event) bool { return e.ID == id }) {
+				continue
+			}
+			if other.Date.Before(event.EndTime()) && event.Date.Before(other.EndTime()) {
+				busy = append(busy, other)
+			}
+		}
+	}
+	slices.SortFunc(busy, func(a, b models.Event) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.ID, b.ID))
+	})
+
+	ids := make([]string, 0, len(busy))
+	for _, e := range busy {
+		ids = append(ids, e.ID)
+	}
+
+	return ids
+}
+
+// page - срез [offset; offset+limit) упорядоченной выборки; limit 0 - до конца.
+func page(events []models.Event, offset, limit int) []models.Event {
+	events = events[min(max(offset, 0), len(events)):]
+	if limit > 0 && limit < len(events) {
+		events = events[:limit]
+	}
+
+	return events
+}
+
 func (db *inmemRepo) matchesFilter(evnt models.Event, opts *infra.ListOptions) bool {
 	if opts == nil {
 		return true
@@ -110,35 +314,107 @@ func (db *inmemRepo) matchesFilter(evnt models.Event, opts *infra.ListOptions) b
 		return false
 	}
 
+	calendarID := evnt.CalendarID
+	if opts.Participant != nil && evnt.UserID != *opts.Participant {
+		a := evnt.Attendee(*opts.Participant)
+		if a == nil || a.Status == models.AttendeeDeclined {
+			return false
+		}
+		calendarID = ""
+	}
+
+	if opts.CalendarIDs != nil && !slices.Contains(opts.CalendarIDs, calendarID) {
+		return false
+	}
+
 	if opts.Archived != nil && evnt.Archived != *opts.Archived {
 		return false
 	}
 
-	if opts.From != nil {
-		eventDay := time.Date(
-			evnt.Date.Year(),
-			evnt.Date.Month(),
-			evnt.Date.Day(),
-			0, 0, 0, 0,
-			time.Local,
-		)
-		if eventDay.Before(*opts.From) {
+	if opts.ReminderPending != nil && evnt.ReminderPending() != *opts.ReminderPending {
+		return false
+	}
+
+	if opts.Recurring != nil && (evnt.RRule != "") != *opts.Recurring {
+		return false
+	}
+
+	if opts.SeriesID != nil && evnt.SeriesID != *opts.SeriesID {
+		return false
+	}
+
+	if opts.ResourceID != nil && !slices.Contains(evnt.Resources, *opts.ResourceID) {
+		return false
+	}
+
+	if opts.ICalUID != nil && evnt.ICalUID != *opts.ICalUID {
+		return false
+	}
+
+	if opts.From != nil || opts.To != nil {
+		var from, to time.Time
+		if opts.From != nil {
+			from = *opts.From
+		}
+		if opts.To != nil {
+			to = *opts.To
+		}
+		if evnt.RRule != "" {
+			// Вхождения серии разворачиваются сервисом, здесь отсекаются только серии, начинающиеся после окна.
+			from = time.Time{}
+		}
+		if !evnt.Overlaps(from, to) {
 			return false
 		}
 	}
 
-	if opts.To != nil {
-		eventDay := time.Date(
-			evnt.Date.Year(),
-			evnt.Date.Month(),
-			evnt.Date.Day(),
-			0, 0, 0, 0,
-			time.Local,
-		)
-		if eventDay.After(*opts.To) {
+	if opts.After != nil {
+		c := evnt.Key().Compare(*opts.After)
+		if opts.Order == infra.OrderDesc {
+			c = -c
+		}
+		if c <= 0 {
 			return false
 		}
 	}
 
 	return true
 }
+
+// cloneEvent - копирует событие вместе со срезами и указателями,
+// чтобы вызывающий код не мог изменить данные хранилища в обход Update.
+func cloneEvent(evnt models.Event) models.Event {
+	if evnt.ExDates != nil {
+		evnt.ExDates = append([]time.Time(nil), evnt.ExDates...)
+	}
+	if evnt.Channels != nil {
+		evnt.Channels = append([]models.Channel(nil), evnt.Channels...)
+	}
+	if evnt.Attendees != nil {
+		evnt.Attendees = append([]models.Attendee(nil), evnt.Attendees...)
+	}
+	if evnt.Resources != nil {
+		evnt.Resources = append([]string(nil), evnt.Resources...)
+	}
+	if evnt.Reminders != nil {
+		reminders := make([]models.Reminder, len(evnt.Reminders))
+		for i, r := range evnt.Reminders {
+			if r.SentAt != nil {
+				t := *r.SentAt
+				r.SentAt = &t
+			}
+			if r.Occurrence != nil {
+				t := *r.Occurrence
+				r.Occurrence = &t
+			}
+			reminders[i] = r
+		}
+		evnt.Reminders = reminders
+	}
+	if evnt.RecurrenceID != nil {
+		t := *evnt.RecurrenceID
+		evnt.RecurrenceID = &t
+	}
+
+	return evnt
+}
@@ -0,0 +1,406 @@
+package inmemdb
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newRepo(t testing.TB) *inmemRepo {
+	t.Helper()
+
+	repo, ok := New(zap.NewNop(), nil).(*inmemRepo)
+	require.True(t, ok)
+
+	return repo
+}
+
+// randomEvent - событие одного из users пользователей в пределах days дней от epoch.
+func randomEvent(rnd *rand.Rand, i, users, days int) models.Event {
+	date := epoch.Add(time.Duration(rnd.Int64N(int64(days) * int64(24*time.Hour))))
+	evnt := models.Event{
+		ID:       fmt.Sprintf("e-%07d", i),
+		UserID:   1 + rnd.Int64N(int64(users)),
+		Date:     date,
+		Text:     "event",
+		Archived: rnd.IntN(2) == 0,
+	}
+	if i%3 == 0 {
+		evnt.CalendarID = fmt.Sprintf("c-%d", i%2)
+	}
+	if rnd.IntN(4) == 0 {
+		evnt.End = date.Add(time.Duration(rnd.IntN(72)) * time.Hour)
+	}
+	if !evnt.Archived && rnd.IntN(10) == 0 {
+		evnt.Reminders = []models.Reminder{{Offset: models.Offset(-time.Hour)}}
+	}
+	if rnd.IntN(100) == 0 {
+		evnt.RRule = "FREQ=WEEKLY"
+	}
+	if rnd.IntN(100) == 0 {
+		evnt.SeriesID = fmt.Sprintf("s-%d", rnd.IntN(10))
+	}
+	if rnd.IntN(8) == 0 {
+		statuses := []models.AttendeeStatus{
+			models.AttendeeNeedsAction, models.AttendeeAccepted, models.AttendeeDeclined, models.AttendeeTentative,
+		}
+		evnt.Attendees = []models.Attendee{{UserID: 1 + rnd.Int64N(int64(users)), Status: statuses[rnd.IntN(len(statuses))]}}
+	}
+
+	return evnt
+}
+
+func fill(t testing.TB, repo *inmemRepo, n, users, days int) {
+	t.Helper()
+
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for i := range n {
+		evnt := randomEvent(rnd, i, users, days)
+		require.NoError(t, repo.Create(context.Background(), &evnt))
+	}
+}
+
+// scanList - выборка полным перебором, как до появления индексов.
+func (db *inmemRepo) scanList(opts *infra.ListOptions) []models.Event {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	res := make([]models.Event, 0)
+	for _, evnt := range db.data {
+		if db.matchesFilter(evnt, opts) {
+			res = append(res, cloneEvent(evnt))
+		}
+	}
+	slices.SortFunc(res, func(a, b models.Event) int {
+		c := cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.ID, b.ID))
+		if opts.Order == infra.OrderDesc {
+			return -c
+		}
+		return c
+	})
+
+	return page(res, opts.Offset, opts.Limit)
+}
+
+func ids(events []models.Event) []string {
+	res := make([]string, 0, len(events))
+	for _, e := range events {
+		res = append(res, e.ID)
+	}
+	return res
+}
+
+func TestIndexedListMatchesScan(t *testing.T) {
+	repo := newRepo(t)
+	ctx := context.Background()
+	fill(t, repo, 5000, 20, 60)
+
+	// Изменения и удаления должны поддерживать индексы в актуальном состоянии.
+	rnd := rand.New(rand.NewPCG(3, 4))
+	for i := range 1000 {
+		id := fmt.Sprintf("e-%07d", rnd.IntN(5000))
+		if i%3 == 0 {
+			_, _ = repo.Delete(ctx, id)
+			continue
+		}
+		updated := randomEvent(rnd, 0, 20, 60)
+		updated.ID = id
+		_ = repo.Update(ctx, &updated)
+	}
+
+	userID := int64(7)
+	archived := false
+	pending := true
+	recurring := false
+	seriesID := "s-3"
+	from := epoch.AddDate(0, 0, 10)
+	to := epoch.AddDate(0, 0, 17)
+	after := models.EventKey{Date: from, ID: "e-0002500"}
+
+	queries := map[string]*infra.ListOptions{
+		"all":                   {},
+		"user":                  {UserID: &userID},
+		"user range":            {UserID: &userID, From: &from, To: &to},
+		"user page":             {UserID: &userID, From: &from, To: &to, Recurring: &recurring, Order: infra.OrderDesc, Limit: 5, Offset: 3},
+		"user after":            {UserID: &userID, Limit: 5, After: &after},
+		"user before":           {UserID: &userID, Order: infra.OrderDesc, Limit: 5, After: &after},
+		"range":                 {From: &from, To: &to},
+		"from":                  {From: &from},
+		"active until":          {Archived: &archived, To: &to},
+		"pending":               {ReminderPending: &pending},
+		"series":                {SeriesID: &seriesID},
+		"calendars":             {UserID: &userID, CalendarIDs: []string{"", "c-1"}},
+		"no calendars":          {CalendarIDs: []string{}},
+		"participant":           {Participant: &userID, From: &from, To: &to},
+		"invitations":           {Participant: &userID, CalendarIDs: []string{""}},
+		"participant calendars": {Participant: &userID, CalendarIDs: []string{"c-1"}},
+	}
+	for name, opts := range queries {
+		t.Run(name, func(t *testing.T) {
+			got, err := repo.List(ctx, opts)
+			require.NoError(t, err)
+			assert.Equal(t, ids(repo.scanList(opts)), ids(got))
+		})
+	}
+}
+
+func TestUpdateMovesIndexes(t *testing.T) {
+	repo := newRepo(t)
+	ctx := context.Background()
+
+	evnt := models.Event{ID: "a", UserID: 1, Date: epoch, Reminders: []models.Reminder{{}}}
+	require.NoError(t, repo.Create(ctx, &evnt))
+
+	evnt.UserID = 2
+	evnt.Date = epoch.AddDate(0, 1, 0)
+	sentAt := epoch
+	evnt.Reminders[0].SentAt = &sentAt
+	evnt.Archived = true
+	require.NoError(t, repo.Update(ctx, &evnt))
+
+	user1, user2 := int64(1), int64(2)
+	archived, pending := false, true
+	from, to := epoch, epoch.AddDate(0, 0, 1)
+
+	for _, opts := range []*infra.ListOptions{
+		{UserID: &user1},
+		{From: &from, To: &to},
+		{Archived: &archived},
+		{ReminderPending: &pending},
+	} {
+		list, err := repo.List(ctx, opts)
+		require.NoError(t, err)
+		assert.Empty(t, list)
+	}
+
+	list, err := repo.List(ctx, &infra.ListOptions{UserID: &user2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, ids(list))
+
+	_, err = repo.Delete(ctx, "a")
+	require.NoError(t, err)
+	assert.Zero(t, repo.index.byDate.Len())
+	assert.Empty(t, repo.index.byUser)
+}
+
+func TestResourceClaims(t *testing.T) {
+	repo := newRepo(t)
+	ctx := context.Background()
+	at := func(h int) time.Time { return epoch.Add(time.Duration(h) * time.Hour) }
+
+	booked := models.Event{ID: "a", UserID: 1, Date: at(10), End: at(12), Resources: []string{"room"}}
+	require.NoError(t, repo.Create(ctx, &booked))
+	require.NoError(t, repo.Create(ctx, &models.Event{ID: "b", UserID: 2, Date: at(12), End: at(13), Resources: []string{"room"}}))
+
+	// Пересечение по любому из ресурсов отклоняет событие целиком.
+	err := repo.Create(ctx, &models.Event{ID: "c", UserID: 3, Date: at(11), End: at(14), Resources: []string{"projector", "room"}})
+	var conflict *models.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []string{"a", "b"}, conflict.EventIDs)
+	_, err = repo.Read(ctx, "c")
+	require.Error(t, err)
+
+	require.NoError(t, repo.Create(ctx, &models.Event{ID: "c", UserID: 3, Date: at(11), End: at(14), Resources: []string{"projector"}}))
+
+	// Событие не конфликтует само с собой, а освобожденное время можно занять.
+	booked.End = at(11)
+	require.NoError(t, repo.Update(ctx, &booked))
+	require.NoError(t, repo.Create(ctx, &models.Event{ID: "d", UserID: 3, Date: at(11), End: at(12), Resources: []string{"room"}}))
+
+	booked.End = at(12)
+	require.ErrorAs(t, repo.Update(ctx, &booked), &conflict)
+	assert.Equal(t, []string{"d"}, conflict.EventIDs)
+
+	resourceID := "room"
+	list, err := repo.List(ctx, &infra.ListOptions{ResourceID: &resourceID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "d", "b"}, ids(list))
+
+	_, err = repo.Delete(ctx, "d")
+	require.NoError(t, err)
+	require.NoError(t, repo.Update(ctx, &booked))
+}
+
+func TestConcurrentClaims(t *testing.T) {
+	repo := newRepo(t)
+	ctx := context.Background()
+
+	// Каждый хочет переговорную на час со сдвигом в 15 минут: пересекаются все, кроме отстоящих на час и больше.
+	const n = 64
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		won []models.Event
+	)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := epoch.Add(time.Duration(i%8) * 15 * time.Minute)
+			evnt := models.Event{
+				ID: fmt.Sprintf("e-%02d", i), UserID: int64(i + 1), Date: start, End: start.Add(time.Hour),
+				Resources: []string{"room", fmt.Sprintf("projector-%d", i%2)},
+			}
+			err := repo.Create(ctx, &evnt)
+			if err == nil {
+				mu.Lock()
+				won = append(won, evnt)
+				mu.Unlock()
+				return
+			}
+			var conflict *models.ConflictError
+			assert.ErrorAs(t, err, &conflict)
+		}()
+	}
+	wg.Wait()
+
+	require.NotEmpty(t, won)
+	for i, a := range won {
+		for _, b := range won[i+1:] {
+			assert.False(t, a.Date.Before(b.End) && b.Date.Before(a.End), "%s и %s заняли переговорную одновременно", a.ID, b.ID)
+		}
+	}
+
+	resourceID := "room"
+	list, err := repo.List(ctx, &infra.ListOptions{ResourceID: &resourceID})
+	require.NoError(t, err)
+	assert.Len(t, list, len(won))
+}
+
+func TestConcurrentCapacity(t *testing.T) {
+	resources := NewResourceRepo(zap.NewNop())
+	repo, ok := New(zap.NewNop(), resources).(*inmemRepo)
+	require.True(t, ok)
+	ctx := context.Background()
+
+	room := &models.Resource{ID: "room", UserID: 1, Name: "Переговорная", Capacity: 3}
+	require.NoError(t, resources.Create(ctx, room))
+	require.NoError(t, repo.Create(ctx, &models.Event{ID: "a", UserID: 1, Date: epoch, End: epoch.Add(time.Hour), Resources: []string{"room"}}))
+
+	// Места организатора и двух участников разбирают одновременные приглашения, остальные отклоняются.
+	const n = 16
+	var (
+		wg       sync.WaitGroup
+		invited  atomic.Int32
+		rejected atomic.Int32
+	)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.UpdateAttendees(ctx, "a", func(event *models.Event) error {
+				event.Attendees = append(event.Attendees, models.Attendee{UserID: int64(i + 2), Status: models.AttendeeNeedsAction})
+				return nil
+			})
+			var booking *models.BookingError
+			switch {
+			case err == nil:
+				invited.Add(1)
+			case assert.ErrorAs(t, err, &booking):
+				assert.Equal(t, "room", booking.ResourceID)
+				rejected.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), invited.Load())
+	assert.Equal(t, int32(n-2), rejected.Load())
+	event, err := repo.Read(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 3, event.Headcount())
+
+	// После уменьшения вместимости участник может отказаться, но не передумать.
+	room.Capacity = 1
+	require.NoError(t, resources.Update(ctx, room))
+	setStatus := func(status models.AttendeeStatus) func(*models.Event) error {
+		return func(event *models.Event) error {
+			event.Attendees[0].Status = status
+			return nil
+		}
+	}
+	_, err = repo.UpdateAttendees(ctx, "a", setStatus(models.AttendeeDeclined))
+	require.NoError(t, err)
+	_, err = repo.UpdateAttendees(ctx, "a", setStatus(models.AttendeeAccepted))
+	var booking *models.BookingError
+	require.ErrorAs(t, err, &booking)
+}
+
+const benchEvents = 1_000_000
+
+var (
+	benchOnce sync.Once
+	benchRepo *inmemRepo
+)
+
+// benchmarkRepo - 1M событий 10 000 пользователей за два года, общий на все бенчмарки.
+func benchmarkRepo(b *testing.B) *inmemRepo {
+	b.Helper()
+
+	benchOnce.Do(func() {
+		benchRepo = newRepo(b)
+		fill(b, benchRepo, benchEvents, 10_000, 730)
+	})
+
+	return benchRepo
+}
+
+func benchmarkList(b *testing.B, opts *infra.ListOptions) {
+	repo := benchmarkRepo(b)
+	ctx := context.Background()
+
+	b.Run("scan", func(b *testing.B) {
+		for b.Loop() {
+			_ = repo.scanList(opts)
+		}
+	})
+	b.Run("index", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = repo.List(ctx, opts)
+		}
+	})
+}
+
+func BenchmarkListUserWeek(b *testing.B) {
+	userID := int64(42)
+	archived := false
+	from := epoch.AddDate(0, 6, 0)
+	to := from.AddDate(0, 0, 7)
+
+	benchmarkList(b, &infra.ListOptions{UserID: &userID, Archived: &archived, From: &from, To: &to})
+}
+
+func BenchmarkListDay(b *testing.B) {
+	from := epoch.AddDate(0, 6, 0)
+	to := from.AddDate(0, 0, 1)
+
+	benchmarkList(b, &infra.ListOptions{From: &from, To: &to})
+}
+
+func BenchmarkListReminderPending(b *testing.B) {
+	pending := true
+
+	benchmarkList(b, &infra.ListOptions{ReminderPending: &pending})
+}
+
+func BenchmarkListArchive(b *testing.B) {
+	archived := false
+	now := epoch.AddDate(0, 0, 1)
+
+	benchmarkList(b, &infra.ListOptions{Archived: &archived, To: &now})
+}
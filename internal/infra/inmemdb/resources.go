@@ -0,0 +1,100 @@
+package inmemdb
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.ResourceRepo = (*inmemResourceRepo)(nil)
+
+type inmemResourceRepo struct {
+	data   map[string]models.Resource
+	logger *zap.Logger
+	mu     sync.RWMutex
+}
+
+// NewResourceRepo - конструктор in-memory хранилища ресурсов.
+func NewResourceRepo(log *zap.Logger) infra.ResourceRepo {
+	return &inmemResourceRepo{
+		data:   make(map[string]models.Resource),
+		logger: log,
+	}
+}
+
+func (db *inmemResourceRepo) Create(_ context.Context, resource *models.Resource) error {
+	if resource == nil {
+		return errNilResource
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.data[resource.ID]; exists {
+		return errDuplicate
+	}
+
+	db.data[resource.ID] = *resource
+	return nil
+}
+
+func (db *inmemResourceRepo) Get(_ context.Context, resourceID string) (*models.Resource, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	resource, exists := db.data[resourceID]
+	if !exists {
+		return nil, nil
+	}
+
+	return &resource, nil
+}
+
+func (db *inmemResourceRepo) List(_ context.Context) ([]models.Resource, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	res := make([]models.Resource, 0, len(db.data))
+	for _, resource := range db.data {
+		res = append(res, resource)
+	}
+	slices.SortFunc(res, func(a, b models.Resource) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+
+	return res, nil
+}
+
+func (db *inmemResourceRepo) Update(_ context.Context, resource *models.Resource) error {
+	if resource == nil {
+		return errNilResource
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.data[resource.ID]; !exists {
+		return errNotFound
+	}
+
+	db.data[resource.ID] = *resource
+	return nil
+}
+
+func (db *inmemResourceRepo) Delete(_ context.Context, resourceID string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.data[resourceID]; !exists {
+		return false, nil
+	}
+	delete(db.data, resourceID)
+
+	return true, nil
+}
@@ -0,0 +1,96 @@
+package inmemdb
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.ShareRepo = (*inmemShareRepo)(nil)
+
+type shareKey struct {
+	owner, grantee int64
+}
+
+type inmemShareRepo struct {
+	data   map[shareKey]models.Share
+	logger *zap.Logger
+	mu     sync.RWMutex
+}
+
+// NewShareRepo - конструктор in-memory хранилища доступов к календарям.
+func NewShareRepo(log *zap.Logger) infra.ShareRepo {
+	return &inmemShareRepo{
+		data:   make(map[shareKey]models.Share),
+		logger: log,
+	}
+}
+
+func (db *inmemShareRepo) Save(_ context.Context, share *models.Share) error {
+	if share == nil {
+		return errNilShare
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key := shareKey{owner: share.OwnerID, grantee: share.GranteeID}
+	stored := *share
+	if prev, ok := db.data[key]; ok {
+		stored.CreatedAt = prev.CreatedAt
+	}
+	db.data[key] = stored
+	return nil
+}
+
+func (db *inmemShareRepo) Get(_ context.Context, ownerID, granteeID int64) (*models.Share, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	share, exists := db.data[shareKey{owner: ownerID, grantee: granteeID}]
+	if !exists {
+		return nil, nil
+	}
+
+	return &share, nil
+}
+
+func (db *inmemShareRepo) List(_ context.Context, userID int64) ([]models.Share, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	res := make([]models.Share, 0)
+	for key, share := range db.data {
+		if key.owner == userID || key.grantee == userID {
+			res = append(res, share)
+		}
+	}
+	slices.SortFunc(res, func(a, b models.Share) int {
+		return cmp.Or(
+			a.CreatedAt.Compare(b.CreatedAt),
+			cmp.Compare(a.OwnerID, b.OwnerID),
+			cmp.Compare(a.GranteeID, b.GranteeID),
+		)
+	})
+
+	return res, nil
+}
+
+func (db *inmemShareRepo) Delete(_ context.Context, ownerID, granteeID int64) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key := shareKey{owner: ownerID, grantee: granteeID}
+	if _, exists := db.data[key]; !exists {
+		return false, nil
+	}
+	delete(db.data, key)
+
+	return true, nil
+}
@@ -0,0 +1,54 @@
+package inmemdb
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.UserRepo = (*inmemUserRepo)(nil)
+
+type inmemUserRepo struct {
+	data   map[int64]models.UserSettings
+	logger *zap.Logger
+	mu     sync.RWMutex
+}
+
+// NewUserRepo - конструктор in-memory хранилища пользовательских настроек.
+func NewUserRepo(log *zap.Logger) infra.UserRepo {
+	return &inmemUserRepo{
+		data:   make(map[int64]models.UserSettings),
+		logger: log,
+	}
+}
+
+func (db *inmemUserRepo) Get(_ context.Context, userID int64) (*models.UserSettings, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	settings, exists := db.data[userID]
+	if !exists {
+		return nil, nil
+	}
+
+	settings.Channels = append([]models.Channel(nil), settings.Channels...)
+	return &settings, nil
+}
+
+func (db *inmemUserRepo) Save(_ context.Context, settings *models.UserSettings) error {
+	if settings == nil {
+		return errNilSettings
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored := *settings
+	stored.Channels = append([]models.Channel(nil), settings.Channels...)
+	db.data[settings.UserID] = stored
+	return nil
+}
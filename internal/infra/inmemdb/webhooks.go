@@ -0,0 +1,131 @@
+package inmemdb
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.WebhookRepo = (*inmemWebhookRepo)(nil)
+
+// maxDeliveries - сколько последних попыток доставки хранится на подписку.
+const maxDeliveries = 1000
+
+type inmemWebhookRepo struct {
+	webhooks   map[string]models.Webhook
+	deliveries map[string][]models.WebhookDelivery
+	logger     *zap.Logger
+	mu         sync.RWMutex
+}
+
+// NewWebhookRepo - конструктор in-memory хранилища подписок на вебхуки.
+func NewWebhookRepo(log *zap.Logger) infra.WebhookRepo {
+	return &inmemWebhookRepo{
+		webhooks:   make(map[string]models.Webhook),
+		deliveries: make(map[string][]models.WebhookDelivery),
+		logger:     log,
+	}
+}
+
+func (db *inmemWebhookRepo) Create(_ context.Context, webhook *models.Webhook) error {
+	if webhook == nil {
+		return errNilWebhook
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.webhooks[webhook.ID]; exists {
+		return errDuplicate
+	}
+
+	db.webhooks[webhook.ID] = cloneWebhook(*webhook)
+	return nil
+}
+
+func (db *inmemWebhookRepo) Get(_ context.Context, webhookID string) (*models.Webhook, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	webhook, exists := db.webhooks[webhookID]
+	if !exists {
+		return nil, nil
+	}
+
+	webhook = cloneWebhook(webhook)
+	return &webhook, nil
+}
+
+func (db *inmemWebhookRepo) List(_ context.Context, userID int64) ([]models.Webhook, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	res := make([]models.Webhook, 0)
+	for _, webhook := range db.webhooks {
+		if webhook.UserID == userID {
+			res = append(res, cloneWebhook(webhook))
+		}
+	}
+	slices.SortFunc(res, func(a, b models.Webhook) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return res, nil
+}
+
+func (db *inmemWebhookRepo) Delete(_ context.Context, webhookID string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.webhooks[webhookID]; !exists {
+		return false, errNotFound
+	}
+
+	delete(db.webhooks, webhookID)
+	delete(db.deliveries, webhookID)
+	return true, nil
+}
+
+func (db *inmemWebhookRepo) AddDelivery(_ context.Context, delivery *models.WebhookDelivery) error {
+	if delivery == nil {
+		return errNilDelivery
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.webhooks[delivery.WebhookID]; !exists {
+		return errNotFound
+	}
+
+	log := append(db.deliveries[delivery.WebhookID], *delivery)
+	if len(log) > maxDeliveries {
+		log = slices.Delete(log, 0, len(log)-maxDeliveries)
+	}
+	db.deliveries[delivery.WebhookID] = log
+	return nil
+}
+
+func (db *inmemWebhookRepo) Deliveries(_ context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	log := db.deliveries[webhookID]
+	res := make([]models.WebhookDelivery, 0, min(len(log), max(limit, 0)))
+	for i := len(log) - 1; i >= 0 && len(res) < limit; i-- {
+		res = append(res, log[i])
+	}
+
+	return res, nil
+}
+
+func cloneWebhook(webhook models.Webhook) models.Webhook {
+	webhook.Types = append([]models.EnvelopeType(nil), webhook.Types...)
+	return webhook
+}
@@ -0,0 +1,9 @@
+package notifier
+
+import "errors"
+
+var (
+	errNoRecipient   = errors.New("не задан адрес доставки")
+	errBadRecipient  = errors.New("некорректный адрес доставки")
+	errWebhookStatus = errors.New("вебхук вернул неуспешный статус")
+)
@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/internal/netguard"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func testNotification(recipient string) models.Notification {
+	return models.Notification{
+		Recipient: recipient,
+		UserID:    1,
+		EventID:   "e-1",
+		Text:      "Созвон",
+		Date:      time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	n := NewWriter(&buf, zap.NewNop())
+
+	require.Equal(t, models.ChannelLog, n.Channel())
+	require.NoError(t, n.Notify(context.Background(), testNotification("")))
+	assert.Equal(t, "НАПОМИНАНИЕ: событие 'Созвон' начинается 2025-01-06T10:00:00Z (user_id=1, event_id=e-1)\n", buf.String())
+}
+
+func TestWebhook(t *testing.T) {
+	var got models.Notification
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n := NewWebhook(time.Second, &netguard.Guard{AllowPrivate: true}, zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, n.Notify(ctx, testNotification(srv.URL+"/hook")))
+	assert.Equal(t, "e-1", got.EventID)
+	assert.Equal(t, "Созвон", got.Text)
+
+	require.ErrorIs(t, n.Notify(ctx, testNotification(srv.URL+"/fail")), errWebhookStatus)
+	require.ErrorIs(t, n.Notify(ctx, testNotification("")), errNoRecipient)
+	require.ErrorIs(t, n.Notify(ctx, testNotification("ftp://example.com")), errBadRecipient)
+
+	// По умолчанию соединения во внутреннюю сеть запрещены.
+	strict := NewWebhook(time.Second, &netguard.Guard{}, zap.NewNop())
+	require.ErrorIs(t, strict.Notify(ctx, testNotification(srv.URL+"/hook")), netguard.ErrForbidden)
+}
+
+func TestSMTP(t *testing.T) {
+	srv := newFakeSMTP(t)
+
+	host, port, err := net.SplitHostPort(srv.addr)
+	require.NoError(t, err)
+
+	n := NewSMTP(config.SMTPConfig{Host: host, Port: port, From: "calendar@localhost"}, zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, n.Notify(ctx, testNotification("user@example.com")))
+
+	msg := <-srv.messages
+	assert.Equal(t, "calendar@localhost", msg.from)
+	assert.Equal(t, []string{"user@example.com"}, msg.to)
+	assert.Contains(t, msg.data, "To: user@example.com\r\n")
+	assert.Contains(t, msg.data, "Subject: =?utf-8?q?")
+	assert.Contains(t, msg.data, "Событие 'Созвон' начинается 06.01.2025 10:00 UTC.")
+
+	require.ErrorIs(t, n.Notify(ctx, testNotification("not an address")), errBadRecipient)
+	require.ErrorIs(t, n.Notify(ctx, testNotification("")), errNoRecipient)
+}
+
+func TestSMTPUnresponsive(t *testing.T) {
+	// Сервер принимает соединение, но не отвечает.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+
+	// Таймаут ограничивает отправку.
+	n := NewSMTP(config.SMTPConfig{Host: host, Port: port, From: "calendar@localhost", Timeout: 100 * time.Millisecond}, zap.NewNop())
+	start := time.Now()
+	require.Error(t, n.Notify(context.Background(), testNotification("user@example.com")))
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// Отмена контекста прерывает отправку раньше таймаута.
+	n = NewSMTP(config.SMTPConfig{Host: host, Port: port, From: "calendar@localhost", Timeout: time.Minute}, zap.NewNop())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	require.Error(t, n.Notify(ctx, testNotification("user@example.com")))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP - минимальный SMTP сервер для тестов: принимает письма и складывает их в канал.
+type fakeSMTP struct {
+	addr     string
+	messages chan smtpMessage
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	srv := &fakeSMTP{addr: ln.Addr().String(), messages: make(chan smtpMessage, 10)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+
+	return srv
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	tp := textproto.NewConn(conn)
+	reply := func(line string) { _ = tp.PrintfLine("%s", line) }
+
+	var msg smtpMessage
+	reply("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			msg = smtpMessage{from: strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")}
+			reply("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 end with .")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n")))
+			s.messages <- msg
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package notifier
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.Notifier = (*smtpNotifier)(nil)
+
+// defaultSMTPTimeout - ограничение на отправку письма, если таймаут не задан.
+const defaultSMTPTimeout = 10 * time.Second
+
+type smtpNotifier struct {
+	cfg     config.SMTPConfig
+	timeout time.Duration
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewSMTP - конструктор канала email: напоминание отправляется письмом через SMTP сервер.
+// cfg.Timeout ограничивает всю отправку письма: подключение и обмен с сервером.
+func NewSMTP(cfg config.SMTPConfig, logger *zap.Logger) infra.Notifier {
+	return &smtpNotifier{
+		cfg:     cfg,
+		timeout: cmp.Or(cfg.Timeout, defaultSMTPTimeout),
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+func (n *smtpNotifier) Channel() models.Channel {
+	return models.ChannelEmail
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, notification models.Notification) error {
+	if notification.Recipient == "" {
+		return errNoRecipient
+	}
+	to, err := mail.ParseAddress(notification.Recipient)
+	if err != nil {
+		return fmt.Errorf("%w: %q", errBadRecipient, notification.Recipient)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return n.send(ctx, to.Address, n.message(to.Address, notification))
+}
+
+// send - отправляет письмо одному получателю (аналог smtp.SendMail с учетом ctx и таймаута).
+// Медленный или не отвечающий сервер не задерживает доставку дольше таймаута,
+// отмена ctx прерывает обмен с сервером.
+func (n *smtpNotifier) send(ctx context.Context, to string, msg []byte) error {
+	dialer := net.Dialer{Timeout: n.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.cfg.Host, n.cfg.Port))
+	if err != nil {
+		return fmt.Errorf("dialer.DialContext: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	deadline := time.Now().Add(n.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("conn.SetDeadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		return fmt.Errorf("smtp.NewClient: %w", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return fmt.Errorf("c.StartTLS: %w", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("SMTP сервер не поддерживает AUTH")
+		}
+		if err := c.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("c.Auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(n.cfg.From); err != nil {
+		return fmt.Errorf("c.Mail: %w", err)
+	}
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("c.Rcpt: %w", err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("c.Data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("w.Write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("w.Close: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// message - письмо в формате RFC 5322. Тема кодируется по RFC 2047, тело - UTF-8.
+func (n *smtpNotifier) message(to string, notification models.Notification) []byte {
+	var buf bytes.Buffer
+
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", n.cfg.From)
+	header("To", to)
+	header("Subject", mime.QEncoding.Encode("utf-8", "Напоминание: "+notification.Text))
+	header("Date", n.now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	buf.WriteString("\r\n")
+
+	body := fmt.Sprintf("Событие '%s' начинается %s.\n", notification.Text, notification.Date.Format("02.01.2006 15:04 MST"))
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+
+	return buf.Bytes()
+}
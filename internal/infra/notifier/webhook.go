@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/internal/netguard"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.Notifier = (*webhookNotifier)(nil)
+
+type webhookNotifier struct {
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewWebhook - конструктор канала webhook: напоминание отправляется JSON-ом методом POST на URL пользователя.
+// Соединения с адресами, которые запрещает guard, отклоняются; перенаправления не выполняются.
+func NewWebhook(timeout time.Duration, guard *netguard.Guard, logger *zap.Logger) infra.Notifier {
+	return &webhookNotifier{
+		client: guard.Client(timeout),
+		logger: logger,
+	}
+}
+
+func (n *webhookNotifier) Channel() models.Channel {
+	return models.ChannelWebhook
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, notification models.Notification) error {
+	if notification.Recipient == "" {
+		return errNoRecipient
+	}
+	u, err := url.Parse(notification.Recipient)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", errBadRecipient, notification.Recipient)
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("http.NewRequest: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("client.Do: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %d", errWebhookStatus, resp.StatusCode)
+	}
+
+	return nil
+}
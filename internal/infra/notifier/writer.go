@@ -0,0 +1,56 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.Notifier = (*writerNotifier)(nil)
+
+type writerNotifier struct {
+	w      io.Writer
+	logger *zap.Logger
+	mu     sync.Mutex
+}
+
+// NewWriter - конструктор канала log: напоминания построчно пишутся в w (файл или stdout).
+func NewWriter(w io.Writer, logger *zap.Logger) infra.Notifier {
+	return &writerNotifier{
+		w:      w,
+		logger: logger,
+	}
+}
+
+func (n *writerNotifier) Channel() models.Channel {
+	return models.ChannelLog
+}
+
+func (n *writerNotifier) Notify(ctx context.Context, notification models.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, err := fmt.Fprintf(
+		n.w,
+		"НАПОМИНАНИЕ: событие '%s' начинается %s (user_id=%d, event_id=%s)\n",
+		notification.Text,
+		notification.Date.Format(time.RFC3339),
+		notification.UserID,
+		notification.EventID,
+	); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
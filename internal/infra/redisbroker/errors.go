@@ -0,0 +1,11 @@
+package redisbroker
+
+import "errors"
+
+var (
+	errClosed     = errors.New("брокер закрыт")
+	errSubscribed = errors.New("у брокера уже есть подписчик")
+	errPanic      = errors.New("паника в обработчике")
+	errAttempts   = errors.New("превышено число попыток обработки")
+	errMessage    = errors.New("некорректное сообщение в потоке")
+)
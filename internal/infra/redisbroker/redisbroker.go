@@ -0,0 +1,532 @@
+// Package redisbroker - брокер на Redis Streams для нескольких реплик сервиса.
+// Топик - поток, подписчик - группа потребителей, реплика - потребитель в группе:
+// реплики с одним именем подписчика делят сообщения между собой, разные подписчики
+// получают каждый свою копию.
+package redisbroker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.Broker = (*Broker)(nil)
+
+const (
+	fieldType     = "type"
+	fieldEnvelope = "env"
+	fieldDead     = "dl"
+
+	deadStream = "dead"
+)
+
+// DeadLetter - сообщение, которое подписчик не смог обработать за MaxAttempts попыток.
+type DeadLetter struct {
+	ID         string          `json:"id"`
+	Topic      models.Topic    `json:"topic"`
+	Subscriber string          `json:"subscriber"`
+	Envelope   models.Envelope `json:"envelope"`
+	Attempts   int             `json:"attempts"`
+	Error      string          `json:"error"`
+	FailedAt   time.Time       `json:"failed_at"`
+}
+
+// subscription - активная подписка реплики: группа потребителей топика.
+type subscription struct {
+	topic   models.Topic
+	name    string
+	stream  string
+	handler infra.Handler
+
+	// cursor - ID, с которого retry читает следующую страницу ожидающих ("" - с начала).
+	// Меняется только в цикле consume подписки.
+	cursor string
+}
+
+// Broker - брокер на Redis Streams.
+// Подписчик получает сообщения топика, опубликованные после создания его группы,
+// в том числе пока ни одна реплика не запущена. Сообщение подтверждается (XACK),
+// когда обработчик вернул nil. При ошибке сообщение остается в списке ожидающих группы
+// и обрабатывается снова, когда его простой достигнет RetryBase * 2^(n-1) (не больше RetryMax);
+// после MaxAttempts попыток оно переносится в поток dead. Сообщения другой реплики
+// забираются после Redis.ClaimIdle простоя - так подхватывается работа упавшей реплики.
+type Broker struct {
+	cfg      config.BrokerConfig
+	logger   *zap.Logger
+	client   *redis.Client
+	consumer string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	active map[models.Topic]map[string]struct{}
+	closed bool
+}
+
+// Open - подключается к Redis и проверяет соединение.
+func Open(cfg config.BrokerConfig, logger *zap.Logger) (*Broker, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("client.Ping: %w", err)
+	}
+
+	consumer := cfg.Redis.Consumer
+	if consumer == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			host = "calendar"
+		}
+		consumer = host + "-" + strconv.Itoa(os.Getpid())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Broker{
+		cfg:      cfg,
+		logger:   logger,
+		client:   client,
+		consumer: consumer,
+		ctx:      ctx,
+		cancel:   cancel,
+		active:   make(map[models.Topic]map[string]struct{}),
+	}
+
+	logger.Info("подключение к Redis брокера установлено",
+		zap.String("service", "redisbroker"),
+		zap.String("addr", cfg.Redis.Addr),
+		zap.String("consumer", consumer),
+	)
+
+	return b, nil
+}
+
+// Publish - добавляет конверт в поток топика. Поток обрезается примерно до Redis.MaxLen сообщений.
+func (b *Broker) Publish(ctx context.Context, topic models.Topic, env models.Envelope) error {
+	if b.isClosed() {
+		return errClosed
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	args := &redis.XAddArgs{
+		Stream: b.stream(topic),
+		Values: map[string]any{fieldType: string(env.Type), fieldEnvelope: data},
+	}
+	if b.cfg.Redis.MaxLen > 0 {
+		args.MaxLen = b.cfg.Redis.MaxLen
+		args.Approx = true
+	}
+
+	if err := b.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("client.XAdd: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe - создает группу subscriber в потоке топика (если ее еще нет)
+// и запускает чтение сообщений группы этой репликой.
+// Обработчик вызывается последовательно; nil подтверждает сообщение, ошибка или паника - повтор.
+func (b *Broker) Subscribe(
+	ctx context.Context,
+	topic models.Topic,
+	subscriber string,
+	handler infra.Handler,
+) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errClosed
+	}
+	if _, ok := b.active[topic][subscriber]; ok {
+		return errSubscribed
+	}
+
+	sub := &subscription{topic: topic, name: subscriber, stream: b.stream(topic), handler: handler}
+	err := b.client.XGroupCreateMkStream(ctx, sub.stream, subscriber, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("client.XGroupCreateMkStream: %w", err)
+	}
+
+	if b.active[topic] == nil {
+		b.active[topic] = make(map[string]struct{})
+	}
+	b.active[topic][subscriber] = struct{}{}
+
+	b.logger.Info("запуск подписки на события брокера",
+		zap.String("service", "redisbroker"),
+		zap.String("op", "Subscribe"),
+		zap.String("topic", string(topic)),
+		zap.String("subscriber", subscriber),
+		zap.String("consumer", b.consumer),
+	)
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(b.ctx, cancel)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer stop()
+		defer cancel()
+
+		b.run(ctx, sub)
+
+		b.mu.Lock()
+		delete(b.active[topic], subscriber)
+		b.mu.Unlock()
+	}()
+
+	return nil
+}
+
+// DeadLetters - сообщения из потока недоставленных.
+func (b *Broker) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	msgs, err := b.client.XRange(ctx, b.cfg.Redis.Prefix+deadStream, "-", "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("client.XRange: %w", err)
+	}
+
+	res := make([]DeadLetter, 0, len(msgs))
+	for _, msg := range msgs {
+		raw, ok := msg.Values[fieldDead].(string)
+		if !ok {
+			return res, fmt.Errorf("%w: %s", errMessage, msg.ID)
+		}
+
+		var dl DeadLetter
+		if err := json.Unmarshal([]byte(raw), &dl); err != nil {
+			return res, fmt.Errorf("разбор недоставленного сообщения %s: %w", msg.ID, err)
+		}
+		res = append(res, dl)
+	}
+
+	return res, nil
+}
+
+// Close - останавливает подписки и закрывает соединение.
+// Неподтвержденные сообщения остаются в группах и будут обработаны репликами.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	b.cancel()
+	b.wg.Wait()
+
+	return b.client.Close()
+}
+
+// run - цикл подписки: повторы и чужие зависшие сообщения, затем новые сообщения группы.
+func (b *Broker) run(ctx context.Context, sub *subscription) {
+	logger := b.logger.With(
+		zap.String("service", "redisbroker"),
+		zap.String("op", "run"),
+		zap.String("topic", string(sub.topic)),
+		zap.String("subscriber", sub.name),
+	)
+
+	for ctx.Err() == nil {
+		if err := b.retry(ctx, sub); err != nil && ctx.Err() == nil {
+			logger.Error("ошибка при повторной обработке сообщений", zap.Error(err))
+		}
+		if err := b.read(ctx, sub); err != nil && ctx.Err() == nil {
+			logger.Error("ошибка при чтении сообщений", zap.Error(err))
+			b.sleep(ctx)
+		}
+	}
+}
+
+// read - читает новые сообщения группы (с блокировкой до Redis.Block) и обрабатывает их.
+func (b *Broker) read(ctx context.Context, sub *subscription) error {
+	streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    sub.name,
+		Consumer: b.consumer,
+		Streams:  []string{sub.stream, ">"},
+		Count:    b.cfg.Redis.Batch,
+		Block:    b.block(),
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return fmt.Errorf("client.XReadGroup: %w", err)
+	}
+
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			if ctx.Err() != nil {
+				return nil
+			}
+			b.deliver(ctx, sub, msg, 1)
+		}
+	}
+
+	return nil
+}
+
+// retry - забирает из ожидающих группы сообщения, чей простой истек: свои - после задержки
+// повтора, чужие - не раньше Redis.ClaimIdle. XCLAIM с MinIdle гарантирует, что одно
+// сообщение заберет только одна реплика. За вызов просматривается страница из Redis.Batch
+// ожидающих с простоем не меньше RetryBase: следующий вызов продолжает с места, где остановился
+// этот, так что сообщения в начале списка, которым еще рано на повтор, не заслоняют остальные.
+func (b *Broker) retry(ctx context.Context, sub *subscription) error {
+	start := sub.cursor
+	if start == "" {
+		start = "-"
+	}
+
+	pending, err := b.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: sub.stream,
+		Group:  sub.name,
+		Idle:   b.cfg.RetryBase,
+		Start:  start,
+		End:    "+",
+		Count:  b.cfg.Redis.Batch,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("client.XPendingExt: %w", err)
+	}
+
+	sub.cursor = ""
+	if n := len(pending); int64(n) == b.cfg.Redis.Batch {
+		sub.cursor = nextID(pending[n-1].ID)
+	}
+
+	for _, p := range pending {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		attempts := int(p.RetryCount)
+		idle := b.backoff(attempts)
+		if p.Consumer != b.consumer {
+			idle = max(idle, b.cfg.Redis.ClaimIdle)
+		}
+		if p.Idle < idle {
+			continue
+		}
+
+		msgs, err := b.client.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   sub.stream,
+			Group:    sub.name,
+			Consumer: b.consumer,
+			MinIdle:  idle,
+			Messages: []string{p.ID},
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("client.XClaim: %w", err)
+		}
+		if len(msgs) == 0 {
+			// Сообщение забрала другая реплика или оно вытеснено из потока по MaxLen.
+			continue
+		}
+
+		if attempts >= b.cfg.MaxAttempts {
+			// Реплика упала во время последней попытки.
+			b.dead(ctx, sub, msgs[0], attempts, errAttempts)
+			continue
+		}
+		b.deliver(ctx, sub, msgs[0], attempts+1)
+	}
+
+	return nil
+}
+
+// deliver - вызывает обработчик и подтверждает сообщение. При ошибке сообщение остается
+// ожидающим до повтора, на попытке MaxAttempts - переносится в поток dead.
+func (b *Broker) deliver(ctx context.Context, sub *subscription, msg redis.XMessage, attempt int) {
+	logger := b.logger.With(
+		zap.String("service", "redisbroker"),
+		zap.String("op", "deliver"),
+		zap.String("topic", string(sub.topic)),
+		zap.String("subscriber", sub.name),
+		zap.String("id", msg.ID),
+	)
+
+	env, err := decode(msg)
+	if err != nil {
+		logger.Error("сообщение перенесено в очередь недоставленных", zap.Error(err))
+		b.dead(ctx, sub, msg, attempt, err)
+		return
+	}
+
+	err = b.call(ctx, sub.handler, env)
+	if ctx.Err() != nil {
+		// Сообщение остается ожидающим и будет обработано снова.
+		return
+	}
+
+	if err == nil {
+		if err := b.client.XAck(ctx, sub.stream, sub.name, msg.ID).Err(); err != nil {
+			logger.Error("ошибка при подтверждении сообщения", zap.Error(err))
+		}
+		return
+	}
+
+	if attempt < b.cfg.MaxAttempts {
+		logger.Warn("ошибка при обработке сообщения, повтор",
+			zap.Error(err),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", b.backoff(attempt)),
+		)
+		return
+	}
+
+	logger.Error("сообщение перенесено в очередь недоставленных",
+		zap.Error(err),
+		zap.Int("attempts", attempt),
+	)
+	b.dead(ctx, sub, msg, attempt, err)
+}
+
+// dead - записывает сообщение в поток dead и подтверждает его в группе.
+func (b *Broker) dead(ctx context.Context, sub *subscription, msg redis.XMessage, attempts int, cause error) {
+	env, _ := decode(msg)
+	dl := DeadLetter{
+		ID:         msg.ID,
+		Topic:      sub.topic,
+		Subscriber: sub.name,
+		Envelope:   env,
+		Attempts:   attempts,
+		Error:      cause.Error(),
+		FailedAt:   time.Now(),
+	}
+
+	data, err := json.Marshal(dl)
+	if err == nil {
+		_, err = b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: b.cfg.Redis.Prefix + deadStream,
+				Values: map[string]any{fieldDead: data},
+			})
+			pipe.XAck(ctx, sub.stream, sub.name, msg.ID)
+			return nil
+		})
+	}
+	if err != nil {
+		b.logger.Error("ошибка при записи в очередь недоставленных",
+			zap.String("service", "redisbroker"),
+			zap.String("topic", string(sub.topic)),
+			zap.String("subscriber", sub.name),
+			zap.String("id", msg.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// call - вызывает обработчик, превращая панику в ошибку.
+func (b *Broker) call(ctx context.Context, handler infra.Handler, env models.Envelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("паника в обработчике брокера",
+				zap.String("service", "redisbroker"),
+				zap.Any("rec", r),
+				zap.String("stack", string(debug.Stack())),
+			)
+			err = fmt.Errorf("%w: %v", errPanic, r)
+		}
+	}()
+
+	return handler(ctx, env)
+}
+
+// backoff - задержка перед попыткой attempt+1: RetryBase * 2^(attempt-1), не больше RetryMax.
+func (b *Broker) backoff(attempt int) time.Duration {
+	delay := b.cfg.RetryBase
+	for i := 1; i < attempt && delay < b.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+
+	return min(delay, b.cfg.RetryMax)
+}
+
+// block - ожидание в XREADGROUP. 0 в Redis означает бесконечное ожидание, поэтому не используется.
+func (b *Broker) block() time.Duration {
+	if b.cfg.Redis.Block <= 0 {
+		return time.Second
+	}
+
+	return b.cfg.Redis.Block
+}
+
+// sleep - пауза после ошибки чтения, например пока Redis недоступен.
+func (b *Broker) sleep(ctx context.Context) {
+	t := time.NewTimer(b.block())
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+func (b *Broker) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.closed
+}
+
+func (b *Broker) stream(topic models.Topic) string {
+	return b.cfg.Redis.Prefix + string(topic)
+}
+
+// decode - конверт из полей сообщения потока.
+func decode(msg redis.XMessage) (models.Envelope, error) {
+	var env models.Envelope
+
+	raw, ok := msg.Values[fieldEnvelope].(string)
+	if !ok {
+		return env, fmt.Errorf("%w: нет поля %s", errMessage, fieldEnvelope)
+	}
+	if err := json.Unmarshal([]byte(raw), &env); err != nil {
+		return env, fmt.Errorf("%w: %v", errMessage, err)
+	}
+
+	return env, nil
+}
+
+// nextID - наименьший ID потока после id ("<ms>-<seq>"): начало следующей страницы XPENDING.
+// "" - если id не разобрать, тогда следующая страница читается с начала.
+func nextID(id string) string {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return ""
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return ""
+	}
+
+	return ms + "-" + strconv.FormatUint(n+1, 10)
+}
@@ -0,0 +1,342 @@
+package redisbroker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// testConfig - конфигурация реплики. Тесты идут против встроенного miniredis,
+// а если задан TEST_REDIS_ADDR - против настоящего Redis (например, из docker-compose).
+// Ключи каждого теста изолированы уникальным префиксом.
+func testConfig(t *testing.T) config.BrokerConfig {
+	t.Helper()
+
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		addr = miniredis.RunT(t).Addr()
+	}
+
+	return config.BrokerConfig{
+		Driver:      "redis",
+		MaxAttempts: 3,
+		RetryBase:   time.Millisecond,
+		RetryMax:    10 * time.Millisecond,
+		Redis: config.RedisConfig{
+			Addr:      addr,
+			Prefix:    "test:" + uuid.NewString() + ":",
+			MaxLen:    1000,
+			ClaimIdle: time.Minute,
+			Block:     20 * time.Millisecond,
+			Batch:     10,
+		},
+	}
+}
+
+// replica - брокер отдельной реплики с именем потребителя consumer.
+func replica(t *testing.T, cfg config.BrokerConfig, consumer string) *Broker {
+	t.Helper()
+
+	cfg.Redis.Consumer = consumer
+	b, err := Open(cfg, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.Close() })
+
+	return b
+}
+
+// collector - обработчик, запоминающий ID событий из полученных конвертов.
+type collector struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (c *collector) handle(_ context.Context, env models.Envelope) error {
+	var event models.Event
+	if err := env.Decode(&event); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ids = append(c.ids, event.ID)
+	return nil
+}
+
+func (c *collector) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.ids...)
+}
+
+const topic = models.TopicEvents
+
+// publish - публикует событие в топик теста.
+func publish(t *testing.T, b *Broker, event models.Event) {
+	t.Helper()
+
+	env, err := models.NewEnvelope(models.EventCreated, event)
+	require.NoError(t, err)
+	require.NoError(t, b.Publish(context.Background(), topic, env))
+}
+
+func TestDeliverAndAck(t *testing.T) {
+	b := replica(t, testConfig(t), "r1")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &collector{}
+	require.NoError(t, b.Subscribe(ctx, topic, "reminders", c.handle))
+	require.ErrorIs(t, b.Subscribe(ctx, topic, "reminders", c.handle), errSubscribed)
+
+	publish(t, b, models.Event{ID: "e-1"})
+	publish(t, b, models.Event{ID: "e-2"})
+
+	require.Eventually(t, func() bool { return len(c.received()) == 2 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"e-1", "e-2"}, c.received())
+
+	require.Eventually(t, func() bool {
+		p, err := b.client.XPending(ctx, b.stream(topic), "reminders").Result()
+		return err == nil && p.Count == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestReplicasShareSubscriber(t *testing.T) {
+	cfg := testConfig(t)
+	first := replica(t, cfg, "r1")
+	second := replica(t, cfg, "r2")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r1, r2, webhooks := &collector{}, &collector{}, &collector{}
+	require.NoError(t, first.Subscribe(ctx, topic, "reminders", r1.handle))
+	require.NoError(t, second.Subscribe(ctx, topic, "reminders", r2.handle))
+	require.NoError(t, second.Subscribe(ctx, topic, "webhooks", webhooks.handle))
+
+	const n = 20
+	want := make([]string, 0, n)
+	for i := range n {
+		id := fmt.Sprintf("e-%d", i)
+		want = append(want, id)
+		publish(t, first, models.Event{ID: id})
+	}
+
+	require.Eventually(t, func() bool {
+		return len(r1.received())+len(r2.received()) == n && len(webhooks.received()) == n
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// Реплики одного подписчика делят сообщения: каждое обработано ровно один раз.
+	assert.ElementsMatch(t, want, append(r1.received(), r2.received()...))
+	// Другой подписчик получает свою копию каждого сообщения.
+	assert.Equal(t, want, webhooks.received())
+}
+
+func TestSubscriberReceivesWhileDown(t *testing.T) {
+	cfg := testConfig(t)
+	b := replica(t, cfg, "r1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, b.Subscribe(ctx, topic, "reminders", func(context.Context, models.Envelope) error { return nil }))
+	cancel()
+	require.NoError(t, b.Close())
+
+	other := replica(t, cfg, "r2")
+	publish(t, other, models.Event{ID: "e-1"})
+
+	restarted := replica(t, cfg, "r1")
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &collector{}
+	require.NoError(t, restarted.Subscribe(ctx, topic, "reminders", c.handle))
+	require.Eventually(t, func() bool { return len(c.received()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"e-1"}, c.received())
+}
+
+func TestRetryThenAck(t *testing.T) {
+	b := replica(t, testConfig(t), "r1")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	require.NoError(t, b.Subscribe(ctx, topic, "reminders", func(context.Context, models.Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if calls < 3 {
+			return errors.New("временная ошибка")
+		}
+		return nil
+	}))
+
+	publish(t, b, models.Event{ID: "e-1"})
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return calls == 3
+	}, 2*time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool {
+		p, err := b.client.XPending(ctx, b.stream(topic), "reminders").Result()
+		return err == nil && p.Count == 0
+	}, time.Second, 5*time.Millisecond)
+
+	dead, err := b.DeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+}
+
+func TestDeadLetters(t *testing.T) {
+	b := replica(t, testConfig(t), "r1")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, b.Subscribe(ctx, topic, "reminders", func(context.Context, models.Envelope) error {
+		panic("сломанный обработчик")
+	}))
+
+	publish(t, b, models.Event{ID: "e-1"})
+
+	var dead []DeadLetter
+	require.Eventually(t, func() bool {
+		var err error
+		dead, err = b.DeadLetters(ctx)
+		return err == nil && len(dead) == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, topic, dead[0].Topic)
+	assert.Equal(t, "reminders", dead[0].Subscriber)
+	assert.Equal(t, 3, dead[0].Attempts)
+	assert.Contains(t, dead[0].Error, "сломанный обработчик")
+
+	var event models.Event
+	require.NoError(t, dead[0].Envelope.Decode(&event))
+	assert.Equal(t, "e-1", event.ID)
+
+	p, err := b.client.XPending(ctx, b.stream(topic), "reminders").Result()
+	require.NoError(t, err)
+	assert.Zero(t, p.Count)
+}
+
+func TestClaimFromCrashedReplica(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Redis.ClaimIdle = 50 * time.Millisecond
+	b := replica(t, cfg, "r2")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &collector{}
+	require.NoError(t, b.Subscribe(ctx, topic, "reminders", func(context.Context, models.Envelope) error {
+		return nil
+	}))
+	cancel()
+	b.wg.Wait()
+
+	// Реплика r1 прочитала сообщение и упала, не подтвердив его.
+	publish(t, b, models.Event{ID: "e-1"})
+	_, err := b.client.XReadGroup(context.Background(), &redis.XReadGroupArgs{
+		Group:    "reminders",
+		Consumer: "r1",
+		Streams:  []string{b.stream(topic), ">"},
+		Count:    1,
+	}).Result()
+	require.NoError(t, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, b.Subscribe(ctx, topic, "reminders", c.handle))
+
+	require.Eventually(t, func() bool { return len(c.received()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"e-1"}, c.received())
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestRetryPastLivePending(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Redis.Batch = 2
+	b := replica(t, cfg, "r2")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, b.Subscribe(ctx, topic, "reminders", func(context.Context, models.Envelope) error {
+		return nil
+	}))
+	cancel()
+	b.wg.Wait()
+
+	// Живая реплика r1 обрабатывает первые сообщения: их рано забирать по ClaimIdle,
+	// и они занимают всю первую страницу ожидающих.
+	for i := range 4 {
+		publish(t, b, models.Event{ID: fmt.Sprintf("r1-%d", i)})
+	}
+	_, err := b.client.XReadGroup(context.Background(), &redis.XReadGroupArgs{
+		Group:    "reminders",
+		Consumer: "r1",
+		Streams:  []string{b.stream(topic), ">"},
+		Count:    4,
+	}).Result()
+	require.NoError(t, err)
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, b.Subscribe(ctx, topic, "reminders", func(context.Context, models.Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if calls == 1 {
+			return errors.New("временная ошибка")
+		}
+		return nil
+	}))
+	publish(t, b, models.Event{ID: "e-1"})
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return calls == 2
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestNextID(t *testing.T) {
+	assert.Equal(t, "1700000000000-1", nextID("1700000000000-0"))
+	assert.Equal(t, "5-10", nextID("5-9"))
+	assert.Empty(t, nextID("bad"))
+}
+
+func TestBackoff(t *testing.T) {
+	b := &Broker{cfg: config.BrokerConfig{RetryBase: time.Second, RetryMax: 5 * time.Second}}
+
+	assert.Equal(t, time.Second, b.backoff(1))
+	assert.Equal(t, 2*time.Second, b.backoff(2))
+	assert.Equal(t, 4*time.Second, b.backoff(3))
+	assert.Equal(t, 5*time.Second, b.backoff(4))
+	assert.Equal(t, 5*time.Second, b.backoff(100))
+}
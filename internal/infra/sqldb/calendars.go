@@ -0,0 +1,170 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.CalendarRepo = (*sqlCalendarRepo)(nil)
+
+const calendarColumns = `id, user_id, name, color, reminders, visibility, no_overlap, created_at`
+
+type sqlCalendarRepo struct {
+	*DB
+}
+
+// NewCalendarRepo - конструктор SQL хранилища календарей.
+func NewCalendarRepo(db *DB) infra.CalendarRepo {
+	return &sqlCalendarRepo{DB: db}
+}
+
+func (db *sqlCalendarRepo) Create(ctx context.Context, calendar *models.Calendar) error {
+	if calendar == nil {
+		return errNilCalendar
+	}
+
+	reminders, err := encodeReminders(calendar.Reminders)
+	if err != nil {
+		return err
+	}
+
+	res, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`INSERT INTO calendars (`+calendarColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO NOTHING`),
+		calendar.ID, calendar.UserID, calendar.Name, calendar.Color, reminders,
+		string(calendar.Visibility), calendar.NoOverlap, calendar.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("insert calendars: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return errDuplicate
+	}
+
+	return nil
+}
+
+func (db *sqlCalendarRepo) Get(ctx context.Context, calendarID string) (*models.Calendar, error) {
+	row := db.conn.QueryRowContext(
+		ctx,
+		db.dialect.rebind(`SELECT `+calendarColumns+` FROM calendars WHERE id = ?`),
+		calendarID,
+	)
+
+	calendar, err := scanCalendar(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select calendars: %w", err)
+	}
+
+	return calendar, nil
+}
+
+func (db *sqlCalendarRepo) List(ctx context.Context, userID int64) ([]models.Calendar, error) {
+	rows, err := db.conn.QueryContext(
+		ctx,
+		db.dialect.rebind(`SELECT `+calendarColumns+` FROM calendars WHERE user_id = ? ORDER BY created_at, id`),
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select calendars: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]models.Calendar, 0)
+	for rows.Next() {
+		calendar, err := scanCalendar(rows)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		res = append(res, *calendar)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (db *sqlCalendarRepo) Update(ctx context.Context, calendar *models.Calendar) error {
+	if calendar == nil {
+		return errNilCalendar
+	}
+
+	reminders, err := encodeReminders(calendar.Reminders)
+	if err != nil {
+		return err
+	}
+
+	res, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`UPDATE calendars SET name = ?, color = ?, reminders = ?, visibility = ?, no_overlap = ?
+			WHERE id = ?`),
+		calendar.Name, calendar.Color, reminders, string(calendar.Visibility), calendar.NoOverlap, calendar.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update calendars: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+func (db *sqlCalendarRepo) Delete(ctx context.Context, calendarID string) (bool, error) {
+	res, err := db.conn.ExecContext(ctx, db.dialect.rebind(`DELETE FROM calendars WHERE id = ?`), calendarID)
+	if err != nil {
+		return false, fmt.Errorf("delete calendars: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("RowsAffected: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func scanCalendar(row scanner) (*models.Calendar, error) {
+	var (
+		calendar   models.Calendar
+		reminders  string
+		visibility string
+		createdAt  int64
+	)
+	if err := row.Scan(
+		&calendar.ID, &calendar.UserID, &calendar.Name, &calendar.Color,
+		&reminders, &visibility, &calendar.NoOverlap, &createdAt,
+	); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if calendar.Reminders, err = decodeReminders(reminders); err != nil {
+		return nil, err
+	}
+	calendar.Visibility = models.CalendarVisibility(visibility)
+	calendar.CreatedAt = time.Unix(0, createdAt)
+
+	return &calendar, nil
+}
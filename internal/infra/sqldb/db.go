@@ -0,0 +1,61 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // драйвер PostgreSQL
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // pure-Go драйвер SQLite
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+)
+
+// DB - соединение с SQL базой (SQLite или PostgreSQL), общее для всех хранилищ пакета.
+type DB struct {
+	conn    *sql.DB
+	dialect dialect
+	logger  *zap.Logger
+}
+
+// Open - открывает соединение и применяет миграции.
+func Open(ctx context.Context, cfg config.DatabaseConfig, logger *zap.Logger) (*DB, error) {
+	d, err := dialectFor(cfg.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := sql.Open(d.driverName, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("sql.Open: %w", err)
+	}
+
+	if d.name == "sqlite" {
+		// SQLite допускает только одного писателя, сериализуем доступ на уровне пула.
+		conn.SetMaxOpenConns(1)
+	}
+
+	if err := conn.PingContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("conn.Ping: %w", err)
+	}
+
+	db := &DB{
+		conn:    conn,
+		dialect: d,
+		logger:  logger,
+	}
+
+	if err := db.migrate(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	return db, nil
+}
+
+// Close - закрывает соединение с базой.
+func (db *DB) Close() error {
+	return db.conn.Close()
+}
@@ -0,0 +1,59 @@
+package sqldb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dialect - особенности конкретной СУБД.
+type dialect struct {
+	name       string
+	driverName string
+	dollarArgs bool
+	// lockResource - запрос, блокирующий ресурс до конца транзакции, чтобы конкурентные брони
+	// выполнялись последовательно. Пустой, если СУБД и так сериализует записи.
+	lockResource string
+}
+
+var dialects = map[string]dialect{
+	"sqlite": {name: "sqlite", driverName: "sqlite"},
+	"postgres": {
+		name:         "postgres",
+		driverName:   "pgx",
+		dollarArgs:   true,
+		lockResource: `SELECT pg_advisory_xact_lock(hashtext(?))`,
+	},
+}
+
+func dialectFor(driver string) (dialect, error) {
+	d, ok := dialects[strings.ToLower(strings.TrimSpace(driver))]
+	if !ok {
+		return dialect{}, fmt.Errorf("%w: %q", errUnknownDriver, driver)
+	}
+
+	return d, nil
+}
+
+// rebind - заменяет плейсхолдеры "?" на "$N" для PostgreSQL.
+func (d dialect) rebind(query string) string {
+	if !d.dollarArgs {
+		return query
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(query) + 8)
+
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,21 @@
+package sqldb
+
+import (
+	"errors"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var (
+	errDuplicate     = errors.New("запись с таким ID уже существует")
+	errNotFound      = models.ErrNotFound
+	errNilEvent      = errors.New("event не может быть nil")
+	errNilSettings   = errors.New("settings не могут быть nil")
+	errNilWebhook    = errors.New("webhook не может быть nil")
+	errNilDelivery   = errors.New("delivery не может быть nil")
+	errNilShare      = errors.New("share не может быть nil")
+	errNilCalendar   = errors.New("calendar не может быть nil")
+	errNilResource   = errors.New("resource не может быть nil")
+	errUnknownDriver = errors.New("неизвестный драйвер БД")
+	errContended     = errors.New("запись одновременно изменяется другими процессами")
+)
@@ -0,0 +1,336 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type migration struct {
+	version int
+	name    string
+	stmts   []string
+	// fill - заполняет данные после stmts в той же транзакции, если их не вычислить в SQL.
+	fill func(ctx context.Context, tx *sql.Tx, d dialect) error
+}
+
+// migrations - упорядоченный список миграций схемы.
+// Уже примененные миграции менять нельзя, только добавлять новые в конец.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create_events",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS events (
+				id               TEXT PRIMARY KEY,
+				user_id          BIGINT NOT NULL,
+				date_ns          BIGINT NOT NULL,
+				text             TEXT NOT NULL,
+				reminder         BOOLEAN NOT NULL DEFAULT FALSE,
+				reminder_sent    BOOLEAN NOT NULL DEFAULT FALSE,
+				reminder_sent_at BIGINT NULL,
+				archived         BOOLEAN NOT NULL DEFAULT FALSE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_events_user_date ON events (user_id, date_ns)`,
+			`CREATE INDEX IF NOT EXISTS idx_events_archived ON events (archived)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "add_recurrence",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN rrule TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE events ADD COLUMN exdates TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE events ADD COLUMN series_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE events ADD COLUMN recurrence_id BIGINT NULL`,
+			`CREATE INDEX IF NOT EXISTS idx_events_series ON events (series_id)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "add_ical_uid",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN ical_uid TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX IF NOT EXISTS idx_events_user_ical_uid ON events (user_id, ical_uid)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "add_end_and_all_day",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN end_ns BIGINT NOT NULL DEFAULT 0`,
+			`ALTER TABLE events ADD COLUMN all_day BOOLEAN NOT NULL DEFAULT FALSE`,
+			`UPDATE events SET end_ns = date_ns`,
+			`CREATE INDEX IF NOT EXISTS idx_events_user_end ON events (user_id, end_ns)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "add_time_zones",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN tz TEXT NOT NULL DEFAULT ''`,
+			`CREATE TABLE IF NOT EXISTS user_settings (
+				user_id BIGINT PRIMARY KEY,
+				tz      TEXT NOT NULL DEFAULT ''
+			)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "add_notification_channels",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN channels TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE user_settings ADD COLUMN channels TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE user_settings ADD COLUMN email TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE user_settings ADD COLUMN webhook_url TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 7,
+		name:    "multiple_reminders",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN reminders TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE events ADD COLUMN reminder_pending BOOLEAN NOT NULL DEFAULT FALSE`,
+			// Единственное напоминание в момент начала переносится в список напоминаний.
+			`UPDATE events SET
+				reminders = '[{"offset_ns":0' ||
+					CASE WHEN reminder_sent_at IS NULL THEN '' ELSE ',"sent_at_ns":' || CAST(reminder_sent_at AS TEXT) END ||
+					'}]',
+				reminder_pending = (rrule <> '' OR reminder_sent_at IS NULL)
+			WHERE reminder`,
+			`ALTER TABLE events DROP COLUMN reminder`,
+			`ALTER TABLE events DROP COLUMN reminder_sent`,
+			`ALTER TABLE events DROP COLUMN reminder_sent_at`,
+			`CREATE INDEX IF NOT EXISTS idx_events_reminder_pending ON events (reminder_pending)`,
+		},
+	},
+	{
+		version: 8,
+		name:    "create_webhooks",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS webhooks (
+				id         TEXT PRIMARY KEY,
+				user_id    BIGINT NOT NULL,
+				url        TEXT NOT NULL,
+				secret     TEXT NOT NULL,
+				types      TEXT NOT NULL DEFAULT '',
+				created_at BIGINT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_webhooks_user ON webhooks (user_id)`,
+			`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+				id          TEXT PRIMARY KEY,
+				webhook_id  TEXT NOT NULL,
+				envelope_id TEXT NOT NULL,
+				type        TEXT NOT NULL,
+				event_id    TEXT NOT NULL,
+				attempt     INTEGER NOT NULL,
+				status      TEXT NOT NULL,
+				status_code INTEGER NOT NULL DEFAULT 0,
+				error       TEXT NOT NULL DEFAULT '',
+				created_at  BIGINT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at)`,
+		},
+	},
+	{
+		version: 9,
+		name:    "create_shares",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS shares (
+				owner_id   BIGINT NOT NULL,
+				grantee_id BIGINT NOT NULL,
+				role       TEXT NOT NULL,
+				created_at BIGINT NOT NULL,
+				PRIMARY KEY (owner_id, grantee_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_shares_grantee ON shares (grantee_id)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "create_calendars",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN calendar_id TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX IF NOT EXISTS idx_events_user_calendar ON events (user_id, calendar_id)`,
+			`CREATE TABLE IF NOT EXISTS calendars (
+				id         TEXT PRIMARY KEY,
+				user_id    BIGINT NOT NULL,
+				name       TEXT NOT NULL,
+				color      TEXT NOT NULL DEFAULT '',
+				reminders  TEXT NOT NULL DEFAULT '',
+				visibility TEXT NOT NULL,
+				created_at BIGINT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_calendars_user ON calendars (user_id)`,
+		},
+	},
+	{
+		version: 11,
+		name:    "add_attendees",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN attendees TEXT NOT NULL DEFAULT ''`,
+			`CREATE TABLE IF NOT EXISTS event_attendees (
+				event_id TEXT NOT NULL,
+				user_id  BIGINT NOT NULL,
+				status   TEXT NOT NULL,
+				PRIMARY KEY (event_id, user_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_event_attendees_user ON event_attendees (user_id)`,
+		},
+	},
+	{
+		version: 12,
+		name:    "add_calendar_no_overlap",
+		stmts: []string{
+			`ALTER TABLE calendars ADD COLUMN no_overlap BOOLEAN NOT NULL DEFAULT FALSE`,
+		},
+	},
+	{
+		version: 13,
+		name:    "create_resources",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN resources TEXT NOT NULL DEFAULT ''`,
+			`CREATE TABLE IF NOT EXISTS resources (
+				id              TEXT PRIMARY KEY,
+				user_id         BIGINT NOT NULL,
+				name            TEXT NOT NULL,
+				kind            TEXT NOT NULL,
+				capacity        INTEGER NOT NULL DEFAULT 0,
+				location        TEXT NOT NULL DEFAULT '',
+				max_duration_ns BIGINT NOT NULL DEFAULT 0,
+				horizon_ns      BIGINT NOT NULL DEFAULT 0,
+				created_at      BIGINT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS event_resources (
+				event_id    TEXT NOT NULL,
+				resource_id TEXT NOT NULL,
+				start_ns    BIGINT NOT NULL,
+				end_ns      BIGINT NOT NULL,
+				PRIMARY KEY (event_id, resource_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_event_resources_resource ON event_resources (resource_id, start_ns)`,
+		},
+	},
+	{
+		version: 14,
+		name:    "add_search_terms",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN search_terms TEXT NOT NULL DEFAULT ''`,
+		},
+		fill: fillSearchTerms,
+	},
+}
+
+// migrate - применяет недостающие миграции, каждую в отдельной транзакции.
+func (db *DB) migrate(ctx context.Context) error {
+	logger := db.logger.With(
+		zap.String("service", "sqldb"),
+		zap.String("op", "migrate"),
+	)
+
+	if _, err := db.conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := db.conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("select schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("rows.Scan: %w", err)
+		}
+		applied[v] = true
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("rows.Close: %w", err)
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		if err := db.applyMigration(ctx, m); err != nil {
+			return fmt.Errorf("миграция %d_%s: %w", m.version, m.name, err)
+		}
+
+		logger.Info("миграция применена",
+			zap.Int("version", m.version),
+			zap.String("name", m.name),
+		)
+	}
+
+	return nil
+}
+
+func (db *DB) applyMigration(ctx context.Context, m migration) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("BeginTx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range m.stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("tx.Exec: %w", err)
+		}
+	}
+	if m.fill != nil {
+		if err := m.fill(ctx, tx, db.dialect); err != nil {
+			return fmt.Errorf("fill: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+		m.version, m.name, time.Now().UnixNano(),
+	); err != nil {
+		return fmt.Errorf("insert schema_migrations: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// fillSearchTerms - заполняет search_terms событий, созданных до миграции add_search_terms.
+func fillSearchTerms(ctx context.Context, tx *sql.Tx, d dialect) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, text FROM events`)
+	if err != nil {
+		return fmt.Errorf("select events: %w", err)
+	}
+
+	terms := make(map[string]string)
+	for rows.Next() {
+		var id, text string
+		if err := rows.Scan(&id, &text); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("rows.Scan: %w", err)
+		}
+		terms[id] = searchTerms(text)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("rows.Close: %w", err)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows.Err: %w", err)
+	}
+
+	for id, t := range terms {
+		if _, err := tx.ExecContext(ctx, d.rebind(`UPDATE events SET search_terms = ? WHERE id = ?`), t, id); err != nil {
+			return fmt.Errorf("update events: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.ResourceRepo = (*sqlResourceRepo)(nil)
+
+const resourceColumns = `id, user_id, name, kind, capacity, location, max_duration_ns, horizon_ns, created_at`
+
+type sqlResourceRepo struct {
+	*DB
+}
+
+// NewResourceRepo - конструктор SQL хранилища ресурсов.
+func NewResourceRepo(db *DB) infra.ResourceRepo {
+	return &sqlResourceRepo{DB: db}
+}
+
+func (db *sqlResourceRepo) Create(ctx context.Context, resource *models.Resource) error {
+	if resource == nil {
+		return errNilResource
+	}
+
+	res, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`INSERT INTO resources (`+resourceColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO NOTHING`),
+		resource.ID, resource.UserID, resource.Name, string(resource.Kind), resource.Capacity, resource.Location,
+		int64(resource.Policy.MaxDuration), int64(resource.Policy.Horizon), resource.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("insert resources: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return errDuplicate
+	}
+
+	return nil
+}
+
+func (db *sqlResourceRepo) Get(ctx context.Context, resourceID string) (*models.Resource, error) {
+	row := db.conn.QueryRowContext(
+		ctx,
+		db.dialect.rebind(`SELECT `+resourceColumns+` FROM resources WHERE id = ?`),
+		resourceID,
+	)
+
+	resource, err := scanResource(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select resources: %w", err)
+	}
+
+	return resource, nil
+}
+
+func (db *sqlResourceRepo) List(ctx context.Context) ([]models.Resource, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+resourceColumns+` FROM resources ORDER BY name, id`)
+	if err != nil {
+		return nil, fmt.Errorf("select resources: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]models.Resource, 0)
+	for rows.Next() {
+		resource, err := scanResource(rows)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		res = append(res, *resource)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (db *sqlResourceRepo) Update(ctx context.Context, resource *models.Resource) error {
+	if resource == nil {
+		return errNilResource
+	}
+
+	res, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`UPDATE resources SET name = ?, kind = ?, capacity = ?, location = ?, max_duration_ns = ?, horizon_ns = ?
+			WHERE id = ?`),
+		resource.Name, string(resource.Kind), resource.Capacity, resource.Location,
+		int64(resource.Policy.MaxDuration), int64(resource.Policy.Horizon), resource.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update resources: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+func (db *sqlResourceRepo) Delete(ctx context.Context, resourceID string) (bool, error) {
+	res, err := db.conn.ExecContext(ctx, db.dialect.rebind(`DELETE FROM resources WHERE id = ?`), resourceID)
+	if err != nil {
+		return false, fmt.Errorf("delete resources: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("RowsAffected: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func scanResource(row scanner) (*models.Resource, error) {
+	var (
+		resource    models.Resource
+		kind        string
+		maxDuration int64
+		horizon     int64
+		createdAt   int64
+	)
+	if err := row.Scan(
+		&resource.ID, &resource.UserID, &resource.Name, &kind, &resource.Capacity,
+		&resource.Location, &maxDuration, &horizon, &createdAt,
+	); err != nil {
+		return nil, err
+	}
+
+	resource.Kind = models.ResourceKind(kind)
+	resource.Policy = models.BookingPolicy{
+		MaxDuration: models.Offset(maxDuration),
+		Horizon:     models.Offset(horizon),
+	}
+	resource.CreatedAt = time.Unix(0, createdAt)
+
+	return &resource, nil
+}
@@ -0,0 +1,118 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.ShareRepo = (*sqlShareRepo)(nil)
+
+type sqlShareRepo struct {
+	*DB
+}
+
+// NewShareRepo - конструктор SQL хранилища доступов к календарям.
+func NewShareRepo(db *DB) infra.ShareRepo {
+	return &sqlShareRepo{DB: db}
+}
+
+func (db *sqlShareRepo) Save(ctx context.Context, share *models.Share) error {
+	if share == nil {
+		return errNilShare
+	}
+
+	if _, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`INSERT INTO shares (owner_id, grantee_id, role, created_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (owner_id, grantee_id) DO UPDATE SET role = excluded.role`),
+		share.OwnerID, share.GranteeID, string(share.Role), share.CreatedAt.UnixNano(),
+	); err != nil {
+		return fmt.Errorf("upsert shares: %w", err)
+	}
+
+	return nil
+}
+
+func (db *sqlShareRepo) Get(ctx context.Context, ownerID, granteeID int64) (*models.Share, error) {
+	row := db.conn.QueryRowContext(
+		ctx,
+		db.dialect.rebind(`SELECT owner_id, grantee_id, role, created_at FROM shares WHERE owner_id = ? AND grantee_id = ?`),
+		ownerID, granteeID,
+	)
+
+	share, err := scanShare(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select shares: %w", err)
+	}
+
+	return share, nil
+}
+
+func (db *sqlShareRepo) List(ctx context.Context, userID int64) ([]models.Share, error) {
+	rows, err := db.conn.QueryContext(
+		ctx,
+		db.dialect.rebind(`SELECT owner_id, grantee_id, role, created_at FROM shares
+			WHERE owner_id = ? OR grantee_id = ? ORDER BY created_at, owner_id, grantee_id`),
+		userID, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select shares: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]models.Share, 0)
+	for rows.Next() {
+		share, err := scanShare(rows)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		res = append(res, *share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (db *sqlShareRepo) Delete(ctx context.Context, ownerID, granteeID int64) (bool, error) {
+	res, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`DELETE FROM shares WHERE owner_id = ? AND grantee_id = ?`),
+		ownerID, granteeID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("delete shares: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("RowsAffected: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func scanShare(row scanner) (*models.Share, error) {
+	var (
+		share     models.Share
+		role      string
+		createdAt int64
+	)
+	if err := row.Scan(&share.OwnerID, &share.GranteeID, &role, &createdAt); err != nil {
+		return nil, err
+	}
+	share.Role = models.ShareRole(role)
+	share.CreatedAt = time.Unix(0, createdAt)
+
+	return &share, nil
+}
@@ -0,0 +1,1015 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/internal/textsearch"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.Database = (*sqlRepo)(nil)
+
+// eventColumnList - колонки таблицы events, которые читает scanEvent; порядок совпадает с eventArgs и scanEvent.
+var eventColumnList = []string{
+	"id", "user_id", "date_ns", "text", "reminders", "reminder_pending", "archived",
+	"rrule", "exdates", "series_id", "recurrence_id", "ical_uid", "end_ns", "all_day",
+	"tz", "channels", "calendar_id", "attendees", "resources",
+}
+
+// writeColumnList - колонки, которые пишут Create и Update: вдобавок к eventColumnList
+// термы текста для Search (см. searchTerms).
+var writeColumnList = append(slices.Clone(eventColumnList), "search_terms")
+
+var (
+	eventColumns = strings.Join(eventColumnList, ", ")
+	insertEvent  = `INSERT INTO events (` + strings.Join(writeColumnList, ", ") + `) VALUES (` +
+		strings.TrimSuffix(strings.Repeat("?, ", len(writeColumnList)), ", ") +
+		`) ON CONFLICT (id) DO NOTHING`
+	updateEvent = `UPDATE events SET ` + strings.Join(writeColumnList[1:], " = ?, ") + ` = ? WHERE id = ?`
+)
+
+type sqlRepo struct {
+	*DB
+}
+
+// New - конструктор SQL хранилища событий поверх открытого соединения.
+func New(db *DB) infra.Database {
+	return &sqlRepo{DB: db}
+}
+
+func (db *sqlRepo) Create(ctx context.Context, event *models.Event) error {
+	if event == nil {
+		return errNilEvent
+	}
+
+	args, err := eventArgs(event)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("BeginTx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(insertEvent),
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("insert events: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return errDuplicate
+	}
+
+	if err := db.saveAttendees(ctx, tx, event); err != nil {
+		return err
+	}
+	if err := db.claimResources(ctx, tx, event, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (db *sqlRepo) Read(ctx context.Context, eventID string) (*models.Event, error) {
+	row := db.conn.QueryRowContext(
+		ctx,
+		db.dialect.rebind(`SELECT `+eventColumns+` FROM events WHERE id = ?`),
+		eventID,
+	)
+
+	evnt, err := scanEvent(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNotFound
+		}
+		return nil, fmt.Errorf("select events: %w", err)
+	}
+
+	return evnt, nil
+}
+
+func (db *sqlRepo) Update(ctx context.Context, event *models.Event) error {
+	if event == nil {
+		return errNilEvent
+	}
+
+	args, err := eventArgs(event)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("BeginTx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	prev, err := db.readClaim(ctx, tx, event.ID)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(updateEvent),
+		append(args[1:], args[0])...,
+	)
+	if err != nil {
+		return fmt.Errorf("update events: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return errNotFound
+	}
+
+	if err := db.saveAttendees(ctx, tx, event); err != nil {
+		return err
+	}
+	if err := db.claimResources(ctx, tx, event, prev); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// readClaim - участники и ресурсы сохраненного события: по ним claimResources проверяет вместимость.
+func (db *sqlRepo) readClaim(ctx context.Context, tx *sql.Tx, eventID string) (*models.Event, error) {
+	var attendees, resources string
+	err := tx.QueryRowContext(
+		ctx,
+		db.dialect.rebind(`SELECT attendees, resources FROM events WHERE id = ?`),
+		eventID,
+	).Scan(&attendees, &resources)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNotFound
+		}
+		return nil, fmt.Errorf("select events: %w", err)
+	}
+
+	prev := &models.Event{ID: eventID}
+	if prev.Attendees, err = decodeAttendees(attendees); err != nil {
+		return nil, err
+	}
+	if resources != "" {
+		prev.Resources = strings.Split(resources, ",")
+	}
+
+	return prev, nil
+}
+
+func (db *sqlRepo) Delete(ctx context.Context, eventID string) (bool, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("BeginTx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(`DELETE FROM events WHERE id = ?`),
+		eventID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("delete events: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return false, errNotFound
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(`DELETE FROM event_attendees WHERE event_id = ?`),
+		eventID,
+	); err != nil {
+		return false, fmt.Errorf("delete event_attendees: %w", err)
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(`DELETE FROM event_resources WHERE event_id = ?`),
+		eventID,
+	); err != nil {
+		return false, fmt.Errorf("delete event_resources: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return true, nil
+}
+
+// casAttempts - сколько раз MarkReminderSent и UpdateAttendees перечитывают событие,
+// если изменяемую колонку одновременно изменил другой процесс.
+const casAttempts = 5
+
+// MarkReminderSent - сравнивает и заменяет колонку reminders: отметка сохраняется, только если напоминания
+// не изменились с момента чтения, иначе они перечитываются и условие проверяется заново.
+func (db *sqlRepo) MarkReminderSent(
+	ctx context.Context,
+	eventID string,
+	offset models.Offset,
+	occurrence, sentAt time.Time,
+) (bool, error) {
+	for range casAttempts {
+		var stored, rule string
+		err := db.conn.QueryRowContext(
+			ctx,
+			db.dialect.rebind(`SELECT reminders, rrule FROM events WHERE id = ?`),
+			eventID,
+		).Scan(&stored, &rule)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, errNotFound
+			}
+			return false, fmt.Errorf("select events: %w", err)
+		}
+
+		reminders, err := decodeReminders(stored)
+		if err != nil {
+			return false, err
+		}
+		event := models.Event{Reminders: reminders, RRule: rule}
+		if !event.MarkReminderSent(offset, occurrence, sentAt) {
+			return false, nil
+		}
+		encoded, err := encodeReminders(event.Reminders)
+		if err != nil {
+			return false, err
+		}
+
+		res, err := db.conn.ExecContext(
+			ctx,
+			db.dialect.rebind(`UPDATE events SET reminders = ?, reminder_pending = ? WHERE id = ? AND reminders = ?`),
+			encoded, event.ReminderPending(), eventID, stored,
+		)
+		if err != nil {
+			return false, fmt.Errorf("update events: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return false, fmt.Errorf("RowsAffected: %w", err)
+		}
+		if n == 1 {
+			return true, nil
+		}
+	}
+
+	return false, errContended
+}
+
+// UpdateAttendees - сравнивает и заменяет колонку attendees: участники сохраняются, только если
+// не изменились с момента чтения, иначе событие перечитывается и change применяется заново.
+func (db *sqlRepo) UpdateAttendees(
+	ctx context.Context,
+	eventID string,
+	change func(event *models.Event) error,
+) (*models.Event, error) {
+	for range casAttempts {
+		event, err := db.Read(ctx, eventID)
+		if err != nil {
+			return nil, err
+		}
+
+		prev := *event
+		changed := *event
+		changed.Attendees = slices.Clone(event.Attendees)
+		if err := change(&changed); err != nil {
+			return nil, err
+		}
+		event.Attendees = changed.Attendees
+
+		ok, err := db.swapAttendees(ctx, event, &prev)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return event, nil
+		}
+	}
+
+	return nil, errContended
+}
+
+// swapAttendees - сохраняет участников события, если они не изменились с прочитанного состояния prev.
+// Вместимость ресурсов проверяется в той же транзакции (см. claimResources).
+func (db *sqlRepo) swapAttendees(ctx context.Context, event, prev *models.Event) (bool, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("BeginTx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(`UPDATE events SET attendees = ? WHERE id = ? AND attendees = ?`),
+		encodeAttendees(event.Attendees), event.ID, encodeAttendees(prev.Attendees),
+	)
+	if err != nil {
+		return false, fmt.Errorf("update events: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+
+	if err := db.saveAttendees(ctx, tx, event); err != nil {
+		return false, err
+	}
+	if err := db.claimResources(ctx, tx, event, prev); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return true, nil
+}
+
+func (db *sqlRepo) Archive(ctx context.Context, eventID string) (bool, error) {
+	res, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`UPDATE events SET archived = ? WHERE id = ? AND archived = ?`),
+		true, eventID, false,
+	)
+	if err != nil {
+		return false, fmt.Errorf("update events: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 1 {
+		return true, nil
+	}
+
+	if _, err := db.Read(ctx, eventID); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// saveAttendees - заменяет строки event_attendees события: по ним выбираются приглашения участника
+// (ListOptions.Participant), сами участники хранятся в колонке attendees.
+func (db *sqlRepo) saveAttendees(ctx context.Context, tx *sql.Tx, event *models.Event) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(`DELETE FROM event_attendees WHERE event_id = ?`),
+		event.ID,
+	); err != nil {
+		return fmt.Errorf("delete event_attendees: %w", err)
+	}
+
+	for _, a := range event.Attendees {
+		if a.UserID == event.UserID {
+			continue
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			db.dialect.rebind(`INSERT INTO event_attendees (event_id, user_id, status) VALUES (?, ?, ?)`),
+			event.ID, a.UserID, string(a.Status),
+		); err != nil {
+			return fmt.Errorf("insert event_attendees: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// claimResources - заменяет брони ресурсов события в event_resources. Если ресурс уже занят другим
+// пересекающимся событием, возвращает *models.ConflictError, если не вмещает участников события -
+// *models.BookingError (см. models.Resource.CheckCapacity; prev - сохраненное состояние, nil - новое событие),
+// и транзакция откатывается. Ресурсы блокируются в порядке ID, чтобы встречные брони нескольких ресурсов
+// не взаимоблокировались; под той же блокировкой конкурентные приглашения не переполнят ресурс.
+func (db *sqlRepo) claimResources(ctx context.Context, tx *sql.Tx, event, prev *models.Event) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(`DELETE FROM event_resources WHERE event_id = ?`),
+		event.ID,
+	); err != nil {
+		return fmt.Errorf("delete event_resources: %w", err)
+	}
+	if len(event.Resources) == 0 {
+		return nil
+	}
+
+	resources := slices.Compact(slices.Sorted(slices.Values(event.Resources)))
+	if db.dialect.lockResource != "" {
+		for _, id := range resources {
+			if _, err := tx.ExecContext(ctx, db.dialect.rebind(db.dialect.lockResource), id); err != nil {
+				return fmt.Errorf("lock resource: %w", err)
+			}
+		}
+	}
+
+	start, end := event.Date.UnixNano(), event.EndTime().UnixNano()
+	args := []any{event.ID}
+	for _, id := range resources {
+		args = append(args, id)
+	}
+	args = append(args, end, start)
+
+	rows, err := tx.QueryContext(
+		ctx,
+		db.dialect.rebind(`SELECT id FROM events WHERE id <> ? AND id IN (
+			SELECT event_id FROM event_resources
+			WHERE resource_id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(resources)), ", ")+`)
+				AND start_ns < ? AND end_ns > ?
+		) ORDER BY date_ns, id`),
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("select event_resources: %w", err)
+	}
+	var busy []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("rows.Scan: %w", err)
+		}
+		busy = append(busy, id)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("rows.Close: %w", err)
+	}
+	if len(busy) > 0 {
+		return &models.ConflictError{EventIDs: busy}
+	}
+	if err := db.checkCapacity(ctx, tx, resources, event, prev); err != nil {
+		return err
+	}
+
+	for _, id := range resources {
+		if _, err := tx.ExecContext(
+			ctx,
+			db.dialect.rebind(`INSERT INTO event_resources (event_id, resource_id, start_ns, end_ns) VALUES (?, ?, ?, ?)`),
+			event.ID, id, start, end,
+		); err != nil {
+			return fmt.Errorf("insert event_resources: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkCapacity - проверяет вместимость существующих ресурсов из resources для события.
+func (db *sqlRepo) checkCapacity(ctx context.Context, tx *sql.Tx, resources []string, event, prev *models.Event) error {
+	args := make([]any, 0, len(resources))
+	for _, id := range resources {
+		args = append(args, id)
+	}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		db.dialect.rebind(`SELECT id, capacity FROM resources
+			WHERE id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(resources)), ", ")+`) ORDER BY id`),
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("select resources: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var res models.Resource
+		if err := rows.Scan(&res.ID, &res.Capacity); err != nil {
+			return fmt.Errorf("rows.Scan: %w", err)
+		}
+		if err := res.CheckCapacity(event, prev); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (db *sqlRepo) List(ctx context.Context, opts *infra.ListOptions) ([]models.Event, error) {
+	where, args := buildFilter(opts)
+
+	query := `SELECT ` + eventColumns + ` FROM events`
+	if where != "" {
+		query += ` WHERE ` + where
+	}
+	page, pageArgs := buildPage(opts)
+	query += page
+	args = append(args, pageArgs...)
+
+	return db.selectEvents(ctx, query, args)
+}
+
+// Search - словоформы и префиксы не выражаются переносимым SQL, поэтому событие хранит термы
+// своего текста (search_terms, см. searchTerms). WHERE отбирает неархивные (если не задано иначе) события
+// пользователя за период, у которых каждый терм запроса - начало одного из термов текста,
+// а ранжирует их тот же анализатор, что и в inmemdb. Ограничения: LIKE '% терм%' не использует индекс,
+// поэтому запрос просматривает search_terms всех событий пользователя за период, а BM25 считается
+// по отобранным событиям, а не по всем, и порядок равных по тексту событий может отличаться от inmemdb.
+func (db *sqlRepo) Search(ctx context.Context, opts infra.SearchOptions) ([]models.Event, error) {
+	terms := textsearch.Terms(opts.Query)
+	if len(terms) == 0 {
+		return make([]models.Event, 0), nil
+	}
+
+	where, args := buildFilter(&infra.ListOptions{
+		UserID:      &opts.UserID,
+		CalendarIDs: opts.CalendarIDs,
+		From:        opts.From,
+		To:          opts.To,
+		Archived:    opts.Archived,
+	})
+	conds := []string{where}
+	for _, term := range terms {
+		conds = append(conds, `' ' || search_terms LIKE ?`)
+		args = append(args, "% "+term+"%")
+	}
+
+	events, err := db.selectEvents(ctx, `SELECT `+eventColumns+` FROM events WHERE `+strings.Join(conds, " AND "), args)
+	if err != nil {
+		return nil, err
+	}
+
+	text := textsearch.New()
+	byID := make(map[string]models.Event, len(events))
+	for _, evnt := range events {
+		text.Add(evnt.ID, evnt.Text)
+		byID[evnt.ID] = evnt
+	}
+
+	res := make([]models.Event, 0)
+	for _, hit := range text.Search(opts.Query) {
+		res = append(res, byID[hit.ID])
+		if len(res) == opts.Limit {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// selectEvents - выполняет выборку событий (SELECT eventColumns ...).
+func (db *sqlRepo) selectEvents(ctx context.Context, query string, args []any) ([]models.Event, error) {
+	rows, err := db.conn.QueryContext(ctx, db.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("select events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	res := make([]models.Event, 0)
+	for rows.Next() {
+		evnt, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		res = append(res, *evnt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return res, nil
+}
+
+// buildFilter - переводит ListOptions в WHERE условие.
+// Фильтр по датам повторяет семантику inmemdb: событие попадает в выборку,
+// если пересекается с полуинтервалом [From; To).
+func buildFilter(opts *infra.ListOptions) (string, []any) {
+	if opts == nil {
+		return "", nil
+	}
+
+	var (
+		conds []string
+		args  []any
+	)
+
+	if opts.UserID != nil {
+		conds = append(conds, "user_id = ?")
+		args = append(args, *opts.UserID)
+	}
+
+	var (
+		calendarCond string
+		calendarArgs []any
+	)
+	if opts.CalendarIDs != nil {
+		calendarCond = "1 = 0"
+		if len(opts.CalendarIDs) > 0 {
+			calendarCond = "calendar_id IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(opts.CalendarIDs)), ", ") + ")"
+			for _, id := range opts.CalendarIDs {
+				calendarArgs = append(calendarArgs, id)
+			}
+		}
+	}
+
+	switch {
+	case opts.Participant != nil:
+		own := "user_id = ?"
+		args = append(args, *opts.Participant)
+		if calendarCond != "" {
+			own += " AND " + calendarCond
+			args = append(args, calendarArgs...)
+		}
+		// Приглашения относятся к календарю участника по умолчанию.
+		if opts.CalendarIDs == nil || slices.Contains(opts.CalendarIDs, "") {
+			own += " OR id IN (SELECT event_id FROM event_attendees WHERE user_id = ? AND status <> ?)"
+			args = append(args, *opts.Participant, string(models.AttendeeDeclined))
+		}
+		conds = append(conds, "("+own+")")
+	case calendarCond != "":
+		conds = append(conds, calendarCond)
+		args = append(args, calendarArgs...)
+	}
+
+	if opts.Archived != nil {
+		conds = append(conds, "archived = ?")
+		args = append(args, *opts.Archived)
+	}
+
+	if opts.ReminderPending != nil {
+		conds = append(conds, "reminder_pending = ?")
+		args = append(args, *opts.ReminderPending)
+	}
+
+	if opts.Recurring != nil {
+		if *opts.Recurring {
+			conds = append(conds, "rrule <> ''")
+		} else {
+			conds = append(conds, "rrule = ''")
+		}
+	}
+
+	if opts.SeriesID != nil {
+		conds = append(conds, "series_id = ?")
+		args = append(args, *opts.SeriesID)
+	}
+
+	if opts.ICalUID != nil {
+		conds = append(conds, "ical_uid = ?")
+		args = append(args, *opts.ICalUID)
+	}
+
+	if opts.ResourceID != nil {
+		conds = append(conds, "id IN (SELECT event_id FROM event_resources WHERE resource_id = ?)")
+		args = append(args, *opts.ResourceID)
+	}
+
+	if opts.From != nil {
+		// Серии разворачиваются сервисом, для них окно ограничивает только начало.
+		conds = append(conds, "(rrule <> '' OR end_ns > ? OR (end_ns <= date_ns AND date_ns >= ?))")
+		from := opts.From.UnixNano()
+		args = append(args, from, from)
+	}
+
+	if opts.To != nil {
+		conds = append(conds, "date_ns < ?")
+		args = append(args, opts.To.UnixNano())
+	}
+
+	if opts.After != nil {
+		// Продолжение выборки после позиции в порядке ORDER BY (см. buildPage).
+		op := ">"
+		if opts.Order == infra.OrderDesc {
+			op = "<"
+		}
+		conds = append(conds, "(date_ns "+op+" ? OR (date_ns = ? AND id "+op+" ?))")
+		date := opts.After.Date.UnixNano()
+		args = append(args, date, date, opts.After.ID)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// buildPage - переводит порядок и пагинацию ListOptions в ORDER BY/LIMIT/OFFSET.
+func buildPage(opts *infra.ListOptions) (string, []any) {
+	if opts == nil {
+		opts = &infra.ListOptions{}
+	}
+
+	query := ` ORDER BY date_ns, id`
+	if opts.Order == infra.OrderDesc {
+		query = ` ORDER BY date_ns DESC, id DESC`
+	}
+
+	if opts.Limit <= 0 && opts.Offset <= 0 {
+		return query, nil
+	}
+
+	// OFFSET без LIMIT не поддерживает SQLite, поэтому "без ограничения" - максимальный BIGINT.
+	limit := int64(math.MaxInt64)
+	if opts.Limit > 0 {
+		limit = int64(opts.Limit)
+	}
+
+	return query + ` LIMIT ? OFFSET ?`, []any{limit, max(opts.Offset, 0)}
+}
+
+func eventArgs(event *models.Event) ([]any, error) {
+	reminders, err := encodeReminders(event.Reminders)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{
+		event.ID,
+		event.UserID,
+		event.Date.UnixNano(),
+		event.Text,
+		reminders,
+		event.ReminderPending(),
+		event.Archived,
+		event.RRule,
+		encodeTimes(event.ExDates),
+		event.SeriesID,
+		nullableTime(event.RecurrenceID),
+		event.ICalUID,
+		event.EndTime().UnixNano(),
+		event.AllDay,
+		event.TimeZone,
+		encodeChannels(event.Channels),
+		event.CalendarID,
+		encodeAttendees(event.Attendees),
+		strings.Join(event.Resources, ","),
+		searchTerms(event.Text),
+	}, nil
+}
+
+func nullableTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+
+	return t.UnixNano()
+}
+
+func timeFromNull(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+
+	t := time.Unix(0, v.Int64)
+	return &t
+}
+
+// encodeTimes - сериализует список дат в строку unix-наносекунд через запятую.
+func encodeTimes(times []time.Time) string {
+	parts := make([]string, 0, len(times))
+	for _, t := range times {
+		parts = append(parts, strconv.FormatInt(t.UnixNano(), 10))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func decodeTimes(s string) ([]time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	res := make([]time.Time, 0, len(parts))
+	for _, p := range parts {
+		ns, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("strconv.ParseInt: %w", err)
+		}
+		res = append(res, time.Unix(0, ns))
+	}
+
+	return res, nil
+}
+
+// storedReminder - представление напоминания в колонке reminders (JSON, время в unix-наносекундах).
+type storedReminder struct {
+	OffsetNs     int64  `json:"offset_ns"`
+	SentAtNs     *int64 `json:"sent_at_ns,omitempty"`
+	OccurrenceNs *int64 `json:"occurrence_ns,omitempty"`
+}
+
+func encodeReminders(reminders []models.Reminder) (string, error) {
+	if len(reminders) == 0 {
+		return "", nil
+	}
+
+	stored := make([]storedReminder, 0, len(reminders))
+	for _, r := range reminders {
+		stored = append(stored, storedReminder{
+			OffsetNs:     int64(r.Offset),
+			SentAtNs:     unixNanos(r.SentAt),
+			OccurrenceNs: unixNanos(r.Occurrence),
+		})
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func decodeReminders(s string) ([]models.Reminder, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var stored []storedReminder
+	if err := json.Unmarshal([]byte(s), &stored); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	res := make([]models.Reminder, 0, len(stored))
+	for _, r := range stored {
+		res = append(res, models.Reminder{
+			Offset:     models.Offset(r.OffsetNs),
+			SentAt:     timeFromNanos(r.SentAtNs),
+			Occurrence: timeFromNanos(r.OccurrenceNs),
+		})
+	}
+
+	return res, nil
+}
+
+func unixNanos(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+
+	ns := t.UnixNano()
+	return &ns
+}
+
+func timeFromNanos(ns *int64) *time.Time {
+	if ns == nil {
+		return nil
+	}
+
+	t := time.Unix(0, *ns)
+	return &t
+}
+
+// encodeChannels - сериализует список каналов в строку через запятую.
+func encodeChannels(channels []models.Channel) string {
+	parts := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		parts = append(parts, string(ch))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func decodeChannels(s string) []models.Channel {
+	if s == "" {
+		return nil
+	}
+
+	parts := strings.Split(s, ",")
+	res := make([]models.Channel, 0, len(parts))
+	for _, p := range parts {
+		res = append(res, models.Channel(p))
+	}
+
+	return res
+}
+
+// encodeAttendees - сериализует участников в строку "user_id:status" через запятую.
+// searchTerms - различные термы текста (textsearch.Terms) через пробел: по ним Search отбирает события.
+func searchTerms(text string) string {
+	terms := textsearch.Terms(text)
+	slices.Sort(terms)
+
+	return strings.Join(slices.Compact(terms), " ")
+}
+
+func encodeAttendees(attendees []models.Attendee) string {
+	parts := make([]string, 0, len(attendees))
+	for _, a := range attendees {
+		parts = append(parts, strconv.FormatInt(a.UserID, 10)+":"+string(a.Status))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func decodeAttendees(s string) ([]models.Attendee, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	res := make([]models.Attendee, 0, len(parts))
+	for _, p := range parts {
+		id, status, _ := strings.Cut(p, ":")
+		uid, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("strconv.ParseInt: %w", err)
+		}
+		res = append(res, models.Attendee{UserID: uid, Status: models.AttendeeStatus(status)})
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(s scanner) (*models.Event, error) {
+	var (
+		evnt         models.Event
+		dateNs       int64
+		endNs        int64
+		reminders    string
+		pending      bool
+		exdates      string
+		recurrenceID sql.NullInt64
+		channels     string
+		attendees    string
+		resources    string
+	)
+
+	if err := s.Scan(
+		&evnt.ID,
+		&evnt.UserID,
+		&dateNs,
+		&evnt.Text,
+		&reminders,
+		&pending,
+		&evnt.Archived,
+		&evnt.RRule,
+		&exdates,
+		&evnt.SeriesID,
+		&recurrenceID,
+		&evnt.ICalUID,
+		&endNs,
+		&evnt.AllDay,
+		&evnt.TimeZone,
+		&channels,
+		&evnt.CalendarID,
+		&attendees,
+		&resources,
+	); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if evnt.ExDates, err = decodeTimes(exdates); err != nil {
+		return nil, err
+	}
+	if evnt.Reminders, err = decodeReminders(reminders); err != nil {
+		return nil, err
+	}
+
+	if evnt.Attendees, err = decodeAttendees(attendees); err != nil {
+		return nil, err
+	}
+
+	evnt.Channels = decodeChannels(channels)
+	if resources != "" {
+		evnt.Resources = strings.Split(resources, ",")
+	}
+	evnt.Date = time.Unix(0, dateNs)
+	evnt.End = time.Unix(0, max(endNs, dateNs))
+	evnt.RecurrenceID = timeFromNull(recurrenceID)
+
+	// Время хранится как момент, зону события восстанавливаем для корректного разворачивания серий.
+	if loc, err := time.LoadLocation(evnt.TimeZone); evnt.TimeZone != "" && err == nil {
+		evnt.Date = evnt.Date.In(loc)
+		evnt.End = evnt.End.In(loc)
+	}
+
+	return &evnt, nil
+}
@@ -0,0 +1,834 @@
+package sqldb
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func openSQLite(t *testing.T, dsn string) *DB {
+	t.Helper()
+
+	db, err := Open(context.Background(), config.DatabaseConfig{Driver: "sqlite", DSN: dsn}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func newSQLiteRepo(t *testing.T, dsn string) *sqlRepo {
+	t.Helper()
+
+	r, ok := New(openSQLite(t, dsn)).(*sqlRepo)
+	require.True(t, ok)
+
+	return r
+}
+
+func TestCRUD(t *testing.T) {
+	repo := newSQLiteRepo(t, filepath.Join(t.TempDir(), "calendar.db"))
+	ctx := context.Background()
+
+	day := time.Date(2025, 1, 2, 13, 14, 15, 0, time.Local)
+	event := &models.Event{
+		ID:        "e-1",
+		UserID:    1,
+		Date:      day,
+		Text:      "meeting",
+		Reminders: []models.Reminder{{Offset: models.Offset(-15 * time.Minute)}, {Offset: 0}},
+		Channels:  []models.Channel{models.ChannelLog},
+	}
+
+	require.NoError(t, repo.Create(ctx, event))
+	require.ErrorIs(t, repo.Create(ctx, event), errDuplicate)
+
+	got, err := repo.Read(ctx, "e-1")
+	require.NoError(t, err)
+	assert.Equal(t, "meeting", got.Text)
+	assert.True(t, got.Date.Equal(day))
+	require.Len(t, got.Reminders, 2)
+	assert.Equal(t, models.Offset(-15*time.Minute), got.Reminders[0].Offset)
+	assert.Nil(t, got.Reminders[0].SentAt)
+	assert.Equal(t, []models.Channel{models.ChannelLog}, got.Channels)
+
+	sentAt := day.Add(time.Minute)
+	got.Text = "updated"
+	got.Reminders[0].SentAt = &sentAt
+	require.NoError(t, repo.Update(ctx, got))
+
+	got, err = repo.Read(ctx, "e-1")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", got.Text)
+	require.NotNil(t, got.Reminders[0].SentAt)
+	assert.True(t, got.Reminders[0].SentAt.Equal(sentAt))
+	assert.Nil(t, got.Reminders[1].SentAt)
+
+	deleted, err := repo.Delete(ctx, "e-1")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	_, err = repo.Read(ctx, "e-1")
+	require.ErrorIs(t, err, errNotFound)
+	require.ErrorIs(t, repo.Update(ctx, event), errNotFound)
+
+	_, err = repo.Delete(ctx, "e-1")
+	require.ErrorIs(t, err, errNotFound)
+}
+
+func TestListFilters(t *testing.T) {
+	repo := newSQLiteRepo(t, filepath.Join(t.TempDir(), "calendar.db"))
+	ctx := context.Background()
+
+	sentAt := time.Date(2025, 1, 7, 0, 0, 0, 0, time.Local)
+	events := []models.Event{
+		{ID: "a", UserID: 1, Date: time.Date(2025, 1, 6, 0, 0, 0, 0, time.Local), Text: "a", Reminders: []models.Reminder{{}}},
+		{ID: "b", UserID: 1, Date: time.Date(2025, 1, 6, 23, 59, 0, 0, time.Local), Text: "b", Archived: true},
+		{ID: "c", UserID: 1, Date: time.Date(2025, 1, 7, 0, 0, 0, 0, time.Local), Text: "c", Reminders: []models.Reminder{{SentAt: &sentAt}}, CalendarID: "work"},
+		{ID: "d", UserID: 2, Date: time.Date(2025, 1, 6, 12, 0, 0, 0, time.Local), Text: "d"},
+	}
+	for i := range events {
+		require.NoError(t, repo.Create(ctx, &events[i]))
+	}
+
+	userID := int64(1)
+	archived := false
+	pending := true
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.Local)
+	nextDay := day.AddDate(0, 0, 1)
+
+	list, err := repo.List(ctx, &infra.ListOptions{UserID: &userID, From: &day, To: &nextDay})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, ids(list))
+
+	list, err = repo.List(ctx, &infra.ListOptions{UserID: &userID, Archived: &archived})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "c"}, ids(list))
+
+	list, err = repo.List(ctx, &infra.ListOptions{ReminderPending: &pending})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a"}, ids(list))
+
+	list, err = repo.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, list, 4)
+
+	list, err = repo.List(ctx, &infra.ListOptions{UserID: &userID, CalendarIDs: []string{"work"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, ids(list))
+	assert.Equal(t, "work", list[0].CalendarID)
+
+	list, err = repo.List(ctx, &infra.ListOptions{UserID: &userID, CalendarIDs: []string{""}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, ids(list))
+
+	list, err = repo.List(ctx, &infra.ListOptions{UserID: &userID, CalendarIDs: []string{}})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestListParticipant(t *testing.T) {
+	repo := newSQLiteRepo(t, filepath.Join(t.TempDir(), "calendar.db"))
+	ctx := context.Background()
+	day := time.Date(2025, 1, 6, 10, 0, 0, 0, time.Local)
+
+	events := []models.Event{
+		{ID: "own", UserID: 2, Date: day, Text: "own"},
+		{ID: "work", UserID: 2, Date: day, Text: "work", CalendarID: "work"},
+		{ID: "invited", UserID: 1, Date: day, Text: "invited", Attendees: []models.Attendee{
+			{UserID: 2, Status: models.AttendeeNeedsAction},
+			{UserID: 3, Status: models.AttendeeAccepted},
+		}},
+		{ID: "declined", UserID: 1, Date: day, Text: "declined", Attendees: []models.Attendee{
+			{UserID: 2, Status: models.AttendeeDeclined},
+		}},
+		{ID: "other", UserID: 1, Date: day, Text: "other"},
+	}
+	for i := range events {
+		require.NoError(t, repo.Create(ctx, &events[i]))
+	}
+
+	got, err := repo.Read(ctx, "invited")
+	require.NoError(t, err)
+	assert.Equal(t, events[2].Attendees, got.Attendees)
+
+	participant := int64(2)
+	list, err := repo.List(ctx, &infra.ListOptions{Participant: &participant})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"own", "work", "invited"}, ids(list))
+
+	// Приглашения относятся к календарю по умолчанию участника.
+	list, err = repo.List(ctx, &infra.ListOptions{Participant: &participant, CalendarIDs: []string{"work"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, ids(list))
+
+	list, err = repo.List(ctx, &infra.ListOptions{Participant: &participant, CalendarIDs: []string{""}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"own", "invited"}, ids(list))
+
+	// Ответ участника и удаление события обновляют приглашения.
+	got.Attendees = []models.Attendee{{UserID: 2, Status: models.AttendeeDeclined}}
+	require.NoError(t, repo.Update(ctx, got))
+	_, err = repo.Delete(ctx, "own")
+	require.NoError(t, err)
+
+	list, err = repo.List(ctx, &infra.ListOptions{Participant: &participant})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, ids(list))
+
+	participant = 3
+	list, err = repo.List(ctx, &infra.ListOptions{Participant: &participant})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestListOrderAndPage(t *testing.T) {
+	repo := newSQLiteRepo(t, filepath.Join(t.TempDir(), "calendar.db"))
+	ctx := context.Background()
+
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.Local)
+	events := []models.Event{
+		{ID: "c", UserID: 1, Date: day.Add(2 * time.Hour), Text: "c"},
+		{ID: "a", UserID: 1, Date: day.Add(time.Hour), Text: "a"},
+		{ID: "d", UserID: 1, Date: day.Add(3 * time.Hour), Text: "d"},
+		{ID: "b", UserID: 1, Date: day.Add(time.Hour), Text: "b"},
+	}
+	for i := range events {
+		require.NoError(t, repo.Create(ctx, &events[i]))
+	}
+
+	list, err := repo.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, ids(list))
+
+	list, err = repo.List(ctx, &infra.ListOptions{Order: infra.OrderDesc, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "c"}, ids(list))
+
+	list, err = repo.List(ctx, &infra.ListOptions{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, ids(list))
+
+	list, err = repo.List(ctx, &infra.ListOptions{Offset: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d"}, ids(list))
+
+	// Продолжение после позиции: при равном начале порядок задает ID.
+	list, err = repo.List(ctx, &infra.ListOptions{Limit: 2, After: &models.EventKey{Date: day.Add(time.Hour), ID: "a"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, ids(list))
+
+	list, err = repo.List(ctx, &infra.ListOptions{Order: infra.OrderDesc, After: &models.EventKey{Date: day.Add(time.Hour), ID: "b"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, ids(list))
+}
+
+func TestSearch(t *testing.T) {
+	repo := newSQLiteRepo(t, filepath.Join(t.TempDir(), "calendar.db"))
+	ctx := context.Background()
+
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.Local)
+	events := []models.Event{
+		{ID: "a", UserID: 1, Date: day, Text: "Встреча с клиентом", Archived: true},
+		{ID: "b", UserID: 1, Date: day.AddDate(0, 0, 7), Text: "встречи"},
+		{ID: "c", UserID: 1, Date: day, Text: "обед"},
+		{ID: "d", UserID: 2, Date: day, Text: "встреча"},
+	}
+	for i := range events {
+		require.NoError(t, repo.Create(ctx, &events[i]))
+	}
+
+	list, err := repo.Search(ctx, infra.SearchOptions{UserID: 1, Query: "ВСТРЕЧАМИ"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "a"}, ids(list))
+
+	to := day.AddDate(0, 0, 1)
+	list, err = repo.Search(ctx, infra.SearchOptions{UserID: 1, Query: "встреча клиент", To: &to})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, ids(list))
+
+	list, err = repo.Search(ctx, infra.SearchOptions{UserID: 1, Query: "встреч", Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	archived := false
+	list, err = repo.Search(ctx, infra.SearchOptions{UserID: 1, Query: "встреча", Archived: &archived})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, ids(list))
+
+	// Префильтр по search_terms: терм запроса совпадает только с началом терма текста.
+	list, err = repo.Search(ctx, infra.SearchOptions{UserID: 1, Query: "стреч"})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// Переименованное событие ищется по новому тексту.
+	events[2].Text = "Встреча за обедом"
+	require.NoError(t, repo.Update(ctx, &events[2]))
+	list, err = repo.Search(ctx, infra.SearchOptions{UserID: 1, Query: "обед встреч", Archived: &archived})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, ids(list))
+}
+
+func TestListOverlap(t *testing.T) {
+	repo := newSQLiteRepo(t, filepath.Join(t.TempDir(), "calendar.db"))
+	ctx := context.Background()
+
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.Local)
+	events := []models.Event{
+		{ID: "multi-day", UserID: 1, Date: day.Add(-24 * time.Hour), End: day.Add(36 * time.Hour), Text: "conf"},
+		{ID: "ends-at-midnight", UserID: 1, Date: day.Add(-time.Hour), End: day, Text: "late"},
+		{ID: "point", UserID: 1, Date: day.Add(10 * time.Hour), Text: "call"},
+		{ID: "series", UserID: 1, Date: day.AddDate(0, 0, -7), Text: "weekly", RRule: "FREQ=WEEKLY"},
+		{ID: "future-series", UserID: 1, Date: day.AddDate(0, 0, 7), Text: "weekly", RRule: "FREQ=WEEKLY"},
+	}
+	for i := range events {
+		require.NoError(t, repo.Create(ctx, &events[i]))
+	}
+
+	from, to := day, day.AddDate(0, 0, 1)
+	list, err := repo.List(ctx, &infra.ListOptions{From: &from, To: &to})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"multi-day", "point", "series"}, ids(list))
+
+	got, err := repo.Read(ctx, "multi-day")
+	require.NoError(t, err)
+	assert.True(t, got.End.Equal(day.Add(36*time.Hour)))
+}
+
+func TestTimeZone(t *testing.T) {
+	repo := newSQLiteRepo(t, filepath.Join(t.TempDir(), "calendar.db"))
+	ctx := context.Background()
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	start := time.Date(2025, 1, 6, 10, 0, 0, 0, moscow)
+	require.NoError(t, repo.Create(ctx, &models.Event{ID: "e-1", UserID: 1, Date: start, Text: "x", TimeZone: "Europe/Moscow"}))
+
+	got, err := repo.Read(ctx, "e-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Moscow", got.TimeZone)
+	assert.Equal(t, moscow, got.Date.Location())
+	assert.True(t, got.Date.Equal(start))
+}
+
+func TestUserSettings(t *testing.T) {
+	users := NewUserRepo(openSQLite(t, filepath.Join(t.TempDir(), "calendar.db")))
+	ctx := context.Background()
+
+	got, err := users.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	require.NoError(t, users.Save(ctx, &models.UserSettings{UserID: 1, TimeZone: "Europe/Moscow"}))
+	require.NoError(t, users.Save(ctx, &models.UserSettings{
+		UserID:     1,
+		TimeZone:   "Asia/Tokyo",
+		Channels:   []models.Channel{models.ChannelEmail, models.ChannelWebhook},
+		Email:      "user@example.com",
+		WebhookURL: "https://hooks.example.com/r",
+	}))
+
+	got, err = users.Get(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "Asia/Tokyo", got.TimeZone)
+	assert.Equal(t, []models.Channel{models.ChannelEmail, models.ChannelWebhook}, got.Channels)
+	assert.Equal(t, "user@example.com", got.Email)
+	assert.Equal(t, "https://hooks.example.com/r", got.WebhookURL)
+}
+
+func TestWebhooks(t *testing.T) {
+	webhooks := NewWebhookRepo(openSQLite(t, filepath.Join(t.TempDir(), "calendar.db")))
+	ctx := context.Background()
+	created := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	webhook := &models.Webhook{
+		ID:        "w-1",
+		UserID:    1,
+		URL:       "https://crm.example.com/hook",
+		Secret:    "s3cr3t",
+		Types:     []models.EnvelopeType{models.EventCreated, models.EventDeleted},
+		CreatedAt: created,
+	}
+	require.NoError(t, webhooks.Create(ctx, webhook))
+	require.ErrorIs(t, webhooks.Create(ctx, webhook), errDuplicate)
+	require.NoError(t, webhooks.Create(ctx, &models.Webhook{ID: "w-2", UserID: 2, URL: "https://bot.example.com", Secret: "x", CreatedAt: created}))
+
+	got, err := webhooks.Get(ctx, "w-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "s3cr3t", got.Secret)
+	assert.Equal(t, webhook.Types, got.Types)
+	assert.True(t, got.CreatedAt.Equal(created))
+
+	missing, err := webhooks.Get(ctx, "nope")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	list, err := webhooks.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "w-1", list[0].ID)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		require.NoError(t, webhooks.AddDelivery(ctx, &models.WebhookDelivery{
+			ID:         "d-" + strconv.Itoa(attempt),
+			WebhookID:  "w-1",
+			EnvelopeID: "env-1",
+			Type:       models.EventCreated,
+			EventID:    "e-1",
+			Attempt:    attempt,
+			Status:     models.DeliveryRetrying,
+			StatusCode: 500,
+			Error:      "500",
+			CreatedAt:  created.Add(time.Duration(attempt) * time.Second),
+		}))
+	}
+
+	deliveries, err := webhooks.Deliveries(ctx, "w-1", 2)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, 3, deliveries[0].Attempt)
+	assert.Equal(t, 2, deliveries[1].Attempt)
+	assert.Equal(t, models.DeliveryRetrying, deliveries[0].Status)
+	assert.Equal(t, 500, deliveries[0].StatusCode)
+
+	ok, err := webhooks.Delete(ctx, "w-1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, err = webhooks.Delete(ctx, "w-1")
+	require.ErrorIs(t, err, errNotFound)
+
+	deliveries, err = webhooks.Deliveries(ctx, "w-1", 10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestShares(t *testing.T) {
+	shares := NewShareRepo(openSQLite(t, filepath.Join(t.TempDir(), "calendar.db")))
+	ctx := context.Background()
+	created := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, shares.Save(ctx, &models.Share{OwnerID: 1, GranteeID: 2, Role: models.ShareViewer, CreatedAt: created}))
+	require.NoError(t, shares.Save(ctx, &models.Share{OwnerID: 3, GranteeID: 1, Role: models.ShareFreeBusy, CreatedAt: created.Add(time.Hour)}))
+	// Повторная выдача меняет роль, но сохраняет время выдачи.
+	require.NoError(t, shares.Save(ctx, &models.Share{OwnerID: 1, GranteeID: 2, Role: models.ShareEditor, CreatedAt: created.Add(2 * time.Hour)}))
+
+	got, err := shares.Get(ctx, 1, 2)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, models.ShareEditor, got.Role)
+	assert.True(t, got.CreatedAt.Equal(created))
+
+	missing, err := shares.Get(ctx, 2, 1)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	list, err := shares.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(2), list[0].GranteeID)
+	assert.Equal(t, int64(3), list[1].OwnerID)
+
+	ok, err := shares.Delete(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = shares.Delete(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCalendars(t *testing.T) {
+	calendars := NewCalendarRepo(openSQLite(t, filepath.Join(t.TempDir(), "calendar.db")))
+	ctx := context.Background()
+	created := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	work := &models.Calendar{
+		ID:         "work",
+		UserID:     1,
+		Name:       "Работа",
+		Color:      "#1E90FF",
+		Reminders:  []models.Reminder{{Offset: models.Offset(-15 * time.Minute)}},
+		Visibility: models.VisibilityShared,
+		CreatedAt:  created,
+	}
+	require.NoError(t, calendars.Create(ctx, work))
+	require.NoError(t, calendars.Create(ctx, &models.Calendar{
+		ID: "personal", UserID: 1, Name: "Личное", Visibility: models.VisibilityPrivate, CreatedAt: created.Add(time.Hour),
+	}))
+	require.NoError(t, calendars.Create(ctx, &models.Calendar{
+		ID: "other", UserID: 2, Name: "Дежурства", Visibility: models.VisibilityBusy, NoOverlap: true, CreatedAt: created,
+	}))
+
+	got, err := calendars.Get(ctx, "work")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "Работа", got.Name)
+	assert.Equal(t, "#1E90FF", got.Color)
+	require.Len(t, got.Reminders, 1)
+	assert.Equal(t, models.Offset(-15*time.Minute), got.Reminders[0].Offset)
+	assert.True(t, got.CreatedAt.Equal(created))
+
+	assert.False(t, got.NoOverlap)
+
+	got, err = calendars.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.True(t, got.NoOverlap)
+
+	missing, err := calendars.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	list, err := calendars.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "work", list[0].ID)
+	assert.Equal(t, "personal", list[1].ID)
+
+	work.Name = "Офис"
+	work.Reminders = nil
+	work.NoOverlap = true
+	require.NoError(t, calendars.Update(ctx, work))
+	got, err = calendars.Get(ctx, "work")
+	require.NoError(t, err)
+	assert.Equal(t, "Офис", got.Name)
+	assert.True(t, got.NoOverlap)
+	assert.Empty(t, got.Reminders)
+	require.ErrorIs(t, calendars.Update(ctx, &models.Calendar{ID: "missing"}), errNotFound)
+
+	ok, err := calendars.Delete(ctx, "work")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = calendars.Delete(ctx, "work")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestResources(t *testing.T) {
+	resources := NewResourceRepo(openSQLite(t, filepath.Join(t.TempDir(), "calendar.db")))
+	ctx := context.Background()
+	created := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	room := &models.Resource{
+		ID:       "room",
+		UserID:   1,
+		Name:     "Переговорная 301",
+		Kind:     models.ResourceRoom,
+		Capacity: 8,
+		Location: "3 этаж",
+		Policy: models.BookingPolicy{
+			MaxDuration: models.Offset(4 * time.Hour),
+			Horizon:     models.Offset(30 * 24 * time.Hour),
+		},
+		CreatedAt: created,
+	}
+	require.NoError(t, resources.Create(ctx, room))
+	require.ErrorIs(t, resources.Create(ctx, room), errDuplicate)
+	require.NoError(t, resources.Create(ctx, &models.Resource{
+		ID: "projector", UserID: 2, Name: "Проектор", Kind: models.ResourceEquipment, CreatedAt: created,
+	}))
+
+	got, err := resources.Get(ctx, "room")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, room.Policy, got.Policy)
+	assert.Equal(t, 8, got.Capacity)
+	assert.Equal(t, "3 этаж", got.Location)
+	assert.True(t, got.CreatedAt.Equal(created))
+
+	list, err := resources.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"room", "projector"}, []string{list[0].ID, list[1].ID})
+
+	room.Capacity = 10
+	room.Policy = models.BookingPolicy{}
+	require.NoError(t, resources.Update(ctx, room))
+	got, err = resources.Get(ctx, "room")
+	require.NoError(t, err)
+	assert.Equal(t, 10, got.Capacity)
+	assert.Zero(t, got.Policy)
+	require.ErrorIs(t, resources.Update(ctx, &models.Resource{ID: "missing"}), errNotFound)
+
+	ok, err := resources.Delete(ctx, "room")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, err = resources.Get(ctx, "room")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestResourceClaims(t *testing.T) {
+	repo := newSQLiteRepo(t, filepath.Join(t.TempDir(), "calendar.db"))
+	ctx := context.Background()
+	at := func(h int) time.Time { return time.Date(2025, 7, 1, h, 0, 0, 0, time.UTC) }
+
+	booked := &models.Event{ID: "a", UserID: 1, Date: at(10), End: at(12), Text: "x", Resources: []string{"room"}}
+	require.NoError(t, repo.Create(ctx, booked))
+	require.NoError(t, repo.Create(ctx, &models.Event{ID: "b", UserID: 2, Date: at(12), End: at(13), Text: "x", Resources: []string{"room"}}))
+
+	err := repo.Create(ctx, &models.Event{ID: "c", UserID: 3, Date: at(11), End: at(14), Text: "x", Resources: []string{"projector", "room"}})
+	var conflict *models.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []string{"a", "b"}, conflict.EventIDs)
+	_, err = repo.Read(ctx, "c")
+	require.ErrorIs(t, err, errNotFound)
+
+	got, err := repo.Read(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"room"}, got.Resources)
+
+	booked.End = at(11)
+	require.NoError(t, repo.Update(ctx, booked))
+	require.NoError(t, repo.Create(ctx, &models.Event{ID: "d", UserID: 3, Date: at(11), End: at(12), Text: "x", Resources: []string{"room"}}))
+	booked.End = at(12)
+	require.ErrorAs(t, repo.Update(ctx, booked), &conflict)
+	assert.Equal(t, []string{"d"}, conflict.EventIDs)
+
+	resourceID := "room"
+	list, err := repo.List(ctx, &infra.ListOptions{ResourceID: &resourceID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "d", "b"}, ids(list))
+
+	// Удаление события освобождает ресурс.
+	_, err = repo.Delete(ctx, "d")
+	require.NoError(t, err)
+	require.NoError(t, repo.Update(ctx, booked))
+}
+
+func TestCapacityClaims(t *testing.T) {
+	repo := newSQLiteRepo(t, filepath.Join(t.TempDir(), "calendar.db"))
+	resources := NewResourceRepo(repo.DB)
+	ctx := context.Background()
+	at := func(h int) time.Time { return time.Date(2025, 7, 1, h, 0, 0, 0, time.UTC) }
+
+	room := &models.Resource{ID: "room", UserID: 1, Name: "Переговорная", Kind: models.ResourceRoom, Capacity: 2, CreatedAt: at(0)}
+	require.NoError(t, resources.Create(ctx, room))
+
+	var booking *models.BookingError
+	crowded := &models.Event{ID: "a", UserID: 1, Date: at(10), End: at(11), Text: "x", Resources: []string{"room"},
+		Attendees: []models.Attendee{{UserID: 2, Status: models.AttendeeNeedsAction}, {UserID: 3, Status: models.AttendeeNeedsAction}}}
+	require.ErrorAs(t, repo.Create(ctx, crowded), &booking)
+	assert.Equal(t, "room", booking.ResourceID)
+
+	crowded.Attendees = crowded.Attendees[:1]
+	require.NoError(t, repo.Create(ctx, crowded))
+
+	invite := func(event *models.Event) error {
+		event.Attendees = append(event.Attendees, models.Attendee{UserID: 3, Status: models.AttendeeNeedsAction})
+		return nil
+	}
+	_, err := repo.UpdateAttendees(ctx, "a", invite)
+	require.ErrorAs(t, err, &booking)
+	got, err := repo.Read(ctx, "a")
+	require.NoError(t, err)
+	assert.Len(t, got.Attendees, 1)
+
+	// Уменьшение вместимости не мешает отказаться и изменить событие, не добавляя людей.
+	room.Capacity = 1
+	require.NoError(t, resources.Update(ctx, room))
+	_, err = repo.UpdateAttendees(ctx, "a", func(event *models.Event) error {
+		event.Attendees[0].Status = models.AttendeeDeclined
+		return nil
+	})
+	require.NoError(t, err)
+	got, err = repo.Read(ctx, "a")
+	require.NoError(t, err)
+	got.Text = "ревью"
+	require.NoError(t, repo.Update(ctx, got))
+
+	got.Attendees[0].Status = models.AttendeeAccepted
+	require.ErrorAs(t, repo.Update(ctx, got), &booking)
+}
+
+func TestReplicaClaims(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "calendar.db")
+	first, second := newSQLiteRepo(t, dsn), newSQLiteRepo(t, dsn)
+	ctx := context.Background()
+	date := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	now := date.Add(time.Minute)
+
+	require.NoError(t, first.Create(ctx, &models.Event{
+		ID: "a", UserID: 1, Date: date, Text: "x", RRule: "FREQ=DAILY",
+		Reminders: []models.Reminder{{Offset: 0}},
+	}))
+
+	// Срабатывание отмечает только одна реплика.
+	ok, err := first.MarkReminderSent(ctx, "a", 0, date, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = second.MarkReminderSent(ctx, "a", 0, date, now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = second.MarkReminderSent(ctx, "a", 0, date.AddDate(0, 0, 1), now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = first.MarkReminderSent(ctx, "a", models.Offset(-time.Hour), date, now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	got, err := first.Read(ctx, "a")
+	require.NoError(t, err)
+	require.Len(t, got.Reminders, 1)
+	require.NotNil(t, got.Reminders[0].Occurrence)
+	assert.True(t, got.Reminders[0].Occurrence.Equal(date.AddDate(0, 0, 1)))
+
+	// Архивирует тоже только одна.
+	ok, err = first.Archive(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = second.Archive(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = first.Archive(ctx, "missing")
+	require.ErrorIs(t, err, errNotFound)
+	_, err = first.MarkReminderSent(ctx, "missing", 0, date, now)
+	require.ErrorIs(t, err, errNotFound)
+}
+
+func TestReplicaAttendees(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "calendar.db")
+	first, second := newSQLiteRepo(t, dsn), newSQLiteRepo(t, dsn)
+	ctx := context.Background()
+
+	require.NoError(t, first.Create(ctx, &models.Event{
+		ID: "a", UserID: 1, Date: time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC), Text: "x",
+		Attendees: []models.Attendee{{UserID: 2, Status: models.AttendeeNeedsAction}, {UserID: 3, Status: models.AttendeeNeedsAction}},
+	}))
+
+	respond := func(userID int64, status models.AttendeeStatus) func(*models.Event) error {
+		return func(event *models.Event) error {
+			event.Attendee(userID).Status = status
+			return nil
+		}
+	}
+
+	// Ответ другой реплики между чтением и записью не теряется: участники перечитываются.
+	calls := 0
+	got, err := first.UpdateAttendees(ctx, "a", func(event *models.Event) error {
+		if calls++; calls == 1 {
+			_, err := second.UpdateAttendees(ctx, "a", respond(3, models.AttendeeDeclined))
+			require.NoError(t, err)
+		}
+		event.Text = "не сохраняется"
+		return respond(2, models.AttendeeAccepted)(event)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	want := []models.Attendee{{UserID: 2, Status: models.AttendeeAccepted}, {UserID: 3, Status: models.AttendeeDeclined}}
+	assert.Equal(t, want, got.Attendees)
+	assert.Equal(t, "x", got.Text)
+
+	got, err = second.Read(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, want, got.Attendees)
+	assert.Equal(t, "x", got.Text)
+
+	participant := int64(3)
+	list, err := second.List(ctx, &infra.ListOptions{Participant: &participant})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// Ошибка change отменяет изменение.
+	_, err = first.UpdateAttendees(ctx, "a", func(*models.Event) error { return errNilEvent })
+	require.ErrorIs(t, err, errNilEvent)
+	_, err = first.UpdateAttendees(ctx, "missing", respond(2, models.AttendeeDeclined))
+	require.ErrorIs(t, err, errNotFound)
+}
+
+func TestMigrationsIdempotent(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "calendar.db")
+	ctx := context.Background()
+
+	repo := newSQLiteRepo(t, dsn)
+	require.NoError(t, repo.Create(ctx, &models.Event{ID: "e-1", UserID: 1, Date: time.Now(), Text: "x"}))
+
+	reopened := newSQLiteRepo(t, dsn)
+	_, err := reopened.Read(ctx, "e-1")
+	require.NoError(t, err)
+}
+
+func TestMigrateSingleReminder(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "calendar.db")
+	ctx := context.Background()
+
+	// Схема до перехода на список напоминаний.
+	all := migrations
+	migrations = all[:6]
+	old := openSQLite(t, dsn)
+	migrations = all
+
+	_, err := old.conn.ExecContext(ctx, `INSERT INTO events
+		(id, user_id, date_ns, text, reminder, reminder_sent, reminder_sent_at, end_ns)
+		VALUES ('sent', 1, 100, 'x', TRUE, TRUE, 150, 100), ('pending', 1, 200, 'y', TRUE, FALSE, NULL, 200)`)
+	require.NoError(t, err)
+	require.NoError(t, old.Close())
+
+	repo := New(openSQLite(t, dsn))
+
+	sent, err := repo.Read(ctx, "sent")
+	require.NoError(t, err)
+	require.Len(t, sent.Reminders, 1)
+	require.NotNil(t, sent.Reminders[0].SentAt)
+	assert.Equal(t, int64(150), sent.Reminders[0].SentAt.UnixNano())
+
+	pending := true
+	list, err := repo.List(ctx, &infra.ListOptions{ReminderPending: &pending})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pending"}, ids(list))
+}
+
+func TestMigrateSearchTerms(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "calendar.db")
+	ctx := context.Background()
+
+	// Схема до появления search_terms.
+	all := migrations
+	migrations = all[:13]
+	old := openSQLite(t, dsn)
+	migrations = all
+
+	_, err := old.conn.ExecContext(ctx, `INSERT INTO events (id, user_id, date_ns, text, end_ns)
+		VALUES ('dentist', 1, 100, 'Запись к стоматологу', 100), ('lunch', 1, 200, 'Обед', 200)`)
+	require.NoError(t, err)
+	require.NoError(t, old.Close())
+
+	repo := New(openSQLite(t, dsn))
+
+	list, err := repo.Search(ctx, infra.SearchOptions{UserID: 1, Query: "стоматолог"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dentist"}, ids(list))
+}
+
+func TestUnknownDriver(t *testing.T) {
+	_, err := Open(context.Background(), config.DatabaseConfig{Driver: "oracle"}, zap.NewNop())
+	require.ErrorIs(t, err, errUnknownDriver)
+}
+
+func TestRebind(t *testing.T) {
+	pg, err := dialectFor("postgres")
+	require.NoError(t, err)
+	assert.Equal(t, "a = $1 AND b = $2", pg.rebind("a = ? AND b = ?"))
+
+	lite, err := dialectFor("sqlite")
+	require.NoError(t, err)
+	assert.Equal(t, "a = ? AND b = ?", lite.rebind("a = ? AND b = ?"))
+}
+
+func ids(events []models.Event) []string {
+	res := make([]string, 0, len(events))
+	for _, e := range events {
+		res = append(res, e.ID)
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.UserRepo = (*sqlUserRepo)(nil)
+
+type sqlUserRepo struct {
+	*DB
+}
+
+// NewUserRepo - конструктор SQL хранилища пользовательских настроек.
+func NewUserRepo(db *DB) infra.UserRepo {
+	return &sqlUserRepo{DB: db}
+}
+
+func (db *sqlUserRepo) Get(ctx context.Context, userID int64) (*models.UserSettings, error) {
+	var (
+		settings = models.UserSettings{UserID: userID}
+		channels string
+	)
+
+	err := db.conn.QueryRowContext(
+		ctx,
+		db.dialect.rebind(`SELECT tz, channels, email, webhook_url FROM user_settings WHERE user_id = ?`),
+		userID,
+	).Scan(&settings.TimeZone, &channels, &settings.Email, &settings.WebhookURL)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select user_settings: %w", err)
+	}
+	settings.Channels = decodeChannels(channels)
+
+	return &settings, nil
+}
+
+func (db *sqlUserRepo) Save(ctx context.Context, settings *models.UserSettings) error {
+	if settings == nil {
+		return errNilSettings
+	}
+
+	if _, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`INSERT INTO user_settings (user_id, tz, channels, email, webhook_url) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (user_id) DO UPDATE SET
+				tz = excluded.tz,
+				channels = excluded.channels,
+				email = excluded.email,
+				webhook_url = excluded.webhook_url`),
+		settings.UserID, settings.TimeZone, encodeChannels(settings.Channels), settings.Email, settings.WebhookURL,
+	); err != nil {
+		return fmt.Errorf("upsert user_settings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ infra.WebhookRepo = (*sqlWebhookRepo)(nil)
+
+type sqlWebhookRepo struct {
+	*DB
+}
+
+// NewWebhookRepo - конструктор SQL хранилища подписок на вебхуки.
+func NewWebhookRepo(db *DB) infra.WebhookRepo {
+	return &sqlWebhookRepo{DB: db}
+}
+
+func (db *sqlWebhookRepo) Create(ctx context.Context, webhook *models.Webhook) error {
+	if webhook == nil {
+		return errNilWebhook
+	}
+
+	res, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`INSERT INTO webhooks (id, user_id, url, secret, types, created_at) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO NOTHING`),
+		webhook.ID, webhook.UserID, webhook.URL, webhook.Secret, encodeTypes(webhook.Types), webhook.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("insert webhooks: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return errDuplicate
+	}
+
+	return nil
+}
+
+func (db *sqlWebhookRepo) Get(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	row := db.conn.QueryRowContext(
+		ctx,
+		db.dialect.rebind(`SELECT id, user_id, url, secret, types, created_at FROM webhooks WHERE id = ?`),
+		webhookID,
+	)
+
+	webhook, err := scanWebhook(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select webhooks: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (db *sqlWebhookRepo) List(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	rows, err := db.conn.QueryContext(
+		ctx,
+		db.dialect.rebind(`SELECT id, user_id, url, secret, types, created_at FROM webhooks
+			WHERE user_id = ? ORDER BY created_at, id`),
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]models.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		res = append(res, *webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return res, nil
+}
+
+func (db *sqlWebhookRepo) Delete(ctx context.Context, webhookID string) (bool, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("BeginTx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(
+		ctx,
+		db.dialect.rebind(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`),
+		webhookID,
+	); err != nil {
+		return false, fmt.Errorf("delete webhook_deliveries: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, db.dialect.rebind(`DELETE FROM webhooks WHERE id = ?`), webhookID)
+	if err != nil {
+		return false, fmt.Errorf("delete webhooks: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("RowsAffected: %w", err)
+	}
+	if n == 0 {
+		return false, errNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return true, nil
+}
+
+func (db *sqlWebhookRepo) AddDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if delivery == nil {
+		return errNilDelivery
+	}
+
+	if _, err := db.conn.ExecContext(
+		ctx,
+		db.dialect.rebind(`INSERT INTO webhook_deliveries
+			(id, webhook_id, envelope_id, type, event_id, attempt, status, status_code, error, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		delivery.ID, delivery.WebhookID, delivery.EnvelopeID, string(delivery.Type), delivery.EventID,
+		delivery.Attempt, string(delivery.Status), delivery.StatusCode, delivery.Error, delivery.CreatedAt.UnixNano(),
+	); err != nil {
+		return fmt.Errorf("insert webhook_deliveries: %w", err)
+	}
+
+	return nil
+}
+
+func (db *sqlWebhookRepo) Deliveries(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := db.conn.QueryContext(
+		ctx,
+		db.dialect.rebind(`SELECT id, webhook_id, envelope_id, type, event_id, attempt, status, status_code, error, created_at
+			FROM webhook_deliveries WHERE webhook_id = ? ORDER BY created_at DESC, attempt DESC LIMIT ?`),
+		webhookID, max(limit, 0),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select webhook_deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		var (
+			d         models.WebhookDelivery
+			typ       string
+			status    string
+			createdAt int64
+		)
+		if err := rows.Scan(
+			&d.ID, &d.WebhookID, &d.EnvelopeID, &typ, &d.EventID,
+			&d.Attempt, &status, &d.StatusCode, &d.Error, &createdAt,
+		); err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		d.Type = models.EnvelopeType(typ)
+		d.Status = models.DeliveryStatus(status)
+		d.CreatedAt = time.Unix(0, createdAt)
+		res = append(res, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return res, nil
+}
+
+func scanWebhook(row scanner) (*models.Webhook, error) {
+	var (
+		webhook   models.Webhook
+		types     string
+		createdAt int64
+	)
+	if err := row.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, &types, &createdAt); err != nil {
+		return nil, err
+	}
+	webhook.Types = decodeTypes(types)
+	webhook.CreatedAt = time.Unix(0, createdAt)
+
+	return &webhook, nil
+}
+
+func encodeTypes(types []models.EnvelopeType) string {
+	parts := make([]string, 0, len(types))
+	for _, typ := range types {
+		parts = append(parts, string(typ))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func decodeTypes(s string) []models.EnvelopeType {
+	if s == "" {
+		return nil
+	}
+
+	parts := strings.Split(s, ",")
+	res := make([]models.EnvelopeType, 0, len(parts))
+	for _, p := range parts {
+		res = append(res, models.EnvelopeType(p))
+	}
+
+	return res
+}
@@ -6,8 +6,14 @@ import (
 	"github.com/sunr3d/simple-http-calendar/models"
 )
 
+// Handler - обработчик сообщений брокера. nil подтверждает обработку сообщения.
+type Handler func(ctx context.Context, env models.Envelope) error
+
 //go:generate go run github.com/vektra/mockery/v2@v2.53.2 --name=Broker --output=../../../mocks --filename=mock_broker.go --with-expecter
 type Broker interface {
-	Publish(ctx context.Context, event *models.Event) error
-	Subscribe(ctx context.Context, handler func(ctx context.Context, event *models.Event) error) error
+	// Publish - публикует конверт в топик. Его получат все подписчики топика.
+	Publish(ctx context.Context, topic models.Topic, env models.Envelope) error
+	// Subscribe - подписывает обработчик на топик. subscriber - имя независимого подписчика:
+	// у каждого своя очередь и свои подтверждения; одно имя в топике может быть подписано один раз.
+	Subscribe(ctx context.Context, topic models.Topic, subscriber string, handler Handler) error
 }
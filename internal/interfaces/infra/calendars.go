@@ -0,0 +1,19 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// CalendarRepo - хранилище именованных календарей. Get возвращает nil без ошибки, если календаря нет.
+// List возвращает календари пользователя по времени создания.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.53.2 --name=CalendarRepo --output=../../../mocks --filename=mock_calendar_repo.go --with-expecter
+type CalendarRepo interface {
+	Create(ctx context.Context, calendar *models.Calendar) error
+	Get(ctx context.Context, calendarID string) (*models.Calendar, error)
+	List(ctx context.Context, userID int64) ([]models.Calendar, error)
+	Update(ctx context.Context, calendar *models.Calendar) error
+	Delete(ctx context.Context, calendarID string) (bool, error)
+}
@@ -7,19 +7,81 @@ import (
 	"github.com/sunr3d/simple-http-calendar/models"
 )
 
+// Order - порядок выборки событий: по началу события, при равенстве - по ID.
+type Order int
+
+const (
+	OrderAsc Order = iota
+	OrderDesc
+)
+
+// ListOptions - фильтр выборки событий. Nil-поля не участвуют в фильтрации.
+// From/To задают полуинтервал [From; To): выбираются события, пересекающиеся с ним
+// (см. models.Event.Overlaps), для повторяющихся серий учитывается только начало серии.
+// Результат упорядочен по Order; Limit ограничивает число событий (0 - без ограничения),
+// Offset пропускает первые события упорядоченной выборки, After оставляет только события,
+// идущие в порядке Order после позиции (см. models.EventKey.Compare).
+// CalendarIDs ограничивает выборку календарями ("" - календарь по умолчанию), nil - все календари.
+// Participant выбирает события, которыми пользователь владеет или на которые приглашен и не отклонил
+// приглашение; чужие события по приглашению относятся к его календарю по умолчанию.
+// ResourceID выбирает события, занявшие ресурс (см. models.Event.Resources).
 type ListOptions struct {
-	UserID       *int64
-	Archived     *bool
-	ReminderSent *bool
-	From         *time.Time
-	To           *time.Time
+	UserID      *int64
+	Participant *int64
+	ResourceID  *string
+	CalendarIDs []string
+	Archived    *bool
+	From        *time.Time
+	To          *time.Time
+	Recurring   *bool
+	SeriesID    *string
+	ICalUID     *string
+	// ReminderPending - есть ли у события неотправленные напоминания (см. models.Event.ReminderPending).
+	ReminderPending *bool
+
+	Order  Order
+	Limit  int
+	Offset int
+	After  *models.EventKey
+}
+
+// SearchOptions - полнотекстовый поиск по тексту событий пользователя.
+// From/To, CalendarIDs и Archived (если заданы) ограничивают выборку так же, как в ListOptions; Limit 0 - без ограничения.
+type SearchOptions struct {
+	UserID      int64
+	CalendarIDs []string
+	Query       string
+	From        *time.Time
+	To          *time.Time
+	Archived    *bool
+	Limit       int
 }
 
+// Database - хранилище событий. Create и Update атомарно занимают ресурсы события (models.Event.Resources)
+// на [Date; EndTime): если ресурс уже занят пересекающимся событием, событие не сохраняется
+// и возвращается *models.ConflictError с ID занявших его событий; если ресурс не вмещает людей события -
+// *models.BookingError (см. models.Resource.CheckCapacity), так же проверяет вместимость UpdateAttendees.
+// Конкурентные брони одного ресурса выполняются последовательно, поэтому два пересекающихся события
+// не могут занять его одновременно, а одновременные приглашения - превысить вместимость.
+// Read, Update, UpdateAttendees, Delete, MarkReminderSent и Archive отсутствующего события возвращают ошибку models.ErrNotFound.
+//
 //go:generate go run github.com/vektra/mockery/v2@v2.53.2 --name=Database --output=../../../mocks --filename=mock_database.go --with-expecter
 type Database interface {
 	Create(ctx context.Context, event *models.Event) error
 	Read(ctx context.Context, eventID string) (*models.Event, error)
 	Update(ctx context.Context, event *models.Event) error
+	// UpdateAttendees - атомарно изменяет участников события: change получает актуальное событие и меняет
+	// его Attendees (остальные поля не сохраняются), ошибка change отменяет изменение и возвращается как есть.
+	// Конкурентные изменения участников одного события не теряются; change может вызываться повторно
+	// и не должна обращаться к хранилищу событий. Возвращает сохраненное событие.
+	UpdateAttendees(ctx context.Context, eventID string, change func(event *models.Event) error) (*models.Event, error)
 	Delete(ctx context.Context, eventID string) (bool, error)
+	// MarkReminderSent - атомарно отмечает срабатывание напоминания отправленным (см. models.Event.MarkReminderSent).
+	// false - срабатывание уже отметил другой процесс, и отправлять напоминание не нужно.
+	MarkReminderSent(ctx context.Context, eventID string, offset models.Offset, occurrence, sentAt time.Time) (bool, error)
+	// Archive - атомарно архивирует событие. false - событие уже архивировано другим процессом.
+	Archive(ctx context.Context, eventID string) (bool, error)
 	List(ctx context.Context, opts *ListOptions) ([]models.Event, error)
+	// Search - события, содержащие все слова запроса (с учетом словоформ и префиксов), по убыванию релевантности.
+	Search(ctx context.Context, opts SearchOptions) ([]models.Event, error)
 }
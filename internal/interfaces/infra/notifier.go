@@ -0,0 +1,15 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// Notifier - канал доставки напоминаний.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.53.2 --name=Notifier --output=../../../mocks --filename=mock_notifier.go --with-expecter
+type Notifier interface {
+	Channel() models.Channel
+	Notify(ctx context.Context, n models.Notification) error
+}
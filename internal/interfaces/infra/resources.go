@@ -0,0 +1,19 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// ResourceRepo - хранилище бронируемых ресурсов. Get возвращает nil без ошибки, если ресурса нет.
+// List возвращает все ресурсы по названию.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.53.2 --name=ResourceRepo --output=../../../mocks --filename=mock_resource_repo.go --with-expecter
+type ResourceRepo interface {
+	Create(ctx context.Context, resource *models.Resource) error
+	Get(ctx context.Context, resourceID string) (*models.Resource, error)
+	List(ctx context.Context) ([]models.Resource, error)
+	Update(ctx context.Context, resource *models.Resource) error
+	Delete(ctx context.Context, resourceID string) (bool, error)
+}
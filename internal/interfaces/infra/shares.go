@@ -0,0 +1,19 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// ShareRepo - хранилище доступов к календарям. Save заменяет роль, если доступ уже выдан.
+// Get возвращает nil без ошибки, если доступа нет. List возвращает доступы, где пользователь
+// владелец или получатель, по времени выдачи.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.53.2 --name=ShareRepo --output=../../../mocks --filename=mock_share_repo.go --with-expecter
+type ShareRepo interface {
+	Save(ctx context.Context, share *models.Share) error
+	Get(ctx context.Context, ownerID, granteeID int64) (*models.Share, error)
+	List(ctx context.Context, userID int64) ([]models.Share, error)
+	Delete(ctx context.Context, ownerID, granteeID int64) (bool, error)
+}
@@ -0,0 +1,16 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// UserRepo - хранилище пользовательских настроек.
+// Get возвращает nil без ошибки, если настройки пользователя еще не сохранялись.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.53.2 --name=UserRepo --output=../../../mocks --filename=mock_user_repo.go --with-expecter
+type UserRepo interface {
+	Get(ctx context.Context, userID int64) (*models.UserSettings, error)
+	Save(ctx context.Context, settings *models.UserSettings) error
+}
@@ -0,0 +1,22 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// WebhookRepo - хранилище подписок на вебхуки и журнала их доставок.
+// Get возвращает nil без ошибки, если подписки нет. Delete удаляет подписку вместе с журналом.
+// Deliveries возвращает не больше limit последних попыток доставки, новые первыми.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.53.2 --name=WebhookRepo --output=../../../mocks --filename=mock_webhook_repo.go --with-expecter
+type WebhookRepo interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	Get(ctx context.Context, webhookID string) (*models.Webhook, error)
+	List(ctx context.Context, userID int64) ([]models.Webhook, error)
+	Delete(ctx context.Context, webhookID string) (bool, error)
+
+	AddDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	Deliveries(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error)
+}
@@ -9,10 +9,38 @@ import (
 
 type CalendarService interface {
 	CreateEvent(ctx context.Context, event models.Event) (string, error)
-	UpdateEvent(ctx context.Context, event models.Event) error
-	DeleteEvent(ctx context.Context, eventID string) error
+	UpdateEvent(ctx context.Context, event models.Event, scope models.EditScope) error
+	DeleteEvent(ctx context.Context, eventID string, scope models.EditScope) error
+	InviteAttendees(ctx context.Context, eventID string, userIDs []int64) error
+	RespondEvent(ctx context.Context, eventID string, userID int64, status models.AttendeeStatus) error
+	CheckConflicts(ctx context.Context, event models.Event) ([]string, error)
 
-	GetEventsForDay(ctx context.Context, userID int64, dateRange time.Time) ([]models.Event, error)
-	GetEventsForWeek(ctx context.Context, userID int64, dateRange time.Time) ([]models.Event, error)
-	GetEventsForMonth(ctx context.Context, userID int64, dateRange time.Time) ([]models.Event, error)
+	GetEvent(ctx context.Context, eventID string) (models.Event, error)
+	GetEventsForDay(ctx context.Context, userID int64, dateRange time.Time, calendarIDs ...string) ([]models.Event, error)
+	GetEventsForWeek(ctx context.Context, userID int64, dateRange time.Time, calendarIDs ...string) ([]models.Event, error)
+	GetEventsForMonth(ctx context.Context, userID int64, dateRange time.Time, calendarIDs ...string) ([]models.Event, error)
+	GetAllEvents(ctx context.Context, userID int64) ([]models.Event, error)
+	ListEvents(ctx context.Context, query models.EventsRange) (models.EventsPage, error)
+	SearchEvents(ctx context.Context, search models.EventsSearch) ([]models.Event, error)
+	FreeBusy(ctx context.Context, query models.FreeBusyQuery) (models.FreeBusy, error)
+	ImportEvents(ctx context.Context, userID int64, events []models.Event) ([]models.ImportResult, error)
+
+	GetUserSettings(ctx context.Context, userID int64) (models.UserSettings, error)
+	SaveUserSettings(ctx context.Context, settings models.UserSettings) error
+	Location(ctx context.Context, userID int64, tz string) (*time.Location, error)
+
+	GrantShare(ctx context.Context, share models.Share) error
+	ListShares(ctx context.Context, userID int64) ([]models.Share, error)
+	RevokeShare(ctx context.Context, ownerID, granteeID int64) (bool, error)
+
+	CreateCalendar(ctx context.Context, cal models.Calendar) (models.Calendar, error)
+	UpdateCalendar(ctx context.Context, cal models.Calendar) (bool, error)
+	DeleteCalendar(ctx context.Context, userID int64, calendarID string) (bool, error)
+	ListCalendars(ctx context.Context, userID int64) ([]models.Calendar, error)
+
+	CreateResource(ctx context.Context, res models.Resource) (models.Resource, error)
+	UpdateResource(ctx context.Context, res models.Resource) (bool, error)
+	DeleteResource(ctx context.Context, userID int64, resourceID string) (bool, error)
+	ListResources(ctx context.Context) ([]models.Resource, error)
+	ResourceBookings(ctx context.Context, resourceID string, from, to time.Time) ([]models.Booking, error)
 }
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// WebhookService - подписки пользователей на изменения событий и их доставка.
+// Методы с bool возвращают false, если подписки нет или она принадлежит другому пользователю.
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	ListWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID int64, webhookID string) (bool, error)
+	ListDeliveries(ctx context.Context, userID int64, webhookID string, limit int) ([]models.WebhookDelivery, bool, error)
+
+	Start(ctx context.Context) error
+}
@@ -8,6 +8,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
 	"github.com/sunr3d/simple-http-calendar/internal/httpx"
 )
 
@@ -31,11 +32,13 @@ func JSONValidator(log *zap.Logger) func(http.Handler) http.Handler {
 			switch r.Method {
 			case http.MethodPost, http.MethodPut, http.MethodPatch:
 				ct := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Type")))
-				if !httpx.IsJSON(ct) && !strings.HasPrefix(ct, "application/x-www-form-urlencoded") {
+				if !httpx.IsJSON(ct) &&
+					!strings.HasPrefix(ct, "application/x-www-form-urlencoded") &&
+					!httpx.IsICal(ct) {
 					if err := httpx.HTTPError(
 						w,
 						http.StatusUnsupportedMediaType,
-						"Ожидается Content-Type: application/json или application/x-www-form-urlencoded"); err != nil {
+						"Ожидается Content-Type: application/json, application/x-www-form-urlencoded или text/calendar"); err != nil {
 						log.Warn("JSONValidator: не удалось записать ошибку",
 							zap.Error(err),
 							zap.String("method", r.Method),
@@ -51,6 +54,35 @@ func JSONValidator(log *zap.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// Auth - аутентифицирует запрос и выполняет его от имени пользователя из токена (см. auth.UserFrom).
+// Запрос без учетных данных или с неверными отклоняется с 401. Если аутентификация не настроена,
+// запросы пропускаются как есть.
+func Auth(authn *auth.Authenticator, log *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !authn.Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := authn.Authenticate(r)
+			if err != nil {
+				log.Warn("запрос не аутентифицирован",
+					zap.Error(err),
+					zap.String("method", r.Method),
+					zap.String("url", r.URL.Path),
+				)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="calendar"`)
+				if err := httpx.HTTPError(w, http.StatusUnauthorized, "Требуется аутентификация"); err != nil {
+					log.Warn("auth: не удалось записать ошибку в ответ", zap.Error(err))
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), userID)))
+		})
+	}
+}
+
 func Recovery(log *zap.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,8 @@
+package netguard
+
+import "errors"
+
+var (
+	ErrForbidden  = errors.New("адрес во внутренней сети недоступен")
+	ErrUnresolved = errors.New("не удалось определить адрес хоста")
+)
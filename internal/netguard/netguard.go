@@ -0,0 +1,111 @@
+// Package netguard - защита исходящих запросов сервиса (вебхуки, напоминания) от SSRF:
+// запросы на адреса пользователей не должны попадать во внутреннюю сеть.
+package netguard
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// internal - диапазоны, не покрытые методами netip.Addr: «этот» хост и CGNAT провайдера.
+var internal = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Allowed - публичный ли адрес: не loopback, link-local, private, unspecified или multicast.
+func Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range internal {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Resolver - поиск адресов хоста (net.DefaultResolver).
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Guard - фильтр адресов исходящих запросов. Нулевое значение запрещает внутренние адреса
+// и ищет адреса хостов через net.DefaultResolver.
+type Guard struct {
+	// AllowPrivate - разрешить внутренние адреса (получатели в одной закрытой сети с сервисом).
+	AllowPrivate bool
+	// Resolver - поиск адресов хоста; nil - net.DefaultResolver.
+	Resolver Resolver
+}
+
+// CheckHost - проверяет при регистрации адреса, что все адреса хоста публичные.
+// Адрес хоста может смениться позже, поэтому соединения дополнительно проверяет Client.
+func (g *Guard) CheckHost(ctx context.Context, host string) error {
+	if g.AllowPrivate {
+		return nil
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbidden, host)
+	}
+
+	var resolver Resolver = net.DefaultResolver
+	if g.Resolver != nil {
+		resolver = g.Resolver
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: %s", ErrUnresolved, host)
+	}
+	for _, addr := range addrs {
+		if !Allowed(addr) {
+			return fmt.Errorf("%w: %s (%s)", ErrForbidden, host, addr)
+		}
+	}
+
+	return nil
+}
+
+// Client - HTTP клиент для запросов на адреса пользователей. Адрес проверяется при каждом
+// соединении (уже после поиска адреса в DNS, поэтому смена адреса хоста проверку не обходит),
+// прокси из окружения не используются, перенаправления не выполняются - ответ 3xx возвращается как есть.
+func (g *Guard) Client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: g.control}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// control - проверка адреса перед соединением (net.Dialer.Control).
+func (g *Guard) control(_, address string, _ syscall.RawConn) error {
+	if g.AllowPrivate {
+		return nil
+	}
+
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil || !Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbidden, address)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticResolver - DNS с фиксированными адресами хостов.
+type staticResolver map[string][]netip.Addr
+
+func (r staticResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	if addrs, ok := r[host]; ok {
+		return addrs, nil
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestAllowed(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":      true,
+		"2606:2800:220:1::1": true,
+		"127.0.0.1":          false,
+		"::1":                false,
+		"0.0.0.0":            false,
+		"::":                 false,
+		"10.1.2.3":           false,
+		"172.16.0.1":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"fe80::1":            false,
+		"fd00::1":            false,
+		"100.64.0.1":         false,
+		"224.0.0.1":          false,
+		"::ffff:127.0.0.1":   false,
+		"::ffff:8.8.8.8":     true,
+	} {
+		assert.Equal(t, want, Allowed(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	guard := &Guard{Resolver: staticResolver{
+		"hooks.example.com":  {netip.MustParseAddr("93.184.216.34")},
+		"mixed.example.com":  {netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.1")},
+		"intranet.corp.test": {netip.MustParseAddr("192.168.0.10")},
+	}}
+	ctx := context.Background()
+
+	require.NoError(t, guard.CheckHost(ctx, "hooks.example.com"))
+	for _, host := range []string{"mixed.example.com", "intranet.corp.test", "127.0.0.1", "169.254.169.254", "::1", "localhost", "api.localhost."} {
+		assert.ErrorIs(t, guard.CheckHost(ctx, host), ErrForbidden, host)
+	}
+	assert.ErrorIs(t, guard.CheckHost(ctx, "missing.example.com"), ErrUnresolved)
+
+	allow := &Guard{AllowPrivate: true}
+	assert.NoError(t, allow.CheckHost(ctx, "127.0.0.1"))
+}
+
+func TestClient(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer redirect.Close()
+
+	// Соединение с внутренним адресом запрещено, даже если адрес прошел проверку раньше.
+	_, err := (&Guard{}).Client(time.Second).Get(target.URL)
+	require.ErrorIs(t, err, ErrForbidden)
+
+	// Перенаправления не выполняются.
+	resp, err := (&Guard{AllowPrivate: true}).Client(time.Second).Get(redirect.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
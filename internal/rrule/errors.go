@@ -0,0 +1,15 @@
+package rrule
+
+import "errors"
+
+var (
+	ErrEmptyRule   = errors.New("правило повторения не может быть пустым")
+	ErrBadPart     = errors.New("некорректная часть правила повторения")
+	ErrBadFreq     = errors.New("некорректная частота повторения FREQ")
+	ErrBadInterval = errors.New("некорректный интервал повторения INTERVAL")
+	ErrBadByDay    = errors.New("некорректное значение BYDAY")
+	ErrBadMonthDay = errors.New("некорректное значение BYMONTHDAY")
+	ErrBadCount    = errors.New("некорректное значение COUNT")
+	ErrBadUntil    = errors.New("некорректное значение UNTIL")
+	ErrCountUntil  = errors.New("COUNT и UNTIL не могут использоваться вместе")
+)
@@ -0,0 +1,546 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency - частота повторения (FREQ).
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// untilLayout - формат UTC даты-времени из RFC 5545.
+const untilLayout = "20060102T150405Z"
+
+// maxPeriods - защита от бесконечного перебора для правил, которые не дают вхождений.
+const maxPeriods = 100000
+
+// WeekdayNum - элемент BYDAY: день недели с необязательным порядковым номером (1MO, -1FR).
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// Rule - подмножество RRULE из RFC 5545: FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+var weekdayNames = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+// Parse - разбирает строку RRULE (с префиксом "RRULE:" или без него).
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "RRULE:"), "rrule:")
+	if s == "" {
+		return nil, ErrEmptyRule
+	}
+
+	r := &Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrBadPart, part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch f := Frequency(strings.ToUpper(val)); f {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = f
+			default:
+				return nil, fmt.Errorf("%w: %q", ErrBadFreq, val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: %q", ErrBadInterval, val)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				wd, err := parseWeekdayNum(item)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(item)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("%w: %q", ErrBadMonthDay, item)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: %q", ErrBadCount, val)
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = &until
+		case "WKST":
+			// Неделя всегда начинается с понедельника.
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrBadPart, part)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, ErrBadFreq
+	}
+	if r.Count > 0 && r.Until != nil {
+		return nil, ErrCountUntil
+	}
+	// Порядковый номер больше 5 бывает только у недель года.
+	for _, wd := range r.ByDay {
+		if r.Freq != Yearly && (wd.N < -5 || wd.N > 5) {
+			return nil, fmt.Errorf("%w: %q", ErrBadByDay, strconv.Itoa(wd.N)+weekdayNames[wd.Weekday])
+		}
+	}
+
+	return r, nil
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("%w: %q", ErrBadByDay, s)
+	}
+
+	wd, ok := weekdayCodes[s[len(s)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("%w: %q", ErrBadByDay, s)
+	}
+
+	res := WeekdayNum{Weekday: wd}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("%w: %q", ErrBadByDay, s)
+		}
+		res.N = n
+	}
+
+	return res, nil
+}
+
+func parseUntil(s string) (time.Time, error) {
+	for _, layout := range []string{untilLayout, "20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			if layout == "20060102" {
+				// Дата без времени включает весь день.
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q", ErrBadUntil, s)
+}
+
+// String - сериализует правило обратно в формат RRULE (без префикса).
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			d := weekdayNames[wd.Weekday]
+			if wd.N != 0 {
+				d = strconv.Itoa(wd.N) + d
+			}
+			days = append(days, d)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Bounded - конечно ли правило (задан COUNT или UNTIL).
+func (r *Rule) Bounded() bool {
+	return r.Count > 0 || r.Until != nil
+}
+
+// Between - возвращает начала вхождений серии с началом dtstart, попадающие в [from; to).
+// Даты из exdates исключаются из результата, но учитываются в COUNT (как в RFC 5545).
+func (r *Rule) Between(dtstart, from, to time.Time, exdates []time.Time) []time.Time {
+	var res []time.Time
+	r.iterate(dtstart, from, func(t time.Time) bool {
+		if !t.Before(to) {
+			return false
+		}
+		if !t.Before(from) && !isExcluded(t, exdates) {
+			res = append(res, t)
+		}
+		return true
+	})
+
+	return res
+}
+
+// Last - возвращает начало последнего вхождения конечной серии.
+// Для бесконечной серии или серии без вхождений ok == false.
+func (r *Rule) Last(dtstart time.Time, exdates []time.Time) (last time.Time, ok bool) {
+	if !r.Bounded() {
+		return time.Time{}, false
+	}
+
+	r.iterate(dtstart, time.Time{}, func(t time.Time) bool {
+		if !isExcluded(t, exdates) {
+			last, ok = t, true
+		}
+		return true
+	})
+
+	return last, ok
+}
+
+// Next - начало первого вхождения серии строго после after.
+// Для завершившейся серии ok == false.
+func (r *Rule) Next(dtstart, after time.Time, exdates []time.Time) (next time.Time, ok bool) {
+	r.iterate(dtstart, after, func(t time.Time) bool {
+		if t.After(after) && !isExcluded(t, exdates) {
+			next, ok = t, true
+			return false
+		}
+		return true
+	})
+
+	return next, ok
+}
+
+// CountBefore - количество вхождений (включая исключенные EXDATE) с началом строго до t.
+func (r *Rule) CountBefore(dtstart, t time.Time) int {
+	n := 0
+	r.iterate(dtstart, time.Time{}, func(occ time.Time) bool {
+		if !occ.Before(t) {
+			return false
+		}
+		n++
+		return true
+	})
+
+	return n
+}
+
+// Occurs - является ли t началом одного из вхождений серии.
+func (r *Rule) Occurs(dtstart, t time.Time, exdates []time.Time) bool {
+	found := false
+	r.iterate(dtstart, t, func(occ time.Time) bool {
+		if occ.After(t) {
+			return false
+		}
+		if occ.Equal(t) {
+			found = true
+			return false
+		}
+		return true
+	})
+
+	return found && !isExcluded(t, exdates)
+}
+
+// iterate - перебирает вхождения по возрастанию, пока yield возвращает true.
+// hint позволяет пропустить периоды до окна, если правило не ограничено COUNT.
+func (r *Rule) iterate(dtstart, hint time.Time, yield func(time.Time) bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	emitted := 0
+	emit := func(t time.Time) bool {
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		return yield(t)
+	}
+
+	first := 0
+	if r.Count == 0 && !hint.IsZero() && hint.After(dtstart) {
+		first = r.skipPeriods(dtstart, hint, interval)
+	}
+
+	if first == 0 {
+		// DTSTART всегда является первым вхождением серии.
+		if !emit(dtstart) {
+			return
+		}
+	}
+
+	for k := first; k < first+maxPeriods; k++ {
+		candidates := r.period(dtstart, k*interval)
+		for _, c := range candidates {
+			if !c.After(dtstart) {
+				continue
+			}
+			if !emit(c) {
+				return
+			}
+		}
+	}
+}
+
+// skipPeriods - номер первого периода, который может содержать вхождения не раньше hint.
+func (r *Rule) skipPeriods(dtstart, hint time.Time, interval int) int {
+	var periods int
+	switch r.Freq {
+	case Daily:
+		periods = int(hint.Sub(dtstart).Hours()/24) - 1
+	case Weekly:
+		periods = int(hint.Sub(dtstart).Hours()/(24*7)) - 1
+	case Monthly:
+		periods = (hint.Year()-dtstart.Year())*12 + int(hint.Month()-dtstart.Month()) - 1
+	case Yearly:
+		periods = hint.Year() - dtstart.Year() - 1
+	}
+	if periods <= 0 {
+		return 0
+	}
+
+	return periods / interval
+}
+
+// period - кандидаты во вхождения в периоде с номером offset (в единицах FREQ) от dtstart.
+func (r *Rule) period(dtstart time.Time, offset int) []time.Time {
+	loc := dtstart.Location()
+	h, mi, sec := dtstart.Clock()
+	ns := dtstart.Nanosecond()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, h, mi, sec, ns, loc)
+	}
+
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		d := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+offset)
+		if r.matchesDay(d) {
+			days = append(days, d)
+		}
+	case Weekly:
+		weekday := (int(dtstart.Weekday()) + 6) % 7
+		monday := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-weekday+7*offset)
+		for i := 0; i < 7; i++ {
+			d := at(monday.Year(), monday.Month(), monday.Day()+i)
+			if len(r.ByDay) == 0 {
+				if d.Weekday() != dtstart.Weekday() {
+					continue
+				}
+			} else if !r.hasWeekday(d.Weekday()) {
+				continue
+			}
+			if len(r.ByMonthDay) > 0 && !r.hasMonthDay(d) {
+				continue
+			}
+			days = append(days, d)
+		}
+	case Monthly:
+		first := at(dtstart.Year(), dtstart.Month()+time.Month(offset), 1)
+		days = r.monthDays(first, dtstart.Day())
+	case Yearly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			days = r.monthDays(at(dtstart.Year()+offset, dtstart.Month(), 1), dtstart.Day())
+			break
+		}
+		// BYMONTH не поддерживается, поэтому BYDAY и BYMONTHDAY действуют на весь год (RFC 5545, 3.3.10):
+		// BYMONTHDAY=15 - 15-е число каждого месяца, 20MO - 20-й понедельник года, -1FR - последняя пятница года.
+		year := dtstart.Year() + offset
+		for d := at(year, time.January, 1); d.Year() == year; d = at(year, time.January, d.YearDay()+1) {
+			if len(r.ByMonthDay) > 0 && !r.hasMonthDay(d) {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchesYearWeekday(d) {
+				continue
+			}
+			days = append(days, d)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	return days
+}
+
+// monthDays - вхождения внутри месяца, который начинается с first.
+func (r *Rule) monthDays(first time.Time, defaultDay int) []time.Time {
+	n := daysIn(first)
+	at := func(d int) time.Time {
+		return time.Date(first.Year(), first.Month(), d, first.Hour(), first.Minute(), first.Second(), first.Nanosecond(), first.Location())
+	}
+
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for d := 1; d <= n; d++ {
+			t := at(d)
+			if r.hasMonthDay(t) && (len(r.ByDay) == 0 || r.matchesMonthWeekday(t)) {
+				days = append(days, t)
+			}
+		}
+	case len(r.ByDay) > 0:
+		for d := 1; d <= n; d++ {
+			t := at(d)
+			if r.matchesMonthWeekday(t) {
+				days = append(days, t)
+			}
+		}
+	default:
+		if defaultDay <= n {
+			days = append(days, at(defaultDay))
+		}
+	}
+
+	return days
+}
+
+// matchesDay - фильтр BYDAY/BYMONTHDAY для ежедневных правил.
+func (r *Rule) matchesDay(t time.Time) bool {
+	if len(r.ByDay) > 0 && !r.hasWeekday(t.Weekday()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !r.hasMonthDay(t) {
+		return false
+	}
+
+	return true
+}
+
+func (r *Rule) hasWeekday(wd time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d.Weekday == wd {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) hasMonthDay(t time.Time) bool {
+	n := daysIn(t)
+	for _, d := range r.ByMonthDay {
+		if d == t.Day() || (d < 0 && n+d+1 == t.Day()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesMonthWeekday - BYDAY внутри месяца с учетом порядкового номера (2TU, -1FR).
+func (r *Rule) matchesMonthWeekday(t time.Time) bool {
+	n := daysIn(t)
+	for _, d := range r.ByDay {
+		if d.Weekday != t.Weekday() {
+			continue
+		}
+		if d.N == 0 {
+			return true
+		}
+		if d.N > 0 && (t.Day()-1)/7+1 == d.N {
+			return true
+		}
+		if d.N < 0 && (n-t.Day())/7+1 == -d.N {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesYearWeekday - BYDAY внутри года с учетом порядкового номера (20MO, -1FR).
+func (r *Rule) matchesYearWeekday(t time.Time) bool {
+	n := time.Date(t.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	for _, d := range r.ByDay {
+		if d.Weekday != t.Weekday() {
+			continue
+		}
+		if d.N == 0 {
+			return true
+		}
+		if d.N > 0 && (t.YearDay()-1)/7+1 == d.N {
+			return true
+		}
+		if d.N < 0 && (n-t.YearDay())/7+1 == -d.N {
+			return true
+		}
+	}
+
+	return false
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func isExcluded(t time.Time, exdates []time.Time) bool {
+	for _, ex := range exdates {
+		if ex.Equal(t) {
+			return true
+		}
+	}
+
+	return false
+}
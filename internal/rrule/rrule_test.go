@@ -0,0 +1,161 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	r, err := Parse("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,-1FR;COUNT=10")
+	require.NoError(t, err)
+	assert.Equal(t, Weekly, r.Freq)
+	assert.Equal(t, 2, r.Interval)
+	assert.Equal(t, []WeekdayNum{{Weekday: time.Monday}, {Weekday: time.Friday, N: -1}}, r.ByDay)
+	assert.Equal(t, 10, r.Count)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,-1FR;COUNT=10", r.String())
+
+	r, err = Parse("FREQ=MONTHLY;BYMONTHDAY=1,-1;UNTIL=20250301T000000Z")
+	require.NoError(t, err)
+	require.NotNil(t, r.Until)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), *r.Until)
+
+	for _, bad := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYDAY=XX",
+		"FREQ=DAILY;BYMONTHDAY=32",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101T000000Z",
+		"FREQ=DAILY;FOO=BAR",
+	} {
+		_, err := Parse(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestBetweenWeekly(t *testing.T) {
+	r, err := Parse("FREQ=WEEKLY;BYDAY=MO,WE")
+	require.NoError(t, err)
+
+	start := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC) // понедельник
+	got := r.Between(start, start, start.AddDate(0, 0, 14), nil)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 8, 10, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 13, 10, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestBetweenFarWindow(t *testing.T) {
+	r, err := Parse("FREQ=DAILY;INTERVAL=3")
+	require.NoError(t, err)
+
+	start := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	got := r.Between(start, from, from.AddDate(0, 0, 7), nil)
+
+	require.NotEmpty(t, got)
+	for _, occ := range got {
+		days := int(occ.Sub(start).Hours() / 24)
+		assert.Zero(t, days%3)
+	}
+	assert.Len(t, got, 2)
+}
+
+func TestBetweenCountAndExdate(t *testing.T) {
+	r, err := Parse("FREQ=DAILY;COUNT=3")
+	require.NoError(t, err)
+
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	exdate := start.AddDate(0, 0, 1)
+	got := r.Between(start, start, start.AddDate(0, 1, 0), []time.Time{exdate})
+
+	assert.Equal(t, []time.Time{start, start.AddDate(0, 0, 2)}, got)
+
+	last, ok := r.Last(start, nil)
+	require.True(t, ok)
+	assert.Equal(t, start.AddDate(0, 0, 2), last)
+	assert.Equal(t, 2, r.CountBefore(start, start.AddDate(0, 0, 2)))
+
+	next, ok := r.Next(start, start, []time.Time{exdate})
+	require.True(t, ok)
+	assert.Equal(t, start.AddDate(0, 0, 2), next)
+
+	_, ok = r.Next(start, start.AddDate(0, 0, 2), nil)
+	assert.False(t, ok)
+}
+
+func TestMonthly(t *testing.T) {
+	lastFriday, err := Parse("FREQ=MONTHLY;BYDAY=-1FR;COUNT=3")
+	require.NoError(t, err)
+
+	start := time.Date(2025, 1, 31, 18, 0, 0, 0, time.UTC)
+	got := lastFriday.Between(start, start, start.AddDate(1, 0, 0), nil)
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 1, 31, 18, 0, 0, 0, time.UTC),
+		time.Date(2025, 2, 28, 18, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 28, 18, 0, 0, 0, time.UTC),
+	}, got)
+
+	dayOfMonth, err := Parse("FREQ=MONTHLY;UNTIL=20250501T000000Z")
+	require.NoError(t, err)
+	got = dayOfMonth.Between(start, start, start.AddDate(1, 0, 0), nil)
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 1, 31, 18, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC),
+	}, got)
+
+	lastDay, err := Parse("FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=2")
+	require.NoError(t, err)
+	febStart := time.Date(2025, 2, 28, 8, 0, 0, 0, time.UTC)
+	got = lastDay.Between(febStart, febStart, febStart.AddDate(1, 0, 0), nil)
+	assert.Equal(t, []time.Time{febStart, time.Date(2025, 3, 31, 8, 0, 0, 0, time.UTC)}, got)
+}
+
+func TestYearly(t *testing.T) {
+	at := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, time.UTC) }
+
+	// Без BYDAY и BYMONTHDAY - день DTSTART; 29 февраля - только в високосные годы.
+	leap, err := Parse("FREQ=YEARLY;COUNT=2")
+	require.NoError(t, err)
+	got := leap.Between(at(2024, 2, 29), at(2024, 1, 1), at(2030, 1, 1), nil)
+	assert.Equal(t, []time.Time{at(2024, 2, 29), at(2028, 2, 29)}, got)
+
+	// BYMONTHDAY без BYMONTH - число каждого месяца года.
+	monthDay, err := Parse("FREQ=YEARLY;BYMONTHDAY=15;COUNT=3")
+	require.NoError(t, err)
+	got = monthDay.Between(at(2025, 1, 15), at(2025, 1, 1), at(2026, 1, 1), nil)
+	assert.Equal(t, []time.Time{at(2025, 1, 15), at(2025, 2, 15), at(2025, 3, 15)}, got)
+
+	// Порядковый BYDAY считается внутри года.
+	lastFriday, err := Parse("FREQ=YEARLY;BYDAY=-1FR;COUNT=3")
+	require.NoError(t, err)
+	got = lastFriday.Between(at(2025, 12, 26), at(2025, 1, 1), at(2030, 1, 1), nil)
+	assert.Equal(t, []time.Time{at(2025, 12, 26), at(2026, 12, 25), at(2027, 12, 31)}, got)
+
+	twentiethMonday, err := Parse("FREQ=YEARLY;BYDAY=20MO")
+	require.NoError(t, err)
+	got = twentiethMonday.Between(at(2025, 5, 19), at(2025, 1, 1), at(2027, 1, 1), nil)
+	assert.Equal(t, []time.Time{at(2025, 5, 19), at(2026, 5, 18)}, got)
+	_, err = Parse("FREQ=MONTHLY;BYDAY=20MO")
+	require.ErrorIs(t, err, ErrBadByDay)
+}
+
+func TestOccurs(t *testing.T) {
+	r, err := Parse("FREQ=WEEKLY")
+	require.NoError(t, err)
+
+	start := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	assert.True(t, r.Occurs(start, start.AddDate(0, 0, 7), nil))
+	assert.False(t, r.Occurs(start, start.AddDate(0, 0, 8), nil))
+	assert.False(t, r.Occurs(start, start.AddDate(0, 0, 7), []time.Time{start.AddDate(0, 0, 7)}))
+
+	_, ok := r.Last(start, nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,172 @@
+// Package scheduler - планировщик отложенных задач на min-куче с одним таймером.
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// FireFunc - обработчик наступившей задачи. Вызывается из цикла Run и не должен блокироваться надолго.
+type FireFunc[T any] func(ctx context.Context, key string, value T)
+
+// Scheduler - планировщик задач по времени срабатывания.
+// Задачи адресуются ключом: повторное планирование с тем же ключом переносит задачу.
+// Задачи на одно и то же время срабатывают в порядке планирования.
+// Schedule, Cancel и срабатывание - O(log n).
+type Scheduler[T any] struct {
+	fire FireFunc[T]
+
+	mu    sync.Mutex
+	queue queue[T]
+	index map[string]*item[T]
+	order uint64
+	wake  chan struct{}
+}
+
+type item[T any] struct {
+	key   string
+	at    time.Time
+	order uint64 // номер планирования: порядок задач с одинаковым at
+	value T
+	pos   int
+}
+
+// New - конструктор планировщика.
+func New[T any](fire FireFunc[T]) *Scheduler[T] {
+	return &Scheduler[T]{
+		fire:  fire,
+		index: make(map[string]*item[T]),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Schedule - планирует задачу на время at или переносит уже запланированную с тем же ключом.
+func (s *Scheduler[T]) Schedule(key string, at time.Time, value T) {
+	s.mu.Lock()
+	s.order++
+	if it, ok := s.index[key]; ok {
+		it.at = at
+		it.order = s.order
+		it.value = value
+		heap.Fix(&s.queue, it.pos)
+	} else {
+		it := &item[T]{key: key, at: at, order: s.order, value: value}
+		heap.Push(&s.queue, it)
+		s.index[key] = it
+	}
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+// Cancel - отменяет задачу. false, если задачи с таким ключом нет.
+func (s *Scheduler[T]) Cancel(key string) bool {
+	s.mu.Lock()
+	it, ok := s.index[key]
+	if ok {
+		heap.Remove(&s.queue, it.pos)
+		delete(s.index, key)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.notify()
+	}
+
+	return ok
+}
+
+// Len - число запланированных задач.
+func (s *Scheduler[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.queue)
+}
+
+// Run - цикл планировщика: ждет ближайшую задачу и вызывает для нее обработчик.
+// Завершается с ctx.Err() при отмене контекста.
+func (s *Scheduler[T]) Run(ctx context.Context) error {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		due, wait := s.popDue(time.Now())
+		for _, it := range due {
+			s.fire(ctx, it.key, it.value)
+		}
+		if len(due) > 0 {
+			continue
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+			timer.Stop()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// popDue - извлекает наступившие задачи; если их нет, возвращает время до ближайшей.
+func (s *Scheduler[T]) popDue(now time.Time) ([]*item[T], time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*item[T]
+	for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+		it := heap.Pop(&s.queue).(*item[T])
+		delete(s.index, it.key)
+		due = append(due, it)
+	}
+	if len(due) > 0 || len(s.queue) == 0 {
+		return due, time.Hour
+	}
+
+	return nil, s.queue[0].at.Sub(now)
+}
+
+// notify - будит цикл Run, чтобы он пересчитал время ожидания.
+func (s *Scheduler[T]) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// queue - min-куча задач по времени срабатывания и порядку планирования (container/heap).
+type queue[T any] []*item[T]
+
+func (q queue[T]) Len() int { return len(q) }
+
+func (q queue[T]) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	return q[i].order < q[j].order
+}
+
+func (q queue[T]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].pos = i
+	q[j].pos = j
+}
+
+func (q *queue[T]) Push(x any) {
+	it := x.(*item[T])
+	it.pos = len(*q)
+	*q = append(*q, it)
+}
+
+func (q *queue[T]) Pop() any {
+	old := *q
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return it
+}
@@ -0,0 +1,153 @@
+package scheduler
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder - запоминает ключи сработавших задач в порядке срабатывания.
+type recorder struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (r *recorder) fire(_ context.Context, key string, _ int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys = append(r.keys, key)
+}
+
+func (r *recorder) fired() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.keys...)
+}
+
+func run(t *testing.T, s *Scheduler[int]) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestFiresInTimeOrder(t *testing.T) {
+	rec := &recorder{}
+	s := New(rec.fire)
+	run(t, s)
+
+	now := time.Now()
+	// Задача на неделю вперед не задерживает остальные.
+	s.Schedule("week", now.Add(7*24*time.Hour), 0)
+	s.Schedule("c", now.Add(60*time.Millisecond), 0)
+	s.Schedule("a", now.Add(20*time.Millisecond), 0)
+	s.Schedule("past", now.Add(-time.Hour), 0)
+	s.Schedule("b", now.Add(40*time.Millisecond), 0)
+
+	require.Eventually(t, func() bool { return len(rec.fired()) == 4 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"past", "a", "b", "c"}, rec.fired())
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestSameTimeInScheduleOrder(t *testing.T) {
+	rec := &recorder{}
+	s := New(rec.fire)
+
+	at := time.Now()
+	keys := make([]string, 50)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		s.Schedule(keys[i], at, i)
+	}
+
+	run(t, s)
+
+	require.Eventually(t, func() bool { return len(rec.fired()) == len(keys) }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, keys, rec.fired())
+}
+
+func TestRescheduleAndCancel(t *testing.T) {
+	rec := &recorder{}
+	s := New(rec.fire)
+
+	now := time.Now()
+	s.Schedule("a", now.Add(time.Hour), 0)
+	s.Schedule("b", now.Add(time.Hour), 0)
+	s.Schedule("c", now.Add(time.Hour), 0)
+	require.Equal(t, 3, s.Len())
+
+	s.Schedule("b", now.Add(10*time.Millisecond), 0)
+	assert.True(t, s.Cancel("c"))
+	assert.False(t, s.Cancel("c"))
+	assert.Equal(t, 2, s.Len())
+
+	run(t, s)
+
+	require.Eventually(t, func() bool { return len(rec.fired()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"b"}, rec.fired())
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	s := New(func(context.Context, string, int) {})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, s.Run(ctx), context.DeadlineExceeded)
+}
+
+// BenchmarkSchedule - планирование, перенос и отмена при 100k ожидающих задач.
+func BenchmarkSchedule(b *testing.B) {
+	const pending = 100_000
+
+	s := New(func(context.Context, string, int) {})
+	now := time.Now()
+	keys := make([]string, pending)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		s.Schedule(keys[i], now.Add(time.Duration(i)*time.Second), i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i%pending]
+		s.Schedule(key, now.Add(time.Duration(pending-i%pending)*time.Second), i)
+		s.Cancel(key)
+		s.Schedule(key, now.Add(time.Duration(i%pending)*time.Second), i)
+	}
+}
+
+// BenchmarkFire - извлечение наступивших задач из очереди в 100k.
+func BenchmarkFire(b *testing.B) {
+	const pending = 100_000
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		s := New(func(context.Context, string, int) {})
+		now := time.Now()
+		for j := 0; j < pending; j++ {
+			s.Schedule(strconv.Itoa(j), now.Add(-time.Duration(j)*time.Millisecond), j)
+		}
+		b.StartTimer()
+
+		due, _ := s.popDue(now)
+		if len(due) != pending {
+			b.Fatalf("сработало %d из %d", len(due), pending)
+		}
+	}
+}
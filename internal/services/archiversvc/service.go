@@ -10,20 +10,25 @@ import (
 	"github.com/sunr3d/simple-http-calendar/internal/config"
 	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
 	"github.com/sunr3d/simple-http-calendar/internal/interfaces/services"
+	"github.com/sunr3d/simple-http-calendar/internal/rrule"
+	"github.com/sunr3d/simple-http-calendar/models"
 )
 
 var _ services.ArchiveService = (*archiveSvc)(nil)
 
 type archiveSvc struct {
 	repo     infra.Database
+	broker   infra.Broker
 	logger   *zap.Logger
 	interval time.Duration
 }
 
 // New - конструктор сервиса архивации.
-func New(repo infra.Database, logger *zap.Logger, cfg config.ArchiverConfig) services.ArchiveService {
+// Об архивированных событиях сервис публикует event.archived в broker.
+func New(repo infra.Database, broker infra.Broker, logger *zap.Logger, cfg config.ArchiverConfig) services.ArchiveService {
 	return &archiveSvc{
 		repo:     repo,
+		broker:   broker,
 		logger:   logger,
 		interval: cfg.Interval,
 	}
@@ -58,34 +63,81 @@ func (s *archiveSvc) Start(ctx context.Context) error {
 	}
 }
 
-// archiveOldEvents - архивирует события, которые уже прошли.
+// archiveOldEvents - архивирует события, которые уже закончились.
 func (s *archiveSvc) archiveOldEvents(ctx context.Context) error {
 	logger := s.logger.With(
 		zap.String("service", "archiver"),
 		zap.String("op", "archiveOldEvents"),
 	)
 
+	// Закончиться могли только уже начавшиеся события.
 	archived := false
+	now := time.Now()
 	events, err := s.repo.List(ctx, &infra.ListOptions{
 		Archived: &archived,
+		To:       &now,
 	})
 	if err != nil {
 		return fmt.Errorf("repo.List: %w", err)
 	}
 
-	now := time.Now()
 	for _, event := range events {
-		if event.Date.Before(now) {
-			event.Archived = true
-			if err := s.repo.Update(ctx, &event); err != nil {
+		if s.finished(event, now) {
+			// Архивация атомарна в хранилище: событие, которое уже архивировала другая реплика, пропускается.
+			archived, err := s.repo.Archive(ctx, event.ID)
+			if err != nil {
 				logger.Warn("ошибка при архивации события",
 					zap.String("event_id", event.ID),
 					zap.Error(err))
 				continue
 			}
+			if !archived {
+				continue
+			}
+			event.Archived = true
 			logger.Info("событие архивировано", zap.String("event_id", event.ID))
+
+			if err := s.publishArchived(ctx, &event); err != nil {
+				logger.Warn("ошибка при публикации event.archived",
+					zap.String("event_id", event.ID),
+					zap.Error(err))
+			}
 		}
 	}
 
 	return nil
 }
+
+// publishArchived - публикует event.archived в топик calendar.events.
+func (s *archiveSvc) publishArchived(ctx context.Context, event *models.Event) error {
+	env, err := models.NewEnvelope(models.EventArchived, event)
+	if err != nil {
+		return err
+	}
+
+	if err := s.broker.Publish(ctx, models.TopicEvents, env); err != nil {
+		return fmt.Errorf("broker.Publish: %w", err)
+	}
+
+	return nil
+}
+
+// finished - прошло ли событие. Повторяющаяся серия считается прошедшей,
+// только если она конечна и ее последнее вхождение уже наступило.
+func (s *archiveSvc) finished(event models.Event, now time.Time) bool {
+	if event.RRule == "" {
+		return event.EndTime().Before(now)
+	}
+
+	rule, err := rrule.Parse(event.RRule)
+	if err != nil {
+		return false
+	}
+
+	last, ok := rule.Last(event.Date, event.ExDates)
+	if !ok {
+		return rule.Bounded()
+	}
+
+	return last.Add(event.Duration()).Before(now)
+}
@@ -2,6 +2,8 @@ package archiversvc
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/internal/infra/inmembroker"
 	"github.com/sunr3d/simple-http-calendar/internal/infra/inmemdb"
 	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
 	"github.com/sunr3d/simple-http-calendar/models"
@@ -19,18 +22,42 @@ func newArchiveSvc(t *testing.T) *archiveSvc {
 	t.Helper()
 
 	logger := zap.NewNop()
-	repo := inmemdb.New(logger)
+	repo := inmemdb.New(logger, nil)
 	cfg := config.ArchiverConfig{
 		Interval: 1 * time.Minute,
 	}
 
-	s := New(repo, logger, cfg)
+	s := New(repo, inmembroker.New(100, logger), logger, cfg)
 	as, ok := s.(*archiveSvc)
 
 	require.True(t, ok)
 	return as
 }
 
+// barrierRepo - БД, задерживающая первые n выборок, пока их не начнут все n реплик.
+type barrierRepo struct {
+	infra.Database
+
+	lists   atomic.Int32
+	n       int32
+	arrived sync.WaitGroup
+}
+
+func newBarrierRepo(repo infra.Database, n int) *barrierRepo {
+	r := &barrierRepo{Database: repo, n: int32(n)}
+	r.arrived.Add(n)
+	return r
+}
+
+func (r *barrierRepo) List(ctx context.Context, opts *infra.ListOptions) ([]models.Event, error) {
+	events, err := r.Database.List(ctx, opts)
+	if r.lists.Add(1) <= r.n {
+		r.arrived.Done()
+		r.arrived.Wait()
+	}
+	return events, err
+}
+
 func TestArchiveOldEvents(t *testing.T) {
 	svc := newArchiveSvc(t)
 	ctx := context.Background()
@@ -94,3 +121,132 @@ func TestStart(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, events, 0)
 }
+
+func TestArchiveRecurringSeries(t *testing.T) {
+	svc := newArchiveSvc(t)
+	ctx := context.Background()
+
+	infinite := models.Event{
+		ID:     "infinite",
+		UserID: 1,
+		Date:   time.Now().AddDate(0, 0, -30),
+		Text:   "weekly",
+		RRule:  "FREQ=WEEKLY",
+	}
+	finished := models.Event{
+		ID:     "finished",
+		UserID: 1,
+		Date:   time.Now().AddDate(0, 0, -30),
+		Text:   "three days",
+		RRule:  "FREQ=DAILY;COUNT=3",
+	}
+	ongoing := models.Event{
+		ID:     "ongoing",
+		UserID: 1,
+		Date:   time.Now().AddDate(0, 0, -2),
+		Text:   "ten days",
+		RRule:  "FREQ=DAILY;COUNT=10",
+	}
+
+	for _, e := range []*models.Event{&infinite, &finished, &ongoing} {
+		require.NoError(t, svc.repo.Create(ctx, e))
+	}
+
+	require.NoError(t, svc.archiveOldEvents(ctx))
+
+	archived := false
+	events, err := svc.repo.List(ctx, &infra.ListOptions{Archived: &archived})
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	assert.ElementsMatch(t, []string{"infinite", "ongoing"}, ids)
+}
+
+func TestArchiveWaitsForEnd(t *testing.T) {
+	svc := newArchiveSvc(t)
+	ctx := context.Background()
+
+	running := models.Event{
+		ID:     "running",
+		UserID: 1,
+		Date:   time.Now().Add(-1 * time.Hour),
+		End:    time.Now().Add(1 * time.Hour),
+		Text:   "long meeting",
+	}
+	require.NoError(t, svc.repo.Create(ctx, &running))
+
+	require.NoError(t, svc.archiveOldEvents(ctx))
+
+	event, err := svc.repo.Read(ctx, "running")
+	require.NoError(t, err)
+	assert.False(t, event.Archived)
+}
+
+func TestArchivePublishes(t *testing.T) {
+	svc := newArchiveSvc(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan models.Envelope, 1)
+	require.NoError(t, svc.broker.Subscribe(ctx, models.TopicEvents, "test",
+		func(_ context.Context, env models.Envelope) error {
+			received <- env
+			return nil
+		}))
+
+	past := models.Event{ID: "past-1", UserID: 1, Date: time.Now().Add(-time.Hour), Text: "old event"}
+	require.NoError(t, svc.repo.Create(ctx, &past))
+	require.NoError(t, svc.archiveOldEvents(ctx))
+
+	select {
+	case env := <-received:
+		assert.Equal(t, models.EventArchived, env.Type)
+		var event models.Event
+		require.NoError(t, env.Decode(&event))
+		assert.Equal(t, "past-1", event.ID)
+		assert.True(t, event.Archived)
+	case <-time.After(time.Second):
+		t.Fatal("event.archived не опубликован")
+	}
+}
+
+func TestReplicasArchiveOnce(t *testing.T) {
+	const n = 4
+	logger := zap.NewNop()
+	repo := newBarrierRepo(inmemdb.New(logger, nil), n)
+	broker := inmembroker.New(100, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var archived atomic.Int32
+	require.NoError(t, broker.Subscribe(ctx, models.TopicEvents, "test",
+		func(_ context.Context, env models.Envelope) error {
+			if env.Type == models.EventArchived {
+				archived.Add(1)
+			}
+			return nil
+		}))
+
+	past := models.Event{ID: "past-1", UserID: 1, Date: time.Now().Add(-time.Hour), Text: "old event"}
+	require.NoError(t, repo.Create(ctx, &past))
+
+	// Реплики с общими БД и брокером архивируют одновременно.
+	var wg sync.WaitGroup
+	for range n {
+		svc, ok := New(repo, broker, logger, config.ArchiverConfig{Interval: time.Minute}).(*archiveSvc)
+		require.True(t, ok)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, svc.archiveOldEvents(ctx))
+		}()
+	}
+	wg.Wait()
+
+	require.Eventually(t, func() bool { return archived.Load() > 0 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualValues(t, 1, archived.Load())
+}
@@ -0,0 +1,142 @@
+package calendarsvc
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// maxAttendees - максимальное число участников одного события.
+const maxAttendees = 100
+
+// mergeAttendees - список участников события организатора organizerID: участники из prev
+// сохраняют свой ответ, новые получают needs-action. Повторы отбрасываются.
+func mergeAttendees(organizerID int64, attendees, prev []models.Attendee) ([]models.Attendee, error) {
+	if len(attendees) == 0 {
+		return nil, nil
+	}
+
+	statuses := make(map[int64]models.AttendeeStatus, len(prev))
+	for _, a := range prev {
+		statuses[a.UserID] = a.Status
+	}
+
+	res := make([]models.Attendee, 0, len(attendees))
+	seen := make(map[int64]bool, len(attendees))
+	for _, a := range attendees {
+		if a.UserID <= 0 || a.UserID == organizerID {
+			return nil, errAttendee
+		}
+		if seen[a.UserID] {
+			continue
+		}
+		seen[a.UserID] = true
+
+		status, ok := statuses[a.UserID]
+		if !ok {
+			status = models.AttendeeNeedsAction
+		}
+		res = append(res, models.Attendee{UserID: a.UserID, Status: status})
+	}
+	if len(res) > maxAttendees {
+		return nil, fmt.Errorf("%w: не больше %d", errAttendees, maxAttendees)
+	}
+
+	return res, nil
+}
+
+// InviteAttendees - добавляет участников к событию; уже приглашенные сохраняют свой ответ.
+// Приглашать может тот, кто может изменять событие. Для вхождения серии приглашение
+// относится ко всей серии. Участникам событие видно в их календаре по умолчанию.
+func (s *calendarService) InviteAttendees(ctx context.Context, eventID string, userIDs []int64) error {
+	if eventID == "" {
+		return errEventID
+	}
+	if len(userIDs) == 0 {
+		return errAttendee
+	}
+
+	target, _, err := s.resolveTarget(ctx, eventID, nil)
+	if err != nil {
+		return err
+	}
+
+	// Участники объединяются с актуальными атомарно: конкурентные приглашения и ответы не теряются.
+	// Новые участники должны поместиться в занятые событием ресурсы, это проверяет хранилище.
+	event, err := s.repo.UpdateAttendees(ctx, target.ID, func(event *models.Event) error {
+		attendees := append([]models.Attendee(nil), event.Attendees...)
+		for _, id := range userIDs {
+			attendees = append(attendees, models.Attendee{UserID: id})
+		}
+
+		var err error
+		event.Attendees, err = mergeAttendees(event.UserID, attendees, event.Attendees)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.publish(ctx, models.EventUpdated, event); err != nil {
+		return err
+	}
+
+	s.logger.Info("участники приглашены на событие",
+		zap.String("service", "calendar"),
+		zap.String("op", "InviteAttendees"),
+		zap.String("event_id", event.ID),
+		zap.Int64s("attendees", userIDs),
+	)
+
+	return nil
+}
+
+// RespondEvent - ответ участника userID на приглашение. Отвечает только сам участник;
+// ответ на вхождение серии относится ко всей серии. Отклоненное событие пропадает из календаря участника.
+// Принять ранее отклоненное приглашение можно, только если участник помещается в ресурсы события
+// (иначе *models.BookingError).
+func (s *calendarService) RespondEvent(
+	ctx context.Context,
+	eventID string,
+	userID int64,
+	status models.AttendeeStatus,
+) error {
+	if eventID == "" {
+		return errEventID
+	}
+	if userID <= 0 {
+		return errUserID
+	}
+	if !status.Valid() {
+		return errAttendeeStatus
+	}
+	if err := auth.Authorize(ctx, userID); err != nil {
+		return err
+	}
+
+	if seriesID, _, ok := parseInstanceID(eventID); ok {
+		eventID = seriesID
+	}
+	var changed bool
+	event, err := s.repo.UpdateAttendees(ctx, eventID, func(event *models.Event) error {
+		// Отвечать на чужие приглашения нельзя: не приглашенному пользователю событие недоступно.
+		attendee := event.Attendee(userID)
+		if attendee == nil {
+			return auth.ErrForbidden
+		}
+		changed = attendee.Status != status
+		attendee.Status = status
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	return s.publish(ctx, models.EventUpdated, event)
+}
@@ -0,0 +1,204 @@
+package calendarsvc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func TestInviteAndRespond(t *testing.T) {
+	svc := newSvc(t)
+	day := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+
+	organizer := auth.WithUser(context.Background(), 1)
+	alice := auth.WithUser(context.Background(), 2)
+	bob := auth.WithUser(context.Background(), 3)
+
+	id, err := svc.CreateEvent(organizer, models.Event{
+		UserID:    1,
+		Date:      day,
+		Text:      "ретро",
+		Attendees: []models.Attendee{{UserID: 2, Status: models.AttendeeAccepted}, {UserID: 2}},
+	})
+	require.NoError(t, err)
+
+	// Статус нового участника всегда needs-action, повторы отбрасываются.
+	got, err := svc.repo.Read(organizer, id)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Attendee{{UserID: 2, Status: models.AttendeeNeedsAction}}, got.Attendees)
+
+	_, err = svc.CreateEvent(organizer, models.Event{UserID: 1, Date: day, Text: "x", Attendees: []models.Attendee{{UserID: 1}}})
+	assert.ErrorIs(t, err, errAttendee)
+
+	// Приглашенный видит встречу в своем календаре, неприглашенный - нет.
+	events, err := svc.GetEventsForDay(alice, 2, day)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "ретро", events[0].Text)
+	assert.Equal(t, int64(1), events[0].UserID)
+
+	events, err = svc.GetEventsForDay(bob, 3, day)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	require.NoError(t, svc.InviteAttendees(organizer, id, []int64{3, 2}))
+	assert.ErrorIs(t, svc.InviteAttendees(alice, id, []int64{4}), auth.ErrForbidden)
+
+	require.NoError(t, svc.RespondEvent(alice, id, 2, models.AttendeeAccepted))
+	assert.ErrorIs(t, svc.RespondEvent(alice, id, 3, models.AttendeeAccepted), auth.ErrForbidden)
+	assert.ErrorIs(t, svc.RespondEvent(auth.WithUser(context.Background(), 4), id, 4, models.AttendeeAccepted), auth.ErrForbidden)
+	assert.ErrorIs(t, svc.RespondEvent(alice, id, 2, "maybe"), errAttendeeStatus)
+
+	got, err = svc.repo.Read(organizer, id)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Attendee{
+		{UserID: 2, Status: models.AttendeeAccepted},
+		{UserID: 3, Status: models.AttendeeNeedsAction},
+	}, got.Attendees)
+
+	// Изменение организатора видно участникам, ответы сохраняются.
+	require.NoError(t, svc.UpdateEvent(organizer, models.Event{ID: id, UserID: 1, Date: day.Add(time.Hour), Text: "ретро спринта"}, models.EditScopeDefault))
+
+	events, err = svc.GetEventsForDay(bob, 3, day)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "ретро спринта", events[0].Text)
+	assert.Equal(t, models.AttendeeAccepted, events[0].Attendee(2).Status)
+
+	// Отклоненная встреча пропадает из календаря участника.
+	require.NoError(t, svc.RespondEvent(bob, id, 3, models.AttendeeDeclined))
+	events, err = svc.GetEventsForDay(bob, 3, day)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// Переданный список участников заменяет текущий.
+	require.NoError(t, svc.UpdateEvent(organizer, models.Event{
+		ID: id, UserID: 1, Date: day.Add(time.Hour), Text: "ретро спринта", Attendees: []models.Attendee{{UserID: 3}},
+	}, models.EditScopeDefault))
+	got, err = svc.repo.Read(organizer, id)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Attendee{{UserID: 3, Status: models.AttendeeDeclined}}, got.Attendees)
+
+	events, err = svc.GetEventsForDay(alice, 2, day)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// Участник не может менять встречу.
+	err = svc.UpdateEvent(bob, models.Event{ID: id, UserID: 1, Date: day, Text: "моя"}, models.EditScopeDefault)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+}
+
+func TestInviteSeries(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	monday := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+
+	id, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: monday, Text: "стендап", RRule: "FREQ=DAILY;COUNT=5"})
+	require.NoError(t, err)
+
+	week, err := svc.GetEventsForWeek(ctx, 1, monday)
+	require.NoError(t, err)
+	require.Len(t, week, 5)
+
+	// Приглашение и ответ на вхождение относятся ко всей серии.
+	require.NoError(t, svc.InviteAttendees(ctx, week[2].ID, []int64{2}))
+	require.NoError(t, svc.RespondEvent(ctx, week[3].ID, 2, models.AttendeeTentative))
+
+	series, err := svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Attendee{{UserID: 2, Status: models.AttendeeTentative}}, series.Attendees)
+
+	events, err := svc.GetEventsForWeek(ctx, 2, monday)
+	require.NoError(t, err)
+	assert.Len(t, events, 5)
+
+	page, err := svc.ListEvents(ctx, models.EventsRange{UserID: 2, From: monday, To: monday.AddDate(0, 0, 2), Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Events, 2)
+
+	// Исключение серии наследует участников.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{ID: week[1].ID, UserID: 1, Date: week[1].Date.Add(time.Hour), Text: "стендап позже"}, models.EditScopeThis))
+
+	events, err = svc.GetEventsForDay(ctx, 2, week[1].Date)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "стендап позже", events[0].Text)
+}
+
+// barrierRepo - БД, задерживающая первые n чтений, пока их не начнут все n читателей.
+// Так конкурентные запросы гарантированно видят одно и то же состояние события.
+type barrierRepo struct {
+	infra.Database
+
+	reads   atomic.Int32
+	n       int32
+	arrived sync.WaitGroup
+}
+
+func newBarrierRepo(repo infra.Database, n int) *barrierRepo {
+	r := &barrierRepo{Database: repo, n: int32(n)}
+	r.arrived.Add(n)
+	return r
+}
+
+func (r *barrierRepo) Read(ctx context.Context, id string) (*models.Event, error) {
+	event, err := r.Database.Read(ctx, id)
+	if r.reads.Add(1) <= r.n {
+		r.arrived.Done()
+		r.arrived.Wait()
+	}
+	return event, err
+}
+
+func TestConcurrentAttendees(t *testing.T) {
+	const n = 8
+
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+
+	id, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day, Text: "планерка"})
+	require.NoError(t, err)
+
+	// Одновременные приглашения не затирают друг друга.
+	svc.repo = newBarrierRepo(svc.repo, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, svc.InviteAttendees(ctx, id, []int64{int64(i + 2)}))
+		}()
+	}
+	wg.Wait()
+
+	event, err := svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, event.Attendees, n)
+
+	// Как и одновременные ответы участников.
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			userID := int64(i + 2)
+			assert.NoError(t, svc.RespondEvent(auth.WithUser(ctx, userID), id, userID, models.AttendeeAccepted))
+		}()
+	}
+	wg.Wait()
+
+	event, err = svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	for _, a := range event.Attendees {
+		assert.Equal(t, models.AttendeeAccepted, a.Status, a.UserID)
+	}
+}
@@ -0,0 +1,282 @@
+package calendarsvc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// maxCalendarName - максимальная длина названия календаря в символах.
+const maxCalendarName = 100
+
+var colorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// calendarView - часть календарей владельца, доступная вызывающему.
+type calendarView struct {
+	got access
+	// calendars - фильтр календарей для хранилища (nil - все).
+	calendars []string
+	// busy - календари, события которых видны только как занятость.
+	busy map[string]bool
+}
+
+// view - права вызывающего на календари ownerID не ниже need и доступные ему календари из requested (nil - все).
+// Владельцу доступны все календари, остальным - календарь по умолчанию и неприватные;
+// календари с видимостью free_busy открыты им только на занятость.
+func (s *calendarService) view(ctx context.Context, ownerID int64, need access, requested []string) (calendarView, error) {
+	got, err := s.authorize(ctx, ownerID, need)
+	if err != nil {
+		return calendarView{}, err
+	}
+
+	v := calendarView{got: got, calendars: requested}
+	if got == accessOwner {
+		return v, nil
+	}
+
+	calendars, err := s.calendars.List(ctx, ownerID)
+	if err != nil {
+		return calendarView{}, fmt.Errorf("calendars.List: %w", err)
+	}
+
+	allowed := []string{""}
+	v.busy = make(map[string]bool)
+	for _, cal := range calendars {
+		switch cal.Visibility {
+		case models.VisibilityPrivate:
+			continue
+		case models.VisibilityBusy:
+			v.busy[cal.ID] = true
+		}
+		allowed = append(allowed, cal.ID)
+	}
+
+	if requested == nil {
+		v.calendars = allowed
+	} else {
+		v.calendars = slices.DeleteFunc(slices.Clone(requested), func(id string) bool {
+			return !slices.Contains(allowed, id)
+		})
+	}
+
+	return v, nil
+}
+
+// options - фильтр выборки событий календарей ownerID в представлении. Владельцу видны
+// и встречи, на которые его пригласили, остальным - только события владельца.
+func (v calendarView) options(ownerID int64) infra.ListOptions {
+	opts := infra.ListOptions{CalendarIDs: v.calendars}
+	if v.got == accessOwner {
+		opts.Participant = &ownerID
+	} else {
+		opts.UserID = &ownerID
+	}
+
+	return opts
+}
+
+// redact - события в объеме, доступном вызывающему: при доступе только к занятости
+// остаются время и повторение, текст, напоминания и iCal UID удаляются.
+func (v calendarView) redact(events []models.Event) []models.Event {
+	for i := range events {
+		if v.got > accessFreeBusy && !v.busy[events[i].CalendarID] {
+			continue
+		}
+		events[i].Text = ""
+		events[i].Reminders = nil
+		events[i].Channels = nil
+		events[i].ICalUID = ""
+	}
+
+	return events
+}
+
+// authorizeCalendar - проверяет право вызывающего изменять события календаря calendarID пользователя ownerID
+// и возвращает календарь (nil для календаря по умолчанию). Календарь должен принадлежать владельцу;
+// в приватные календари и календари занятости пишет только владелец.
+func (s *calendarService) authorizeCalendar(ctx context.Context, ownerID int64, calendarID string) (*models.Calendar, error) {
+	got, err := s.authorize(ctx, ownerID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	if calendarID == "" {
+		return nil, nil
+	}
+
+	cal, err := s.calendars.Get(ctx, calendarID)
+	if err != nil {
+		return nil, fmt.Errorf("calendars.Get: %w", err)
+	}
+	if cal == nil || cal.UserID != ownerID {
+		return nil, errCalendar
+	}
+	if got != accessOwner && cal.Visibility != models.VisibilityShared {
+		return nil, auth.ErrForbidden
+	}
+
+	return cal, nil
+}
+
+// validateCalendar - проверяет и нормализует поля календаря.
+func validateCalendar(cal *models.Calendar) error {
+	cal.Name = strings.TrimSpace(cal.Name)
+	if cal.Name == "" || utf8.RuneCountInString(cal.Name) > maxCalendarName {
+		return errCalendarName
+	}
+	if cal.Color != "" && !colorRe.MatchString(cal.Color) {
+		return errColor
+	}
+	if cal.Visibility == "" {
+		cal.Visibility = models.VisibilityShared
+	}
+	if !cal.Visibility.Valid() {
+		return errVisibility
+	}
+	if err := validateReminders(cal.Reminders); err != nil {
+		return err
+	}
+	cal.Reminders = normalizeReminders(cal.Reminders)
+
+	return nil
+}
+
+// CreateCalendar - создает календарь пользователя. Создавать календари может только сам пользователь.
+func (s *calendarService) CreateCalendar(ctx context.Context, cal models.Calendar) (models.Calendar, error) {
+	if cal.UserID <= 0 {
+		return models.Calendar{}, errUserID
+	}
+	if err := auth.Authorize(ctx, cal.UserID); err != nil {
+		return models.Calendar{}, err
+	}
+	if err := validateCalendar(&cal); err != nil {
+		return models.Calendar{}, err
+	}
+
+	cal.ID = uuid.NewString()
+	cal.CreatedAt = time.Now()
+	if err := s.calendars.Create(ctx, &cal); err != nil {
+		return models.Calendar{}, fmt.Errorf("calendars.Create: %w", err)
+	}
+
+	s.logger.Info("создан календарь",
+		zap.String("service", "calendar"),
+		zap.String("op", "CreateCalendar"),
+		zap.Int64("user_id", cal.UserID),
+		zap.String("calendar_id", cal.ID),
+	)
+
+	return cal, nil
+}
+
+// UpdateCalendar - заменяет название, цвет, напоминания и видимость календаря.
+// false - у пользователя нет такого календаря.
+func (s *calendarService) UpdateCalendar(ctx context.Context, cal models.Calendar) (bool, error) {
+	if cal.ID == "" {
+		return false, errCalendar
+	}
+	if cal.UserID <= 0 {
+		return false, errUserID
+	}
+	if err := auth.Authorize(ctx, cal.UserID); err != nil {
+		return false, err
+	}
+	if err := validateCalendar(&cal); err != nil {
+		return false, err
+	}
+
+	stored, err := s.calendars.Get(ctx, cal.ID)
+	if err != nil {
+		return false, fmt.Errorf("calendars.Get: %w", err)
+	}
+	if stored == nil || stored.UserID != cal.UserID {
+		return false, nil
+	}
+
+	cal.CreatedAt = stored.CreatedAt
+	if err := s.calendars.Update(ctx, &cal); err != nil {
+		return false, fmt.Errorf("calendars.Update: %w", err)
+	}
+
+	return true, nil
+}
+
+// DeleteCalendar - удаляет календарь пользователя вместе с его событиями; по каждому событию
+// публикуется event.deleted. false - у пользователя нет такого календаря.
+func (s *calendarService) DeleteCalendar(ctx context.Context, userID int64, calendarID string) (bool, error) {
+	if calendarID == "" {
+		return false, errCalendar
+	}
+	if userID <= 0 {
+		return false, errUserID
+	}
+	if err := auth.Authorize(ctx, userID); err != nil {
+		return false, err
+	}
+
+	stored, err := s.calendars.Get(ctx, calendarID)
+	if err != nil {
+		return false, fmt.Errorf("calendars.Get: %w", err)
+	}
+	if stored == nil || stored.UserID != userID {
+		return false, nil
+	}
+
+	events, err := s.repo.List(ctx, &infra.ListOptions{UserID: &userID, CalendarIDs: []string{calendarID}})
+	if err != nil {
+		return false, fmt.Errorf("repo.List: %w", err)
+	}
+	for i := range events {
+		if err := s.remove(ctx, &events[i]); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := s.calendars.Delete(ctx, calendarID); err != nil {
+		return false, fmt.Errorf("calendars.Delete: %w", err)
+	}
+
+	s.logger.Info("удален календарь",
+		zap.String("service", "calendar"),
+		zap.String("op", "DeleteCalendar"),
+		zap.Int64("user_id", userID),
+		zap.String("calendar_id", calendarID),
+		zap.Int("events", len(events)),
+	)
+
+	return true, nil
+}
+
+// ListCalendars - календари пользователя по времени создания. Пользователям с выданным доступом
+// приватные календари не показываются.
+func (s *calendarService) ListCalendars(ctx context.Context, userID int64) ([]models.Calendar, error) {
+	if userID <= 0 {
+		return nil, errUserID
+	}
+	got, err := s.authorize(ctx, userID, accessFreeBusy)
+	if err != nil {
+		return nil, err
+	}
+
+	calendars, err := s.calendars.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("calendars.List: %w", err)
+	}
+	if got != accessOwner {
+		calendars = slices.DeleteFunc(calendars, func(cal models.Calendar) bool {
+			return cal.Visibility == models.VisibilityPrivate
+		})
+	}
+
+	return calendars, nil
+}
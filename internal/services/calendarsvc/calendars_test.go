@@ -0,0 +1,216 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func TestCalendarCRUD(t *testing.T) {
+	svc := newSvc(t)
+	ctx := auth.WithUser(context.Background(), 1)
+	day := time.Date(2025, 5, 5, 9, 0, 0, 0, time.UTC)
+
+	work, err := svc.CreateCalendar(ctx, models.Calendar{
+		UserID:    1,
+		Name:      " Работа ",
+		Color:     "#1E90FF",
+		Reminders: []models.Reminder{{Offset: models.Offset(-15 * time.Minute)}},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, work.ID)
+	assert.Equal(t, "Работа", work.Name)
+	assert.Equal(t, models.VisibilityShared, work.Visibility)
+
+	_, err = svc.CreateCalendar(ctx, models.Calendar{UserID: 1, Name: " "})
+	assert.ErrorIs(t, err, errCalendarName)
+	_, err = svc.CreateCalendar(ctx, models.Calendar{UserID: 1, Name: "Личное", Color: "red"})
+	assert.ErrorIs(t, err, errColor)
+	_, err = svc.CreateCalendar(ctx, models.Calendar{UserID: 1, Name: "Личное", Visibility: "hidden"})
+	assert.ErrorIs(t, err, errVisibility)
+	_, err = svc.CreateCalendar(ctx, models.Calendar{UserID: 2, Name: "Чужой"})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	// Событие без напоминаний получает напоминания календаря, явный пустой список - нет.
+	withDefault, err := svc.CreateEvent(ctx, models.Event{UserID: 1, CalendarID: work.ID, Date: day, Text: "стендап"})
+	require.NoError(t, err)
+	without, err := svc.CreateEvent(ctx, models.Event{
+		UserID: 1, CalendarID: work.ID, Date: day.Add(time.Hour), Text: "ревью", Reminders: []models.Reminder{},
+	})
+	require.NoError(t, err)
+
+	got, err := svc.repo.Read(ctx, withDefault)
+	require.NoError(t, err)
+	assert.Equal(t, work.ID, got.CalendarID)
+	require.Len(t, got.Reminders, 1)
+	assert.Equal(t, models.Offset(-15*time.Minute), got.Reminders[0].Offset)
+
+	got, err = svc.repo.Read(ctx, without)
+	require.NoError(t, err)
+	assert.Empty(t, got.Reminders)
+
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 1, CalendarID: "missing", Date: day, Text: "x"})
+	assert.ErrorIs(t, err, errCalendar)
+
+	work.Name = "Офис"
+	work.Visibility = models.VisibilityBusy
+	ok, err := svc.UpdateCalendar(ctx, work)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = svc.UpdateCalendar(ctx, models.Calendar{ID: "missing", UserID: 1, Name: "x"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	calendars, err := svc.ListCalendars(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, calendars, 1)
+	assert.Equal(t, "Офис", calendars[0].Name)
+	assert.Equal(t, models.VisibilityBusy, calendars[0].Visibility)
+
+	// Удаление календаря удаляет его события.
+	ok, err = svc.DeleteCalendar(ctx, 1, work.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = svc.repo.Read(ctx, withDefault)
+	assert.Error(t, err)
+
+	ok, err = svc.DeleteCalendar(ctx, 1, work.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCalendarFilter(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 5, 5, 9, 0, 0, 0, time.UTC)
+
+	work, err := svc.CreateCalendar(ctx, models.Calendar{UserID: 1, Name: "Работа"})
+	require.NoError(t, err)
+	onCall, err := svc.CreateCalendar(ctx, models.Calendar{UserID: 1, Name: "Дежурства"})
+	require.NoError(t, err)
+
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day, Text: "спортзал"})
+	require.NoError(t, err)
+	meeting, err := svc.CreateEvent(ctx, models.Event{UserID: 1, CalendarID: work.ID, Date: day.Add(time.Hour), Text: "планерка"})
+	require.NoError(t, err)
+	_, err = svc.CreateEvent(ctx, models.Event{
+		UserID: 1, CalendarID: onCall.ID, Date: day.Add(2 * time.Hour), Text: "дежурство", RRule: "FREQ=DAILY",
+	})
+	require.NoError(t, err)
+
+	texts := func(events []models.Event) []string {
+		res := make([]string, 0, len(events))
+		for _, e := range events {
+			res = append(res, e.Text)
+		}
+		return res
+	}
+
+	events, err := svc.GetEventsForDay(ctx, 1, day)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"спортзал", "планерка", "дежурство"}, texts(events))
+
+	events, err = svc.GetEventsForDay(ctx, 1, day, work.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"планерка"}, texts(events))
+
+	events, err = svc.GetEventsForWeek(ctx, 1, day, "", onCall.ID)
+	require.NoError(t, err)
+	assert.Len(t, events, 8)
+	assert.Equal(t, onCall.ID, events[1].CalendarID)
+
+	events, err = svc.GetEventsForMonth(ctx, 1, day, work.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"спортзал", "планерка"}, texts(events))
+
+	page, err := svc.ListEvents(ctx, models.EventsRange{
+		UserID: 1, CalendarIDs: []string{onCall.ID}, From: day, To: day.AddDate(0, 0, 3), Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Len(t, page.Events, 3)
+
+	// Без calendar_id событие остается в своем календаре, с ним - переносится.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{ID: meeting, UserID: 1, Date: day.Add(time.Hour), Text: "планерка"}, models.EditScopeDefault))
+	events, err = svc.GetEventsForDay(ctx, 1, day, work.ID)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{
+		ID: meeting, UserID: 1, CalendarID: onCall.ID, Date: day.Add(time.Hour), Text: "планерка",
+	}, models.EditScopeDefault))
+	events, err = svc.GetEventsForDay(ctx, 1, day, work.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestCalendarVisibility(t *testing.T) {
+	svc := newSvc(t)
+	day := time.Date(2025, 5, 5, 9, 0, 0, 0, time.UTC)
+
+	owner := auth.WithUser(context.Background(), 1)
+	editor := auth.WithUser(context.Background(), 2)
+
+	shared, err := svc.CreateCalendar(owner, models.Calendar{UserID: 1, Name: "Работа"})
+	require.NoError(t, err)
+	busy, err := svc.CreateCalendar(owner, models.Calendar{UserID: 1, Name: "Дежурства", Visibility: models.VisibilityBusy})
+	require.NoError(t, err)
+	private, err := svc.CreateCalendar(owner, models.Calendar{UserID: 1, Name: "Личное", Visibility: models.VisibilityPrivate})
+	require.NoError(t, err)
+
+	for i, cal := range []string{"", shared.ID, busy.ID, private.ID} {
+		_, err := svc.CreateEvent(owner, models.Event{UserID: 1, CalendarID: cal, Date: day.Add(time.Duration(i) * time.Hour), Text: "событие " + cal})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 2, Role: models.ShareEditor}))
+
+	calendars, err := svc.ListCalendars(editor, 1)
+	require.NoError(t, err)
+	require.Len(t, calendars, 2)
+	assert.Equal(t, shared.ID, calendars[0].ID)
+	assert.Equal(t, busy.ID, calendars[1].ID)
+
+	// Приватный календарь скрыт, календарь занятости отдается без текста.
+	events, err := svc.GetEventsForDay(editor, 1, day)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.NotEmpty(t, events[0].Text)
+	assert.NotEmpty(t, events[1].Text)
+	assert.Equal(t, busy.ID, events[2].CalendarID)
+	assert.Empty(t, events[2].Text)
+
+	events, err = svc.GetEventsForDay(editor, 1, day, private.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	found, err := svc.SearchEvents(editor, models.EventsSearch{UserID: 1, Query: "событие", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	// Редактор пишет только в общие календари.
+	_, err = svc.CreateEvent(editor, models.Event{UserID: 1, CalendarID: shared.ID, Date: day, Text: "созвон"})
+	require.NoError(t, err)
+	_, err = svc.CreateEvent(editor, models.Event{UserID: 1, CalendarID: busy.ID, Date: day, Text: "созвон"})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	_, err = svc.CreateEvent(editor, models.Event{UserID: 1, CalendarID: private.ID, Date: day, Text: "созвон"})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	// Событие нельзя положить в чужой календарь.
+	_, err = svc.CreateEvent(editor, models.Event{UserID: 2, CalendarID: shared.ID, Date: day, Text: "свое"})
+	assert.ErrorIs(t, err, errCalendar)
+
+	_, err = svc.DeleteCalendar(editor, 1, shared.ID)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	events, err = svc.GetEventsForDay(owner, 1, day)
+	require.NoError(t, err)
+	assert.Len(t, events, 5)
+}
@@ -0,0 +1,111 @@
+package calendarsvc
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// conflictHorizon - период от начала серии, в котором ее вхождения проверяются на пересечения.
+const conflictHorizon = 366 * 24 * time.Hour
+
+// CheckConflicts - ID событий пользователя, пересекающихся с событием: новым или, если задан ID,
+// измененным (само событие и его серия не учитываются). Учитываются доступные вызывающему календари
+// и неотклоненные приглашения; для серии - вхождения в пределах года от начала. Для вхождения
+// серии проверяется только оно само, если не передано новое правило повторения.
+func (s *calendarService) CheckConflicts(ctx context.Context, event models.Event) ([]string, error) {
+	var fallbackTZ string
+	if event.ID != "" {
+		data, occ, err := s.resolveTarget(ctx, event.ID, event.RecurrenceID)
+		if err != nil {
+			return nil, err
+		}
+		event.ID = data.ID
+		event.UserID = data.UserID
+		fallbackTZ = data.TimeZone
+		if occ == nil && event.RRule == "" {
+			event.RRule = data.RRule
+		}
+	}
+	if event.UserID <= 0 {
+		return nil, errUserID
+	}
+
+	v, err := s.view(ctx, event.UserID, accessWrite, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyTimeZone(ctx, &event, fallbackTZ); err != nil {
+		return nil, err
+	}
+	if err := normalizeTimes(&event); err != nil {
+		return nil, err
+	}
+
+	return s.overlaps(ctx, event, v.calendars)
+}
+
+// checkCalendar - *models.ConflictError, если календарь запрещает пересечения,
+// а событие пересекается с другими его событиями.
+func (s *calendarService) checkCalendar(ctx context.Context, cal *models.Calendar, event models.Event) error {
+	if cal == nil || !cal.NoOverlap {
+		return nil
+	}
+
+	ids, err := s.overlaps(ctx, event, []string{cal.ID})
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		return &models.ConflictError{EventIDs: ids}
+	}
+
+	return nil
+}
+
+// overlaps - ID событий календарей calendarIDs (nil - все) пользователя event.UserID, пересекающихся
+// с событием или вхождениями его серии, в порядке начала. Событие с ID event.ID и вхождения его серии
+// пропускаются; события без длительности ни с чем не пересекаются.
+func (s *calendarService) overlaps(ctx context.Context, event models.Event, calendarIDs []string) ([]string, error) {
+	if event.Duration() <= 0 {
+		return nil, nil
+	}
+
+	intervals := []models.Interval{{Start: event.Date, End: event.EndTime()}}
+	if event.RRule != "" {
+		intervals = intervals[:0]
+		for _, occ := range s.expandSeries(event, event.Date, event.Date.Add(conflictHorizon)) {
+			intervals = append(intervals, models.Interval{Start: occ.Date, End: occ.End})
+		}
+		if len(intervals) == 0 {
+			return nil, nil
+		}
+	}
+
+	from, to := intervals[0].Start, intervals[len(intervals)-1].End
+	events, err := s.eventsInRange(ctx, infra.ListOptions{Participant: &event.UserID, CalendarIDs: calendarIDs}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range events {
+		if e.Duration() <= 0 {
+			continue
+		}
+		if event.ID != "" && (e.ID == event.ID || e.SeriesID == event.ID) {
+			continue
+		}
+
+		// Вхождения идут по возрастанию и имеют одну длительность, поэтому упорядочены и по окончанию.
+		i := sort.Search(len(intervals), func(i int) bool { return intervals[i].End.After(e.Date) })
+		if i < len(intervals) && intervals[i].Start.Before(e.EndTime()) {
+			ids = append(ids, e.ID)
+		}
+	}
+
+	return ids, nil
+}
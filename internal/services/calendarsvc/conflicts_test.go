@@ -0,0 +1,117 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func TestCheckConflicts(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+
+	create := func(event models.Event) string {
+		t.Helper()
+		id, err := svc.CreateEvent(ctx, event)
+		require.NoError(t, err)
+		return id
+	}
+
+	review := create(models.Event{UserID: 1, Date: at(10, 0), End: at(11, 0), Text: "ревью"})
+	planning := create(models.Event{UserID: 1, Date: at(11, 0), End: at(12, 0), Text: "планирование"})
+	create(models.Event{UserID: 1, Date: at(10, 30), Text: "без длительности"})
+	standup := create(models.Event{UserID: 1, Date: at(15, 0), End: at(15, 30), Text: "стендап", RRule: "FREQ=DAILY;COUNT=5"})
+	meeting := create(models.Event{UserID: 3, Date: at(13, 0), End: at(14, 0), Text: "встреча", Attendees: []models.Attendee{{UserID: 1}}})
+
+	conflicts, err := svc.CheckConflicts(ctx, models.Event{UserID: 1, Date: at(10, 30), End: at(11, 30), Text: "x"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{review, planning}, conflicts)
+
+	// Смежные события не пересекаются.
+	conflicts, err = svc.CheckConflicts(ctx, models.Event{UserID: 1, Date: at(9, 0), End: at(10, 0), Text: "x"})
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// Вхождение серии и неотклоненное приглашение тоже занимают время.
+	conflicts, err = svc.CheckConflicts(ctx, models.Event{UserID: 1, Date: at(13, 30).AddDate(0, 0, 2), End: at(15, 15).AddDate(0, 0, 2), Text: "x"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{instanceID(standup, at(15, 0).AddDate(0, 0, 2))}, conflicts)
+
+	conflicts, err = svc.CheckConflicts(ctx, models.Event{UserID: 1, Date: at(13, 30), End: at(14, 30), Text: "x"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{meeting}, conflicts)
+
+	require.NoError(t, svc.RespondEvent(ctx, meeting, 1, models.AttendeeDeclined))
+	conflicts, err = svc.CheckConflicts(ctx, models.Event{UserID: 1, Date: at(13, 30), End: at(14, 30), Text: "x"})
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// Новая серия проверяется по всем вхождениям.
+	conflicts, err = svc.CheckConflicts(ctx, models.Event{
+		UserID: 1, Date: at(10, 45).AddDate(0, 0, -7), End: at(11, 15).AddDate(0, 0, -7), Text: "x", RRule: "FREQ=WEEKLY",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{review, planning}, conflicts)
+
+	// Изменяемое событие и его серия не конфликтуют сами с собой.
+	conflicts, err = svc.CheckConflicts(ctx, models.Event{ID: review, Date: at(10, 30), End: at(11, 30), Text: "ревью"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{planning}, conflicts)
+
+	conflicts, err = svc.CheckConflicts(ctx, models.Event{ID: standup, Date: at(15, 10), End: at(15, 40), Text: "стендап"})
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	_, err = svc.CheckConflicts(ctx, models.Event{Date: at(9, 0), Text: "x"})
+	assert.ErrorIs(t, err, errUserID)
+}
+
+func TestNoOverlapCalendar(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+
+	room, err := svc.CreateCalendar(ctx, models.Calendar{UserID: 1, Name: "Переговорная", NoOverlap: true})
+	require.NoError(t, err)
+
+	booked, err := svc.CreateEvent(ctx, models.Event{UserID: 1, CalendarID: room.ID, Date: day, End: day.Add(time.Hour), Text: "бронь"})
+	require.NoError(t, err)
+
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 1, CalendarID: room.ID, Date: day.Add(30 * time.Minute), End: day.Add(90 * time.Minute), Text: "бронь"})
+	var conflict *models.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []string{booked}, conflict.EventIDs)
+
+	// События других календарей не мешают.
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.Add(30 * time.Minute), End: day.Add(90 * time.Minute), Text: "личное"})
+	require.NoError(t, err)
+
+	next, err := svc.CreateEvent(ctx, models.Event{UserID: 1, CalendarID: room.ID, Date: day.Add(time.Hour), End: day.Add(2 * time.Hour), Text: "бронь"})
+	require.NoError(t, err)
+
+	err = svc.UpdateEvent(ctx, models.Event{ID: next, UserID: 1, Date: day.Add(45 * time.Minute), End: day.Add(2 * time.Hour), Text: "бронь"}, models.EditScopeDefault)
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []string{booked}, conflict.EventIDs)
+
+	// Сдвиг внутри собственного интервала разрешен.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{ID: next, UserID: 1, Date: day.Add(70 * time.Minute), End: day.Add(2 * time.Hour), Text: "бронь"}, models.EditScopeDefault))
+
+	_, err = svc.CreateEvent(ctx, models.Event{
+		UserID: 1, CalendarID: room.ID, Date: day.AddDate(0, 0, -3), End: day.AddDate(0, 0, -3).Add(time.Hour), Text: "ежедневно", RRule: "FREQ=DAILY",
+	})
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []string{booked}, conflict.EventIDs)
+
+	// Перенос в календарь без пересечений проверяется по его событиям.
+	other, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day, End: day.Add(time.Hour), Text: "перенос"})
+	require.NoError(t, err)
+	err = svc.UpdateEvent(ctx, models.Event{ID: other, UserID: 1, CalendarID: room.ID, Date: day, End: day.Add(time.Hour), Text: "перенос"}, models.EditScopeDefault)
+	require.ErrorAs(t, err, &conflict)
+}
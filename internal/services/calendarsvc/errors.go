@@ -1,9 +1,39 @@
 package calendarsvc
 
-import "errors"
+import "github.com/sunr3d/simple-http-calendar/models"
 
 var (
-	errUserID     = errors.New("некорректный user_id")
-	errEventID    = errors.New("некорректный event_id")
-	errEmptyEvent = errors.New("описание события не может быть пустым")
+	errUserID         = models.Invalid("некорректный user_id")
+	errEventID        = models.Invalid("некорректный event_id")
+	errEmptyEvent     = models.Invalid("описание события не может быть пустым")
+	errRRule          = models.Invalid("некорректное правило повторения")
+	errScope          = models.Invalid("некорректная область изменения серии")
+	errOccurrence     = models.NotFound("вхождение серии не найдено или не указано")
+	errDuplicate      = models.Invalid("событие с таким iCal UID уже существует")
+	errNoSeries       = models.NotFound("серия для RECURRENCE-ID не найдена")
+	errEndBefore      = models.Invalid("окончание события не может быть раньше начала")
+	errTimeZone       = models.Invalid("неизвестный часовой пояс")
+	errChannel        = models.Invalid("неизвестный канал напоминаний")
+	errRecipient      = models.Invalid("не задан или некорректен адрес для канала напоминаний")
+	errReminders      = models.Invalid("слишком много напоминаний")
+	errRange          = models.Invalid("начало периода должно быть раньше окончания")
+	errPage           = models.Invalid("некорректные параметры страницы")
+	errQuery          = models.Invalid("пустой поисковый запрос")
+	errSelfShare      = models.Invalid("нельзя выдать доступ к календарю самому себе")
+	errRole           = models.Invalid("неизвестная роль доступа")
+	errCalendar       = models.Invalid("календарь не найден или не указан")
+	errCalendarName   = models.Invalid("название календаря не может быть пустым или длиннее 100 символов")
+	errColor          = models.Invalid("цвет календаря должен быть в формате #RRGGBB")
+	errVisibility     = models.Invalid("неизвестная видимость календаря")
+	errAttendee       = models.Invalid("некорректный участник встречи")
+	errAttendees      = models.Invalid("слишком много участников встречи")
+	errAttendeeStatus = models.Invalid("неизвестный ответ на приглашение")
+	errUsers          = models.Invalid("некорректное число пользователей")
+	errSlot           = models.Invalid("длительность свободного промежутка не может быть отрицательной")
+	errWorkHours      = models.Invalid("начало рабочего дня должно быть раньше окончания в пределах суток")
+	errResource       = models.Invalid("ресурс не найден или не указан")
+	errResourceName   = models.Invalid("название ресурса не может быть пустым или длиннее 100 символов")
+	errResourceKind   = models.Invalid("неизвестный вид ресурса")
+	errCapacity       = models.Invalid("вместимость ресурса не может быть отрицательной")
+	errBookingPolicy  = models.Invalid("ограничения бронирования не могут быть отрицательными")
 )
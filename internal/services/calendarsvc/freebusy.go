@@ -0,0 +1,150 @@
+package calendarsvc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// maxFreeBusyUsers - максимальное число пользователей в одном запросе занятости.
+const maxFreeBusyUsers = 50
+
+// FreeBusy - занятость пользователей в [From; To) и, при SlotDuration > 0, общие свободные
+// промежутки в рабочие часы. Занятость считается по событиям доступных вызывающему календарей
+// и неотклоненным приглашениям; события без длительности время не занимают.
+// Для чужих календарей достаточно доступа free_busy.
+func (s *calendarService) FreeBusy(ctx context.Context, query models.FreeBusyQuery) (models.FreeBusy, error) {
+	if len(query.UserIDs) == 0 || len(query.UserIDs) > maxFreeBusyUsers {
+		return models.FreeBusy{}, fmt.Errorf("%w: от 1 до %d", errUsers, maxFreeBusyUsers)
+	}
+	if !query.From.Before(query.To) {
+		return models.FreeBusy{}, errRange
+	}
+	if query.SlotDuration < 0 {
+		return models.FreeBusy{}, errSlot
+	}
+	if query.SlotDuration > 0 && (query.WorkStart < 0 || query.WorkEnd > 24*time.Hour || query.WorkStart >= query.WorkEnd) {
+		return models.FreeBusy{}, errWorkHours
+	}
+
+	res := models.FreeBusy{Users: make([]models.UserBusy, 0, len(query.UserIDs))}
+	var all []models.Interval
+	for _, userID := range query.UserIDs {
+		if userID <= 0 {
+			return models.FreeBusy{}, errUserID
+		}
+		if slices.ContainsFunc(res.Users, func(u models.UserBusy) bool { return u.UserID == userID }) {
+			continue
+		}
+
+		busy, err := s.busy(ctx, userID, query.From, query.To)
+		if err != nil {
+			return models.FreeBusy{}, err
+		}
+		res.Users = append(res.Users, models.UserBusy{UserID: userID, Busy: busy})
+		all = append(all, busy...)
+	}
+
+	if query.SlotDuration > 0 {
+		res.Free = freeSlots(mergeIntervals(all), query)
+	}
+
+	return res, nil
+}
+
+// busy - объединенные занятые промежутки пользователя userID внутри [from; to) в поясе from.
+func (s *calendarService) busy(ctx context.Context, userID int64, from, to time.Time) ([]models.Interval, error) {
+	v, err := s.view(ctx, userID, accessFreeBusy, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Приглашения занимают время участника независимо от того, кто спрашивает.
+	events, err := s.eventsInRange(ctx, infra.ListOptions{Participant: &userID, CalendarIDs: v.calendars}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := from.Location()
+	res := make([]models.Interval, 0, len(events))
+	for _, e := range events {
+		start, end := e.Date, e.EndTime()
+		if !end.After(start) {
+			continue
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		res = append(res, models.Interval{Start: start.In(loc), End: end.In(loc)})
+	}
+
+	return mergeIntervals(res), nil
+}
+
+// mergeIntervals - упорядочивает промежутки по началу и объединяет пересекающиеся и смежные.
+func mergeIntervals(intervals []models.Interval) []models.Interval {
+	slices.SortFunc(intervals, func(a, b models.Interval) int { return a.Start.Compare(b.Start) })
+
+	res := make([]models.Interval, 0, len(intervals))
+	for _, in := range intervals {
+		if n := len(res); n > 0 && !in.Start.After(res[n-1].End) {
+			if in.End.After(res[n-1].End) {
+				res[n-1].End = in.End
+			}
+			continue
+		}
+		res = append(res, in)
+	}
+
+	return res
+}
+
+// freeSlots - свободные от busy промежутки не короче query.SlotDuration в рабочие часы каждого дня
+// периода. busy должны быть упорядочены и объединены. Рабочие часы считаются по стенным часам
+// пояса From, поэтому в дни перехода на летнее время окно сохраняет показания часов.
+func freeSlots(busy []models.Interval, query models.FreeBusyQuery) []models.Interval {
+	loc := query.From.Location()
+	res := make([]models.Interval, 0)
+
+	i := 0
+	for day := startOfDay(query.From); day.Before(query.To); day = day.AddDate(0, 0, 1) {
+		if !query.Weekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, int(query.WorkStart), loc)
+		end := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, int(query.WorkEnd), loc)
+		if start.Before(query.From) {
+			start = query.From
+		}
+		if end.After(query.To) {
+			end = query.To
+		}
+
+		for i < len(busy) && !busy[i].End.After(start) {
+			i++
+		}
+
+		cursor := start
+		for j := i; j < len(busy) && busy[j].Start.Before(end); j++ {
+			if busy[j].Start.Sub(cursor) >= query.SlotDuration {
+				res = append(res, models.Interval{Start: cursor, End: busy[j].Start})
+			}
+			if busy[j].End.After(cursor) {
+				cursor = busy[j].End
+			}
+		}
+		if end.Sub(cursor) >= query.SlotDuration {
+			res = append(res, models.Interval{Start: cursor, End: end})
+		}
+	}
+
+	return res
+}
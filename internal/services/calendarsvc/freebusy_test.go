@@ -0,0 +1,113 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func TestFreeBusy(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	monday := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return monday.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+
+	create := func(event models.Event) string {
+		t.Helper()
+		id, err := svc.CreateEvent(ctx, event)
+		require.NoError(t, err)
+		return id
+	}
+
+	create(models.Event{UserID: 1, Date: at(10, 0), End: at(11, 0), Text: "ревью"})
+	create(models.Event{UserID: 1, Date: at(10, 30), End: at(12, 0), Text: "архитектура"})
+	create(models.Event{UserID: 1, Date: at(12, 30), Text: "без длительности"})
+	create(models.Event{UserID: 1, Date: at(15, 0), End: at(15, 30), Text: "стендап", RRule: "FREQ=DAILY;COUNT=3"})
+	create(models.Event{UserID: 3, Date: at(13, 0), End: at(14, 0), Text: "встреча", Attendees: []models.Attendee{{UserID: 2}}})
+	declined := create(models.Event{UserID: 3, Date: at(16, 0), End: at(17, 0), Text: "отказ", Attendees: []models.Attendee{{UserID: 2}}})
+	require.NoError(t, svc.RespondEvent(ctx, declined, 2, models.AttendeeDeclined))
+
+	fb, err := svc.FreeBusy(ctx, models.FreeBusyQuery{UserIDs: []int64{1, 2, 1}, From: monday, To: monday.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	assert.Nil(t, fb.Free)
+	assert.Equal(t, []models.UserBusy{
+		{UserID: 1, Busy: []models.Interval{{Start: at(10, 0), End: at(12, 0)}, {Start: at(15, 0), End: at(15, 30)}}},
+		{UserID: 2, Busy: []models.Interval{{Start: at(13, 0), End: at(14, 0)}}},
+	}, fb.Users)
+
+	// Общие свободные промежутки от часа в рабочие часы 09:00-18:00.
+	query := models.FreeBusyQuery{
+		UserIDs:      []int64{1, 2},
+		From:         monday,
+		To:           monday.AddDate(0, 0, 1),
+		SlotDuration: time.Hour,
+		WorkStart:    9 * time.Hour,
+		WorkEnd:      18 * time.Hour,
+	}
+	fb, err = svc.FreeBusy(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Interval{
+		{Start: at(9, 0), End: at(10, 0)},
+		{Start: at(12, 0), End: at(13, 0)},
+		{Start: at(14, 0), End: at(15, 0)},
+		{Start: at(15, 30), End: at(18, 0)},
+	}, fb.Free)
+
+	query.SlotDuration = 90 * time.Minute
+	fb, err = svc.FreeBusy(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Interval{{Start: at(15, 30), End: at(18, 0)}}, fb.Free)
+
+	// Выходные пропускаются, если не запрошены явно.
+	query.From, query.To = monday.AddDate(0, 0, 5), monday.AddDate(0, 0, 7)
+	fb, err = svc.FreeBusy(ctx, query)
+	require.NoError(t, err)
+	assert.Empty(t, fb.Free)
+
+	query.Weekends = true
+	fb, err = svc.FreeBusy(ctx, query)
+	require.NoError(t, err)
+	assert.Len(t, fb.Free, 2)
+
+	_, err = svc.FreeBusy(ctx, models.FreeBusyQuery{From: monday, To: monday.AddDate(0, 0, 1)})
+	assert.ErrorIs(t, err, errUsers)
+	query.WorkStart, query.WorkEnd = 18*time.Hour, 9*time.Hour
+	_, err = svc.FreeBusy(ctx, query)
+	assert.ErrorIs(t, err, errWorkHours)
+}
+
+func TestFreeBusyAccess(t *testing.T) {
+	svc := newSvc(t)
+	day := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+
+	owner := auth.WithUser(context.Background(), 1)
+	colleague := auth.WithUser(context.Background(), 2)
+
+	private, err := svc.CreateCalendar(owner, models.Calendar{UserID: 1, Name: "Личное", Visibility: models.VisibilityPrivate})
+	require.NoError(t, err)
+	_, err = svc.CreateEvent(owner, models.Event{UserID: 1, Date: day, End: day.Add(time.Hour), Text: "работа"})
+	require.NoError(t, err)
+	_, err = svc.CreateEvent(owner, models.Event{UserID: 1, CalendarID: private.ID, Date: day.Add(2 * time.Hour), End: day.Add(3 * time.Hour), Text: "врач"})
+	require.NoError(t, err)
+
+	query := models.FreeBusyQuery{UserIDs: []int64{1}, From: day.Add(-time.Hour), To: day.Add(5 * time.Hour)}
+
+	_, err = svc.FreeBusy(colleague, query)
+	require.ErrorIs(t, err, auth.ErrForbidden)
+
+	fb, err := svc.FreeBusy(owner, query)
+	require.NoError(t, err)
+	assert.Len(t, fb.Users[0].Busy, 2)
+
+	// Доступа free_busy достаточно, приватный календарь не раскрывается.
+	require.NoError(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 2, Role: models.ShareFreeBusy}))
+	fb, err = svc.FreeBusy(colleague, query)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Interval{{Start: day, End: day.Add(time.Hour)}}, fb.Users[0].Busy)
+}
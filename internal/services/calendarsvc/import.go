@@ -0,0 +1,139 @@
+package calendarsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// ImportEvents - импортирует события пользователя (например, из iCalendar).
+// Обычные события и серии создаются через CreateEvent и дедуплицируются по iCal UID,
+// измененные вхождения (с RecurrenceID) применяются к уже импортированной серии.
+// Результаты возвращаются в порядке входных событий.
+func (s *calendarService) ImportEvents(
+	ctx context.Context,
+	userID int64,
+	events []models.Event,
+) ([]models.ImportResult, error) {
+	if userID <= 0 {
+		return nil, errUserID
+	}
+	if _, err := s.authorize(ctx, userID, accessWrite); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.ImportResult, len(events))
+
+	// Сначала серии и обычные события, чтобы исключениям было к чему привязаться.
+	for _, exceptions := range []bool{false, true} {
+		for i, event := range events {
+			if (event.RecurrenceID != nil) != exceptions {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			event.UserID = userID
+			res := models.ImportResult{UID: event.ICalUID}
+
+			var err error
+			if exceptions {
+				res.EventID, res.Status, err = s.importException(ctx, event)
+			} else {
+				res.EventID, err = s.CreateEvent(ctx, event)
+				res.Status = models.ImportCreated
+				if errors.Is(err, errDuplicate) {
+					res.Status, err = models.ImportDuplicate, nil
+				}
+			}
+			if err != nil {
+				res.Status = models.ImportFailed
+				res.Error = err.Error()
+			}
+
+			results[i] = res
+		}
+	}
+
+	return results, nil
+}
+
+// importException - применяет импортированное измененное вхождение к серии с тем же UID.
+func (s *calendarService) importException(ctx context.Context, event models.Event) (string, models.ImportStatus, error) {
+	if event.Text == "" {
+		return "", "", errEmptyEvent
+	}
+
+	series, err := s.findByICalUID(ctx, event.UserID, event.ICalUID)
+	if err != nil {
+		return "", "", err
+	}
+	if series == nil || series.RRule == "" {
+		return "", "", errNoSeries
+	}
+
+	if err := s.applyTimeZone(ctx, &event, series.TimeZone); err != nil {
+		return "", "", err
+	}
+	if err := normalizeTimes(&event); err != nil {
+		return "", "", err
+	}
+
+	occ := event.RecurrenceID.In(series.Date.Location())
+	if _, _, err := s.checkOccurrence(series, &occ); err != nil {
+		return "", "", err
+	}
+
+	exceptions, err := s.exceptions(ctx, series.ID)
+	if err != nil {
+		return "", "", err
+	}
+	for _, ex := range exceptions {
+		if ex.RecurrenceID == nil || !ex.RecurrenceID.Equal(occ) {
+			continue
+		}
+		if ex.Date.Equal(event.Date) && ex.Text == event.Text {
+			return ex.ID, models.ImportDuplicate, nil
+		}
+		if err := s.updateOccurrence(ctx, series, event, occ); err != nil {
+			return "", "", err
+		}
+		return ex.ID, models.ImportUpdated, nil
+	}
+
+	if err := s.updateOccurrence(ctx, series, event, occ); err != nil {
+		return "", "", err
+	}
+
+	exceptions, err = s.exceptions(ctx, series.ID)
+	if err != nil {
+		return "", "", err
+	}
+	for _, ex := range exceptions {
+		if ex.RecurrenceID != nil && ex.RecurrenceID.Equal(occ) {
+			return ex.ID, models.ImportCreated, nil
+		}
+	}
+
+	return "", models.ImportCreated, nil
+}
+
+// findByICalUID - ищет событие или серию пользователя с данным iCal UID (без исключений серии).
+func (s *calendarService) findByICalUID(ctx context.Context, userID int64, uid string) (*models.Event, error) {
+	list, err := s.repo.List(ctx, &infra.ListOptions{UserID: &userID, ICalUID: &uid})
+	if err != nil {
+		return nil, fmt.Errorf("repo.List: %w", err)
+	}
+
+	for i := range list {
+		if list[i].RecurrenceID == nil {
+			return &list[i], nil
+		}
+	}
+
+	return nil, nil
+}
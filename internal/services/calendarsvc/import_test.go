@@ -0,0 +1,68 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func TestImportEvents(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+
+	start := time.Date(2025, 1, 6, 10, 0, 0, 0, time.Local)
+	occ := start.AddDate(0, 0, 7)
+	events := []models.Event{
+		{ICalUID: "exception@x", Date: occ.Add(time.Hour), Text: "moved", RecurrenceID: &occ},
+		{ICalUID: "series@x", Date: start, Text: "standup", RRule: "FREQ=WEEKLY"},
+		{ICalUID: "single@x", Date: start.Add(2 * time.Hour), Text: "lunch"},
+		{ICalUID: "empty@x", Date: start},
+	}
+	events[0].ICalUID = "series@x"
+
+	results, err := svc.ImportEvents(ctx, 1, events)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, models.ImportCreated, results[0].Status)
+	assert.Equal(t, models.ImportCreated, results[1].Status)
+	assert.Equal(t, models.ImportCreated, results[2].Status)
+	assert.Equal(t, models.ImportFailed, results[3].Status)
+	assert.NotEmpty(t, results[3].Error)
+
+	month, err := svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	assert.Len(t, month, 5)
+
+	again, err := svc.ImportEvents(ctx, 1, events)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportDuplicate, again[0].Status)
+	assert.Equal(t, results[0].EventID, again[0].EventID)
+	assert.Equal(t, models.ImportDuplicate, again[1].Status)
+	assert.Equal(t, results[1].EventID, again[1].EventID)
+	assert.Equal(t, models.ImportDuplicate, again[2].Status)
+
+	month, err = svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	assert.Len(t, month, 5)
+
+	other, err := svc.ImportEvents(ctx, 2, events[1:3])
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportCreated, other[0].Status)
+}
+
+func TestImportExceptionWithoutSeries(t *testing.T) {
+	svc := newSvc(t)
+	occ := time.Date(2025, 1, 13, 10, 0, 0, 0, time.Local)
+
+	results, err := svc.ImportEvents(context.Background(), 1, []models.Event{
+		{ICalUID: "missing@x", Date: occ, Text: "orphan", RecurrenceID: &occ},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, models.ImportFailed, results[0].Status)
+}
@@ -0,0 +1,41 @@
+package calendarsvc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// save - сохраняет измененное событие и публикует event.updated.
+func (s *calendarService) save(ctx context.Context, data *models.Event) error {
+	if err := s.repo.Update(ctx, data); err != nil {
+		return err
+	}
+
+	return s.publish(ctx, models.EventUpdated, data)
+}
+
+// remove - удаляет событие и публикует event.deleted с его последним состоянием.
+func (s *calendarService) remove(ctx context.Context, event *models.Event) error {
+	if _, err := s.repo.Delete(ctx, event.ID); err != nil {
+		return fmt.Errorf("repo.Delete: %w", err)
+	}
+
+	return s.publish(ctx, models.EventDeleted, event)
+}
+
+// publish - публикует изменение события в топик calendar.events.
+// Подписчики (например, сервис напоминаний) берут актуальное состояние события из БД.
+func (s *calendarService) publish(ctx context.Context, typ models.EnvelopeType, event *models.Event) error {
+	env, err := models.NewEnvelope(typ, event)
+	if err != nil {
+		return err
+	}
+
+	if err := s.broker.Publish(ctx, models.TopicEvents, env); err != nil {
+		return fmt.Errorf("broker.Publish: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// recordingBroker - брокер, запоминающий опубликованные конверты.
+type recordingBroker struct {
+	published []models.Envelope
+}
+
+func (b *recordingBroker) Publish(_ context.Context, topic models.Topic, env models.Envelope) error {
+	if topic == models.TopicEvents {
+		b.published = append(b.published, env)
+	}
+	return nil
+}
+
+func (b *recordingBroker) Subscribe(context.Context, models.Topic, string, infra.Handler) error {
+	return nil
+}
+
+// changes - типы и ID событий из опубликованных конвертов.
+func (b *recordingBroker) changes(t *testing.T) []string {
+	t.Helper()
+
+	res := make([]string, 0, len(b.published))
+	for _, env := range b.published {
+		var event models.Event
+		require.NoError(t, env.Decode(&event))
+		assert.Equal(t, models.EnvelopeVersion, env.Version)
+		assert.False(t, env.OccurredAt.IsZero())
+		res = append(res, string(env.Type)+" "+event.ID)
+	}
+
+	return res
+}
+
+func TestLifecyclePublishes(t *testing.T) {
+	svc := newSvc(t)
+	broker := &recordingBroker{}
+	svc.broker = broker
+	ctx := context.Background()
+	day := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	plain, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day, Text: "без напоминаний"})
+	require.NoError(t, err)
+	id, err := svc.CreateEvent(ctx, models.Event{
+		UserID:    1,
+		Date:      day,
+		Text:      "с напоминанием",
+		Reminders: []models.Reminder{{Offset: 0}},
+	})
+	require.NoError(t, err)
+
+	// Снятие напоминаний тоже публикуется, чтобы сервис напоминаний их отменил.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{
+		ID:        id,
+		UserID:    1,
+		Date:      day,
+		Text:      "с напоминанием",
+		Reminders: []models.Reminder{},
+	}, models.EditScopeDefault))
+	require.NoError(t, svc.DeleteEvent(ctx, id, models.EditScopeDefault))
+	require.NoError(t, svc.DeleteEvent(ctx, plain, models.EditScopeDefault))
+
+	assert.Equal(t, []string{
+		"event.created " + plain,
+		"event.created " + id,
+		"event.updated " + id,
+		"event.deleted " + id,
+		"event.deleted " + plain,
+	}, broker.changes(t))
+}
+
+func TestSeriesLifecyclePublishes(t *testing.T) {
+	svc := newSvc(t)
+	broker := &recordingBroker{}
+	svc.broker = broker
+	ctx := context.Background()
+	start := time.Date(2025, 7, 7, 9, 0, 0, 0, time.UTC)
+
+	id, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: start, Text: "daily", RRule: "FREQ=DAILY;COUNT=5"})
+	require.NoError(t, err)
+
+	occ := start.AddDate(0, 0, 1)
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{
+		ID:           id,
+		UserID:       1,
+		Date:         occ.Add(time.Hour),
+		Text:         "moved",
+		RecurrenceID: &occ,
+	}, models.EditScopeThis))
+
+	exceptions, err := svc.exceptions(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, exceptions, 1)
+
+	require.NoError(t, svc.DeleteEvent(ctx, id, models.EditScopeAll))
+
+	assert.Equal(t, []string{
+		"event.created " + id,
+		"event.updated " + id,
+		"event.created " + exceptions[0].ID,
+		"event.deleted " + exceptions[0].ID,
+		"event.deleted " + id,
+	}, broker.changes(t))
+}
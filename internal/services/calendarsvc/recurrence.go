@@ -0,0 +1,434 @@
+package calendarsvc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/internal/rrule"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// instanceLayout - формат времени вхождения в ID экземпляра серии.
+const instanceLayout = "20060102T150405Z"
+
+// instanceID - ID вхождения серии: "<series_id>_<начало вхождения в UTC>".
+func instanceID(seriesID string, occ time.Time) string {
+	return seriesID + "_" + occ.UTC().Format(instanceLayout)
+}
+
+// parseInstanceID - разбирает ID вхождения серии.
+func parseInstanceID(id string) (string, time.Time, bool) {
+	idx := strings.LastIndex(id, "_")
+	if idx <= 0 {
+		return "", time.Time{}, false
+	}
+
+	occ, err := time.ParseInLocation(instanceLayout, id[idx+1:], time.UTC)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	return id[:idx], occ, true
+}
+
+// resolveTarget - находит сохраненное событие по ID (в т.ч. по ID вхождения серии).
+// Для вхождения возвращает саму серию и начало вхождения в часовом поясе серии.
+// Событие, которое вызывающий не может изменять (в т.ч. из-за видимости его календаря),
+// отклоняется с auth.ErrForbidden.
+func (s *calendarService) resolveTarget(
+	ctx context.Context,
+	eventID string,
+	recurrenceID *time.Time,
+) (*models.Event, *time.Time, error) {
+	if seriesID, occ, ok := parseInstanceID(eventID); ok {
+		series, err := s.repo.Read(ctx, seriesID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("repo.Read: %w", err)
+		}
+		if _, err := s.authorizeCalendar(ctx, series.UserID, series.CalendarID); err != nil {
+			return nil, nil, err
+		}
+		occ = occ.In(series.Date.Location())
+		return s.checkOccurrence(series, &occ)
+	}
+
+	event, err := s.repo.Read(ctx, eventID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("repo.Read: %w", err)
+	}
+	if _, err := s.authorizeCalendar(ctx, event.UserID, event.CalendarID); err != nil {
+		return nil, nil, err
+	}
+
+	if event.RRule == "" || recurrenceID == nil {
+		return event, nil, nil
+	}
+
+	return s.checkOccurrence(event, recurrenceID)
+}
+
+func (s *calendarService) checkOccurrence(series *models.Event, occ *time.Time) (*models.Event, *time.Time, error) {
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rrule.Parse: %w", err)
+	}
+	if !rule.Occurs(series.Date, *occ, nil) {
+		return nil, nil, errOccurrence
+	}
+
+	return series, occ, nil
+}
+
+// resolveScope - область изменения по умолчанию: вхождение для экземпляра, серия целиком иначе.
+func resolveScope(scope models.EditScope, occ *time.Time) (models.EditScope, error) {
+	switch scope {
+	case models.EditScopeDefault:
+		if occ != nil {
+			return models.EditScopeThis, nil
+		}
+		return models.EditScopeAll, nil
+	case models.EditScopeAll:
+		return scope, nil
+	case models.EditScopeThis, models.EditScopeFollowing:
+		if occ == nil {
+			return "", errOccurrence
+		}
+		return scope, nil
+	default:
+		return "", errScope
+	}
+}
+
+// expandSeries - разворачивает серию во вхождения, пересекающиеся с [from; to).
+func (s *calendarService) expandSeries(series models.Event, from, to time.Time) []models.Event {
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		s.logger.Warn("некорректное правило повторения у серии",
+			zap.String("service", "calendar"),
+			zap.String("event_id", series.ID),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	duration := series.Duration()
+	occs := rule.Between(series.Date, from.Add(-duration), to, series.ExDates)
+	res := make([]models.Event, 0, len(occs))
+	for _, occ := range occs {
+		instance := series
+		instance.ID = instanceID(series.ID, occ)
+		instance.Date = occ
+		instance.End = occ.Add(duration)
+		if !instance.Overlaps(from, to) {
+			continue
+		}
+		instance.SeriesID = series.ID
+		instance.ExDates = nil
+		recurrenceID := occ
+		instance.RecurrenceID = &recurrenceID
+		res = append(res, instance)
+	}
+
+	return res
+}
+
+// instance - вхождение серии, начинающееся в occ: сохраненное исключение, если вхождение
+// изменено, иначе развернутый экземпляр. models.ErrNotFound, если такого вхождения нет.
+func (s *calendarService) instance(ctx context.Context, series models.Event, occ time.Time) (models.Event, error) {
+	if series.RRule == "" {
+		return models.Event{}, fmt.Errorf("%w: %s", models.ErrNotFound, instanceID(series.ID, occ))
+	}
+
+	exceptions, err := s.exceptions(ctx, series.ID)
+	if err != nil {
+		return models.Event{}, err
+	}
+	for _, e := range exceptions {
+		if e.RecurrenceID != nil && e.RecurrenceID.Equal(occ) {
+			return e, nil
+		}
+	}
+
+	for _, instance := range s.expandSeries(series, occ, occ.Add(time.Nanosecond)) {
+		if instance.Date.Equal(occ) {
+			return instance, nil
+		}
+	}
+
+	return models.Event{}, fmt.Errorf("%w: %s", models.ErrNotFound, instanceID(series.ID, occ))
+}
+
+// exceptions - сохраненные измененные вхождения серии.
+func (s *calendarService) exceptions(ctx context.Context, seriesID string) ([]models.Event, error) {
+	list, err := s.repo.List(ctx, &infra.ListOptions{SeriesID: &seriesID})
+	if err != nil {
+		return nil, fmt.Errorf("repo.List: %w", err)
+	}
+
+	return list, nil
+}
+
+// updateSeries - изменение серии целиком. Если редактируется вхождение,
+// серия сдвигается на ту же величину, на которую сдвинуто вхождение.
+func (s *calendarService) updateSeries(ctx context.Context, series *models.Event, event models.Event, occ *time.Time) error {
+	newStart := event.Date
+	if occ != nil {
+		newStart = series.Date.Add(event.Date.Sub(*occ))
+	}
+
+	shift := newStart.Sub(series.Date)
+	if shift != 0 {
+		series.ExDates = shiftTimes(series.ExDates, shift)
+	}
+	series.Reminders = mergeReminders(series.Reminders, event.Reminders, shift != 0)
+	series.Date = newStart
+	series.End = newStart.Add(event.Duration())
+	series.AllDay = event.AllDay
+	series.TimeZone = event.TimeZone
+	series.Channels = event.Channels
+	series.Text = event.Text
+	series.CalendarID = event.CalendarID
+	series.Attendees = event.Attendees
+	if event.RRule != "" {
+		series.RRule = event.RRule
+	}
+
+	return s.save(ctx, series)
+}
+
+// updateOccurrence - изменение одного вхождения: вхождение исключается из серии
+// и сохраняется отдельным событием-исключением.
+func (s *calendarService) updateOccurrence(ctx context.Context, series *models.Event, event models.Event, occ time.Time) error {
+	exceptions, err := s.exceptions(ctx, series.ID)
+	if err != nil {
+		return err
+	}
+	for i := range exceptions {
+		if exceptions[i].RecurrenceID != nil && exceptions[i].RecurrenceID.Equal(occ) {
+			moved := !exceptions[i].Date.Equal(event.Date)
+			exceptions[i].Reminders = mergeReminders(exceptions[i].Reminders, event.Reminders, moved)
+			exceptions[i].Date = event.Date
+			exceptions[i].End = event.End
+			exceptions[i].AllDay = event.AllDay
+			exceptions[i].TimeZone = event.TimeZone
+			exceptions[i].Channels = event.Channels
+			exceptions[i].Text = event.Text
+			exceptions[i].CalendarID = event.CalendarID
+			exceptions[i].Attendees = event.Attendees
+			return s.save(ctx, &exceptions[i])
+		}
+	}
+
+	exception := &models.Event{
+		ID:           uuid.NewString(),
+		UserID:       series.UserID,
+		CalendarID:   event.CalendarID,
+		Attendees:    event.Attendees,
+		Date:         event.Date,
+		End:          event.End,
+		AllDay:       event.AllDay,
+		TimeZone:     event.TimeZone,
+		Text:         event.Text,
+		Reminders:    inheritReminders(series, event),
+		Channels:     event.Channels,
+		SeriesID:     series.ID,
+		RecurrenceID: &occ,
+		ICalUID:      series.ICalUID,
+	}
+	if err := s.repo.Create(ctx, exception); err != nil {
+		return fmt.Errorf("repo.Create: %w", err)
+	}
+
+	series.ExDates = append(series.ExDates, occ)
+	if err := s.repo.Update(ctx, series); err != nil {
+		return fmt.Errorf("repo.Update: %w", err)
+	}
+	if err := s.publish(ctx, models.EventUpdated, series); err != nil {
+		return err
+	}
+
+	return s.publish(ctx, models.EventCreated, exception)
+}
+
+// updateFollowing - изменение вхождения и всех последующих:
+// исходная серия обрезается перед вхождением, с него начинается новая серия.
+func (s *calendarService) updateFollowing(ctx context.Context, series *models.Event, event models.Event, occ time.Time) error {
+	if occ.Equal(series.Date) {
+		return s.updateSeries(ctx, series, event, &occ)
+	}
+
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return fmt.Errorf("rrule.Parse: %w", err)
+	}
+
+	newRule := *rule
+	if event.RRule != "" {
+		parsed, err := rrule.Parse(event.RRule)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errRRule, err)
+		}
+		newRule = *parsed
+	} else if rule.Count > 0 {
+		newRule.Count = rule.Count - rule.CountBefore(series.Date, occ)
+	}
+
+	// Само вхождение occ становится первым в новой серии, поэтому его EXDATE не переносится.
+	shift := event.Date.Sub(occ)
+	var exdates []time.Time
+	for _, ex := range series.ExDates {
+		if ex.After(occ) {
+			exdates = append(exdates, ex.Add(shift))
+		}
+	}
+
+	newSeries := &models.Event{
+		ID:         uuid.NewString(),
+		UserID:     series.UserID,
+		CalendarID: event.CalendarID,
+		Attendees:  event.Attendees,
+		Date:       event.Date,
+		End:        event.End,
+		AllDay:     event.AllDay,
+		TimeZone:   event.TimeZone,
+		Text:       event.Text,
+		Reminders:  inheritReminders(series, event),
+		Channels:   event.Channels,
+		RRule:      newRule.String(),
+		ExDates:    exdates,
+	}
+	if err := s.repo.Create(ctx, newSeries); err != nil {
+		return fmt.Errorf("repo.Create: %w", err)
+	}
+
+	// Измененные вхождения после occ переходят в новую серию со сдвигом, как и ее EXDATE;
+	// исключение самого occ заменяется новой серией и удаляется вместе с обрезкой.
+	exceptions, err := s.exceptions(ctx, series.ID)
+	if err != nil {
+		return err
+	}
+	for i := range exceptions {
+		if exceptions[i].RecurrenceID == nil || !exceptions[i].RecurrenceID.After(occ) {
+			continue
+		}
+		recurrenceID := exceptions[i].RecurrenceID.Add(shift)
+		exceptions[i].SeriesID = newSeries.ID
+		exceptions[i].RecurrenceID = &recurrenceID
+		if err := s.save(ctx, &exceptions[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.truncateSeries(ctx, series, rule, occ); err != nil {
+		return err
+	}
+
+	return s.publish(ctx, models.EventCreated, newSeries)
+}
+
+// deleteOccurrence - удаление одного вхождения через EXDATE.
+func (s *calendarService) deleteOccurrence(ctx context.Context, series *models.Event, occ time.Time) error {
+	exceptions, err := s.exceptions(ctx, series.ID)
+	if err != nil {
+		return err
+	}
+	for _, ex := range exceptions {
+		if ex.RecurrenceID != nil && ex.RecurrenceID.Equal(occ) {
+			if err := s.remove(ctx, &ex); err != nil {
+				return err
+			}
+		}
+	}
+
+	series.ExDates = append(series.ExDates, occ)
+	if err := s.repo.Update(ctx, series); err != nil {
+		return fmt.Errorf("repo.Update: %w", err)
+	}
+
+	return s.publish(ctx, models.EventUpdated, series)
+}
+
+// deleteFollowing - удаление вхождения и всех последующих.
+func (s *calendarService) deleteFollowing(ctx context.Context, series *models.Event, occ time.Time) error {
+	if occ.Equal(series.Date) {
+		return s.deleteSeries(ctx, series)
+	}
+
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return fmt.Errorf("rrule.Parse: %w", err)
+	}
+
+	return s.truncateSeries(ctx, series, rule, occ)
+}
+
+// deleteSeries - удаление серии вместе со всеми исключениями.
+func (s *calendarService) deleteSeries(ctx context.Context, series *models.Event) error {
+	exceptions, err := s.exceptions(ctx, series.ID)
+	if err != nil {
+		return err
+	}
+	for _, ex := range exceptions {
+		if err := s.remove(ctx, &ex); err != nil {
+			return err
+		}
+	}
+
+	return s.remove(ctx, series)
+}
+
+// truncateSeries - завершает серию перед вхождением occ и удаляет исключения начиная с него.
+func (s *calendarService) truncateSeries(ctx context.Context, series *models.Event, rule *rrule.Rule, occ time.Time) error {
+	truncated := *rule
+	if rule.Count > 0 {
+		truncated.Count = rule.CountBefore(series.Date, occ)
+	} else {
+		until := occ.Add(-time.Second).UTC()
+		truncated.Until = &until
+	}
+	series.RRule = truncated.String()
+
+	exdates := series.ExDates[:0]
+	for _, ex := range series.ExDates {
+		if ex.Before(occ) {
+			exdates = append(exdates, ex)
+		}
+	}
+	series.ExDates = exdates
+
+	if err := s.repo.Update(ctx, series); err != nil {
+		return fmt.Errorf("repo.Update: %w", err)
+	}
+	if err := s.publish(ctx, models.EventUpdated, series); err != nil {
+		return err
+	}
+
+	exceptions, err := s.exceptions(ctx, series.ID)
+	if err != nil {
+		return err
+	}
+	for _, ex := range exceptions {
+		if ex.RecurrenceID != nil && !ex.RecurrenceID.Before(occ) {
+			if err := s.remove(ctx, &ex); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func shiftTimes(times []time.Time, shift time.Duration) []time.Time {
+	res := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		res = append(res, t.Add(shift))
+	}
+
+	return res
+}
@@ -0,0 +1,234 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func createWeeklyMondays(t *testing.T, svc *calendarService, rule string) (string, time.Time) {
+	t.Helper()
+
+	start := time.Date(2025, 1, 6, 10, 0, 0, 0, time.Local) // понедельник
+	id, err := svc.CreateEvent(context.Background(), models.Event{
+		UserID: 1,
+		Date:   start,
+		Text:   "standup",
+		RRule:  rule,
+	})
+	require.NoError(t, err)
+
+	return id, start
+}
+
+func textsByDay(events []models.Event) map[int]string {
+	res := make(map[int]string, len(events))
+	for _, e := range events {
+		res[e.Date.Day()] = e.Text
+	}
+	return res
+}
+
+func TestRecurringExpansion(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	id, start := createWeeklyMondays(t, svc, "FREQ=WEEKLY;BYDAY=MO")
+
+	events, err := svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, map[int]string{6: "standup", 13: "standup", 20: "standup", 27: "standup"}, textsByDay(events))
+	for _, e := range events {
+		assert.Equal(t, id, e.SeriesID)
+		require.NotNil(t, e.RecurrenceID)
+		assert.Equal(t, instanceID(id, e.Date), e.ID)
+	}
+
+	day, err := svc.GetEventsForDay(ctx, 1, start.AddDate(0, 2, 0).AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Empty(t, day)
+
+	week, err := svc.GetEventsForWeek(ctx, 1, start.AddDate(1, 0, 3))
+	require.NoError(t, err)
+	assert.Len(t, week, 1)
+}
+
+func TestRecurringUpdateThis(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	id, start := createWeeklyMondays(t, svc, "FREQ=WEEKLY")
+
+	occ := start.AddDate(0, 0, 7)
+	err := svc.UpdateEvent(ctx, models.Event{
+		ID:     instanceID(id, occ),
+		UserID: 1,
+		Date:   occ.Add(2 * time.Hour),
+		Text:   "moved standup",
+	}, models.EditScopeDefault)
+	require.NoError(t, err)
+
+	events, err := svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, map[int]string{6: "standup", 13: "moved standup", 20: "standup", 27: "standup"}, textsByDay(events))
+
+	week, err := svc.GetEventsForWeek(ctx, 1, occ)
+	require.NoError(t, err)
+	require.Len(t, week, 1)
+	assert.Equal(t, occ.Add(2*time.Hour), week[0].Date)
+	assert.Equal(t, id, week[0].SeriesID)
+}
+
+func TestRecurringUpdateFollowing(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	id, start := createWeeklyMondays(t, svc, "FREQ=WEEKLY;COUNT=4")
+
+	occ := start.AddDate(0, 0, 14)
+	err := svc.UpdateEvent(ctx, models.Event{
+		ID:     instanceID(id, occ),
+		UserID: 1,
+		Date:   occ,
+		Text:   "new standup",
+	}, models.EditScopeFollowing)
+	require.NoError(t, err)
+
+	events, err := svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{6: "standup", 13: "standup", 20: "new standup", 27: "new standup"}, textsByDay(events))
+
+	feb, err := svc.GetEventsForMonth(ctx, 1, start.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Empty(t, feb)
+}
+
+func TestRecurringUpdateFollowingKeepsLaterExceptions(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	id, start := createWeeklyMondays(t, svc, "FREQ=WEEKLY;BYDAY=MO")
+
+	later := start.AddDate(0, 0, 21)
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{
+		ID:     instanceID(id, later),
+		UserID: 1,
+		Date:   later.Add(time.Hour),
+		Text:   "moved standup",
+	}, models.EditScopeThis))
+
+	occ := start.AddDate(0, 0, 7)
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{
+		ID:     instanceID(id, occ),
+		UserID: 1,
+		Date:   occ,
+		Text:   "new standup",
+	}, models.EditScopeFollowing))
+
+	events, err := svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, map[int]string{6: "standup", 13: "new standup", 20: "new standup", 27: "moved standup"}, textsByDay(events))
+
+	// Исключение относится к новой серии и по-прежнему заменяет свое вхождение.
+	for _, e := range events {
+		if e.Text == "moved standup" {
+			assert.NotEqual(t, id, e.SeriesID)
+			require.NotNil(t, e.RecurrenceID)
+			assert.True(t, later.Equal(*e.RecurrenceID))
+		}
+	}
+}
+
+func TestRecurringUpdateAll(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	id, start := createWeeklyMondays(t, svc, "FREQ=WEEKLY")
+
+	occ := start.AddDate(0, 0, 7)
+	err := svc.UpdateEvent(ctx, models.Event{
+		ID:     instanceID(id, occ),
+		UserID: 1,
+		Date:   occ.Add(time.Hour),
+		Text:   "later standup",
+	}, models.EditScopeAll)
+	require.NoError(t, err)
+
+	events, err := svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	for _, e := range events {
+		assert.Equal(t, "later standup", e.Text)
+		assert.Equal(t, 11, e.Date.Hour())
+	}
+}
+
+func TestRecurringDelete(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	id, start := createWeeklyMondays(t, svc, "FREQ=WEEKLY")
+
+	require.NoError(t, svc.DeleteEvent(ctx, instanceID(id, start.AddDate(0, 0, 7)), models.EditScopeDefault))
+
+	events, err := svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{6: "standup", 20: "standup", 27: "standup"}, textsByDay(events))
+
+	require.NoError(t, svc.DeleteEvent(ctx, instanceID(id, start.AddDate(0, 0, 21)), models.EditScopeFollowing))
+
+	events, err = svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{6: "standup", 20: "standup"}, textsByDay(events))
+
+	require.NoError(t, svc.DeleteEvent(ctx, id, models.EditScopeDefault))
+
+	events, err = svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestRecurringDeleteAllRemovesExceptions(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	id, start := createWeeklyMondays(t, svc, "FREQ=WEEKLY")
+
+	occ := start.AddDate(0, 0, 7)
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{
+		ID:     instanceID(id, occ),
+		UserID: 1,
+		Date:   occ,
+		Text:   "exception",
+	}, models.EditScopeThis))
+
+	require.NoError(t, svc.DeleteEvent(ctx, instanceID(id, start), models.EditScopeAll))
+
+	events, err := svc.GetEventsForMonth(ctx, 1, start)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestRecurringScopeErrors(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	id, start := createWeeklyMondays(t, svc, "FREQ=WEEKLY")
+
+	err := svc.DeleteEvent(ctx, id, models.EditScopeThis)
+	require.ErrorIs(t, err, errOccurrence)
+
+	err = svc.DeleteEvent(ctx, instanceID(id, start.Add(time.Hour)), models.EditScopeDefault)
+	require.ErrorIs(t, err, errOccurrence)
+	// Несуществующее вхождение - отсутствующая запись, а не сбой сервиса.
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 1, Date: start, Text: "x", RRule: "FREQ=SOMETIMES"})
+	require.ErrorIs(t, err, errRRule)
+	require.ErrorIs(t, err, models.ErrInvalid)
+
+	err = svc.UpdateEvent(ctx, models.Event{ID: id, UserID: 1, Date: start, Text: "y"}, "sometimes")
+	require.ErrorIs(t, err, errScope)
+	require.ErrorIs(t, err, models.ErrInvalid)
+	assert.NotErrorIs(t, err, models.ErrNotFound)
+}
@@ -0,0 +1,77 @@
+package calendarsvc
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// maxReminders - максимальное число напоминаний у одного события.
+const maxReminders = 10
+
+// validateReminders - проверяет число напоминаний события.
+func validateReminders(reminders []models.Reminder) error {
+	if len(reminders) > maxReminders {
+		return fmt.Errorf("%w: не больше %d", errReminders, maxReminders)
+	}
+
+	return nil
+}
+
+// normalizeReminders - копия напоминаний без отметок об отправке,
+// отсортированная по смещению (самое раннее - первым) и без повторов.
+func normalizeReminders(reminders []models.Reminder) []models.Reminder {
+	if len(reminders) == 0 {
+		return nil
+	}
+
+	res := make([]models.Reminder, 0, len(reminders))
+	for _, r := range reminders {
+		res = append(res, models.Reminder{Offset: r.Offset})
+	}
+	slices.SortFunc(res, func(a, b models.Reminder) int {
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+
+	return slices.CompactFunc(res, func(a, b models.Reminder) bool {
+		return a.Offset == b.Offset
+	})
+}
+
+// mergeReminders - напоминания события после изменения.
+// nil в updated оставляет текущий набор напоминаний. Отметки об отправке сохраняются
+// для неизменившихся смещений, если начало события не сдвинулось (moved == false).
+func mergeReminders(current, updated []models.Reminder, moved bool) []models.Reminder {
+	if updated == nil {
+		updated = current
+	}
+
+	res := normalizeReminders(updated)
+	if moved {
+		return res
+	}
+
+	for i := range res {
+		for _, r := range current {
+			if r.Offset == res[i].Offset {
+				res[i].SentAt = r.SentAt
+				res[i].Occurrence = r.Occurrence
+				break
+			}
+		}
+	}
+
+	return res
+}
+
+// inheritReminders - напоминания нового события, отделенного от серии:
+// переданные в изменении, иначе напоминания серии. Отметки об отправке не переносятся.
+func inheritReminders(series *models.Event, event models.Event) []models.Reminder {
+	if event.Reminders != nil {
+		return normalizeReminders(event.Reminders)
+	}
+
+	return normalizeReminders(series.Reminders)
+}
@@ -0,0 +1,131 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func TestUpdateReminders(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 5, 6, 10, 0, 0, 0, time.UTC)
+	sentAt := day.Add(-time.Hour)
+
+	id, err := svc.CreateEvent(ctx, models.Event{
+		UserID: 1,
+		Date:   day,
+		Text:   "standup",
+		Reminders: []models.Reminder{
+			{Offset: models.Offset(-time.Hour)},
+			{Offset: models.Offset(-15 * time.Minute)},
+		},
+	})
+	require.NoError(t, err)
+
+	stored, err := svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	stored.Reminders[0].SentAt = &sentAt
+	require.NoError(t, svc.repo.Update(ctx, stored))
+
+	// nil - напоминания и отметки об отправке не меняются.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{ID: id, UserID: 1, Date: day, Text: "standup 2"}, models.EditScopeDefault))
+	stored, err = svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, stored.Reminders, 2)
+	require.NotNil(t, stored.Reminders[0].SentAt)
+	assert.True(t, stored.Reminders[0].SentAt.Equal(sentAt))
+
+	// Новый набор: отметка сохраняется у неизменившегося смещения.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{
+		ID:        id,
+		UserID:    1,
+		Date:      day,
+		Text:      "standup",
+		Reminders: []models.Reminder{{Offset: models.Offset(-time.Hour)}, {Offset: 0}},
+	}, models.EditScopeDefault))
+	stored, err = svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, stored.Reminders, 2)
+	assert.Equal(t, models.Offset(-time.Hour), stored.Reminders[0].Offset)
+	assert.NotNil(t, stored.Reminders[0].SentAt)
+	assert.Equal(t, models.Offset(0), stored.Reminders[1].Offset)
+	assert.Nil(t, stored.Reminders[1].SentAt)
+
+	// Перенос события сбрасывает отметки об отправке.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{ID: id, UserID: 1, Date: day.Add(time.Hour), Text: "standup"}, models.EditScopeDefault))
+	stored, err = svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, stored.Reminders, 2)
+	assert.Nil(t, stored.Reminders[0].SentAt)
+
+	// Пустой список удаляет напоминания.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{
+		ID:        id,
+		UserID:    1,
+		Date:      day,
+		Text:      "standup",
+		Reminders: []models.Reminder{},
+	}, models.EditScopeDefault))
+	stored, err = svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Reminders)
+	assert.False(t, stored.ReminderPending())
+}
+
+func TestOccurrenceInheritsReminders(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	start := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	sentAt := start
+
+	id, err := svc.CreateEvent(ctx, models.Event{
+		UserID:    1,
+		Date:      start,
+		Text:      "daily",
+		RRule:     "FREQ=DAILY;COUNT=5",
+		Reminders: []models.Reminder{{Offset: models.Offset(-10 * time.Minute)}},
+	})
+	require.NoError(t, err)
+
+	series, err := svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	series.Reminders[0].SentAt = &sentAt
+	series.Reminders[0].Occurrence = &start
+	require.NoError(t, svc.repo.Update(ctx, series))
+
+	occ := start.AddDate(0, 0, 2)
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{
+		ID:           id,
+		UserID:       1,
+		Date:         occ.Add(time.Hour),
+		Text:         "moved",
+		RecurrenceID: &occ,
+	}, models.EditScopeThis))
+
+	exceptions, err := svc.exceptions(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, exceptions, 1)
+	assert.Equal(t, []models.Reminder{{Offset: models.Offset(-10 * time.Minute)}}, exceptions[0].Reminders)
+}
+
+func TestTooManyReminders(t *testing.T) {
+	svc := newSvc(t)
+	reminders := make([]models.Reminder, maxReminders+1)
+	for i := range reminders {
+		reminders[i].Offset = models.Offset(-time.Duration(i) * time.Minute)
+	}
+
+	_, err := svc.CreateEvent(context.Background(), models.Event{
+		UserID:    1,
+		Date:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Text:      "x",
+		Reminders: reminders,
+	})
+	require.ErrorIs(t, err, errReminders)
+}
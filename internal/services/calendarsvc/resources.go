@@ -0,0 +1,239 @@
+package calendarsvc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+const (
+	// maxResourceName - максимальная длина названия ресурса в символах.
+	maxResourceName = 100
+	// maxEventResources - максимальное число ресурсов, которые занимает одно событие.
+	maxEventResources = 10
+)
+
+// validateResource - проверяет и нормализует поля ресурса.
+func validateResource(res *models.Resource) error {
+	res.Name = strings.TrimSpace(res.Name)
+	if res.Name == "" || utf8.RuneCountInString(res.Name) > maxResourceName {
+		return errResourceName
+	}
+	if res.Kind == "" {
+		res.Kind = models.ResourceRoom
+	}
+	if !res.Kind.Valid() {
+		return errResourceKind
+	}
+	if res.Capacity < 0 {
+		return errCapacity
+	}
+	if res.Policy.MaxDuration < 0 || res.Policy.Horizon < 0 {
+		return errBookingPolicy
+	}
+	res.Location = strings.TrimSpace(res.Location)
+
+	return nil
+}
+
+// CreateResource - создает ресурс; вызывающий становится его администратором.
+func (s *calendarService) CreateResource(ctx context.Context, res models.Resource) (models.Resource, error) {
+	if res.UserID <= 0 {
+		return models.Resource{}, errUserID
+	}
+	if err := auth.Authorize(ctx, res.UserID); err != nil {
+		return models.Resource{}, err
+	}
+	if err := validateResource(&res); err != nil {
+		return models.Resource{}, err
+	}
+
+	res.ID = uuid.NewString()
+	res.CreatedAt = time.Now()
+	if err := s.resources.Create(ctx, &res); err != nil {
+		return models.Resource{}, fmt.Errorf("resources.Create: %w", err)
+	}
+
+	s.logger.Info("создан ресурс",
+		zap.String("service", "calendar"),
+		zap.String("op", "CreateResource"),
+		zap.Int64("user_id", res.UserID),
+		zap.String("resource_id", res.ID),
+	)
+
+	return res, nil
+}
+
+// UpdateResource - заменяет название, вид, вместимость, расположение и правила бронирования ресурса.
+// Уже сделанные брони не перепроверяются. false - у пользователя нет такого ресурса.
+func (s *calendarService) UpdateResource(ctx context.Context, res models.Resource) (bool, error) {
+	if res.ID == "" {
+		return false, errResource
+	}
+	if res.UserID <= 0 {
+		return false, errUserID
+	}
+	if err := auth.Authorize(ctx, res.UserID); err != nil {
+		return false, err
+	}
+	if err := validateResource(&res); err != nil {
+		return false, err
+	}
+
+	stored, err := s.resources.Get(ctx, res.ID)
+	if err != nil {
+		return false, fmt.Errorf("resources.Get: %w", err)
+	}
+	if stored == nil || stored.UserID != res.UserID {
+		return false, nil
+	}
+
+	res.CreatedAt = stored.CreatedAt
+	if err := s.resources.Update(ctx, &res); err != nil {
+		return false, fmt.Errorf("resources.Update: %w", err)
+	}
+
+	return true, nil
+}
+
+// DeleteResource - удаляет ресурс и снимает его брони: события остаются, но больше его не занимают,
+// по каждому публикуется event.updated. false - у пользователя нет такого ресурса.
+func (s *calendarService) DeleteResource(ctx context.Context, userID int64, resourceID string) (bool, error) {
+	if resourceID == "" {
+		return false, errResource
+	}
+	if userID <= 0 {
+		return false, errUserID
+	}
+	if err := auth.Authorize(ctx, userID); err != nil {
+		return false, err
+	}
+
+	stored, err := s.resources.Get(ctx, resourceID)
+	if err != nil {
+		return false, fmt.Errorf("resources.Get: %w", err)
+	}
+	if stored == nil || stored.UserID != userID {
+		return false, nil
+	}
+
+	events, err := s.repo.List(ctx, &infra.ListOptions{ResourceID: &resourceID})
+	if err != nil {
+		return false, fmt.Errorf("repo.List: %w", err)
+	}
+	for i := range events {
+		events[i].Resources = slices.DeleteFunc(events[i].Resources, func(id string) bool { return id == resourceID })
+		if err := s.save(ctx, &events[i]); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := s.resources.Delete(ctx, resourceID); err != nil {
+		return false, fmt.Errorf("resources.Delete: %w", err)
+	}
+
+	s.logger.Info("удален ресурс",
+		zap.String("service", "calendar"),
+		zap.String("op", "DeleteResource"),
+		zap.Int64("user_id", userID),
+		zap.String("resource_id", resourceID),
+		zap.Int("bookings", len(events)),
+	)
+
+	return true, nil
+}
+
+// ListResources - все ресурсы по названию.
+func (s *calendarService) ListResources(ctx context.Context) ([]models.Resource, error) {
+	resources, err := s.resources.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resources.List: %w", err)
+	}
+
+	return resources, nil
+}
+
+// ResourceBookings - календарь ресурса: брони, пересекающиеся с [from; to), по началу.
+// Брони видны всем, но без текста событий.
+func (s *calendarService) ResourceBookings(ctx context.Context, resourceID string, from, to time.Time) ([]models.Booking, error) {
+	if resourceID == "" {
+		return nil, errResource
+	}
+	if !from.Before(to) {
+		return nil, errRange
+	}
+
+	res, err := s.resources.Get(ctx, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("resources.Get: %w", err)
+	}
+	if res == nil {
+		return nil, errResource
+	}
+
+	events, err := s.repo.List(ctx, &infra.ListOptions{ResourceID: &resourceID, From: &from, To: &to})
+	if err != nil {
+		return nil, fmt.Errorf("repo.List: %w", err)
+	}
+
+	bookings := make([]models.Booking, 0, len(events))
+	for _, e := range events {
+		bookings = append(bookings, models.Booking{EventID: e.ID, UserID: e.UserID, Start: e.Date, End: e.EndTime()})
+	}
+
+	return bookings, nil
+}
+
+// checkResources - проверяет, что событие может занять свои ресурсы: они существуют, а бронь
+// укладывается в их правила. Занятость и вместимость ресурсов проверяет хранилище при сохранении,
+// атомарно с конкурентными бронями и приглашениями.
+// Ресурсы занимают только одиночные события с длительностью. Повторы в event.Resources отбрасываются.
+func (s *calendarService) checkResources(ctx context.Context, event *models.Event) error {
+	if len(event.Resources) == 0 {
+		event.Resources = nil
+		return nil
+	}
+
+	event.Resources = slices.Compact(slices.Sorted(slices.Values(event.Resources)))
+	switch {
+	case len(event.Resources) > maxEventResources:
+		return &models.BookingError{Reason: fmt.Sprintf("событие занимает не больше %d ресурсов", maxEventResources)}
+	case event.RRule != "" || event.SeriesID != "":
+		return &models.BookingError{Reason: "ресурсы бронируются только одиночными событиями"}
+	case event.Duration() <= 0:
+		return &models.BookingError{Reason: "у брони должно быть окончание"}
+	}
+
+	now := time.Now()
+	for _, id := range event.Resources {
+		res, err := s.resources.Get(ctx, id)
+		if err != nil {
+			return fmt.Errorf("resources.Get: %w", err)
+		}
+
+		var reason string
+		switch {
+		case res == nil:
+			reason = "ресурс не найден"
+		case res.Policy.MaxDuration > 0 && event.Duration() > res.Policy.MaxDuration.Duration():
+			reason = "бронь длиннее " + res.Policy.MaxDuration.String()
+		case res.Policy.Horizon > 0 && event.Date.After(now.Add(res.Policy.Horizon.Duration())):
+			reason = "бронировать можно не дальше чем на " + res.Policy.Horizon.String() + " вперед"
+		}
+		if reason != "" {
+			return &models.BookingError{ResourceID: id, Reason: reason}
+		}
+	}
+
+	return nil
+}
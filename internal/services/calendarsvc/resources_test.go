@@ -0,0 +1,127 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func TestResourceBooking(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+
+	room, err := svc.CreateResource(ctx, models.Resource{
+		UserID: 1, Name: " Переговорная 301 ", Capacity: 3,
+		Policy: models.BookingPolicy{MaxDuration: models.Offset(2 * time.Hour), Horizon: models.Offset(30 * 24 * time.Hour)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Переговорная 301", room.Name)
+	assert.Equal(t, models.ResourceRoom, room.Kind)
+	projector, err := svc.CreateResource(ctx, models.Resource{UserID: 1, Name: "Проектор", Kind: models.ResourceEquipment})
+	require.NoError(t, err)
+
+	both := []string{room.ID, projector.ID, room.ID}
+	booked, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day, End: day.Add(time.Hour), Text: "ревью", Resources: both})
+	require.NoError(t, err)
+	event, err := svc.repo.Read(ctx, booked)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{room.ID, projector.ID}, event.Resources)
+
+	// Занятый ресурс отклоняет событие целиком, даже если остальные свободны.
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 2, Date: day.Add(30 * time.Minute), End: day.Add(90 * time.Minute), Text: "x", Resources: []string{projector.ID}})
+	var conflict *models.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []string{booked}, conflict.EventIDs)
+
+	next, err := svc.CreateEvent(ctx, models.Event{UserID: 2, Date: day.Add(time.Hour), End: day.Add(2 * time.Hour), Text: "x", Resources: []string{room.ID}})
+	require.NoError(t, err)
+
+	var booking *models.BookingError
+	for name, event := range map[string]models.Event{
+		"capacity": {UserID: 1, Date: day.Add(3 * time.Hour), End: day.Add(4 * time.Hour), Text: "x", Resources: []string{room.ID},
+			Attendees: []models.Attendee{{UserID: 2}, {UserID: 3}, {UserID: 4}}},
+		"duration": {UserID: 1, Date: day.Add(3 * time.Hour), End: day.Add(6 * time.Hour), Text: "x", Resources: []string{room.ID}},
+		"horizon":  {UserID: 1, Date: time.Now().AddDate(0, 2, 0), End: time.Now().AddDate(0, 2, 0).Add(time.Hour), Text: "x", Resources: []string{room.ID}},
+		"unknown":  {UserID: 1, Date: day.Add(3 * time.Hour), End: day.Add(4 * time.Hour), Text: "x", Resources: []string{"missing"}},
+		"series":   {UserID: 1, Date: day.Add(3 * time.Hour), End: day.Add(4 * time.Hour), Text: "x", Resources: []string{room.ID}, RRule: "FREQ=DAILY"},
+		"no end":   {UserID: 1, Date: day.Add(3 * time.Hour), Text: "x", Resources: []string{room.ID}},
+	} {
+		_, err := svc.CreateEvent(ctx, event)
+		assert.ErrorAs(t, err, &booking, name)
+	}
+
+	// Освобождение ресурса при изменении позволяет занять его другому событию.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{ID: booked, UserID: 1, Date: day, End: day.Add(time.Hour), Text: "ревью", Resources: []string{projector.ID}}, models.EditScopeDefault))
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{ID: next, UserID: 2, Date: day.Add(30 * time.Minute), End: day.Add(2 * time.Hour), Text: "x"}, models.EditScopeDefault))
+	event, err = svc.repo.Read(ctx, next)
+	require.NoError(t, err)
+	assert.Equal(t, []string{room.ID}, event.Resources)
+
+	err = svc.UpdateEvent(ctx, models.Event{ID: booked, UserID: 1, Date: day, End: day.Add(time.Hour), Text: "ревью", Resources: both}, models.EditScopeDefault)
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []string{next}, conflict.EventIDs)
+
+	// Новые участники должны поместиться в переговорную.
+	require.NoError(t, svc.InviteAttendees(ctx, next, []int64{3, 4}))
+	require.ErrorAs(t, svc.InviteAttendees(ctx, next, []int64{5}), &booking)
+
+	// Место отказавшегося участника можно отдать, и передумать он уже не сможет.
+	require.NoError(t, svc.RespondEvent(ctx, next, 3, models.AttendeeDeclined))
+	require.NoError(t, svc.InviteAttendees(ctx, next, []int64{5}))
+	require.ErrorAs(t, svc.RespondEvent(ctx, next, 3, models.AttendeeAccepted), &booking)
+	assert.Equal(t, room.ID, booking.ResourceID)
+	event, err = svc.repo.Read(ctx, next)
+	require.NoError(t, err)
+	assert.Equal(t, models.AttendeeDeclined, event.Attendee(3).Status)
+	require.NoError(t, svc.RespondEvent(ctx, next, 4, models.AttendeeAccepted))
+
+	bookings, err := svc.ResourceBookings(ctx, room.ID, day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []models.Booking{{EventID: next, UserID: 2, Start: day.Add(30 * time.Minute), End: day.Add(2 * time.Hour)}}, bookings)
+
+	_, err = svc.ResourceBookings(ctx, "missing", day, day.AddDate(0, 0, 1))
+	assert.ErrorIs(t, err, errResource)
+}
+
+func TestDeleteResource(t *testing.T) {
+	svc := newSvc(t)
+	day := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+
+	admin := auth.WithUser(context.Background(), 1)
+	colleague := auth.WithUser(context.Background(), 2)
+
+	room, err := svc.CreateResource(admin, models.Resource{UserID: 1, Name: "Переговорная"})
+	require.NoError(t, err)
+	_, err = svc.CreateResource(colleague, models.Resource{UserID: 1, Name: "Чужая"})
+	require.ErrorIs(t, err, auth.ErrForbidden)
+
+	booked, err := svc.CreateEvent(colleague, models.Event{UserID: 2, Date: day, End: day.Add(time.Hour), Text: "x", Resources: []string{room.ID}})
+	require.NoError(t, err)
+
+	found, err := svc.UpdateResource(colleague, models.Resource{ID: room.ID, UserID: 2, Name: "Моя"})
+	require.NoError(t, err)
+	assert.False(t, found)
+	found, err = svc.DeleteResource(colleague, 2, room.ID)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	found, err = svc.DeleteResource(admin, 1, room.ID)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// Событие остается, но ресурс больше не занимает.
+	event, err := svc.repo.Read(context.Background(), booked)
+	require.NoError(t, err)
+	assert.Empty(t, event.Resources)
+
+	resources, err := svc.ListResources(admin)
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+}
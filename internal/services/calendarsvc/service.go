@@ -1,130 +1,399 @@
 package calendarsvc
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
 	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
 	"github.com/sunr3d/simple-http-calendar/internal/interfaces/services"
+	"github.com/sunr3d/simple-http-calendar/internal/netguard"
+	"github.com/sunr3d/simple-http-calendar/internal/rrule"
 	"github.com/sunr3d/simple-http-calendar/models"
 )
 
 var _ services.CalendarService = (*calendarService)(nil)
 
 type calendarService struct {
-	repo   infra.Database
-	broker infra.Broker
-	logger *zap.Logger
+	repo      infra.Database
+	users     infra.UserRepo
+	shares    infra.ShareRepo
+	calendars infra.CalendarRepo
+	resources infra.ResourceRepo
+	broker    infra.Broker
+	// recipients - проверка webhook_url настроек пользователя (см. netguard.Guard.CheckHost).
+	recipients *netguard.Guard
+	logger     *zap.Logger
 }
 
 // New - конструктор сервиса календаря.
-func New(repo infra.Database, broker infra.Broker, logger *zap.Logger) services.CalendarService {
+func New(
+	repo infra.Database,
+	users infra.UserRepo,
+	shares infra.ShareRepo,
+	calendars infra.CalendarRepo,
+	resources infra.ResourceRepo,
+	broker infra.Broker,
+	recipients *netguard.Guard,
+	logger *zap.Logger,
+) services.CalendarService {
 	return &calendarService{
-		repo:   repo,
-		broker: broker,
-		logger: logger,
+		repo:       repo,
+		users:      users,
+		shares:     shares,
+		calendars:  calendars,
+		resources:  resources,
+		broker:     broker,
+		recipients: recipients,
+		logger:     logger,
 	}
 }
 
-// CreateEvent - создает новое событие в календаре.
-// Если событие имеет флаг напоминания, то оно отправляется в брокер для дальнейшей обработки.
+// CreateEvent - создает новое событие в календаре и публикует event.created.
+// Событие без напоминаний получает напоминания календаря по умолчанию.
+// В календаре, запрещающем пересечения, пересекающееся событие отклоняется с *models.ConflictError.
+// Ресурсы (Resources) занимаются атомарно с созданием: если хотя бы один занят, событие не создается
+// (*models.ConflictError), бронь вне правил ресурса отклоняется с *models.BookingError.
 func (s *calendarService) CreateEvent(ctx context.Context, event models.Event) (string, error) {
 	if event.UserID <= 0 {
 		return "", errUserID
 	}
+	cal, err := s.authorizeCalendar(ctx, event.UserID, event.CalendarID)
+	if err != nil {
+		return "", err
+	}
+	if cal != nil && event.Reminders == nil {
+		event.Reminders = cal.Reminders
+	}
 	if event.Text == "" {
 		return "", errEmptyEvent
 	}
+	if event.RRule != "" {
+		if _, err := rrule.Parse(event.RRule); err != nil {
+			return "", fmt.Errorf("%w: %v", errRRule, err)
+		}
+	}
+	if err := validateChannels(event.Channels); err != nil {
+		return "", err
+	}
+	if err := validateReminders(event.Reminders); err != nil {
+		return "", err
+	}
+	attendees, err := mergeAttendees(event.UserID, event.Attendees, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := s.applyTimeZone(ctx, &event, ""); err != nil {
+		return "", err
+	}
+	if err := normalizeTimes(&event); err != nil {
+		return "", err
+	}
+	if err := s.checkCalendar(ctx, cal, event); err != nil {
+		return "", err
+	}
+	event.Attendees = attendees
+	if err := s.checkResources(ctx, &event); err != nil {
+		return "", err
+	}
+	if event.ICalUID != "" {
+		existing, err := s.findByICalUID(ctx, event.UserID, event.ICalUID)
+		if err != nil {
+			return "", err
+		}
+		if existing != nil {
+			return existing.ID, errDuplicate
+		}
+	}
 
 	id := uuid.NewString()
 	newEvent := &models.Event{
-		ID:       id,
-		UserID:   event.UserID,
-		Date:     event.Date,
-		Text:     event.Text,
-		Reminder: event.Reminder,
+		ID:         id,
+		UserID:     event.UserID,
+		CalendarID: event.CalendarID,
+		Date:       event.Date,
+		End:        event.End,
+		AllDay:     event.AllDay,
+		TimeZone:   event.TimeZone,
+		Text:       event.Text,
+		Reminders:  normalizeReminders(event.Reminders),
+		Channels:   event.Channels,
+		Attendees:  attendees,
+		Resources:  event.Resources,
+		RRule:      event.RRule,
+		ExDates:    event.ExDates,
+		ICalUID:    event.ICalUID,
 	}
 
 	if err := s.repo.Create(ctx, newEvent); err != nil {
 		return "", fmt.Errorf("repo.Create: %w", err)
 	}
 
-	if newEvent.Reminder {
-		if err := s.broker.Publish(ctx, newEvent); err != nil {
-			return id, fmt.Errorf("broker.Publish: %w", err)
-		}
+	if err := s.publish(ctx, models.EventCreated, newEvent); err != nil {
+		return id, err
 	}
 
 	return id, nil
 }
 
 // UpdateEvent - обновляет событие в календаре.
-// Если событие имеет флаг напоминания, то оно отправляется в брокер для дальнейшей обработки.
-func (s *calendarService) UpdateEvent(ctx context.Context, event models.Event) error {
+// Для повторяющихся событий scope задает область изменения: вхождение, вхождение и последующие или вся серия.
+// Напоминания заменяются, если они переданы (пустой список удаляет все); nil оставляет текущие.
+// Непустой CalendarID переносит событие в другой календарь пользователя.
+// Участники (Attendees) заменяются, если переданы; изменения встречи видны всем участникам.
+// В календаре, запрещающем пересечения, пересекающееся изменение отклоняется с *models.ConflictError.
+// Ресурсы заменяются, если переданы, и проверяются так же, как при создании.
+// Изменения публикуются в брокер (event.created/updated/deleted), по ним сервис напоминаний
+// переносит или отменяет напоминания.
+func (s *calendarService) UpdateEvent(ctx context.Context, event models.Event, scope models.EditScope) error {
 	if event.ID == "" {
 		return errEventID
 	}
 	if event.UserID <= 0 {
 		return errUserID
 	}
+	if _, err := s.authorize(ctx, event.UserID, accessWrite); err != nil {
+		return err
+	}
 	if event.Text == "" {
 		return errEmptyEvent
 	}
+	if event.RRule != "" {
+		if _, err := rrule.Parse(event.RRule); err != nil {
+			return fmt.Errorf("%w: %v", errRRule, err)
+		}
+	}
+	if err := validateChannels(event.Channels); err != nil {
+		return err
+	}
+	if err := validateReminders(event.Reminders); err != nil {
+		return err
+	}
 
-	data, err := s.repo.Read(ctx, event.ID)
+	data, occ, err := s.resolveTarget(ctx, event.ID, event.RecurrenceID)
 	if err != nil {
-		return fmt.Errorf("repo.Read: %w", err)
+		return err
+	}
+	// Событие остается в календаре владельца, даже если его меняет редактор.
+	event.UserID = data.UserID
+	// Без calendar_id событие остается в своем календаре, с ним - переносится в указанный.
+	var cal *models.Calendar
+	if event.CalendarID == "" {
+		event.CalendarID = data.CalendarID
 	}
+	if event.CalendarID != data.CalendarID {
+		if cal, err = s.authorizeCalendar(ctx, data.UserID, event.CalendarID); err != nil {
+			return err
+		}
+	} else if event.CalendarID != "" {
+		if cal, err = s.calendars.Get(ctx, event.CalendarID); err != nil {
+			return fmt.Errorf("calendars.Get: %w", err)
+		}
+	}
+	// Участники заменяются, если переданы; оставшиеся сохраняют свои ответы.
+	if event.Attendees == nil {
+		event.Attendees = data.Attendees
+	} else if event.Attendees, err = mergeAttendees(data.UserID, event.Attendees, data.Attendees); err != nil {
+		return err
+	}
+
+	if err := s.applyTimeZone(ctx, &event, data.TimeZone); err != nil {
+		return err
+	}
+	if event.Channels == nil {
+		event.Channels = data.Channels
+	}
+	if err := normalizeTimes(&event); err != nil {
+		return err
+	}
+	// Серия целиком проверяется по всем вхождениям, отдельное вхождение - только само.
+	check := event
+	check.ID = data.ID
+	if occ == nil && check.RRule == "" {
+		check.RRule = data.RRule
+	}
+	if err := s.checkCalendar(ctx, cal, check); err != nil {
+		return err
+	}
+	if event.Resources == nil {
+		event.Resources = data.Resources
+	}
+	// Вхождение серии остается частью серии, а ресурсы занимают только одиночные события.
+	check.RRule = cmp.Or(check.RRule, data.RRule)
+	check.SeriesID = data.SeriesID
+	check.Resources = event.Resources
+	if err := s.checkResources(ctx, &check); err != nil {
+		return err
+	}
+	event.Resources = check.Resources
 
-	data.Date = event.Date
-	data.Text = event.Text
-	if event.Reminder {
-		data.Reminder = true
-		if err := s.broker.Publish(ctx, data); err != nil {
-			return fmt.Errorf("broker.Publish: %w", err)
+	if data.RRule == "" {
+		series, seriesOcc, ok := s.seriesOfException(ctx, data, scope)
+		if !ok {
+			data.Reminders = mergeReminders(data.Reminders, event.Reminders, !data.Date.Equal(event.Date))
+			data.Date = event.Date
+			data.End = event.End
+			data.AllDay = event.AllDay
+			data.TimeZone = event.TimeZone
+			data.Channels = event.Channels
+			data.Text = event.Text
+			data.CalendarID = event.CalendarID
+			data.Attendees = event.Attendees
+			data.Resources = event.Resources
+			if data.SeriesID == "" {
+				data.RRule = event.RRule
+			}
+			return s.save(ctx, data)
 		}
+		data, occ = series, seriesOcc
+	}
+
+	scope, err = resolveScope(scope, occ)
+	if err != nil {
+		return err
 	}
 
-	return s.repo.Update(ctx, data)
+	switch scope {
+	case models.EditScopeThis:
+		return s.updateOccurrence(ctx, data, event, *occ)
+	case models.EditScopeFollowing:
+		return s.updateFollowing(ctx, data, event, *occ)
+	default:
+		return s.updateSeries(ctx, data, event, occ)
+	}
 }
 
 // DeleteEvent - удаляет событие из календара.
-func (s *calendarService) DeleteEvent(ctx context.Context, eventID string) error {
+// Для повторяющихся событий scope задает область удаления.
+// Для удаленных событий публикуется event.deleted, ожидающие напоминания отменяются.
+func (s *calendarService) DeleteEvent(ctx context.Context, eventID string, scope models.EditScope) error {
 	if eventID == "" {
 		return errEventID
 	}
 
-	_, err := s.repo.Delete(ctx, eventID)
+	data, occ, err := s.resolveTarget(ctx, eventID, nil)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if data.RRule == "" {
+		series, seriesOcc, ok := s.seriesOfException(ctx, data, scope)
+		if !ok {
+			return s.remove(ctx, data)
+		}
+		data, occ = series, seriesOcc
+	}
+
+	scope, err = resolveScope(scope, occ)
+	if err != nil {
+		return err
+	}
+
+	switch scope {
+	case models.EditScopeThis:
+		return s.deleteOccurrence(ctx, data, *occ)
+	case models.EditScopeFollowing:
+		return s.deleteFollowing(ctx, data, *occ)
+	default:
+		return s.deleteSeries(ctx, data)
+	}
+}
+
+// seriesOfException - для исключения серии при scope following/all возвращает саму серию и вхождение,
+// чтобы изменение применилось к серии. В остальных случаях исключение изменяется как обычное событие.
+func (s *calendarService) seriesOfException(
+	ctx context.Context,
+	event *models.Event,
+	scope models.EditScope,
+) (*models.Event, *time.Time, bool) {
+	if event.SeriesID == "" || event.RecurrenceID == nil {
+		return nil, nil, false
+	}
+	if scope != models.EditScopeFollowing && scope != models.EditScopeAll {
+		return nil, nil, false
+	}
+
+	series, err := s.repo.Read(ctx, event.SeriesID)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	occ := *event.RecurrenceID
+	return series, &occ, true
+}
+
+// GetEvent - событие по ID, в т.ч. вхождение серии по ID экземпляра; измененное вхождение
+// возвращается как сохраненное исключение. Событие видно тем, кому доступен его календарь
+// (при доступе только к занятости - без текста), и приглашенным участникам.
+// Отсутствующее событие или вхождение - models.ErrNotFound.
+func (s *calendarService) GetEvent(ctx context.Context, eventID string) (models.Event, error) {
+	if eventID == "" {
+		return models.Event{}, errEventID
+	}
+
+	var event models.Event
+	if seriesID, occ, ok := parseInstanceID(eventID); ok {
+		series, err := s.repo.Read(ctx, seriesID)
+		if err != nil {
+			return models.Event{}, fmt.Errorf("repo.Read: %w", err)
+		}
+		if event, err = s.instance(ctx, *series, occ); err != nil {
+			return models.Event{}, err
+		}
+	} else {
+		data, err := s.repo.Read(ctx, eventID)
+		if err != nil {
+			return models.Event{}, fmt.Errorf("repo.Read: %w", err)
+		}
+		event = *data
+	}
+
+	if caller, ok := auth.UserFrom(ctx); ok && event.Attendee(caller) != nil {
+		return event, nil
+	}
+	v, err := s.view(ctx, event.UserID, accessFreeBusy, []string{event.CalendarID})
+	if err != nil {
+		return models.Event{}, err
+	}
+	if len(v.calendars) == 0 {
+		return models.Event{}, auth.ErrForbidden
+	}
+
+	return v.redact([]models.Event{event})[0], nil
 }
 
 // GetEventsForDay - получает все события для указанного дня.
+// Границы дня, недели и месяца считаются в часовом поясе dateRange.
+// calendarIDs выбирают календари пользователя ("" - календарь по умолчанию), без них - все календари.
 func (s *calendarService) GetEventsForDay(
 	ctx context.Context,
 	userID int64,
 	dateRange time.Time,
+	calendarIDs ...string,
 ) ([]models.Event, error) {
 	if userID <= 0 {
 		return nil, errUserID
 	}
+	v, err := s.view(ctx, userID, accessFreeBusy, calendarIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	day := time.Date(dateRange.Year(), dateRange.Month(), dateRange.Day(), 0, 0, 0, 0, time.Local)
-	archived := false
+	day := startOfDay(dateRange)
+
+	events, err := s.eventsInRange(ctx, v.options(userID), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
 
-	return s.repo.List(
-		ctx,
-		&infra.ListOptions{
-			UserID:   &userID,
-			Archived: &archived,
-			From:     &day,
-			To:       &day,
-		},
-	)
+	return v.redact(events), nil
 }
 
 // GetEventsForWeek - получает все события для указанной недели.
@@ -132,29 +401,30 @@ func (s *calendarService) GetEventsForWeek(
 	ctx context.Context,
 	userID int64,
 	dateRange time.Time,
+	calendarIDs ...string,
 ) ([]models.Event, error) {
 	if userID <= 0 {
 		return nil, errUserID
 	}
+	v, err := s.view(ctx, userID, accessFreeBusy, calendarIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	day := time.Date(dateRange.Year(), dateRange.Month(), dateRange.Day(), 0, 0, 0, 0, time.Local)
+	day := startOfDay(dateRange)
 	weekday := int(day.Weekday())
 	if weekday == 0 {
 		weekday = 7
 	}
 	weekStart := day.AddDate(0, 0, -(weekday - 1))
-	weekEnd := weekStart.AddDate(0, 0, 6)
-	archived := false
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	events, err := s.eventsInRange(ctx, v.options(userID), weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
 
-	return s.repo.List(
-		ctx,
-		&infra.ListOptions{
-			UserID:   &userID,
-			Archived: &archived,
-			From:     &weekStart,
-			To:       &weekEnd,
-		},
-	)
+	return v.redact(events), nil
 }
 
 // GetEventsForMonth - получает все события для указанного месяца.
@@ -162,23 +432,247 @@ func (s *calendarService) GetEventsForMonth(
 	ctx context.Context,
 	userID int64,
 	dateRange time.Time,
+	calendarIDs ...string,
 ) ([]models.Event, error) {
 	if userID <= 0 {
 		return nil, errUserID
 	}
+	v, err := s.view(ctx, userID, accessFreeBusy, calendarIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	day := startOfDay(dateRange)
+	monthStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
 
-	day := time.Date(dateRange.Year(), dateRange.Month(), dateRange.Day(), 0, 0, 0, 0, time.Local)
-	monthStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.Local)
-	monthEnd := monthStart.AddDate(0, 1, -1)
+	events, err := s.eventsInRange(ctx, v.options(userID), monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.redact(events), nil
+}
+
+// GetAllEvents - получает все события пользователя, включая архивные.
+// Повторяющиеся серии возвращаются без разворачивания во вхождения.
+func (s *calendarService) GetAllEvents(ctx context.Context, userID int64) ([]models.Event, error) {
+	if userID <= 0 {
+		return nil, errUserID
+	}
+	v, err := s.view(ctx, userID, accessFreeBusy, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.repo.List(ctx, &infra.ListOptions{UserID: &userID, CalendarIDs: v.calendars})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.redact(events), nil
+}
+
+// ListEvents - события пользователя, пересекающиеся с [From; To), упорядоченные по началу и разбитые на страницы.
+// Вхождения серий разворачиваются в пределах периода и сливаются с одиночными событиями;
+// если серий в периоде нет, страницу отбирает само хранилище. Страница продолжает выборку
+// после позиции query.After, поэтому события, добавленные или удаленные между запросами страниц,
+// не приводят к пропускам и повторам.
+func (s *calendarService) ListEvents(ctx context.Context, query models.EventsRange) (models.EventsPage, error) {
+	if query.UserID <= 0 {
+		return models.EventsPage{}, errUserID
+	}
+	v, err := s.view(ctx, query.UserID, accessFreeBusy, query.CalendarIDs)
+	if err != nil {
+		return models.EventsPage{}, err
+	}
+	if !query.From.Before(query.To) {
+		return models.EventsPage{}, errRange
+	}
+	if query.Limit <= 0 {
+		return models.EventsPage{}, errPage
+	}
+
+	order := infra.OrderAsc
+	if query.Sort == models.SortDesc {
+		order = infra.OrderDesc
+	}
+
+	occurrences, err := s.occurrencesInRange(ctx, v.options(query.UserID), query.From, query.To)
+	if err != nil {
+		return models.EventsPage{}, err
+	}
+	if query.After != nil {
+		occurrences = slices.DeleteFunc(occurrences, func(e models.Event) bool {
+			c := e.Key().Compare(*query.After)
+			return c == 0 || (c < 0) == (order == infra.OrderAsc)
+		})
+	}
+
+	archived := false
+	recurring := false
+	opts := v.options(query.UserID)
+	opts.Archived = &archived
+	opts.From, opts.To = &query.From, &query.To
+	opts.Recurring = &recurring
+	opts.Order = order
+	opts.Limit, opts.After = query.Limit+1, query.After
+
+	events, err := s.repo.List(ctx, &opts)
+	if err != nil {
+		return models.EventsPage{}, fmt.Errorf("repo.List: %w", err)
+	}
+
+	if len(occurrences) > 0 {
+		events = append(events, occurrences...)
+		sortEvents(events, order)
+	}
+
+	page := models.EventsPage{Events: v.redact(events)}
+	if len(events) > query.Limit {
+		page.Events, page.More = events[:query.Limit], true
+	}
+
+	return page, nil
+}
+
+// SearchEvents - события пользователя, содержащие все слова запроса, по убыванию релевантности.
+// Архивные события ищутся только при search.Archived.
+// Повторяющиеся серии возвращаются без разворачивания и попадают в период по началу серии.
+func (s *calendarService) SearchEvents(ctx context.Context, search models.EventsSearch) ([]models.Event, error) {
+	if search.UserID <= 0 {
+		return nil, errUserID
+	}
+	// Поиск идет по тексту событий, при доступе только к занятости он недоступен.
+	v, err := s.view(ctx, search.UserID, accessRead, search.CalendarIDs)
+	if err != nil {
+		return nil, err
+	}
+	calendarIDs := v.calendars
+	if len(v.busy) > 0 {
+		calendarIDs = slices.DeleteFunc(calendarIDs, func(id string) bool { return v.busy[id] })
+	}
+	if strings.TrimSpace(search.Query) == "" {
+		return nil, errQuery
+	}
+	if !search.From.IsZero() && !search.To.IsZero() && !search.From.Before(search.To) {
+		return nil, errRange
+	}
+
+	opts := infra.SearchOptions{
+		UserID:      search.UserID,
+		CalendarIDs: calendarIDs,
+		Query:       search.Query,
+		Limit:       search.Limit,
+	}
+	if !search.Archived {
+		archived := false
+		opts.Archived = &archived
+	}
+	if !search.From.IsZero() {
+		opts.From = &search.From
+	}
+	if !search.To.IsZero() {
+		opts.To = &search.To
+	}
+
+	events, err := s.repo.Search(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("repo.Search: %w", err)
+	}
+
+	return events, nil
+}
+
+// eventsInRange - события пользователя, пересекающиеся с полуинтервалом [from; to), в порядке начала.
+// Многодневные события попадают в каждый день, который они охватывают.
+// Повторяющиеся серии разворачиваются во вхождения внутри диапазона.
+func (s *calendarService) eventsInRange(ctx context.Context, base infra.ListOptions, from, to time.Time) ([]models.Event, error) {
+	archived := false
+	recurring := false
+
+	opts := base
+	opts.Archived = &archived
+	opts.From, opts.To = &from, &to
+	opts.Recurring = &recurring
+
+	events, err := s.repo.List(ctx, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("repo.List: %w", err)
+	}
+
+	occurrences, err := s.occurrencesInRange(ctx, base, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	events = append(events, occurrences...)
+	sortEvents(events, infra.OrderAsc)
+
+	return events, nil
+}
+
+// occurrencesInRange - вхождения повторяющихся серий пользователя внутри [from; to).
+func (s *calendarService) occurrencesInRange(ctx context.Context, base infra.ListOptions, from, to time.Time) ([]models.Event, error) {
 	archived := false
+	recurring := true
+
+	opts := base
+	opts.Archived = &archived
+	opts.To = &to
+	opts.Recurring = &recurring
+
+	series, err := s.repo.List(ctx, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("repo.List: %w", err)
+	}
+
+	var res []models.Event
+	for _, ser := range series {
+		res = append(res, s.expandSeries(ser, from, to)...)
+	}
+
+	return res, nil
+}
+
+// sortEvents - упорядочивает события так же, как хранилище: по началу, при равенстве - по ID.
+func sortEvents(events []models.Event, order infra.Order) {
+	slices.SortFunc(events, func(a, b models.Event) int {
+		c := cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.ID, b.ID))
+		if order == infra.OrderDesc {
+			return -c
+		}
+		return c
+	})
+}
+
+// normalizeTimes - приводит время события к каноническому виду:
+// событие на весь день начинается в полночь и заканчивается в полночь (не включительно),
+// событие без окончания получает окончание, равное началу.
+func normalizeTimes(event *models.Event) error {
+	if !event.End.IsZero() && event.End.Before(event.Date) {
+		return errEndBefore
+	}
+
+	if event.AllDay {
+		event.Date = startOfDay(event.Date)
+		end := startOfDay(event.End)
+		if end.Before(event.End) {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !end.After(event.Date) {
+			end = event.Date.AddDate(0, 0, 1)
+		}
+		event.End = end
+	}
+
+	if event.End.IsZero() {
+		event.End = event.Date
+	}
+
+	return nil
+}
 
-	return s.repo.List(
-		ctx,
-		&infra.ListOptions{
-			UserID:   &userID,
-			Archived: &archived,
-			From:     &monthStart,
-			To:       &monthEnd,
-		},
-	)
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
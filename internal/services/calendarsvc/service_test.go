@@ -2,6 +2,7 @@ package calendarsvc
 
 import (
 	"context"
+	"net/netip"
 	"testing"
 	"time"
 
@@ -9,20 +10,42 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
 	"github.com/sunr3d/simple-http-calendar/internal/infra/inmembroker"
 	"github.com/sunr3d/simple-http-calendar/internal/infra/inmemdb"
+	"github.com/sunr3d/simple-http-calendar/internal/netguard"
 	"github.com/sunr3d/simple-http-calendar/models"
 )
 
+// publicResolver - DNS тестов: IP адрес отдается как есть, любой другой хост - публичный адрес.
+type publicResolver struct{}
+
+func (publicResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+}
+
 func newSvc(t *testing.T) *calendarService {
 	t.Helper()
 
 	logger := zap.NewNop()
 
-	repo := inmemdb.New(logger)
+	resources := inmemdb.NewResourceRepo(logger)
+	repo := inmemdb.New(logger, resources)
 	broker := inmembroker.New(100, logger)
 
-	s := New(repo, broker, logger)
+	s := New(
+		repo,
+		inmemdb.NewUserRepo(logger),
+		inmemdb.NewShareRepo(logger),
+		inmemdb.NewCalendarRepo(logger),
+		resources,
+		broker,
+		&netguard.Guard{Resolver: publicResolver{}},
+		logger,
+	)
 	cs, ok := s.(*calendarService)
 
 	require.True(t, ok)
@@ -58,10 +81,14 @@ func TestCreateWithReminder(t *testing.T) {
 	day := time.Date(2025, 1, 2, 13, 14, 15, 0, time.UTC)
 
 	id, err := svc.CreateEvent(ctx, models.Event{
-		UserID:   1,
-		Date:     day,
-		Text:     "meeting with reminder",
-		Reminder: true,
+		UserID: 1,
+		Date:   day,
+		Text:   "meeting with reminder",
+		Reminders: []models.Reminder{
+			{Offset: models.Offset(-15 * time.Minute)},
+			{Offset: models.Offset(-24 * time.Hour)},
+			{Offset: models.Offset(-15 * time.Minute)},
+		},
 	})
 	require.NoError(t, err)
 	require.NotEmpty(t, id)
@@ -72,8 +99,11 @@ func TestCreateWithReminder(t *testing.T) {
 	assert.Equal(t, "meeting with reminder", events[0].Text)
 	assert.Equal(t, int64(1), events[0].UserID)
 	assert.Equal(t, day, events[0].Date)
-	assert.True(t, events[0].Reminder)
-	assert.False(t, events[0].ReminderSent)
+	assert.Equal(t, []models.Reminder{
+		{Offset: models.Offset(-24 * time.Hour)},
+		{Offset: models.Offset(-15 * time.Minute)},
+	}, events[0].Reminders)
+	assert.True(t, events[0].ReminderPending())
 }
 
 func TestUpdate(t *testing.T) {
@@ -89,7 +119,7 @@ func TestUpdate(t *testing.T) {
 		UserID: 42,
 		Date:   day,
 		Text:   "new",
-	})
+	}, models.EditScopeDefault)
 	require.NoError(t, err)
 
 	list, err := svc.GetEventsForDay(ctx, 42, day)
@@ -106,13 +136,65 @@ func TestDelete(t *testing.T) {
 	id, err := svc.CreateEvent(ctx, models.Event{UserID: 7, Date: day, Text: "to remove"})
 	require.NoError(t, err)
 
-	require.NoError(t, svc.DeleteEvent(ctx, id))
+	require.NoError(t, svc.DeleteEvent(ctx, id, models.EditScopeDefault))
 
 	list, err := svc.GetEventsForDay(ctx, 7, day)
 	require.NoError(t, err)
 	assert.Len(t, list, 0)
 }
 
+func TestGetEvent(t *testing.T) {
+	svc := newSvc(t)
+	day := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+
+	owner := auth.WithUser(context.Background(), 1)
+	busy := auth.WithUser(context.Background(), 2)
+	guest := auth.WithUser(context.Background(), 3)
+	stranger := auth.WithUser(context.Background(), 4)
+	require.NoError(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 2, Role: models.ShareFreeBusy}))
+
+	id, err := svc.CreateEvent(owner, models.Event{UserID: 1, Date: day, End: day.Add(time.Hour), Text: "планерка",
+		Attendees: []models.Attendee{{UserID: 3}}})
+	require.NoError(t, err)
+
+	event, err := svc.GetEvent(owner, id)
+	require.NoError(t, err)
+	assert.Equal(t, "планерка", event.Text)
+	event, err = svc.GetEvent(guest, id)
+	require.NoError(t, err)
+	assert.Equal(t, "планерка", event.Text)
+	event, err = svc.GetEvent(busy, id)
+	require.NoError(t, err)
+	assert.Empty(t, event.Text)
+	assert.Equal(t, day, event.Date)
+	_, err = svc.GetEvent(stranger, id)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	_, err = svc.GetEvent(owner, "missing")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	// Вхождение серии доступно по ID экземпляра, измененное - как исключение.
+	seriesID, err := svc.CreateEvent(owner, models.Event{UserID: 1, Date: day, Text: "daily", RRule: "FREQ=DAILY;COUNT=3"})
+	require.NoError(t, err)
+	second := instanceID(seriesID, day.AddDate(0, 0, 1))
+	event, err = svc.GetEvent(owner, second)
+	require.NoError(t, err)
+	assert.Equal(t, second, event.ID)
+	assert.Equal(t, seriesID, event.SeriesID)
+	assert.Equal(t, day.AddDate(0, 0, 1), event.Date)
+
+	require.NoError(t, svc.UpdateEvent(owner, models.Event{ID: second, UserID: 1, Date: day.AddDate(0, 0, 1).Add(time.Hour), Text: "moved"}, models.EditScopeThis))
+	event, err = svc.GetEvent(owner, second)
+	require.NoError(t, err)
+	assert.NotEqual(t, second, event.ID)
+	assert.Equal(t, "moved", event.Text)
+
+	_, err = svc.GetEvent(owner, instanceID(seriesID, day.AddDate(0, 0, 5)))
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	_, err = svc.GetEvent(owner, instanceID(seriesID, day.Add(time.Minute)))
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
 func TestValidationErrors(t *testing.T) {
 	svc := newSvc(t)
 	ctx := context.Background()
@@ -121,10 +203,10 @@ func TestValidationErrors(t *testing.T) {
 	_, err := svc.CreateEvent(ctx, models.Event{UserID: 0, Date: day, Text: "x"})
 	require.Error(t, err)
 
-	err = svc.UpdateEvent(ctx, models.Event{ID: "", UserID: 1, Date: day, Text: "x"})
+	err = svc.UpdateEvent(ctx, models.Event{ID: "", UserID: 1, Date: day, Text: "x"}, models.EditScopeDefault)
 	require.Error(t, err)
 
-	err = svc.DeleteEvent(ctx, "")
+	err = svc.DeleteEvent(ctx, "", models.EditScopeDefault)
 	require.Error(t, err)
 }
 
@@ -181,3 +263,271 @@ func TestGetEventsForMonth(t *testing.T) {
 	assert.ElementsMatch(t, eventIDs, []string{id1, id2})
 	assert.NotContains(t, eventIDs, id3)
 }
+
+func TestMultiDayEvent(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+
+	start := time.Date(2025, 5, 5, 22, 0, 0, 0, time.Local)
+	end := time.Date(2025, 5, 7, 1, 30, 0, 0, time.Local)
+	id, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: start, End: end, Text: "hackathon"})
+	require.NoError(t, err)
+
+	for _, day := range []int{5, 6, 7} {
+		events, err := svc.GetEventsForDay(ctx, 1, time.Date(2025, 5, day, 12, 0, 0, 0, time.Local))
+		require.NoError(t, err)
+		require.Len(t, events, 1, "day %d", day)
+		assert.Equal(t, id, events[0].ID)
+		assert.Equal(t, end, events[0].End)
+	}
+
+	events, err := svc.GetEventsForDay(ctx, 1, time.Date(2025, 5, 8, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestAllDayEvent(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateEvent(ctx, models.Event{
+		UserID: 1,
+		Date:   time.Date(2025, 5, 5, 15, 0, 0, 0, time.Local),
+		AllDay: true,
+		Text:   "day off",
+	})
+	require.NoError(t, err)
+
+	events, err := svc.GetEventsForDay(ctx, 1, time.Date(2025, 5, 5, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].AllDay)
+	assert.Equal(t, time.Date(2025, 5, 5, 0, 0, 0, 0, time.Local), events[0].Date)
+	assert.Equal(t, time.Date(2025, 5, 6, 0, 0, 0, 0, time.Local), events[0].End)
+
+	events, err = svc.GetEventsForDay(ctx, 1, time.Date(2025, 5, 6, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestRecurringWithDurationSpansMidnight(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+
+	start := time.Date(2025, 5, 5, 23, 0, 0, 0, time.Local) // понедельник
+	_, err := svc.CreateEvent(ctx, models.Event{
+		UserID: 1,
+		Date:   start,
+		End:    start.Add(2 * time.Hour),
+		Text:   "night shift",
+		RRule:  "FREQ=WEEKLY",
+	})
+	require.NoError(t, err)
+
+	tuesday, err := svc.GetEventsForDay(ctx, 1, time.Date(2025, 5, 13, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	require.Len(t, tuesday, 1)
+	assert.Equal(t, start.AddDate(0, 0, 7), tuesday[0].Date)
+	assert.Equal(t, start.AddDate(0, 0, 7).Add(2*time.Hour), tuesday[0].End)
+}
+
+func TestEndBeforeStart(t *testing.T) {
+	svc := newSvc(t)
+	start := time.Date(2025, 5, 5, 10, 0, 0, 0, time.Local)
+
+	_, err := svc.CreateEvent(context.Background(), models.Event{
+		UserID: 1,
+		Date:   start,
+		End:    start.Add(-time.Hour),
+		Text:   "broken",
+	})
+	require.ErrorIs(t, err, errEndBefore)
+}
+
+// pages - обходит выборку постранично и возвращает тексты событий в порядке выдачи.
+func pages(t *testing.T, svc *calendarService, query models.EventsRange) []string {
+	t.Helper()
+
+	var texts []string
+	for {
+		page, err := svc.ListEvents(context.Background(), query)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Events), query.Limit)
+		for _, e := range page.Events {
+			texts = append(texts, e.Text)
+		}
+		if !page.More {
+			return texts
+		}
+		last := page.Events[len(page.Events)-1].Key()
+		query.After = &last
+	}
+}
+
+func TestListEventsPages(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	for _, days := range []int{4, 2, 5, 1, 3} {
+		text := string(rune('a' + days - 1))
+		_, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.AddDate(0, 0, days), Text: text})
+		require.NoError(t, err)
+	}
+	_, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.AddDate(0, 0, 20), Text: "позже"})
+	require.NoError(t, err)
+
+	query := models.EventsRange{UserID: 1, From: day, To: day.AddDate(0, 0, 10), Sort: models.SortAsc, Limit: 2}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, pages(t, svc, query))
+
+	query.Sort = models.SortDesc
+	assert.Equal(t, []string{"e", "d", "c", "b", "a"}, pages(t, svc, query))
+
+	query.After = &models.EventKey{Date: day}
+	page, err := svc.ListEvents(ctx, query)
+	require.NoError(t, err)
+	assert.Empty(t, page.Events)
+	assert.False(t, page.More)
+
+	_, err = svc.ListEvents(ctx, models.EventsRange{UserID: 1, From: day, To: day, Limit: 1})
+	require.ErrorIs(t, err, errRange)
+	_, err = svc.ListEvents(ctx, models.EventsRange{UserID: 1, From: day, To: day.AddDate(0, 0, 1)})
+	require.ErrorIs(t, err, errPage)
+}
+
+func TestListEventsMergesSeries(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC) // понедельник
+
+	_, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.Add(9 * time.Hour), Text: "standup", RRule: "FREQ=DAILY;COUNT=3"})
+	require.NoError(t, err)
+	for i, text := range []string{"пн", "вт", "ср"} {
+		_, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.AddDate(0, 0, i).Add(12 * time.Hour), Text: text})
+		require.NoError(t, err)
+	}
+
+	query := models.EventsRange{UserID: 1, From: day, To: day.AddDate(0, 0, 7), Sort: models.SortAsc, Limit: 4}
+	want := []string{"standup", "пн", "standup", "вт", "standup", "ср"}
+	assert.Equal(t, want, pages(t, svc, query))
+
+	query.Limit = 1
+	assert.Equal(t, want, pages(t, svc, query))
+
+	query.Sort = models.SortDesc
+	query.Limit = 5
+	assert.Equal(t, []string{"ср", "standup", "вт", "standup", "пн", "standup"}, pages(t, svc, query))
+}
+
+func TestListEventsPagesStableUnderChanges(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC) // понедельник
+
+	_, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.Add(9 * time.Hour), Text: "standup", RRule: "FREQ=DAILY;COUNT=3"})
+	require.NoError(t, err)
+	for i, text := range []string{"пн", "вт", "ср"} {
+		_, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.AddDate(0, 0, i).Add(12 * time.Hour), Text: text})
+		require.NoError(t, err)
+	}
+
+	query := models.EventsRange{UserID: 1, From: day, To: day.AddDate(0, 0, 7), Sort: models.SortAsc, Limit: 2}
+	page, err := svc.ListEvents(ctx, query)
+	require.NoError(t, err)
+	require.True(t, page.More)
+	texts := []string{page.Events[0].Text, page.Events[1].Text}
+
+	// Между страницами одно событие добавлено до курсора, другое - после.
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.Add(10 * time.Hour), Text: "раньше"})
+	require.NoError(t, err)
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.AddDate(0, 0, 1).Add(10 * time.Hour), Text: "новое"})
+	require.NoError(t, err)
+
+	last := page.Events[len(page.Events)-1].Key()
+	query.After = &last
+	texts = append(texts, pages(t, svc, query)...)
+	assert.Equal(t, []string{"standup", "пн", "standup", "новое", "вт", "standup", "ср"}, texts)
+}
+
+func TestSearchEvents(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+
+	dentist, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day, Text: "Запись к стоматологу"})
+	require.NoError(t, err)
+	checkup, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day.AddDate(0, 6, 0), Text: "Стоматолог: осмотр"})
+	require.NoError(t, err)
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 2, Date: day, Text: "стоматолог"})
+	require.NoError(t, err)
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day, Text: "Созвон с командой"})
+	require.NoError(t, err)
+
+	found, err := svc.SearchEvents(ctx, models.EventsSearch{UserID: 1, Query: "стоматолога", Limit: 10})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{dentist, checkup}, eventIDs(found))
+
+	found, err = svc.SearchEvents(ctx, models.EventsSearch{UserID: 1, Query: "стомат", To: day.AddDate(0, 1, 0), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{dentist}, eventIDs(found))
+
+	// Переименованное событие ищется по новому тексту.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{ID: checkup, UserID: 1, Date: day.AddDate(0, 6, 0), Text: "Dentist checkup"}, models.EditScopeDefault))
+	found, err = svc.SearchEvents(ctx, models.EventsSearch{UserID: 1, Query: "dentists", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{checkup}, eventIDs(found))
+
+	// Архивные события ищутся только по запросу.
+	_, err = svc.repo.Archive(ctx, dentist)
+	require.NoError(t, err)
+	found, err = svc.SearchEvents(ctx, models.EventsSearch{UserID: 1, Query: "стоматолог", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	found, err = svc.SearchEvents(ctx, models.EventsSearch{UserID: 1, Query: "стоматолог", Archived: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{dentist}, eventIDs(found))
+
+	_, err = svc.SearchEvents(ctx, models.EventsSearch{UserID: 1, Query: "  ", Limit: 10})
+	require.ErrorIs(t, err, errQuery)
+	_, err = svc.SearchEvents(ctx, models.EventsSearch{UserID: 1, Query: "x", From: day, To: day, Limit: 10})
+	require.ErrorIs(t, err, errRange)
+}
+
+func eventIDs(events []models.Event) []string {
+	res := make([]string, 0, len(events))
+	for _, e := range events {
+		res = append(res, e.ID)
+	}
+	return res
+}
+
+func TestForeignEventsForbidden(t *testing.T) {
+	svc := newSvc(t)
+	day := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	owner := auth.WithUser(context.Background(), 1)
+	other := auth.WithUser(context.Background(), 2)
+
+	id, err := svc.CreateEvent(owner, models.Event{UserID: 1, Date: day, Text: "private"})
+	require.NoError(t, err)
+	seriesID, err := svc.CreateEvent(owner, models.Event{UserID: 1, Date: day, Text: "daily", RRule: "FREQ=DAILY"})
+	require.NoError(t, err)
+
+	_, err = svc.CreateEvent(other, models.Event{UserID: 1, Date: day, Text: "spoofed"})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	_, err = svc.GetEventsForDay(other, 1, day)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	err = svc.UpdateEvent(other, models.Event{ID: id, UserID: 2, Date: day, Text: "hijacked"}, models.EditScopeDefault)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	occurrence := instanceID(seriesID, day.AddDate(0, 0, 1))
+	assert.ErrorIs(t, svc.DeleteEvent(other, occurrence, models.EditScopeDefault), auth.ErrForbidden)
+	assert.ErrorIs(t, svc.DeleteEvent(other, id, models.EditScopeDefault), auth.ErrForbidden)
+
+	events, err := svc.GetEventsForDay(owner, 1, day)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.ElementsMatch(t, []string{"private", "daily"}, []string{events[0].Text, events[1].Text})
+}
@@ -0,0 +1,179 @@
+package calendarsvc
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// GetUserSettings - настройки пользователя. Если они не сохранялись, возвращаются настройки по умолчанию.
+func (s *calendarService) GetUserSettings(ctx context.Context, userID int64) (models.UserSettings, error) {
+	if userID <= 0 {
+		return models.UserSettings{}, errUserID
+	}
+	if err := auth.Authorize(ctx, userID); err != nil {
+		return models.UserSettings{}, err
+	}
+
+	return s.userSettings(ctx, userID)
+}
+
+// userSettings - настройки пользователя без проверки прав: часовой пояс владельца календаря
+// нужен и тем, кому выдан доступ к календарю.
+func (s *calendarService) userSettings(ctx context.Context, userID int64) (models.UserSettings, error) {
+	settings, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return models.UserSettings{}, fmt.Errorf("users.Get: %w", err)
+	}
+	if settings == nil {
+		return models.UserSettings{UserID: userID}, nil
+	}
+
+	return *settings, nil
+}
+
+// SaveUserSettings - сохраняет настройки пользователя.
+func (s *calendarService) SaveUserSettings(ctx context.Context, settings models.UserSettings) error {
+	if settings.UserID <= 0 {
+		return errUserID
+	}
+	if err := auth.Authorize(ctx, settings.UserID); err != nil {
+		return err
+	}
+	if _, err := loadLocation(settings.TimeZone); err != nil {
+		return err
+	}
+	if err := validateChannels(settings.Channels); err != nil {
+		return err
+	}
+	if err := s.validateRecipients(ctx, settings); err != nil {
+		return err
+	}
+
+	if err := s.users.Save(ctx, &settings); err != nil {
+		return fmt.Errorf("users.Save: %w", err)
+	}
+
+	return nil
+}
+
+// Location - часовой пояс, в котором считаются границы дней для пользователя:
+// явно переданный tz, иначе пояс из настроек пользователя, иначе пояс сервера.
+func (s *calendarService) Location(ctx context.Context, userID int64, tz string) (*time.Location, error) {
+	if tz == "" && userID > 0 {
+		if _, err := s.authorize(ctx, userID, accessFreeBusy); err != nil {
+			return nil, err
+		}
+		settings, err := s.userSettings(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		tz = settings.TimeZone
+	}
+
+	return loadLocation(tz)
+}
+
+// applyTimeZone - определяет часовой пояс события (собственный, fallback или пояс пользователя)
+// и переводит в него время события. Время обычных событий сохраняет момент,
+// у целодневных событий сохраняется дата по стенным часам.
+func (s *calendarService) applyTimeZone(ctx context.Context, event *models.Event, fallback string) error {
+	tz := event.TimeZone
+	if tz == "" {
+		tz = fallback
+	}
+	if tz == "" {
+		settings, err := s.userSettings(ctx, event.UserID)
+		if err != nil {
+			return err
+		}
+		tz = settings.TimeZone
+	}
+	if tz == "" {
+		return nil
+	}
+
+	loc, err := loadLocation(tz)
+	if err != nil {
+		return err
+	}
+
+	event.TimeZone = tz
+	convert := func(t time.Time) time.Time { return t.In(loc) }
+	if event.AllDay {
+		convert = func(t time.Time) time.Time { return wallClockIn(t, loc) }
+	}
+
+	event.Date = convert(event.Date)
+	if !event.End.IsZero() {
+		event.End = convert(event.End)
+	}
+
+	return nil
+}
+
+// loadLocation - загружает IANA часовой пояс. Пустая строка означает пояс сервера.
+func loadLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.Local, nil
+	}
+	if tz == "Local" {
+		return nil, fmt.Errorf("%w: %q", errTimeZone, tz)
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", errTimeZone, tz)
+	}
+
+	return loc, nil
+}
+
+// wallClockIn - то же показание часов в другом часовом поясе.
+func wallClockIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// validateChannels - проверяет, что все каналы напоминаний известны.
+func validateChannels(channels []models.Channel) error {
+	for _, ch := range channels {
+		if !ch.Valid() {
+			return fmt.Errorf("%w: %q", errChannel, ch)
+		}
+	}
+
+	return nil
+}
+
+// validateRecipients - проверяет адреса доставки и их наличие для выбранных каналов.
+// webhook_url не может указывать во внутреннюю сеть (см. netguard.Guard.CheckHost).
+func (s *calendarService) validateRecipients(ctx context.Context, settings models.UserSettings) error {
+	if settings.Email != "" {
+		if _, err := mail.ParseAddress(settings.Email); err != nil {
+			return fmt.Errorf("%w: email %q", errRecipient, settings.Email)
+		}
+	}
+	if settings.WebhookURL != "" {
+		u, err := url.Parse(settings.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: webhook_url %q", errRecipient, settings.WebhookURL)
+		}
+		if err := s.recipients.CheckHost(ctx, u.Hostname()); err != nil {
+			return fmt.Errorf("%w: webhook_url: %w", errRecipient, err)
+		}
+	}
+
+	for _, ch := range settings.Channels {
+		if (ch == models.ChannelEmail && settings.Email == "") ||
+			(ch == models.ChannelWebhook && settings.WebhookURL == "") {
+			return fmt.Errorf("%w: %s", errRecipient, ch)
+		}
+	}
+
+	return nil
+}
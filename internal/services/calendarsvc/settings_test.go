@@ -0,0 +1,165 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/internal/netguard"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func mustLoc(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+
+	return loc
+}
+
+func TestDayBoundariesInCallerZone(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	moscow := mustLoc(t, "Europe/Moscow")
+
+	// 01:00 по Москве 2 января - это 22:00 UTC 1 января.
+	_, err := svc.CreateEvent(ctx, models.Event{
+		UserID:   1,
+		Date:     time.Date(2025, 1, 2, 1, 0, 0, 0, moscow),
+		Text:     "ночной созвон",
+		TimeZone: "Europe/Moscow",
+	})
+	require.NoError(t, err)
+
+	events, err := svc.GetEventsForDay(ctx, 1, time.Date(2025, 1, 2, 0, 0, 0, 0, moscow))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Europe/Moscow", events[0].TimeZone)
+
+	events, err = svc.GetEventsForDay(ctx, 1, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	events, err = svc.GetEventsForDay(ctx, 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestRecurrenceKeepsWallClockAcrossDST(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	ny := mustLoc(t, "America/New_York")
+
+	_, err := svc.CreateEvent(ctx, models.Event{
+		UserID:   1,
+		Date:     time.Date(2025, 3, 3, 10, 0, 0, 0, ny), // понедельник, до перехода на летнее время
+		Text:     "standup",
+		RRule:    "FREQ=WEEKLY",
+		TimeZone: "America/New_York",
+	})
+	require.NoError(t, err)
+
+	events, err := svc.GetEventsForDay(ctx, 1, time.Date(2025, 3, 10, 0, 0, 0, 0, ny))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, 10, events[0].Date.In(ny).Hour())
+	assert.Equal(t, time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC), events[0].Date.UTC())
+}
+
+func TestUserTimeZone(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+
+	settings, err := svc.GetUserSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.UserSettings{UserID: 1}, settings)
+
+	loc, err := svc.Location(ctx, 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Local, loc)
+
+	require.ErrorIs(t, svc.SaveUserSettings(ctx, models.UserSettings{UserID: 1, TimeZone: "Mars/Olympus"}), errTimeZone)
+	require.ErrorIs(t, svc.SaveUserSettings(ctx, models.UserSettings{UserID: 0, TimeZone: "UTC"}), errUserID)
+	require.NoError(t, svc.SaveUserSettings(ctx, models.UserSettings{UserID: 1, TimeZone: "Asia/Tokyo"}))
+
+	loc, err = svc.Location(ctx, 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", loc.String())
+
+	loc, err = svc.Location(ctx, 1, "Europe/Moscow")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Moscow", loc.String())
+
+	// Событие без собственного пояса получает пояс пользователя, целодневное сохраняет дату.
+	id, err := svc.CreateEvent(ctx, models.Event{
+		UserID: 1,
+		Date:   time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		AllDay: true,
+		Text:   "отпуск",
+	})
+	require.NoError(t, err)
+
+	got, err := svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", got.TimeZone)
+	tokyo := mustLoc(t, "Asia/Tokyo")
+	assert.True(t, got.Date.Equal(time.Date(2025, 1, 2, 0, 0, 0, 0, tokyo)))
+	assert.True(t, got.End.Equal(time.Date(2025, 1, 3, 0, 0, 0, 0, tokyo)))
+
+	_, err = svc.CreateEvent(ctx, models.Event{UserID: 1, Date: time.Now(), Text: "x", TimeZone: "Nowhere/City"})
+	require.ErrorIs(t, err, errTimeZone)
+}
+
+func TestNotificationSettings(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+
+	err := svc.SaveUserSettings(ctx, models.UserSettings{UserID: 1, Channels: []models.Channel{"sms"}})
+	require.ErrorIs(t, err, errChannel)
+
+	err = svc.SaveUserSettings(ctx, models.UserSettings{UserID: 1, Channels: []models.Channel{models.ChannelEmail}})
+	require.ErrorIs(t, err, errRecipient)
+
+	err = svc.SaveUserSettings(ctx, models.UserSettings{UserID: 1, WebhookURL: "ftp://example.com"})
+	require.ErrorIs(t, err, errRecipient)
+
+	for _, url := range []string{"http://127.0.0.1:9000/r", "http://169.254.169.254/latest", "http://[fd00::1]/r", "http://localhost/r"} {
+		err = svc.SaveUserSettings(ctx, models.UserSettings{UserID: 1, WebhookURL: url})
+		require.ErrorIs(t, err, netguard.ErrForbidden, url)
+		require.ErrorIs(t, err, errRecipient, url)
+	}
+
+	settings := models.UserSettings{
+		UserID:     1,
+		Channels:   []models.Channel{models.ChannelEmail, models.ChannelWebhook},
+		Email:      "user@example.com",
+		WebhookURL: "https://hooks.example.com/r",
+	}
+	require.NoError(t, svc.SaveUserSettings(ctx, settings))
+
+	got, err := svc.GetUserSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, settings, got)
+}
+
+func TestEventChannels(t *testing.T) {
+	svc := newSvc(t)
+	ctx := context.Background()
+	day := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	_, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day, Text: "x", Channels: []models.Channel{"pigeon"}})
+	require.ErrorIs(t, err, errChannel)
+
+	id, err := svc.CreateEvent(ctx, models.Event{UserID: 1, Date: day, Text: "x", Channels: []models.Channel{models.ChannelWebhook}})
+	require.NoError(t, err)
+
+	// Обновление без каналов сохраняет каналы события.
+	require.NoError(t, svc.UpdateEvent(ctx, models.Event{ID: id, UserID: 1, Date: day, Text: "y"}, models.EditScopeDefault))
+	got, err := svc.repo.Read(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Channel{models.ChannelWebhook}, got.Channels)
+}
@@ -0,0 +1,131 @@
+package calendarsvc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// access - права вызывающего на календарь пользователя, по возрастанию.
+type access int
+
+const (
+	accessNone access = iota
+	accessFreeBusy
+	accessRead
+	accessWrite
+	accessOwner
+)
+
+// access - права вызывающего на календарь ownerID. Владелец и неаутентифицированный вызов
+// (аутентификация отключена, фоновые сервисы) имеют полный доступ, остальные - по выданной роли.
+func (s *calendarService) access(ctx context.Context, ownerID int64) (access, error) {
+	caller, ok := auth.UserFrom(ctx)
+	if !ok || caller == ownerID {
+		return accessOwner, nil
+	}
+
+	share, err := s.shares.Get(ctx, ownerID, caller)
+	if err != nil {
+		return accessNone, fmt.Errorf("shares.Get: %w", err)
+	}
+	if share == nil {
+		return accessNone, nil
+	}
+
+	switch share.Role {
+	case models.ShareEditor:
+		return accessWrite, nil
+	case models.ShareViewer:
+		return accessRead, nil
+	case models.ShareFreeBusy:
+		return accessFreeBusy, nil
+	default:
+		return accessNone, nil
+	}
+}
+
+// authorize - права вызывающего на календарь ownerID; auth.ErrForbidden, если они ниже need.
+func (s *calendarService) authorize(ctx context.Context, ownerID int64, need access) (access, error) {
+	got, err := s.access(ctx, ownerID)
+	if err != nil {
+		return accessNone, err
+	}
+	if got < need {
+		return accessNone, auth.ErrForbidden
+	}
+
+	return got, nil
+}
+
+// GrantShare - выдает пользователю GranteeID доступ к календарю OwnerID или меняет роль выданного.
+// Выдать доступ может только владелец.
+func (s *calendarService) GrantShare(ctx context.Context, share models.Share) error {
+	if share.OwnerID <= 0 || share.GranteeID <= 0 {
+		return errUserID
+	}
+	if share.OwnerID == share.GranteeID {
+		return errSelfShare
+	}
+	if !share.Role.Valid() {
+		return errRole
+	}
+	if err := auth.Authorize(ctx, share.OwnerID); err != nil {
+		return err
+	}
+
+	share.CreatedAt = time.Now()
+	if err := s.shares.Save(ctx, &share); err != nil {
+		return fmt.Errorf("shares.Save: %w", err)
+	}
+
+	s.logger.Info("выдан доступ к календарю",
+		zap.String("service", "calendar"),
+		zap.String("op", "GrantShare"),
+		zap.Int64("owner_id", share.OwnerID),
+		zap.Int64("grantee_id", share.GranteeID),
+		zap.String("role", string(share.Role)),
+	)
+
+	return nil
+}
+
+// ListShares - доступы, выданные пользователем и выданные ему.
+func (s *calendarService) ListShares(ctx context.Context, userID int64) ([]models.Share, error) {
+	if userID <= 0 {
+		return nil, errUserID
+	}
+	if err := auth.Authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	shares, err := s.shares.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("shares.List: %w", err)
+	}
+
+	return shares, nil
+}
+
+// RevokeShare - отзывает доступ. Отозвать может владелец календаря или сам получатель.
+// false - доступ не выдавался.
+func (s *calendarService) RevokeShare(ctx context.Context, ownerID, granteeID int64) (bool, error) {
+	if ownerID <= 0 || granteeID <= 0 {
+		return false, errUserID
+	}
+	if auth.Authorize(ctx, ownerID) != nil && auth.Authorize(ctx, granteeID) != nil {
+		return false, auth.ErrForbidden
+	}
+
+	ok, err := s.shares.Delete(ctx, ownerID, granteeID)
+	if err != nil {
+		return false, fmt.Errorf("shares.Delete: %w", err)
+	}
+
+	return ok, nil
+}
@@ -0,0 +1,111 @@
+package calendarsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func TestShareRoles(t *testing.T) {
+	svc := newSvc(t)
+	day := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	owner := auth.WithUser(context.Background(), 1)
+	viewer := auth.WithUser(context.Background(), 2)
+	editor := auth.WithUser(context.Background(), 3)
+	busy := auth.WithUser(context.Background(), 4)
+
+	id, err := svc.CreateEvent(owner, models.Event{
+		UserID:    1,
+		Date:      day,
+		Text:      "визит к врачу",
+		Reminders: []models.Reminder{{Offset: models.Offset(-time.Hour)}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 2, Role: models.ShareViewer}))
+	require.NoError(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 3, Role: models.ShareEditor}))
+	require.NoError(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 4, Role: models.ShareFreeBusy}))
+
+	// Выдать доступ к чужому календарю нельзя.
+	assert.ErrorIs(t, svc.GrantShare(viewer, models.Share{OwnerID: 1, GranteeID: 5, Role: models.ShareEditor}), auth.ErrForbidden)
+
+	events, err := svc.GetEventsForDay(viewer, 1, day)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "визит к врачу", events[0].Text)
+
+	found, err := svc.SearchEvents(viewer, models.EventsSearch{UserID: 1, Query: "врач", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	err = svc.UpdateEvent(viewer, models.Event{ID: id, UserID: 1, Date: day, Text: "изменено"}, models.EditScopeDefault)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	_, err = svc.CreateEvent(viewer, models.Event{UserID: 1, Date: day, Text: "новое"})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	// Занятость: время без текста и напоминаний, поиск по тексту недоступен.
+	events, err = svc.GetEventsForDay(busy, 1, day)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Empty(t, events[0].Text)
+	assert.Empty(t, events[0].Reminders)
+	assert.Equal(t, day, events[0].Date)
+
+	page, err := svc.ListEvents(busy, models.EventsRange{UserID: 1, From: day.AddDate(0, 0, -1), To: day.AddDate(0, 0, 1), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Empty(t, page.Events[0].Text)
+
+	_, err = svc.SearchEvents(busy, models.EventsSearch{UserID: 1, Query: "врач", Limit: 10})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	// Редактор меняет и создает события в календаре владельца.
+	require.NoError(t, svc.UpdateEvent(editor, models.Event{ID: id, UserID: 3, Date: day, Text: "перенесено"}, models.EditScopeDefault))
+	_, err = svc.CreateEvent(editor, models.Event{UserID: 1, Date: day.Add(time.Hour), Text: "созвон"})
+	require.NoError(t, err)
+
+	events, err = svc.GetEventsForDay(owner, 1, day)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "перенесено", events[0].Text)
+	assert.Equal(t, int64(1), events[0].UserID)
+
+	// Без доступа и после отзыва календарь закрыт.
+	_, err = svc.GetEventsForDay(auth.WithUser(context.Background(), 9), 1, day)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	ok, err := svc.RevokeShare(viewer, 1, 2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, err = svc.GetEventsForDay(viewer, 1, day)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	_, err = svc.RevokeShare(viewer, 1, 3)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	require.NoError(t, svc.DeleteEvent(editor, id, models.EditScopeDefault))
+}
+
+func TestListShares(t *testing.T) {
+	svc := newSvc(t)
+	owner := auth.WithUser(context.Background(), 1)
+
+	require.NoError(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 2, Role: models.ShareViewer}))
+	require.NoError(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 2, Role: models.ShareEditor}))
+	assert.ErrorIs(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 1, Role: models.ShareViewer}), errSelfShare)
+	assert.ErrorIs(t, svc.GrantShare(owner, models.Share{OwnerID: 1, GranteeID: 2, Role: "admin"}), errRole)
+
+	shares, err := svc.ListShares(auth.WithUser(context.Background(), 2), 2)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.Equal(t, models.ShareEditor, shares[0].Role)
+
+	_, err = svc.ListShares(auth.WithUser(context.Background(), 2), 1)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+}
@@ -0,0 +1,68 @@
+package remindersvc
+
+import (
+	"slices"
+	"time"
+
+	"github.com/sunr3d/simple-http-calendar/internal/rrule"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+// alarm - срабатывание одного напоминания события для конкретного вхождения.
+type alarm struct {
+	offset     models.Offset
+	occurrence time.Time
+	at         time.Time
+}
+
+// pendingAlarms - ближайшие неотправленные срабатывания напоминаний события, по возрастанию времени.
+// Для серии берется первое вхождение после последнего отправленного. Если его напоминание уже
+// просрочено, берется последнее просроченное вхождение, чтобы пропущенные напоминания не отправлялись пачкой.
+func pendingAlarms(event *models.Event, now time.Time) []alarm {
+	var rule *rrule.Rule
+	if event.RRule != "" {
+		var err error
+		if rule, err = rrule.Parse(event.RRule); err != nil {
+			return nil
+		}
+	}
+
+	var res []alarm
+	for _, r := range event.Reminders {
+		if rule == nil {
+			if r.SentAt == nil {
+				res = append(res, alarm{offset: r.Offset, occurrence: event.Date, at: event.Date.Add(r.Offset.Duration())})
+			}
+			continue
+		}
+
+		after := event.Date.Add(-time.Nanosecond)
+		if r.Occurrence != nil {
+			after = *r.Occurrence
+		}
+		next, ok := rule.Next(event.Date, after, event.ExDates)
+		if !ok {
+			continue
+		}
+
+		occ := next
+		if !next.Add(r.Offset.Duration()).After(now) {
+			overdue := rule.Between(event.Date, next, now.Add(-r.Offset.Duration()).Add(time.Nanosecond), event.ExDates)
+			if len(overdue) > 0 {
+				occ = overdue[len(overdue)-1]
+			}
+		}
+		res = append(res, alarm{offset: r.Offset, occurrence: occ, at: occ.Add(r.Offset.Duration())})
+	}
+
+	slices.SortStableFunc(res, func(a, b alarm) int {
+		return a.at.Compare(b.at)
+	})
+
+	return res
+}
+
+// due - наступило ли время срабатывания.
+func (a alarm) due(now time.Time) bool {
+	return !a.at.After(now)
+}
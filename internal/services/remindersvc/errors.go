@@ -0,0 +1,5 @@
+package remindersvc
+
+import "errors"
+
+var errNoNotifier = errors.New("канал напоминаний не настроен")
@@ -2,36 +2,75 @@ package remindersvc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/sunr3d/simple-http-calendar/internal/config"
 	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
 	"github.com/sunr3d/simple-http-calendar/internal/interfaces/services"
+	"github.com/sunr3d/simple-http-calendar/internal/scheduler"
 	"github.com/sunr3d/simple-http-calendar/models"
 )
 
 var _ services.ReminderService = (*reminderSvc)(nil)
 
 type reminderSvc struct {
-	repo   infra.Database
-	broker infra.Broker
-	logger *zap.Logger
+	repo      infra.Database
+	users     infra.UserRepo
+	broker    infra.Broker
+	notifiers map[models.Channel]infra.Notifier
+	defaults  []models.Channel
+	logger    *zap.Logger
+	cfg       config.ReminderConfig
+	sched     *scheduler.Scheduler[job]
+
+	// scheduled - ключи задач планировщика по событиям, чтобы отменять напоминания
+	// удаленных событий и снятые напоминания.
+	schedMu   sync.Mutex
+	scheduled map[string]map[string]struct{}
 }
 
 // New - конструктор сервиса напоминаний.
-func New(repo infra.Database, broker infra.Broker, logger *zap.Logger) services.ReminderService {
-	return &reminderSvc{
-		repo:   repo,
-		broker: broker,
-		logger: logger,
+// notifiers - доступные каналы доставки, defaults - каналы для пользователей без собственных настроек.
+// cfg задает повторы доставки по каналам, не принявшим напоминание.
+func New(
+	repo infra.Database,
+	users infra.UserRepo,
+	broker infra.Broker,
+	notifiers []infra.Notifier,
+	defaults []models.Channel,
+	logger *zap.Logger,
+	cfg config.ReminderConfig,
+) services.ReminderService {
+	byChannel := make(map[models.Channel]infra.Notifier, len(notifiers))
+	for _, n := range notifiers {
+		byChannel[n.Channel()] = n
+	}
+
+	s := &reminderSvc{
+		repo:      repo,
+		users:     users,
+		broker:    broker,
+		notifiers: byChannel,
+		defaults:  defaults,
+		logger:    logger,
+		cfg:       cfg,
+		scheduled: make(map[string]map[string]struct{}),
 	}
+	s.sched = scheduler.New(s.fire)
+
+	return s
 }
 
 // Start - запуск сервиса напоминаний.
-// Подписывается на канал событий брокера и проверяет ожидающие напоминания.
-// Есть фоллбэк на случай, если событие уже в прошлом, то оно отправляется сразу.
+// Запускает планировщик, подписывается на канал событий брокера и периодически
+// загружает ожидающие напоминания из БД (в том числе при старте).
+// Просроченные напоминания отправляются сразу.
 func (s *reminderSvc) Start(ctx context.Context, interval time.Duration) error {
 	logger := s.logger.With(
 		zap.String("service", "reminder"),
@@ -41,10 +80,16 @@ func (s *reminderSvc) Start(ctx context.Context, interval time.Duration) error {
 		zap.Duration("interval", interval),
 	)
 
-	if err := s.broker.Subscribe(ctx, s.handleReminder); err != nil {
+	go func() { _ = s.sched.Run(ctx) }()
+
+	if err := s.broker.Subscribe(ctx, models.TopicEvents, subscriberName, s.handleReminder); err != nil {
 		return fmt.Errorf("broker.Subscribe: %w", err)
 	}
 
+	if err := s.checkPendingReminders(ctx); err != nil {
+		logger.Warn("ошибка при проверке ожидающих напоминаний", zap.Error(err))
+	}
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -62,89 +107,365 @@ func (s *reminderSvc) Start(ctx context.Context, interval time.Duration) error {
 	}
 }
 
-// handleReminder - обработчик событий брокера.
-// Проверяет, если событие уже в прошлом, то оно отправляется сразу.
-// Если событие еще не наступило, то ждет и отправляет позже.
-func (s *reminderSvc) handleReminder(ctx context.Context, event *models.Event) error {
-	waitDur := time.Until(event.Date)
-	if waitDur > 0 {
-		select {
-		case <-time.After(waitDur):
-		case <-ctx.Done():
-			return ctx.Err()
-		}
-	}
-
-	s.sendReminder(ctx, event)
+// subscriberName - имя подписчика сервиса напоминаний в топике calendar.events.
+const subscriberName = "reminders"
 
-	event.ReminderSent = true
-	sentAt := time.Now()
-	event.ReminderSentAt = &sentAt
+// handleReminder - обработчик изменений событий из брокера (event.created/updated/deleted/archived).
+// Сообщение - только повод перечитать событие: напоминания планируются по его актуальному
+// состоянию из БД, поэтому перенос события переносит их, а удаление события или напоминаний - отменяет.
+// Обработчик не блокируется: срабатывания передаются планировщику.
+func (s *reminderSvc) handleReminder(ctx context.Context, env models.Envelope) error {
+	var event models.Event
+	if err := env.Decode(&event); err != nil {
+		return fmt.Errorf("env.Decode: %w", err)
+	}
 
-	if err := s.repo.Update(ctx, event); err != nil {
-		return fmt.Errorf("repo.Update: %w", err)
+	current, err := s.repo.Read(ctx, event.ID)
+	if err != nil {
+		// Событие удалено. При временной ошибке БД напоминания вернет периодическая проверка.
+		s.reschedule(event.ID, nil, time.Now())
+		return nil
 	}
 
+	s.reschedule(current.ID, current, time.Now())
+
 	return nil
 }
 
-// checkPendingReminders - проверяет ожидающие напоминания из БД (фоллбэк хелпер).
-// Получает из БД все события, у которых не был отправлен статус напоминания.
-// Если событие уже в прошлом или сейчас время совпадает с временем события,
-// то напоминание отправляется сразу, а статус отправки напоминания устанавливается в true.
+// checkPendingReminders - загружает ожидающие напоминания из БД в планировщик (фоллбэк хелпер).
+// Повторное планирование того же срабатывания только переносит его, поэтому проверка идемпотентна.
 func (s *reminderSvc) checkPendingReminders(ctx context.Context) error {
-	logger := s.logger.With(
-		zap.String("service", "reminder"),
-		zap.String("op", "checkPendingReminders"),
-	)
-
-	reminderSent := false
+	pending := true
 	events, err := s.repo.List(ctx, &infra.ListOptions{
-		ReminderSent: &reminderSent,
+		ReminderPending: &pending,
 	})
 	if err != nil {
 		return fmt.Errorf("repo.List: %w", err)
 	}
 
 	now := time.Now()
-	for _, event := range events {
-		if event.Reminder &&
-			!event.ReminderSent &&
-			(now.After(event.Date) || now.Equal(event.Date)) {
-			event.ReminderSent = true
-			sentAt := time.Now()
-			event.ReminderSentAt = &sentAt
-
-			if err := s.repo.Update(ctx, &event); err != nil {
-				logger.Warn("ошибка при обновлении статуса напоминания в БД", zap.Error(err))
-				continue
-			}
+	for i := range events {
+		s.reschedule(events[i].ID, &events[i], now)
+	}
+
+	return nil
+}
+
+// job - задача планировщика: срабатывание напоминания события.
+// Повтор (attempt > 0) доставляет срабатывание только по каналам channels.
+type job struct {
+	eventID  string
+	alarm    alarm
+	channels []models.Channel
+	attempt  int
+}
+
+// jobKey - ключ задачи планировщика: одно напоминание события ожидает не больше одного срабатывания.
+func jobKey(eventID string, offset models.Offset) string {
+	return eventID + "#" + offset.String()
+}
+
+// reschedule - приводит задачи планировщика для события к его ближайшим срабатываниям:
+// планирует или переносит актуальные и отменяет остальные. event == nil - событие удалено.
+func (s *reminderSvc) reschedule(eventID string, event *models.Event, now time.Time) {
+	var alarms []alarm
+	if event != nil {
+		alarms = pendingAlarms(event, now)
+	}
 
-			go s.sendReminder(ctx, &event)
+	s.schedMu.Lock()
+	defer s.schedMu.Unlock()
+
+	keys := make(map[string]struct{}, len(alarms))
+	for _, a := range alarms {
+		key := jobKey(eventID, a.offset)
+		keys[key] = struct{}{}
+		s.sched.Schedule(key, a.at, job{eventID: eventID, alarm: a})
+	}
+
+	for key := range s.scheduled[eventID] {
+		if _, ok := keys[key]; !ok {
+			s.sched.Cancel(key)
 		}
 	}
 
+	if len(keys) == 0 {
+		delete(s.scheduled, eventID)
+		return
+	}
+	s.scheduled[eventID] = keys
+}
+
+// fire - обработчик планировщика. Доставка идет в отдельной горутине, чтобы не задерживать цикл планировщика.
+func (s *reminderSvc) fire(ctx context.Context, _ string, j job) {
+	go func() { _ = s.deliver(ctx, j, time.Now()) }()
+}
+
+// deliver - отмечает срабатывание отправленным, доставляет напоминание
+// и планирует следующие срабатывания события (для серий - следующее вхождение).
+// Повтор уже отмеченного срабатывания только доставляет его по оставшимся каналам.
+func (s *reminderSvc) deliver(ctx context.Context, j job, now time.Time) error {
+	logger := s.logger.With(
+		zap.String("service", "reminder"),
+		zap.String("op", "deliver"),
+		zap.String("event_id", j.eventID),
+	)
+
+	if j.attempt > 0 {
+		return s.redeliver(ctx, j, now)
+	}
+
+	current, ok, err := s.markSent(ctx, j.eventID, j.alarm, now)
+	if err != nil {
+		logger.Warn("ошибка при обновлении статуса напоминания в БД", zap.Error(err))
+		s.reschedule(j.eventID, nil, now)
+		return err
+	}
+	if ok {
+		// Ошибки доставки логируются в sendReminder. Срабатывание уже отмечено,
+		// поэтому повторяются только каналы, не принявшие напоминание.
+		delivered, failed, _ := s.sendReminder(ctx, current, j.alarm, nil)
+		if err := s.publishSent(ctx, current, j.alarm, now, delivered); err != nil {
+			logger.Warn("ошибка при публикации reminder.sent", zap.Error(err))
+		}
+		s.scheduleRetry(j, failed, now)
+	}
+
+	s.reschedule(current.ID, current, now)
+
 	return nil
 }
 
-// sendReminder - отправляет напоминание.
-func (s *reminderSvc) sendReminder(ctx context.Context, event *models.Event) {
+// redeliver - повтор доставки срабатывания по каналам j.channels.
+// Удаленному событию и снятому напоминанию повтор не нужен.
+func (s *reminderSvc) redeliver(ctx context.Context, j job, now time.Time) error {
 	logger := s.logger.With(
 		zap.String("service", "reminder"),
-		zap.String("op", "sendReminder"),
+		zap.String("op", "redeliver"),
+		zap.String("event_id", j.eventID),
+		zap.Int("attempt", j.attempt+1),
 	)
 
-	select {
-	case <-ctx.Done():
+	event, err := s.repo.Read(ctx, j.eventID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		logger.Warn("ошибка при чтении события из БД", zap.Error(err))
+		s.scheduleRetry(j, j.channels, now)
+		return err
+	}
+	if !slices.ContainsFunc(event.Reminders, func(r models.Reminder) bool { return r.Offset == j.alarm.offset }) {
+		return nil
+	}
+
+	delivered, failed, _ := s.sendReminder(ctx, event, j.alarm, j.channels)
+	if len(delivered) > 0 {
+		if err := s.publishSent(ctx, event, j.alarm, now, delivered); err != nil {
+			logger.Warn("ошибка при публикации reminder.sent", zap.Error(err))
+		}
+	}
+	s.scheduleRetry(j, failed, now)
+
+	return nil
+}
+
+// scheduleRetry - планирует повтор доставки по каналам failed с задержкой
+// RetryBase * 2^(n-1) (не больше RetryMax), пока не исчерпаны MaxAttempts попыток.
+// Повторы не входят в scheduled: их не отменяет перенос событий, они проверяют событие сами.
+func (s *reminderSvc) scheduleRetry(j job, failed []models.Channel, now time.Time) {
+	if len(failed) == 0 {
 		return
-	default:
 	}
 
-	logger.Info("отправлено напоминание",
+	attempt := j.attempt + 1
+	if attempt >= s.cfg.MaxAttempts {
+		s.logger.Warn("исчерпаны попытки доставки напоминания",
+			zap.String("service", "reminder"),
+			zap.String("op", "scheduleRetry"),
+			zap.String("event_id", j.eventID),
+			zap.Stringer("offset", j.alarm.offset),
+			zap.Any("channels", failed),
+		)
+		return
+	}
+
+	key := fmt.Sprintf("%s#%d#retry", jobKey(j.eventID, j.alarm.offset), j.alarm.occurrence.Unix())
+	s.sched.Schedule(key, now.Add(s.backoff(attempt)), job{
+		eventID:  j.eventID,
+		alarm:    j.alarm,
+		channels: failed,
+		attempt:  attempt,
+	})
+}
+
+// backoff - задержка перед повтором attempt: RetryBase * 2^(attempt-1), не больше RetryMax.
+func (s *reminderSvc) backoff(attempt int) time.Duration {
+	delay := s.cfg.RetryBase
+	for i := 1; i < attempt && delay < s.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+
+	return min(delay, s.cfg.RetryMax)
+}
+
+// markSent - отмечает срабатывание напоминания как отправленное.
+// Событие перечитывается из БД: если напоминание удалено, событие перенесено
+// или срабатывание уже отмечено, ok == false и напоминание отправлять не нужно.
+// Отметка атомарна в хранилище, поэтому из нескольких реплик (и из обработчика брокера
+// и периодической проверки одной реплики) срабатывание отправит только одна.
+func (s *reminderSvc) markSent(
+	ctx context.Context,
+	eventID string,
+	a alarm,
+	sentAt time.Time,
+) (event *models.Event, ok bool, err error) {
+	event, err = s.repo.Read(ctx, eventID)
+	if err != nil {
+		return nil, false, fmt.Errorf("repo.Read: %w", err)
+	}
+
+	for _, p := range pendingAlarms(event, sentAt) {
+		if p.offset != a.offset || !p.occurrence.Equal(a.occurrence) {
+			continue
+		}
+
+		claimed, err := s.repo.MarkReminderSent(ctx, eventID, a.offset, a.occurrence, sentAt)
+		if err != nil {
+			return nil, false, fmt.Errorf("repo.MarkReminderSent: %w", err)
+		}
+		if !claimed {
+			// Срабатывание отметил другой процесс: дальше планируется по его отметке.
+			if event, err = s.repo.Read(ctx, eventID); err != nil {
+				return nil, false, fmt.Errorf("repo.Read: %w", err)
+			}
+			return event, false, nil
+		}
+
+		event.MarkReminderSent(a.offset, a.occurrence, sentAt)
+		return event, true, nil
+	}
+
+	return event, false, nil
+}
+
+// sendReminder - доставляет срабатывание напоминания по каналам only, а если они не заданы - по всем каналам события.
+// Возвращает каналы, по которым напоминание доставлено, и каналы, которые стоит повторить.
+// Ненастроенный канал не повторяется. Сбой одного канала не мешает доставке по остальным, ошибки объединяются.
+func (s *reminderSvc) sendReminder(
+	ctx context.Context,
+	event *models.Event,
+	a alarm,
+	only []models.Channel,
+) (delivered, failed []models.Channel, err error) {
+	logger := s.logger.With(
+		zap.String("service", "reminder"),
+		zap.String("op", "sendReminder"),
 		zap.Int64("user_id", event.UserID),
 		zap.String("event_id", event.ID),
-		zap.String("event", event.Text),
-		zap.Time("date", event.Date),
+		zap.Stringer("offset", a.offset),
 	)
-	fmt.Printf("НАПОМИНАНИЕ: событие '%s' начинается сейчас!\n", event.Text)
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	settings, err := s.users.Get(ctx, event.UserID)
+	if err != nil {
+		logger.Warn("не удалось получить настройки пользователя, используются каналы по умолчанию", zap.Error(err))
+	}
+	if settings == nil {
+		settings = &models.UserSettings{UserID: event.UserID}
+	}
+
+	channels := only
+	if len(channels) == 0 {
+		channels = s.channelsFor(event, settings)
+	}
+
+	var errs []error
+	for _, ch := range channels {
+		notifier, ok := s.notifiers[ch]
+		if !ok {
+			logger.Warn("канал напоминаний не настроен", zap.String("channel", string(ch)))
+			errs = append(errs, fmt.Errorf("%w: %s", errNoNotifier, ch))
+			continue
+		}
+
+		notification := models.Notification{
+			Channel:   ch,
+			Recipient: recipient(settings, ch),
+			UserID:    event.UserID,
+			EventID:   event.ID,
+			Text:      event.Text,
+			Date:      a.occurrence,
+			Offset:    a.offset,
+		}
+		if err := notifier.Notify(ctx, notification); err != nil {
+			logger.Warn("ошибка при доставке напоминания", zap.String("channel", string(ch)), zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", ch, err))
+			failed = append(failed, ch)
+			continue
+		}
+
+		delivered = append(delivered, ch)
+		logger.Info("отправлено напоминание",
+			zap.String("channel", string(ch)),
+			zap.String("event", event.Text),
+			zap.Time("date", a.occurrence),
+		)
+	}
+
+	return delivered, failed, errors.Join(errs...)
+}
+
+// publishSent - публикует reminder.sent в топик calendar.reminders.
+func (s *reminderSvc) publishSent(
+	ctx context.Context,
+	event *models.Event,
+	a alarm,
+	sentAt time.Time,
+	channels []models.Channel,
+) error {
+	env, err := models.NewEnvelope(models.ReminderSent, models.SentReminder{
+		EventID:    event.ID,
+		UserID:     event.UserID,
+		Offset:     a.offset,
+		Occurrence: a.occurrence,
+		SentAt:     sentAt,
+		Channels:   channels,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.broker.Publish(ctx, models.TopicReminders, env); err != nil {
+		return fmt.Errorf("broker.Publish: %w", err)
+	}
+
+	return nil
+}
+
+// channelsFor - каналы события, иначе каналы из настроек пользователя, иначе каналы по умолчанию.
+func (s *reminderSvc) channelsFor(event *models.Event, settings *models.UserSettings) []models.Channel {
+	switch {
+	case len(event.Channels) > 0:
+		return event.Channels
+	case len(settings.Channels) > 0:
+		return settings.Channels
+	default:
+		return s.defaults
+	}
+}
+
+// recipient - адрес доставки для канала из настроек пользователя.
+func recipient(settings *models.UserSettings, ch models.Channel) string {
+	switch ch {
+	case models.ChannelEmail:
+		return settings.Email
+	case models.ChannelWebhook:
+		return settings.WebhookURL
+	default:
+		return ""
+	}
 }
@@ -2,6 +2,9 @@ package remindersvc
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -9,41 +12,262 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/sunr3d/simple-http-calendar/internal/config"
 	"github.com/sunr3d/simple-http-calendar/internal/infra/inmembroker"
 	"github.com/sunr3d/simple-http-calendar/internal/infra/inmemdb"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
 	"github.com/sunr3d/simple-http-calendar/models"
 )
 
-func newReminderSvc(t *testing.T) *reminderSvc {
+// testConfig - повторы доставки без ожидания.
+var testConfig = config.ReminderConfig{MaxAttempts: 3, RetryBase: 5 * time.Millisecond, RetryMax: 20 * time.Millisecond}
+
+// recordingNotifier - канал доставки, запоминающий отправленные напоминания.
+// err возвращается первым failures отправкам, а при failures == 0 - всем.
+type recordingNotifier struct {
+	channel  models.Channel
+	err      error
+	failures int
+
+	mu   sync.Mutex
+	sent []models.Notification
+}
+
+func (n *recordingNotifier) Channel() models.Channel {
+	return n.channel
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification models.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.sent = append(n.sent, notification)
+	if n.failures > 0 && len(n.sent) > n.failures {
+		return nil
+	}
+	return n.err
+}
+
+func (n *recordingNotifier) notifications() []models.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]models.Notification(nil), n.sent...)
+}
+
+func newReminderSvc(t *testing.T, notifiers ...infra.Notifier) *reminderSvc {
 	t.Helper()
 
 	logger := zap.NewNop()
 
-	repo := inmemdb.New(logger)
+	repo := inmemdb.New(logger, nil)
 	broker := inmembroker.New(100, logger)
+	if len(notifiers) == 0 {
+		notifiers = []infra.Notifier{&recordingNotifier{channel: models.ChannelLog}}
+	}
 
-	s := New(repo, broker, logger)
+	s := New(repo, inmemdb.NewUserRepo(logger), broker, notifiers, []models.Channel{models.ChannelLog}, logger, testConfig)
 	rs, ok := s.(*reminderSvc)
 
 	require.True(t, ok)
 	return rs
 }
 
+// runScheduler - запускает планировщик напоминаний до конца теста.
+func runScheduler(t *testing.T, svc *reminderSvc) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = svc.sched.Run(ctx) }()
+}
+
+// changed - конверт event.updated для обработчика изменений событий.
+func changed(t *testing.T, event *models.Event) models.Envelope {
+	t.Helper()
+
+	env, err := models.NewEnvelope(models.EventUpdated, event)
+	require.NoError(t, err)
+
+	return env
+}
+
+// barrierRepo - БД, задерживающая первые n чтений, пока их не начнут все n читателей.
+// Так реплики гарантированно видят одно и то же состояние события перед отметкой.
+type barrierRepo struct {
+	infra.Database
+
+	reads   atomic.Int32
+	n       int32
+	arrived sync.WaitGroup
+}
+
+func newBarrierRepo(repo infra.Database, n int) *barrierRepo {
+	r := &barrierRepo{Database: repo, n: int32(n)}
+	r.arrived.Add(n)
+	return r
+}
+
+func (r *barrierRepo) Read(ctx context.Context, id string) (*models.Event, error) {
+	event, err := r.Database.Read(ctx, id)
+	if r.reads.Add(1) <= r.n {
+		r.arrived.Done()
+		r.arrived.Wait()
+	}
+	return event, err
+}
+
+// sentAt - время отправки напоминания idx события из БД.
+func sentAt(svc *reminderSvc, eventID string, idx int) *time.Time {
+	event, err := svc.repo.Read(context.Background(), eventID)
+	if err != nil || len(event.Reminders) <= idx {
+		return nil
+	}
+
+	return event.Reminders[idx].SentAt
+}
+
 func TestSendReminder(t *testing.T) {
-	svc := newReminderSvc(t)
+	logCh := &recordingNotifier{channel: models.ChannelLog}
+	svc := newReminderSvc(t, logCh)
 	ctx := context.Background()
 
 	event := &models.Event{
-		ID:       "test-1",
-		UserID:   1,
-		Date:     time.Now().Add(-1 * time.Hour),
-		Text:     "test event",
-		Reminder: true,
+		ID:        "test-1",
+		UserID:    1,
+		Date:      time.Now().Add(-1 * time.Hour),
+		Text:      "test event",
+		Reminders: []models.Reminder{{Offset: models.Offset(-15 * time.Minute)}},
 	}
 
-	svc.sendReminder(ctx, event)
+	alarms := pendingAlarms(event, time.Now())
+	require.Len(t, alarms, 1)
+	delivered, failed, err := svc.sendReminder(ctx, event, alarms[0], nil)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Channel{models.ChannelLog}, delivered)
+	assert.Empty(t, failed)
+
+	sent := logCh.notifications()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "test-1", sent[0].EventID)
+	assert.Equal(t, "test event", sent[0].Text)
+	assert.Equal(t, event.Date, sent[0].Date)
+	assert.Equal(t, models.Offset(-15*time.Minute), sent[0].Offset)
+}
+
+func TestSendReminderChannels(t *testing.T) {
+	logCh := &recordingNotifier{channel: models.ChannelLog}
+	email := &recordingNotifier{channel: models.ChannelEmail}
+	webhook := &recordingNotifier{channel: models.ChannelWebhook, err: errors.New("недоступен")}
+	svc := newReminderSvc(t, logCh, email, webhook)
+	ctx := context.Background()
+
+	require.NoError(t, svc.users.Save(ctx, &models.UserSettings{
+		UserID:     1,
+		Channels:   []models.Channel{models.ChannelEmail, models.ChannelWebhook},
+		Email:      "user@example.com",
+		WebhookURL: "http://hooks.local/reminder",
+	}))
+
+	// Каналы пользователя; сбой вебхука не мешает доставке письма.
+	delivered, failed, err := svc.sendReminder(ctx, &models.Event{ID: "e-1", UserID: 1, Text: "x", Date: time.Now()}, alarm{}, nil)
+	require.Error(t, err)
+	assert.Equal(t, []models.Channel{models.ChannelEmail}, delivered)
+	assert.Equal(t, []models.Channel{models.ChannelWebhook}, failed)
+	require.Len(t, email.notifications(), 1)
+	assert.Equal(t, "user@example.com", email.notifications()[0].Recipient)
+	require.Len(t, webhook.notifications(), 1)
+	assert.Equal(t, "http://hooks.local/reminder", webhook.notifications()[0].Recipient)
+	assert.Empty(t, logCh.notifications())
+
+	// Каналы события важнее каналов пользователя.
+	_, _, err = svc.sendReminder(ctx, &models.Event{ID: "e-2", UserID: 1, Text: "x", Date: time.Now(), Channels: []models.Channel{models.ChannelLog}}, alarm{}, nil)
+	require.NoError(t, err)
+	assert.Len(t, logCh.notifications(), 1)
+	assert.Len(t, email.notifications(), 1)
+
+	// Повтор доставляет только по указанным каналам.
+	delivered, failed, err = svc.sendReminder(ctx, &models.Event{ID: "e-1", UserID: 1, Text: "x", Date: time.Now()}, alarm{}, []models.Channel{models.ChannelWebhook})
+	require.Error(t, err)
+	assert.Empty(t, delivered)
+	assert.Equal(t, []models.Channel{models.ChannelWebhook}, failed)
+	assert.Len(t, email.notifications(), 1)
+	assert.Len(t, webhook.notifications(), 2)
+}
+
+func TestRetryUndeliveredChannels(t *testing.T) {
+	logCh := &recordingNotifier{channel: models.ChannelLog}
+	webhook := &recordingNotifier{channel: models.ChannelWebhook, err: errors.New("недоступен"), failures: 1}
+	email := &recordingNotifier{channel: models.ChannelEmail, err: errors.New("таймаут")}
+	svc := newReminderSvc(t, logCh, webhook, email)
+	runScheduler(t, svc)
+	ctx := context.Background()
 
-	assert.True(t, true)
+	event := &models.Event{
+		ID:        "retry-1",
+		UserID:    1,
+		Date:      time.Now().Add(-time.Minute),
+		Text:      "retry",
+		Reminders: []models.Reminder{{Offset: 0}},
+		Channels:  []models.Channel{models.ChannelLog, models.ChannelWebhook, models.ChannelEmail},
+	}
+	require.NoError(t, svc.repo.Create(ctx, event))
+
+	alarms := pendingAlarms(event, time.Now())
+	require.Len(t, alarms, 1)
+	require.NoError(t, svc.deliver(ctx, job{eventID: event.ID, alarm: alarms[0]}, time.Now()))
+
+	// Вебхук доставлен со второй попытки, email исчерпал MaxAttempts, log не повторялся.
+	require.Eventually(t, func() bool {
+		return len(webhook.notifications()) == 2 && len(email.notifications()) == testConfig.MaxAttempts
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(5 * testConfig.RetryMax)
+	assert.Len(t, logCh.notifications(), 1)
+	assert.Len(t, webhook.notifications(), 2)
+	assert.Len(t, email.notifications(), testConfig.MaxAttempts)
+	assert.Equal(t, alarms[0].occurrence, email.notifications()[testConfig.MaxAttempts-1].Date)
+}
+
+func TestRetrySkipsDeletedEvent(t *testing.T) {
+	email := &recordingNotifier{channel: models.ChannelEmail, err: errors.New("таймаут")}
+	svc := newReminderSvc(t, email)
+	ctx := context.Background()
+
+	event := &models.Event{
+		ID:        "retry-2",
+		UserID:    1,
+		Date:      time.Now().Add(-time.Minute),
+		Text:      "retry",
+		Reminders: []models.Reminder{{Offset: 0}},
+		Channels:  []models.Channel{models.ChannelEmail},
+	}
+	require.NoError(t, svc.repo.Create(ctx, event))
+
+	alarms := pendingAlarms(event, time.Now())
+	require.Len(t, alarms, 1)
+	require.NoError(t, svc.deliver(ctx, job{eventID: event.ID, alarm: alarms[0]}, time.Now()))
+	require.Len(t, email.notifications(), 1)
+
+	deleted, err := svc.repo.Delete(ctx, event.ID)
+	require.NoError(t, err)
+	require.True(t, deleted)
+	retry := job{eventID: event.ID, alarm: alarms[0], channels: []models.Channel{models.ChannelEmail}, attempt: 1}
+	require.NoError(t, svc.deliver(ctx, retry, time.Now()))
+	assert.Len(t, email.notifications(), 1)
+}
+
+func TestSendReminderUnknownChannel(t *testing.T) {
+	svc := newReminderSvc(t)
+
+	_, failed, err := svc.sendReminder(context.Background(), &models.Event{
+		ID:       "e-1",
+		UserID:   1,
+		Text:     "x",
+		Date:     time.Now(),
+		Channels: []models.Channel{models.ChannelEmail},
+	}, alarm{}, nil)
+	require.ErrorIs(t, err, errNoNotifier)
+	assert.Empty(t, failed)
 }
 
 func TestCheckPendingReminders(t *testing.T) {
@@ -51,19 +275,21 @@ func TestCheckPendingReminders(t *testing.T) {
 	ctx := context.Background()
 
 	pastEvent := models.Event{
-		ID:           "past-1",
-		UserID:       1,
-		Date:         time.Now().Add(-1 * time.Hour),
-		Text:         "past event",
-		Reminder:     true,
-		ReminderSent: false,
+		ID:        "past-1",
+		UserID:    1,
+		Date:      time.Now().Add(-1 * time.Hour),
+		Text:      "past event",
+		Reminders: []models.Reminder{{Offset: 0}},
 	}
 
 	err := svc.repo.Create(ctx, &pastEvent)
 	require.NoError(t, err)
 
+	runScheduler(t, svc)
 	err = svc.checkPendingReminders(ctx)
 	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return sentAt(svc, pastEvent.ID, 0) != nil }, time.Second, 10*time.Millisecond)
 }
 
 func TestHandleReminder(t *testing.T) {
@@ -71,44 +297,83 @@ func TestHandleReminder(t *testing.T) {
 	ctx := context.Background()
 
 	pastEvent := &models.Event{
-		ID:       "past-1",
-		UserID:   1,
-		Date:     time.Now().Add(-1 * time.Hour),
-		Text:     "past event",
-		Reminder: true,
+		ID:        "past-1",
+		UserID:    1,
+		Date:      time.Now().Add(-1 * time.Hour),
+		Text:      "past event",
+		Reminders: []models.Reminder{{Offset: 0}},
 	}
 
 	err := svc.repo.Create(ctx, pastEvent)
 	require.NoError(t, err)
 
-	err = svc.handleReminder(ctx, pastEvent)
+	runScheduler(t, svc)
+	err = svc.handleReminder(ctx, changed(t, pastEvent))
 	require.NoError(t, err)
 
+	require.Eventually(t, func() bool { return sentAt(svc, pastEvent.ID, 0) != nil }, time.Second, 10*time.Millisecond)
 	updatedEvent, err := svc.repo.Read(ctx, pastEvent.ID)
 	require.NoError(t, err)
-	assert.True(t, updatedEvent.ReminderSent)
-	assert.NotNil(t, updatedEvent.ReminderSentAt)
+	require.Len(t, updatedEvent.Reminders, 1)
+	assert.NotNil(t, updatedEvent.Reminders[0].SentAt)
+	assert.False(t, updatedEvent.ReminderPending())
 }
 
 func TestHandleReminderFuture(t *testing.T) {
-	svc := newReminderSvc(t)
-	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
-	defer cancel()
+	logCh := &recordingNotifier{channel: models.ChannelLog}
+	svc := newReminderSvc(t, logCh)
+	ctx := context.Background()
+	runScheduler(t, svc)
 
 	futureEvent := &models.Event{
-		ID:       "future-1",
-		UserID:   1,
-		Date:     time.Now().Add(1 * time.Hour),
-		Text:     "future event",
-		Reminder: true,
+		ID:        "future-1",
+		UserID:    1,
+		Date:      time.Now().Add(1 * time.Hour),
+		Text:      "future event",
+		Reminders: []models.Reminder{{Offset: 0}},
 	}
 
 	err := svc.repo.Create(ctx, futureEvent)
 	require.NoError(t, err)
 
-	err = svc.handleReminder(ctx, futureEvent)
-	require.Error(t, err)
-	require.Equal(t, context.DeadlineExceeded, err)
+	// Обработчик не ждет наступления события, а передает его планировщику.
+	err = svc.handleReminder(ctx, changed(t, futureEvent))
+	require.NoError(t, err)
+	assert.Equal(t, 1, svc.sched.Len())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, logCh.notifications())
+	assert.Nil(t, sentAt(svc, futureEvent.ID, 0))
+}
+
+func TestFarReminderDoesNotBlockOthers(t *testing.T) {
+	logCh := &recordingNotifier{channel: models.ChannelLog}
+	svc := newReminderSvc(t, logCh)
+	ctx := context.Background()
+	runScheduler(t, svc)
+
+	far := &models.Event{
+		ID:        "far",
+		UserID:    1,
+		Date:      time.Now().Add(7 * 24 * time.Hour),
+		Text:      "через неделю",
+		Reminders: []models.Reminder{{Offset: 0}},
+	}
+	soon := &models.Event{
+		ID:        "soon",
+		UserID:    1,
+		Date:      time.Now().Add(30 * time.Millisecond),
+		Text:      "скоро",
+		Reminders: []models.Reminder{{Offset: 0}},
+	}
+	for _, e := range []*models.Event{far, soon} {
+		require.NoError(t, svc.repo.Create(ctx, e))
+		require.NoError(t, svc.handleReminder(ctx, changed(t, e)))
+	}
+
+	require.Eventually(t, func() bool { return len(logCh.notifications()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "soon", logCh.notifications()[0].EventID)
+	assert.Equal(t, 1, svc.sched.Len())
 }
 
 func TestHandleReminderUpdateError(t *testing.T) {
@@ -116,14 +381,17 @@ func TestHandleReminderUpdateError(t *testing.T) {
 	ctx := context.Background()
 
 	pastEvent := &models.Event{
-		ID:       "past-1",
-		UserID:   1,
-		Date:     time.Now().Add(-1 * time.Hour),
-		Text:     "past event",
-		Reminder: true,
+		ID:        "past-1",
+		UserID:    1,
+		Date:      time.Now().Add(-1 * time.Hour),
+		Text:      "past event",
+		Reminders: []models.Reminder{{Offset: 0}},
 	}
 
-	err := svc.handleReminder(ctx, pastEvent)
+	alarms := pendingAlarms(pastEvent, time.Now())
+	require.Len(t, alarms, 1)
+
+	err := svc.deliver(ctx, job{eventID: pastEvent.ID, alarm: alarms[0]}, time.Now())
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "не найден")
 }
@@ -134,12 +402,11 @@ func TestStart(t *testing.T) {
 	defer cancel()
 
 	pastEvent := models.Event{
-		ID:           "past-1",
-		UserID:       1,
-		Date:         time.Now().Add(-1 * time.Hour),
-		Text:         "old event",
-		Reminder:     true,
-		ReminderSent: false,
+		ID:        "past-1",
+		UserID:    1,
+		Date:      time.Now().Add(-1 * time.Hour),
+		Text:      "old event",
+		Reminders: []models.Reminder{{Offset: 0}},
 	}
 
 	err := svc.repo.Create(ctx, &pastEvent)
@@ -152,5 +419,204 @@ func TestStart(t *testing.T) {
 
 	updatedEvent, err := svc.repo.Read(ctx, pastEvent.ID)
 	require.NoError(t, err)
-	assert.True(t, updatedEvent.ReminderSent)
+	require.Len(t, updatedEvent.Reminders, 1)
+	assert.NotNil(t, updatedEvent.Reminders[0].SentAt)
+}
+
+func TestMultipleAlarms(t *testing.T) {
+	logCh := &recordingNotifier{channel: models.ChannelLog}
+	svc := newReminderSvc(t, logCh)
+	ctx := context.Background()
+
+	// Первое напоминание уже просрочено, второе - через час.
+	event := models.Event{
+		ID:     "multi-1",
+		UserID: 1,
+		Date:   time.Now().Add(2 * time.Hour),
+		Text:   "meeting",
+		Reminders: []models.Reminder{
+			{Offset: models.Offset(-3 * time.Hour)},
+			{Offset: models.Offset(-time.Hour)},
+		},
+	}
+	require.NoError(t, svc.repo.Create(ctx, &event))
+
+	runScheduler(t, svc)
+	require.NoError(t, svc.checkPendingReminders(ctx))
+	require.Eventually(t, func() bool { return len(logCh.notifications()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, models.Offset(-3*time.Hour), logCh.notifications()[0].Offset)
+
+	updated, err := svc.repo.Read(ctx, event.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, updated.Reminders[0].SentAt)
+	assert.Nil(t, updated.Reminders[1].SentAt)
+	assert.True(t, updated.ReminderPending())
+	assert.Equal(t, 1, svc.sched.Len())
+
+	// Повторная проверка не отправляет напоминание второй раз.
+	require.NoError(t, svc.checkPendingReminders(ctx))
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, logCh.notifications(), 1)
+}
+
+func TestSeriesAlarms(t *testing.T) {
+	logCh := &recordingNotifier{channel: models.ChannelLog}
+	svc := newReminderSvc(t, logCh)
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Second)
+	start := now.AddDate(0, 0, -3)
+	series := models.Event{
+		ID:        "series-1",
+		UserID:    1,
+		Date:      start,
+		Text:      "daily",
+		RRule:     "FREQ=DAILY",
+		Reminders: []models.Reminder{{Offset: models.Offset(-time.Minute)}},
+	}
+	require.NoError(t, svc.repo.Create(ctx, &series))
+
+	// Из пропущенных вхождений напоминание отправляется только для последнего.
+	alarms := pendingAlarms(&series, now)
+	require.Len(t, alarms, 1)
+	assert.True(t, alarms[0].occurrence.Equal(now))
+
+	runScheduler(t, svc)
+	require.NoError(t, svc.checkPendingReminders(ctx))
+	require.Eventually(t, func() bool { return len(logCh.notifications()) == 1 }, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return svc.sched.Len() == 1 }, time.Second, 10*time.Millisecond)
+
+	updated, err := svc.repo.Read(ctx, series.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updated.Reminders[0].Occurrence)
+	assert.True(t, updated.Reminders[0].Occurrence.Equal(now))
+
+	// Следующее срабатывание - за минуту до завтрашнего вхождения.
+	alarms = pendingAlarms(updated, now)
+	require.Len(t, alarms, 1)
+	assert.True(t, alarms[0].occurrence.Equal(now.AddDate(0, 0, 1)))
+	assert.False(t, alarms[0].due(now))
+}
+
+func TestRescheduleOnUpdate(t *testing.T) {
+	logCh := &recordingNotifier{channel: models.ChannelLog}
+	svc := newReminderSvc(t, logCh)
+	ctx := context.Background()
+	runScheduler(t, svc)
+
+	event := &models.Event{
+		ID:        "moved",
+		UserID:    1,
+		Date:      time.Now().Add(50 * time.Millisecond),
+		Text:      "перенос",
+		Reminders: []models.Reminder{{Offset: 0}, {Offset: models.Offset(-time.Minute)}},
+	}
+	require.NoError(t, svc.repo.Create(ctx, event))
+	require.NoError(t, svc.handleReminder(ctx, changed(t, event)))
+
+	// Второе сообщение о том же событии не дублирует напоминания.
+	require.NoError(t, svc.handleReminder(ctx, changed(t, event)))
+	require.Eventually(t, func() bool { return len(logCh.notifications()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, svc.sched.Len())
+
+	// Перенос: оставшееся напоминание срабатывает в новое время, а не в старое.
+	moved := *event
+	moved.Date = time.Now().Add(time.Hour)
+	moved.Reminders = []models.Reminder{{Offset: 0}}
+	require.NoError(t, svc.repo.Update(ctx, &moved))
+	require.NoError(t, svc.handleReminder(ctx, changed(t, &moved)))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, logCh.notifications(), 1)
+	assert.Equal(t, 1, svc.sched.Len())
+
+	// Снятие напоминаний отменяет ожидающие.
+	moved.Reminders = nil
+	require.NoError(t, svc.repo.Update(ctx, &moved))
+	require.NoError(t, svc.handleReminder(ctx, changed(t, &moved)))
+	assert.Equal(t, 0, svc.sched.Len())
+}
+
+func TestCancelOnDelete(t *testing.T) {
+	logCh := &recordingNotifier{channel: models.ChannelLog}
+	svc := newReminderSvc(t, logCh)
+	ctx := context.Background()
+	runScheduler(t, svc)
+
+	event := &models.Event{
+		ID:        "deleted",
+		UserID:    1,
+		Date:      time.Now().Add(50 * time.Millisecond),
+		Text:      "удаление",
+		Reminders: []models.Reminder{{Offset: 0}},
+	}
+	require.NoError(t, svc.repo.Create(ctx, event))
+	require.NoError(t, svc.handleReminder(ctx, changed(t, event)))
+	require.Equal(t, 1, svc.sched.Len())
+
+	_, err := svc.repo.Delete(ctx, event.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.handleReminder(ctx, changed(t, event)))
+	assert.Equal(t, 0, svc.sched.Len())
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, logCh.notifications())
+}
+
+func TestReplicasDeliverOnce(t *testing.T) {
+	const n = 4
+	logger := zap.NewNop()
+	repo := newBarrierRepo(inmemdb.New(logger, nil), n)
+	users := inmemdb.NewUserRepo(logger)
+	broker := inmembroker.New(100, logger)
+	ctx := context.Background()
+
+	// Реплики с общими БД и брокером, у каждой свой планировщик.
+	var (
+		replicas  []*reminderSvc
+		notifiers []*recordingNotifier
+	)
+	for range n {
+		notifier := &recordingNotifier{channel: models.ChannelLog}
+		s, ok := New(repo, users, broker, []infra.Notifier{notifier}, []models.Channel{models.ChannelLog}, logger, testConfig).(*reminderSvc)
+		require.True(t, ok)
+		replicas = append(replicas, s)
+		notifiers = append(notifiers, notifier)
+	}
+
+	event := &models.Event{
+		ID:        "past-1",
+		UserID:    1,
+		Date:      time.Now().Add(-1 * time.Hour),
+		Text:      "past event",
+		Reminders: []models.Reminder{{Offset: 0}},
+	}
+	require.NoError(t, repo.Create(ctx, event))
+
+	alarms := pendingAlarms(event, time.Now())
+	require.Len(t, alarms, 1)
+
+	var wg sync.WaitGroup
+	for _, s := range replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.deliver(ctx, job{eventID: event.ID, alarm: alarms[0]}, time.Now()))
+		}()
+	}
+	wg.Wait()
+
+	// Периодическая проверка после доставки не находит ожидающих срабатываний.
+	for _, s := range replicas {
+		runScheduler(t, s)
+		require.NoError(t, s.checkPendingReminders(ctx))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	total := 0
+	for _, notifier := range notifiers {
+		total += len(notifier.notifications())
+	}
+	assert.Equal(t, 1, total)
+	assert.NotNil(t, sentAt(replicas[0], event.ID, 0))
 }
@@ -0,0 +1,14 @@
+package webhooksvc
+
+import (
+	"errors"
+
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var (
+	errUserID = models.Invalid("некорректный user_id")
+	errURL    = models.Invalid("некорректный URL вебхука")
+	errTypes  = models.Invalid("неизвестный тип изменения события")
+	errStatus = errors.New("вебхук ответил неуспешным статусом")
+)
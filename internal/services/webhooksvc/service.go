@@ -0,0 +1,388 @@
+package webhooksvc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/auth"
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/infra"
+	"github.com/sunr3d/simple-http-calendar/internal/interfaces/services"
+	"github.com/sunr3d/simple-http-calendar/internal/netguard"
+	"github.com/sunr3d/simple-http-calendar/internal/scheduler"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+var _ services.WebhookService = (*webhookSvc)(nil)
+
+// subscriberName - имя подписчика сервиса вебхуков в топике calendar.events.
+const subscriberName = "webhooks"
+
+// Заголовки доставки. Подпись - HMAC-SHA256 секрета подписки от "<timestamp>.<тело>" в hex.
+const (
+	HeaderEvent     = "X-Calendar-Event"
+	HeaderDelivery  = "X-Calendar-Delivery"
+	HeaderTimestamp = "X-Calendar-Timestamp"
+	HeaderSignature = "X-Calendar-Signature"
+)
+
+// delivery - отправка одного изменения на одну подписку; attempt - номер очередной попытки.
+type delivery struct {
+	webhookID string
+	eventID   string
+	env       models.Envelope
+	attempt   int
+}
+
+type webhookSvc struct {
+	repo   infra.WebhookRepo
+	broker infra.Broker
+	guard  *netguard.Guard
+	client *http.Client
+	cfg    config.WebhookConfig
+	logger *zap.Logger
+	sched  *scheduler.Scheduler[*delivery]
+}
+
+// New - конструктор сервиса вебхуков.
+func New(
+	repo infra.WebhookRepo,
+	broker infra.Broker,
+	logger *zap.Logger,
+	cfg config.WebhookConfig,
+) services.WebhookService {
+	guard := &netguard.Guard{AllowPrivate: cfg.AllowPrivate}
+	s := &webhookSvc{
+		repo:   repo,
+		broker: broker,
+		guard:  guard,
+		client: guard.Client(cfg.Timeout),
+		cfg:    cfg,
+		logger: logger,
+	}
+	s.sched = scheduler.New(s.fire)
+
+	return s
+}
+
+// Start - запуск сервиса вебхуков: планировщик доставок и подписка на изменения событий.
+// Повторы доставок хранятся в памяти и не переживают перезапуск сервиса.
+func (s *webhookSvc) Start(ctx context.Context) error {
+	logger := s.logger.With(
+		zap.String("service", "webhook"),
+		zap.String("op", "Start"),
+	)
+	logger.Info("запуск сервиса вебхуков...")
+
+	go func() { _ = s.sched.Run(ctx) }()
+
+	if err := s.broker.Subscribe(ctx, models.TopicEvents, subscriberName, s.handleEvent); err != nil {
+		return fmt.Errorf("broker.Subscribe: %w", err)
+	}
+
+	<-ctx.Done()
+	logger.Info("отмена контекста, сервис вебхуков остановлен")
+
+	return ctx.Err()
+}
+
+// CreateWebhook - регистрирует подписку. Если секрет не задан, он генерируется;
+// возвращенная подписка содержит секрет, в дальнейшем он не отдается.
+// Адрес во внутренней сети (loopback, link-local, private) отклоняется, если это не разрешено
+// настройкой AllowPrivate; доставка проверяет адрес еще раз при каждом соединении.
+func (s *webhookSvc) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	if webhook.UserID <= 0 {
+		return models.Webhook{}, errUserID
+	}
+	if err := auth.Authorize(ctx, webhook.UserID); err != nil {
+		return models.Webhook{}, err
+	}
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.Webhook{}, fmt.Errorf("%w: %q", errURL, webhook.URL)
+	}
+	if err := s.guard.CheckHost(ctx, u.Hostname()); err != nil {
+		return models.Webhook{}, fmt.Errorf("%w: %w", errURL, err)
+	}
+	for _, typ := range webhook.Types {
+		if !slices.Contains(models.WebhookTypes, typ) {
+			return models.Webhook{}, fmt.Errorf("%w: %q", errTypes, typ)
+		}
+	}
+
+	if webhook.Secret == "" {
+		webhook.Secret, err = newSecret()
+		if err != nil {
+			return models.Webhook{}, err
+		}
+	}
+	webhook.ID = uuid.NewString()
+	webhook.CreatedAt = time.Now()
+
+	if err := s.repo.Create(ctx, &webhook); err != nil {
+		return models.Webhook{}, fmt.Errorf("repo.Create: %w", err)
+	}
+
+	s.logger.Info("подписка на вебхук создана",
+		zap.String("service", "webhook"),
+		zap.String("op", "CreateWebhook"),
+		zap.String("webhook_id", webhook.ID),
+		zap.Int64("user_id", webhook.UserID),
+	)
+
+	return webhook, nil
+}
+
+// ListWebhooks - подписки пользователя.
+func (s *webhookSvc) ListWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	if userID <= 0 {
+		return nil, errUserID
+	}
+	if err := auth.Authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo.List: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook - удаляет подписку пользователя вместе с журналом доставок.
+func (s *webhookSvc) DeleteWebhook(ctx context.Context, userID int64, webhookID string) (bool, error) {
+	webhook, err := s.owned(ctx, userID, webhookID)
+	if err != nil || webhook == nil {
+		return false, err
+	}
+
+	if _, err := s.repo.Delete(ctx, webhookID); err != nil {
+		return false, fmt.Errorf("repo.Delete: %w", err)
+	}
+
+	return true, nil
+}
+
+// ListDeliveries - последние limit попыток доставки по подписке пользователя, новые первыми.
+func (s *webhookSvc) ListDeliveries(
+	ctx context.Context,
+	userID int64,
+	webhookID string,
+	limit int,
+) ([]models.WebhookDelivery, bool, error) {
+	webhook, err := s.owned(ctx, userID, webhookID)
+	if err != nil || webhook == nil {
+		return nil, false, err
+	}
+
+	deliveries, err := s.repo.Deliveries(ctx, webhookID, limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("repo.Deliveries: %w", err)
+	}
+
+	return deliveries, true, nil
+}
+
+// owned - подписка webhookID, если она принадлежит пользователю userID, иначе nil.
+func (s *webhookSvc) owned(ctx context.Context, userID int64, webhookID string) (*models.Webhook, error) {
+	if userID <= 0 {
+		return nil, errUserID
+	}
+	if err := auth.Authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.repo.Get(ctx, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("repo.Get: %w", err)
+	}
+	if webhook == nil || webhook.UserID != userID {
+		return nil, nil
+	}
+
+	return webhook, nil
+}
+
+// handleEvent - обработчик изменений событий из брокера: планирует доставку изменения
+// на все подписки владельца события, принимающие этот тип изменения.
+func (s *webhookSvc) handleEvent(ctx context.Context, env models.Envelope) error {
+	if !slices.Contains(models.WebhookTypes, env.Type) {
+		return nil
+	}
+
+	var event models.Event
+	if err := env.Decode(&event); err != nil {
+		return fmt.Errorf("env.Decode: %w", err)
+	}
+
+	webhooks, err := s.repo.List(ctx, event.UserID)
+	if err != nil {
+		return fmt.Errorf("repo.List: %w", err)
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(env.Type) {
+			continue
+		}
+		s.sched.Schedule(env.ID+"#"+webhook.ID, now, &delivery{
+			webhookID: webhook.ID,
+			eventID:   event.ID,
+			env:       env,
+			attempt:   1,
+		})
+	}
+
+	return nil
+}
+
+// fire - срабатывание планировщика: отправка выполняется в отдельной горутине,
+// чтобы медленный получатель не задерживал остальные доставки.
+func (s *webhookSvc) fire(ctx context.Context, key string, d *delivery) {
+	go s.deliver(ctx, key, d)
+}
+
+// deliver - попытка доставки с записью в журнал. При неудаче планирует повтор
+// с задержкой RetryBase * 2^(n-1) (не больше RetryMax), пока не исчерпаны MaxAttempts попыток.
+func (s *webhookSvc) deliver(ctx context.Context, key string, d *delivery) {
+	logger := s.logger.With(
+		zap.String("service", "webhook"),
+		zap.String("op", "deliver"),
+		zap.String("webhook_id", d.webhookID),
+		zap.String("envelope_id", d.env.ID),
+		zap.Int("attempt", d.attempt),
+	)
+
+	// Подписка перечитывается перед каждой попыткой: удаленная подписка отменяет повторы.
+	webhook, err := s.repo.Get(ctx, d.webhookID)
+	if err == nil && webhook == nil {
+		return
+	}
+
+	code := 0
+	if err == nil {
+		code, err = s.send(ctx, webhook, d.env)
+	} else {
+		err = fmt.Errorf("repo.Get: %w", err)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	record := models.WebhookDelivery{
+		ID:         uuid.NewString(),
+		WebhookID:  d.webhookID,
+		EnvelopeID: d.env.ID,
+		Type:       d.env.Type,
+		EventID:    d.eventID,
+		Attempt:    d.attempt,
+		Status:     models.DeliveryDelivered,
+		StatusCode: code,
+		CreatedAt:  time.Now(),
+	}
+
+	switch {
+	case err == nil:
+	case d.attempt < s.cfg.MaxAttempts:
+		delay := s.backoff(d.attempt)
+		logger.Warn("ошибка при доставке вебхука, повтор", zap.Error(err), zap.Duration("delay", delay))
+		record.Status, record.Error = models.DeliveryRetrying, err.Error()
+		s.sched.Schedule(key, time.Now().Add(delay), &delivery{
+			webhookID: d.webhookID,
+			eventID:   d.eventID,
+			env:       d.env,
+			attempt:   d.attempt + 1,
+		})
+	default:
+		logger.Error("вебхук не доставлен", zap.Error(err))
+		record.Status, record.Error = models.DeliveryFailed, err.Error()
+	}
+
+	if webhook == nil {
+		// Хранилище недоступно - записать попытку в журнал тоже не получится.
+		return
+	}
+	if err := s.repo.AddDelivery(ctx, &record); err != nil {
+		logger.Warn("ошибка при записи в журнал доставок", zap.Error(err))
+	}
+}
+
+// send - подписывает и отправляет конверт на URL подписки. Возвращает HTTP статус ответа.
+// Перенаправления не выполняются: ответ 3xx - неуспешная доставка.
+func (s *webhookSvc) send(ctx context.Context, webhook *models.Webhook, env models.Envelope) (int, error) {
+	body, err := json.Marshal(env)
+	if err != nil {
+		return 0, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("http.NewRequest: %w", err)
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(env.Type))
+	req.Header.Set(HeaderDelivery, env.ID)
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(webhook.Secret, ts, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("client.Do: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("%w: %d", errStatus, resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff - задержка перед попыткой attempt+1: RetryBase * 2^(attempt-1), не больше RetryMax.
+func (s *webhookSvc) backoff(attempt int) time.Duration {
+	delay := s.cfg.RetryBase
+	for i := 1; i < attempt && delay < s.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+
+	return min(delay, s.cfg.RetryMax)
+}
+
+// Sign - подпись доставки: hex(HMAC-SHA256(secret, timestamp + "." + body)).
+// Получатель сравнивает ее с заголовком X-Calendar-Signature (без префикса sha256=).
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
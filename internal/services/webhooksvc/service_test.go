@@ -0,0 +1,346 @@
+package webhooksvc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/sunr3d/simple-http-calendar/internal/config"
+	"github.com/sunr3d/simple-http-calendar/internal/infra/inmembroker"
+	"github.com/sunr3d/simple-http-calendar/internal/infra/inmemdb"
+	"github.com/sunr3d/simple-http-calendar/models"
+)
+
+func newWebhookSvc(t *testing.T) *webhookSvc {
+	t.Helper()
+
+	logger := zap.NewNop()
+	s := New(inmemdb.NewWebhookRepo(logger), inmembroker.New(100, logger), logger, config.WebhookConfig{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		RetryBase:   time.Millisecond,
+		RetryMax:    10 * time.Millisecond,
+		// Тестовые получатели (httptest) слушают loopback.
+		AllowPrivate: true,
+	})
+	ws, ok := s.(*webhookSvc)
+	require.True(t, ok)
+
+	return ws
+}
+
+// runScheduler - запускает планировщик доставок до конца теста.
+func runScheduler(t *testing.T, svc *webhookSvc) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = svc.sched.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// receiver - получатель вебхуков, отвечающий статусами из statuses по очереди (затем 200).
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return len(rc.requests)
+}
+
+// changed - передает сервису изменение события, как это делает брокер.
+func changed(t *testing.T, svc *webhookSvc, typ models.EnvelopeType, event models.Event) models.Envelope {
+	t.Helper()
+
+	env, err := models.NewEnvelope(typ, event)
+	require.NoError(t, err)
+	require.NoError(t, svc.handleEvent(context.Background(), env))
+
+	return env
+}
+
+func TestSignedDelivery(t *testing.T) {
+	svc := newWebhookSvc(t)
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	ctx := context.Background()
+
+	webhook, err := svc.CreateWebhook(ctx, models.Webhook{UserID: 1, URL: srv.URL})
+	require.NoError(t, err)
+	require.Len(t, webhook.Secret, 64)
+
+	runScheduler(t, svc)
+	env := changed(t, svc, models.EventCreated, models.Event{ID: "e-1", UserID: 1, Text: "meeting"})
+	changed(t, svc, models.EventCreated, models.Event{ID: "e-2", UserID: 2, Text: "чужое"})
+
+	require.Eventually(t, func() bool { return rc.count() == 1 }, time.Second, 5*time.Millisecond)
+
+	rc.mu.Lock()
+	req, body := rc.requests[0], rc.bodies[0]
+	rc.mu.Unlock()
+
+	assert.Equal(t, "event.created", req.Header.Get(HeaderEvent))
+	assert.Equal(t, env.ID, req.Header.Get(HeaderDelivery))
+	assert.Equal(t,
+		"sha256="+Sign(webhook.Secret, req.Header.Get(HeaderTimestamp), body),
+		req.Header.Get(HeaderSignature),
+	)
+
+	var got models.Envelope
+	require.NoError(t, json.Unmarshal(body, &got))
+	var event models.Event
+	require.NoError(t, got.Decode(&event))
+	assert.Equal(t, "e-1", event.ID)
+
+	require.Eventually(t, func() bool {
+		deliveries, ok, err := svc.ListDeliveries(ctx, 1, webhook.ID, 10)
+		return err == nil && ok && len(deliveries) == 1
+	}, time.Second, 5*time.Millisecond)
+	deliveries, _, err := svc.ListDeliveries(ctx, 1, webhook.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+	assert.Equal(t, "e-1", deliveries[0].EventID)
+}
+
+func TestRetryThenDelivered(t *testing.T) {
+	svc := newWebhookSvc(t)
+	rc := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	ctx := context.Background()
+
+	webhook, err := svc.CreateWebhook(ctx, models.Webhook{UserID: 1, URL: srv.URL, Secret: "s3cr3t"})
+	require.NoError(t, err)
+
+	runScheduler(t, svc)
+	changed(t, svc, models.EventUpdated, models.Event{ID: "e-1", UserID: 1})
+
+	var deliveries []models.WebhookDelivery
+	require.Eventually(t, func() bool {
+		deliveries, _, err = svc.ListDeliveries(ctx, 1, webhook.ID, 10)
+		return err == nil && len(deliveries) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	// Новые попытки первыми.
+	assert.Equal(t, models.DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, 3, deliveries[0].Attempt)
+	assert.Equal(t, models.DeliveryRetrying, deliveries[1].Status)
+	assert.Equal(t, http.StatusBadGateway, deliveries[1].StatusCode)
+	assert.NotEmpty(t, deliveries[1].Error)
+	assert.Equal(t, 1, deliveries[2].Attempt)
+}
+
+func TestDeliveryFails(t *testing.T) {
+	svc := newWebhookSvc(t)
+	rc := &receiver{statuses: []int{500, 500, 500, 500}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	ctx := context.Background()
+
+	webhook, err := svc.CreateWebhook(ctx, models.Webhook{UserID: 1, URL: srv.URL})
+	require.NoError(t, err)
+
+	runScheduler(t, svc)
+	changed(t, svc, models.EventDeleted, models.Event{ID: "e-1", UserID: 1})
+
+	require.Eventually(t, func() bool {
+		deliveries, _, err := svc.ListDeliveries(ctx, 1, webhook.ID, 1)
+		return err == nil && len(deliveries) == 1 && deliveries[0].Status == models.DeliveryFailed
+	}, time.Second, 5*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 3, rc.count())
+}
+
+func TestWebhookTypes(t *testing.T) {
+	svc := newWebhookSvc(t)
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, err := svc.CreateWebhook(ctx, models.Webhook{
+		UserID: 1,
+		URL:    srv.URL,
+		Types:  []models.EnvelopeType{models.EventDeleted, models.EventArchived},
+	})
+	require.NoError(t, err)
+
+	_, err = svc.CreateWebhook(ctx, models.Webhook{UserID: 1, URL: srv.URL, Types: []models.EnvelopeType{"reminder.sent"}})
+	require.ErrorIs(t, err, errTypes)
+	_, err = svc.CreateWebhook(ctx, models.Webhook{UserID: 1, URL: "ftp://example.com"})
+	require.ErrorIs(t, err, errURL)
+
+	runScheduler(t, svc)
+	changed(t, svc, models.EventCreated, models.Event{ID: "e-1", UserID: 1})
+	changed(t, svc, models.EventArchived, models.Event{ID: "e-1", UserID: 1})
+
+	require.Eventually(t, func() bool { return rc.count() == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	require.Len(t, rc.requests, 1)
+	assert.Equal(t, "event.archived", rc.requests[0].Header.Get(HeaderEvent))
+}
+
+func TestWebhookOwnership(t *testing.T) {
+	svc := newWebhookSvc(t)
+	ctx := context.Background()
+
+	webhook, err := svc.CreateWebhook(ctx, models.Webhook{UserID: 1, URL: "https://example.com/hook"})
+	require.NoError(t, err)
+
+	list, err := svc.ListWebhooks(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, webhook.ID, list[0].ID)
+
+	data, err := json.Marshal(list[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), webhook.Secret)
+
+	_, ok, err := svc.ListDeliveries(ctx, 2, webhook.ID, 10)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = svc.DeleteWebhook(ctx, 2, webhook.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = svc.DeleteWebhook(ctx, 1, webhook.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	list, err = svc.ListWebhooks(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestStartSubscribes(t *testing.T) {
+	svc := newWebhookSvc(t)
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	_, err := svc.CreateWebhook(context.Background(), models.Webhook{UserID: 1, URL: srv.URL})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- svc.Start(ctx) }()
+
+	// Изменения, опубликованные до подписки, in-memory брокер не сохраняет - публикуем, пока не дойдут.
+	require.Eventually(t, func() bool {
+		env, err := models.NewEnvelope(models.EventCreated, models.Event{ID: "e-1", UserID: 1})
+		if err != nil || svc.broker.Publish(ctx, models.TopicEvents, env) != nil {
+			return false
+		}
+		return rc.count() > 0
+	}, time.Second, 20*time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestPrivateAddresses(t *testing.T) {
+	logger := zap.NewNop()
+	s, ok := New(inmemdb.NewWebhookRepo(logger), inmembroker.New(100, logger), logger, config.WebhookConfig{
+		Timeout:     time.Second,
+		MaxAttempts: 1,
+	}).(*webhookSvc)
+	require.True(t, ok)
+	ctx := context.Background()
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.1/hook",
+		"http://[::1]/hook",
+		"http://localhost/hook",
+	} {
+		_, err := s.CreateWebhook(ctx, models.Webhook{UserID: 1, URL: url})
+		assert.ErrorIs(t, err, errURL, url)
+		assert.ErrorIs(t, err, models.ErrInvalid, url)
+	}
+
+	// Адрес, прошедший регистрацию и затем смененный в DNS, отклоняется при соединении.
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	webhook := &models.Webhook{ID: "w-1", UserID: 1, URL: srv.URL, Secret: "secret", CreatedAt: time.Now()}
+	require.NoError(t, s.repo.Create(ctx, webhook))
+
+	runScheduler(t, s)
+	changed(t, s, models.EventCreated, models.Event{ID: "e-1", UserID: 1})
+
+	require.Eventually(t, func() bool {
+		deliveries, _, err := s.ListDeliveries(ctx, 1, webhook.ID, 1)
+		return err == nil && len(deliveries) == 1 && deliveries[0].Status == models.DeliveryFailed
+	}, time.Second, 5*time.Millisecond)
+	deliveries, _, err := s.ListDeliveries(ctx, 1, webhook.ID, 1)
+	require.NoError(t, err)
+	assert.Zero(t, deliveries[0].StatusCode)
+	assert.Zero(t, rc.count())
+}
+
+func TestRedirectNotFollowed(t *testing.T) {
+	svc := newWebhookSvc(t)
+	target := &receiver{}
+	targetSrv := httptest.NewServer(target)
+	defer targetSrv.Close()
+	srv := httptest.NewServer(http.RedirectHandler(targetSrv.URL, http.StatusTemporaryRedirect))
+	defer srv.Close()
+	ctx := context.Background()
+
+	webhook, err := svc.CreateWebhook(ctx, models.Webhook{UserID: 1, URL: srv.URL})
+	require.NoError(t, err)
+
+	runScheduler(t, svc)
+	changed(t, svc, models.EventCreated, models.Event{ID: "e-1", UserID: 1})
+
+	require.Eventually(t, func() bool {
+		deliveries, _, err := svc.ListDeliveries(ctx, 1, webhook.ID, 1)
+		return err == nil && len(deliveries) == 1 && deliveries[0].Status == models.DeliveryFailed
+	}, time.Second, 5*time.Millisecond)
+	assert.Zero(t, target.count())
+}
@@ -0,0 +1,128 @@
+// Package skiplist - упорядоченное множество на списке с пропусками.
+package skiplist
+
+import (
+	"iter"
+	"math/rand/v2"
+)
+
+// maxLevel - число уровней, достаточное для 4^16 элементов при p = 1/4.
+const maxLevel = 16
+
+// List - упорядоченное множество ключей. Insert, Delete и поиск позиции - O(log n) в среднем.
+// Не потокобезопасен: синхронизация остается за владельцем.
+type List[K any] struct {
+	cmp   func(a, b K) int
+	head  node[K]
+	level int
+	len   int
+}
+
+type node[K any] struct {
+	key  K
+	next []*node[K]
+}
+
+// New - конструктор множества с порядком cmp (отрицательное значение - a < b).
+func New[K any](cmp func(a, b K) int) *List[K] {
+	return &List[K]{
+		cmp:   cmp,
+		head:  node[K]{next: make([]*node[K], maxLevel)},
+		level: 1,
+	}
+}
+
+// Len - число ключей.
+func (l *List[K]) Len() int {
+	return l.len
+}
+
+// Insert - добавляет ключ. Возвращает false, если ключ уже есть.
+func (l *List[K]) Insert(key K) bool {
+	var update [maxLevel]*node[K]
+	x := l.seek(key, &update)
+	if x != nil && l.cmp(x.key, key) == 0 {
+		return false
+	}
+
+	lvl := randomLevel()
+	if lvl > l.level {
+		for i := l.level; i < lvl; i++ {
+			update[i] = &l.head
+		}
+		l.level = lvl
+	}
+
+	n := &node[K]{key: key, next: make([]*node[K], lvl)}
+	for i := range lvl {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+	}
+	l.len++
+
+	return true
+}
+
+// Delete - удаляет ключ. Возвращает false, если ключа нет.
+func (l *List[K]) Delete(key K) bool {
+	var update [maxLevel]*node[K]
+	x := l.seek(key, &update)
+	if x == nil || l.cmp(x.key, key) != 0 {
+		return false
+	}
+
+	for i := range len(x.next) {
+		update[i].next[i] = x.next[i]
+	}
+	for l.level > 1 && l.head.next[l.level-1] == nil {
+		l.level--
+	}
+	l.len--
+
+	return true
+}
+
+// All - все ключи по возрастанию.
+func (l *List[K]) All() iter.Seq[K] {
+	return l.iterate(l.head.next[0])
+}
+
+// From - ключи не меньше from по возрастанию.
+func (l *List[K]) From(from K) iter.Seq[K] {
+	return l.iterate(l.seek(from, nil))
+}
+
+func (l *List[K]) iterate(start *node[K]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for x := start; x != nil; x = x.next[0] {
+			if !yield(x.key) {
+				return
+			}
+		}
+	}
+}
+
+// seek - первый узел с ключом не меньше key. В update (если задан) записываются
+// последние узлы каждого уровня, стоящие перед ним.
+func (l *List[K]) seek(key K, update *[maxLevel]*node[K]) *node[K] {
+	x := &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && l.cmp(x.next[i].key, key) < 0 {
+			x = x.next[i]
+		}
+		if update != nil {
+			update[i] = x
+		}
+	}
+
+	return x.next[0]
+}
+
+func randomLevel() int {
+	lvl := 1
+	for lvl < maxLevel && rand.IntN(4) == 0 {
+		lvl++
+	}
+
+	return lvl
+}
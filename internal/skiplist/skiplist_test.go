@@ -0,0 +1,76 @@
+package skiplist
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertDelete(t *testing.T) {
+	l := New(cmp.Compare[int])
+
+	assert.True(t, l.Insert(3))
+	assert.True(t, l.Insert(1))
+	assert.True(t, l.Insert(2))
+	assert.False(t, l.Insert(2))
+	assert.Equal(t, 3, l.Len())
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(l.All()))
+
+	assert.True(t, l.Delete(2))
+	assert.False(t, l.Delete(2))
+	assert.False(t, l.Delete(42))
+	assert.Equal(t, 2, l.Len())
+	assert.Equal(t, []int{1, 3}, slices.Collect(l.All()))
+}
+
+func TestFrom(t *testing.T) {
+	l := New(cmp.Compare[int])
+	for _, k := range []int{10, 20, 30, 40} {
+		l.Insert(k)
+	}
+
+	assert.Equal(t, []int{20, 30, 40}, slices.Collect(l.From(20)))
+	assert.Equal(t, []int{30, 40}, slices.Collect(l.From(21)))
+	assert.Equal(t, []int{10, 20, 30, 40}, slices.Collect(l.From(0)))
+	assert.Empty(t, slices.Collect(l.From(41)))
+
+	var got []int
+	for k := range l.From(10) {
+		if k > 20 {
+			break
+		}
+		got = append(got, k)
+	}
+	assert.Equal(t, []int{10, 20}, got)
+}
+
+func TestMatchesSortedSet(t *testing.T) {
+	l := New(cmp.Compare[int])
+	want := make(map[int]struct{})
+
+	for range 10000 {
+		k := rand.IntN(2000)
+		if rand.IntN(3) == 0 {
+			_, ok := want[k]
+			require.Equal(t, ok, l.Delete(k))
+			delete(want, k)
+			continue
+		}
+		_, ok := want[k]
+		require.Equal(t, !ok, l.Insert(k))
+		want[k] = struct{}{}
+	}
+
+	keys := make([]int, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	assert.Equal(t, len(keys), l.Len())
+	assert.Equal(t, keys, slices.Collect(l.All()))
+}
@@ -0,0 +1,200 @@
+package textsearch
+
+import "strings"
+
+// stemEnglish - английский стеммер Портера. Слово должно быть в нижнем регистре и из латиницы.
+func stemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	s := porter{b: []byte(word)}
+	s.step1ab()
+	s.step1c()
+	s.replaceFirst(0, step2Rules)
+	s.replaceFirst(0, step3Rules)
+	s.step4()
+	s.step5()
+
+	return string(s.b)
+}
+
+type porter struct {
+	b []byte
+}
+
+type porterRule struct {
+	suffix, repl string
+}
+
+var step2Rules = []porterRule{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"}, {"izer", "ize"},
+	{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"},
+	{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"},
+	{"fulness", "ful"}, {"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}, {"logi", "log"},
+}
+
+var step3Rules = []porterRule{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"}, {"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment", "ent",
+	"ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+// cons - является ли b[i] согласной; y - согласная в начале слова и после гласной.
+func (s *porter) cons(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.cons(i-1)
+	}
+	return true
+}
+
+// measure - число последовательностей VC в b[:n].
+func (s *porter) measure(n int) int {
+	m, i := 0, 0
+	for i < n && s.cons(i) {
+		i++
+	}
+	for i < n {
+		for i < n && !s.cons(i) {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		m++
+		for i < n && s.cons(i) {
+			i++
+		}
+	}
+
+	return m
+}
+
+func (s *porter) vowelIn(n int) bool {
+	for i := range n {
+		if !s.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *porter) doubleCons(n int) bool {
+	return n >= 2 && s.b[n-1] == s.b[n-2] && s.cons(n-1)
+}
+
+// cvc - b[:n] оканчивается на согласная-гласная-согласная, последняя не w, x, y.
+func (s *porter) cvc(n int) bool {
+	if n < 3 || !s.cons(n-1) || s.cons(n-2) || !s.cons(n-3) {
+		return false
+	}
+	c := s.b[n-1]
+	return c != 'w' && c != 'x' && c != 'y'
+}
+
+func (s *porter) ends(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+// stem - длина основы перед suffix.
+func (s *porter) stem(suffix string) int {
+	return len(s.b) - len(suffix)
+}
+
+func (s *porter) set(n int, repl string) {
+	s.b = append(s.b[:n], repl...)
+}
+
+func (s *porter) step1ab() {
+	switch {
+	case s.ends("sses"):
+		s.set(s.stem("sses"), "ss")
+	case s.ends("ies"):
+		s.set(s.stem("ies"), "i")
+	case s.ends("ss"):
+	case s.ends("s"):
+		s.set(s.stem("s"), "")
+	}
+
+	if s.ends("eed") {
+		if s.measure(s.stem("eed")) > 0 {
+			s.set(s.stem("eed"), "ee")
+		}
+		return
+	}
+
+	var n int
+	switch {
+	case s.ends("ed") && s.vowelIn(s.stem("ed")):
+		n = s.stem("ed")
+	case s.ends("ing") && s.vowelIn(s.stem("ing")):
+		n = s.stem("ing")
+	default:
+		return
+	}
+	s.set(n, "")
+
+	switch {
+	case s.ends("at"), s.ends("bl"), s.ends("iz"):
+		s.set(len(s.b), "e")
+	case s.doubleCons(len(s.b)):
+		if c := s.b[len(s.b)-1]; c != 'l' && c != 's' && c != 'z' {
+			s.set(len(s.b)-1, "")
+		}
+	case s.measure(len(s.b)) == 1 && s.cvc(len(s.b)):
+		s.set(len(s.b), "e")
+	}
+}
+
+func (s *porter) step1c() {
+	if s.ends("y") && s.vowelIn(s.stem("y")) {
+		s.set(s.stem("y"), "i")
+	}
+}
+
+// replaceFirst - заменяет первое совпавшее окончание, если мера основы больше minMeasure.
+func (s *porter) replaceFirst(minMeasure int, rules []porterRule) {
+	for _, r := range rules {
+		if !s.ends(r.suffix) {
+			continue
+		}
+		if n := s.stem(r.suffix); s.measure(n) > minMeasure {
+			s.set(n, r.repl)
+		}
+		return
+	}
+}
+
+func (s *porter) step4() {
+	for _, suffix := range step4Suffixes {
+		if !s.ends(suffix) {
+			continue
+		}
+		n := s.stem(suffix)
+		if suffix == "ion" && (n == 0 || (s.b[n-1] != 's' && s.b[n-1] != 't')) {
+			return
+		}
+		if s.measure(n) > 1 {
+			s.set(n, "")
+		}
+		return
+	}
+}
+
+func (s *porter) step5() {
+	if s.ends("e") {
+		n := s.stem("e")
+		if m := s.measure(n); m > 1 || (m == 1 && !s.cvc(n)) {
+			s.set(n, "")
+		}
+	}
+	if s.ends("ll") && s.measure(len(s.b)) > 1 {
+		s.set(len(s.b)-1, "")
+	}
+}
@@ -0,0 +1,139 @@
+package textsearch
+
+import "slices"
+
+// Окончания русского стеммера Snowball. Группы "1" допустимы только после а/я.
+var (
+	ruPerfectiveGerund1 = []string{"в", "вши", "вшись"}
+	ruPerfectiveGerund2 = []string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"}
+	ruReflexive         = []string{"ся", "сь"}
+	ruAdjective         = []string{
+		"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	}
+	ruParticiple1 = []string{"ем", "нн", "вш", "ющ", "щ"}
+	ruParticiple2 = []string{"ивш", "ывш", "ующ"}
+	ruVerb1       = []string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"}
+	ruVerb2       = []string{
+		"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+		"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю",
+	}
+	ruNoun = []string{
+		"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й",
+		"иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я",
+	}
+	ruDerivational = []string{"ост", "ость"}
+	ruSuperlative  = []string{"ейш", "ейше"}
+)
+
+func isRuVowel(r rune) bool {
+	switch r {
+	case 'а', 'е', 'и', 'о', 'у', 'ы', 'э', 'ю', 'я':
+		return true
+	}
+	return false
+}
+
+// stemRussian - русский стеммер Snowball. Слово должно быть в нижнем регистре, ё заменена на е.
+func stemRussian(word []rune) []rune {
+	rv, r2 := ruRegions(word)
+	if rv >= len(word) {
+		return word
+	}
+
+	// Шаг 1.
+	if n := ruSuffix(word, rv, ruPerfectiveGerund2, false); n > 0 {
+		word = word[:len(word)-n]
+	} else if n := ruSuffix(word, rv, ruPerfectiveGerund1, true); n > 0 {
+		word = word[:len(word)-n]
+	} else {
+		if n := ruSuffix(word, rv, ruReflexive, false); n > 0 {
+			word = word[:len(word)-n]
+		}
+		if n := ruAdjectival(word, rv); n > 0 {
+			word = word[:len(word)-n]
+		} else if n := ruGroupSuffix(word, rv, ruVerb1, ruVerb2); n > 0 {
+			word = word[:len(word)-n]
+		} else if n := ruSuffix(word, rv, ruNoun, false); n > 0 {
+			word = word[:len(word)-n]
+		}
+	}
+
+	// Шаг 2.
+	if n := ruSuffix(word, rv, []string{"и"}, false); n > 0 {
+		word = word[:len(word)-n]
+	}
+
+	// Шаг 3.
+	if n := ruSuffix(word, r2, ruDerivational, false); n > 0 {
+		word = word[:len(word)-n]
+	}
+
+	// Шаг 4: превосходная степень, затем нн -> н или мягкий знак.
+	if n := ruSuffix(word, rv, ruSuperlative, false); n > 0 {
+		word = word[:len(word)-n]
+	}
+	if ruSuffix(word, rv, []string{"нн"}, false) > 0 || ruSuffix(word, rv, []string{"ь"}, false) > 0 {
+		word = word[:len(word)-1]
+	}
+
+	return word
+}
+
+// ruRegions - начала областей RV (после первой гласной) и R2 Snowball.
+func ruRegions(word []rune) (int, int) {
+	rv := len(word)
+	for i, r := range word {
+		if isRuVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+
+	r1 := afterVowelConsonant(word, 0)
+	return rv, afterVowelConsonant(word, r1)
+}
+
+// afterVowelConsonant - позиция после первой согласной, следующей за гласной, начиная с from.
+func afterVowelConsonant(word []rune, from int) int {
+	for i := from + 1; i < len(word); i++ {
+		if !isRuVowel(word[i]) && isRuVowel(word[i-1]) {
+			return i + 1
+		}
+	}
+	return len(word)
+}
+
+// ruSuffix - длина самого длинного окончания из suffixes, лежащего в области с начала region.
+// afterA - окончание должно следовать за а/я, также лежащей в области.
+func ruSuffix(word []rune, region int, suffixes []string, afterA bool) int {
+	best := 0
+	for _, s := range suffixes {
+		suffix := []rune(s)
+		start := len(word) - len(suffix)
+		if len(suffix) <= best || start < region || !slices.Equal(word[start:], suffix) {
+			continue
+		}
+		if afterA && (start-1 < region || (word[start-1] != 'а' && word[start-1] != 'я')) {
+			continue
+		}
+		best = len(suffix)
+	}
+
+	return best
+}
+
+// ruGroupSuffix - самое длинное окончание из группы 1 (после а/я) и группы 2.
+func ruGroupSuffix(word []rune, region int, group1, group2 []string) int {
+	return max(ruSuffix(word, region, group1, true), ruSuffix(word, region, group2, false))
+}
+
+// ruAdjectival - длина адъективного окончания: прилагательного, возможно после причастия.
+func ruAdjectival(word []rune, rv int) int {
+	n := ruSuffix(word, rv, ruAdjective, false)
+	if n == 0 {
+		return 0
+	}
+
+	return n + ruGroupSuffix(word[:len(word)-n], rv, ruParticiple1, ruParticiple2)
+}
@@ -0,0 +1,214 @@
+// Package textsearch - полнотекстовый поиск: разбиение на термы с приведением регистра,
+// стемминг русского (Snowball) и английского (Портер) языков и инвертированный индекс
+// с ранжированием BM25 и поиском по префиксу.
+package textsearch
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sunr3d/simple-http-calendar/internal/skiplist"
+)
+
+const (
+	// Параметры BM25.
+	k1 = 1.2
+	b  = 0.75
+
+	// prefixWeight - вес совпадения по префиксу относительно точного совпадения основы.
+	prefixWeight = 0.5
+	// minPrefix - минимальная длина основы запроса в рунах для поиска по префиксу.
+	minPrefix = 3
+)
+
+// Terms - термы текста: слова из букв и цифр в нижнем регистре (ё -> е) после стемминга.
+func Terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	res := make([]string, 0, len(words))
+	for _, w := range words {
+		res = append(res, stem(strings.ReplaceAll(w, "ё", "е")))
+	}
+
+	return res
+}
+
+// stem - основа слова: кириллица - русский стеммер, латиница - английский, прочее без изменений.
+func stem(word string) string {
+	var cyrillic, latin bool
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic = true
+		case r >= 'a' && r <= 'z':
+			latin = true
+		case unicode.IsLetter(r):
+			return word
+		}
+	}
+
+	switch {
+	case cyrillic && !latin:
+		return string(stemRussian([]rune(word)))
+	case latin && !cyrillic:
+		return stemEnglish(word)
+	}
+
+	return word
+}
+
+// Hit - найденный документ и его релевантность.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Index - инвертированный индекс документов. Не потокобезопасен: синхронизация остается за владельцем.
+type Index struct {
+	postings map[string]map[string]int // терм -> документ -> число вхождений
+	docs     map[string][]string       // документ -> его термы
+	terms    *skiplist.List[string]    // упорядоченный словарь для поиска по префиксу
+	total    int                       // суммарная длина документов
+}
+
+// New - конструктор пустого индекса.
+func New() *Index {
+	return &Index{
+		postings: make(map[string]map[string]int),
+		docs:     make(map[string][]string),
+		terms:    skiplist.New(strings.Compare),
+	}
+}
+
+// Len - число документов.
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Add - индексирует текст документа, заменяя прежний.
+func (ix *Index) Add(id, text string) {
+	ix.Remove(id)
+
+	terms := Terms(text)
+	if len(terms) == 0 {
+		return
+	}
+
+	for _, term := range terms {
+		docs, ok := ix.postings[term]
+		if !ok {
+			docs = make(map[string]int)
+			ix.postings[term] = docs
+			ix.terms.Insert(term)
+		}
+		docs[id]++
+	}
+	ix.docs[id] = terms
+	ix.total += len(terms)
+}
+
+// Remove - удаляет документ из индекса.
+func (ix *Index) Remove(id string) {
+	terms, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range terms {
+		docs := ix.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(ix.postings, term)
+			ix.terms.Delete(term)
+		}
+	}
+	delete(ix.docs, id)
+	ix.total -= len(terms)
+}
+
+// Search - документы, содержащие каждый терм запроса точно или как префикс (основа не короче minPrefix рун),
+// по убыванию релевантности BM25, при равенстве - по ID. Точное совпадение весит больше префиксного.
+func (ix *Index) Search(query string) []Hit {
+	terms := Terms(query)
+	slices.Sort(terms)
+	terms = slices.Compact(terms)
+	if len(terms) == 0 || len(ix.docs) == 0 {
+		return nil
+	}
+
+	var scores map[string]float64
+	for _, term := range terms {
+		termScores := ix.termScores(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if add, ok := termScores[id]; ok {
+				scores[id] = score + add
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	slices.SortFunc(hits, func(a, b Hit) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.ID, b.ID))
+	})
+
+	return hits
+}
+
+// termScores - вклад терма запроса в релевантность документов: лучшее из совпадений с термами словаря.
+func (ix *Index) termScores(term string) map[string]float64 {
+	scores := make(map[string]float64)
+	match := func(indexed string, weight float64) {
+		for id, score := range ix.bm25(indexed) {
+			scores[id] = max(scores[id], weight*score)
+		}
+	}
+
+	if utf8.RuneCountInString(term) < minPrefix {
+		match(term, 1)
+		return scores
+	}
+
+	for indexed := range ix.terms.From(term) {
+		if !strings.HasPrefix(indexed, term) {
+			break
+		}
+		if indexed == term {
+			match(indexed, 1)
+		} else {
+			match(indexed, prefixWeight)
+		}
+	}
+
+	return scores
+}
+
+// bm25 - релевантность документов, содержащих терм словаря.
+func (ix *Index) bm25(term string) map[string]float64 {
+	docs := ix.postings[term]
+	n := float64(len(ix.docs))
+	idf := math.Log(1 + (n-float64(len(docs))+0.5)/(float64(len(docs))+0.5))
+	avg := float64(ix.total) / n
+
+	res := make(map[string]float64, len(docs))
+	for id, tf := range docs {
+		length := float64(len(ix.docs[id]))
+		res[id] = idf * float64(tf) * (k1 + 1) / (float64(tf) + k1*(1-b+b*length/avg))
+	}
+
+	return res
+}
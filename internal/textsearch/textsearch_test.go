@@ -0,0 +1,106 @@
+package textsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"запиш", "к", "стоматолог", "в", "10"}, Terms("Запиши: к СТОМАТОЛОГУ в 10!"))
+	assert.Equal(t, Terms("ёлка"), Terms("Елки"))
+	assert.Equal(t, []string{"dentist", "appoint"}, Terms("Dentist appointments"))
+	assert.Empty(t, Terms(" ,.!? "))
+}
+
+func TestStemRussian(t *testing.T) {
+	cases := map[string]string{
+		"встреча":      "встреч",
+		"встречами":    "встреч",
+		"стоматологом": "стоматолог",
+		"приходил":     "приход",
+		"сильная":      "сильн",
+		"красивейший":  "красив",
+		"обследования": "обследован",
+	}
+	for word, want := range cases {
+		assert.Equal(t, want, stem(word), word)
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	cases := map[string]string{
+		"meetings":    "meet",
+		"running":     "run",
+		"caresses":    "caress",
+		"ponies":      "poni",
+		"relational":  "relat",
+		"hopeful":     "hope",
+		"goodness":    "good",
+		"agreed":      "agre",
+		"conditional": "condit",
+	}
+	for word, want := range cases {
+		assert.Equal(t, want, stem(word), word)
+	}
+}
+
+func ids(hits []Hit) []string {
+	res := make([]string, 0, len(hits))
+	for _, h := range hits {
+		res = append(res, h.ID)
+	}
+	return res
+}
+
+func TestSearch(t *testing.T) {
+	ix := New()
+	ix.Add("1", "Запись к стоматологу")
+	ix.Add("2", "Стоматология: консультация")
+	ix.Add("3", "Dentist appointment")
+	ix.Add("4", "Созвон с командой")
+
+	assert.ElementsMatch(t, []string{"1", "2"}, ids(ix.Search("стоматологом")))
+	assert.Equal(t, []string{"3"}, ids(ix.Search("appointments")))
+
+	// "dentist" - префикс основы "dentistri", но точное совпадение выше.
+	ix.Add("5", "dentistry")
+	assert.Equal(t, []string{"3", "5"}, ids(ix.Search("dentist")))
+
+	assert.ElementsMatch(t, []string{"3", "5"}, ids(ix.Search("DENT")))
+	assert.Equal(t, []string{"1"}, ids(ix.Search("стоматолог запись")))
+	assert.Empty(t, ix.Search("стоматолог созвон"))
+	assert.Empty(t, ix.Search("de"))
+	assert.Empty(t, ix.Search("!!"))
+}
+
+func TestSearchRanking(t *testing.T) {
+	ix := New()
+	ix.Add("long", "встреча с клиентом по проекту в офисе на втором этаже")
+	ix.Add("short", "встреча")
+	ix.Add("twice", "встреча, встреча с командой")
+	ix.Add("other", "обед")
+
+	hits := ix.Search("встречи")
+	require.Len(t, hits, 3)
+	assert.Equal(t, "long", hits[2].ID)
+	assert.Greater(t, hits[0].Score, hits[2].Score)
+}
+
+func TestAddReplacesAndRemove(t *testing.T) {
+	ix := New()
+	ix.Add("1", "dentist")
+	ix.Add("1", "barber")
+
+	assert.Empty(t, ix.Search("dentist"))
+	assert.Equal(t, []string{"1"}, ids(ix.Search("barber")))
+	assert.Equal(t, 1, ix.Len())
+
+	ix.Remove("1")
+	ix.Remove("1")
+	assert.Empty(t, ix.Search("barber"))
+	assert.Zero(t, ix.Len())
+	assert.Zero(t, ix.terms.Len())
+	assert.Empty(t, ix.postings)
+}
@@ -1,19 +1,309 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+)
 
 type Event struct {
-	ID             string     `json:"id"`
-	UserID         int64      `json:"user_id"`
-	Date           time.Time  `json:"date"`
-	Text           string     `json:"event"`
-	Reminder       bool       `json:"reminder"`
-	ReminderSent   bool       `json:"reminder_sent"`
-	ReminderSentAt *time.Time `json:"reminder_sent_at,omitempty"`
-	Archived       bool       `json:"archived"`
+	ID        string     `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Date      time.Time  `json:"date"`
+	End       time.Time  `json:"end"`
+	AllDay    bool       `json:"all_day"`
+	Text      string     `json:"event"`
+	Reminders []Reminder `json:"reminders,omitempty"`
+	Archived  bool       `json:"archived"`
+
+	// CalendarID - календарь пользователя (см. Calendar); пустое значение - календарь по умолчанию.
+	CalendarID string `json:"calendar_id,omitempty"`
+	// TimeZone - IANA часовой пояс события; в нем разворачиваются повторения и границы целодневных событий.
+	TimeZone string `json:"tz,omitempty"`
+	// Channels - каналы напоминания события; пустой список - каналы из настроек пользователя.
+	Channels []Channel `json:"channels,omitempty"`
+	// Attendees - приглашенные участники встречи; организатор - владелец события (UserID).
+	Attendees []Attendee `json:"attendees,omitempty"`
+	// Resources - ID ресурсов, которые событие занимает на [Date; End) (см. Resource).
+	Resources []string `json:"resources,omitempty"`
+
+	// RRule - правило повторения серии в формате RFC 5545 (FREQ=WEEKLY;BYDAY=MO).
+	RRule string `json:"rrule,omitempty"`
+	// ExDates - исключенные из серии вхождения (EXDATE).
+	ExDates []time.Time `json:"exdates,omitempty"`
+	// SeriesID - ID серии, к которой относится вхождение или исключение.
+	SeriesID string `json:"series_id,omitempty"`
+	// RecurrenceID - исходное начало вхождения серии (RECURRENCE-ID).
+	RecurrenceID *time.Time `json:"recurrence_id,omitempty"`
+
+	// ICalUID - UID события из импортированного iCalendar, используется для дедупликации.
+	ICalUID string `json:"ical_uid,omitempty"`
+}
+
+// EndTime - время окончания события. Для события без длительности совпадает с началом.
+func (e Event) EndTime() time.Time {
+	if e.End.After(e.Date) {
+		return e.End
+	}
+
+	return e.Date
+}
+
+// Duration - длительность события.
+func (e Event) Duration() time.Duration {
+	return e.EndTime().Sub(e.Date)
+}
+
+// Overlaps - пересекается ли событие с полуинтервалом [from; to).
+// Нулевые from/to означают неограниченное окно с соответствующей стороны.
+// Событие без длительности попадает в окно, если его начало лежит в [from; to).
+func (e Event) Overlaps(from, to time.Time) bool {
+	if !to.IsZero() && !e.Date.Before(to) {
+		return false
+	}
+	if from.IsZero() {
+		return true
+	}
+
+	end := e.EndTime()
+	if end.Equal(e.Date) {
+		return !e.Date.Before(from)
+	}
+
+	return end.After(from)
 }
 
 type EventsByDay struct {
 	UserID int64
 	Day    time.Time
 }
+
+// SortOrder - порядок событий в выборке по времени начала.
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// EventKey - позиция события в выборке, упорядоченной по началу, при равном начале - по ID.
+type EventKey struct {
+	Date time.Time
+	ID   string
+}
+
+// Key - позиция события в упорядоченной выборке.
+func (e *Event) Key() EventKey {
+	return EventKey{Date: e.Date, ID: e.ID}
+}
+
+// Compare - сравнивает позиции по началу, при равном начале - по ID: -1, 0 или +1.
+func (k EventKey) Compare(other EventKey) int {
+	if c := k.Date.Compare(other.Date); c != 0 {
+		return c
+	}
+	return strings.Compare(k.ID, other.ID)
+}
+
+// EventsRange - постраничная выборка событий пользователя, пересекающихся с полуинтервалом [From; To).
+// After - позиция последнего события предыдущей страницы (nil - первая страница): страница начинается
+// со следующего за ним в порядке Sort события, поэтому вставки и удаления между запросами страниц
+// не сдвигают выборку. Limit - размер страницы.
+// CalendarIDs - календари выборки (nil - все, "" - календарь по умолчанию).
+type EventsRange struct {
+	UserID      int64
+	CalendarIDs []string
+	From        time.Time
+	To          time.Time
+	Sort        SortOrder
+	Limit       int
+	After       *EventKey
+}
+
+// EventsSearch - полнотекстовый поиск событий пользователя, включая архивные.
+// Нулевые From/To не ограничивают период с соответствующей стороны, nil CalendarIDs - все календари.
+type EventsSearch struct {
+	UserID      int64
+	CalendarIDs []string
+	Query       string
+	From        time.Time
+	To          time.Time
+	Archived    bool // искать и среди архивных событий
+	Limit       int
+}
+
+// EventsPage - страница выборки событий; More - есть ли события после страницы.
+type EventsPage struct {
+	Events []Event
+	More   bool
+}
+
+// EditScope - область изменения повторяющегося события.
+type EditScope string
+
+const (
+	// EditScopeDefault - "this" для вхождения серии, "all" для самой серии.
+	EditScopeDefault EditScope = ""
+	// EditScopeThis - только выбранное вхождение.
+	EditScopeThis EditScope = "this"
+	// EditScopeFollowing - выбранное вхождение и все последующие.
+	EditScopeFollowing EditScope = "following"
+	// EditScopeAll - вся серия целиком.
+	EditScopeAll EditScope = "all"
+)
+
+// ImportStatus - итог импорта одного VEVENT.
+type ImportStatus string
+
+const (
+	ImportCreated   ImportStatus = "created"
+	ImportUpdated   ImportStatus = "updated"
+	ImportDuplicate ImportStatus = "duplicate"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportResult - результат импорта одного VEVENT.
+type ImportResult struct {
+	UID     string       `json:"uid"`
+	EventID string       `json:"event_id,omitempty"`
+	Status  ImportStatus `json:"status"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// CalendarVisibility - что видят в календаре пользователи, которым выдан доступ (см. Share).
+type CalendarVisibility string
+
+const (
+	// VisibilityShared - события видны согласно роли доступа.
+	VisibilityShared CalendarVisibility = "shared"
+	// VisibilityBusy - видна только занятость, независимо от роли; изменять события могут только владельцы.
+	VisibilityBusy CalendarVisibility = "free_busy"
+	// VisibilityPrivate - календарь виден только владельцу.
+	VisibilityPrivate CalendarVisibility = "private"
+)
+
+// Valid - известна ли видимость.
+func (v CalendarVisibility) Valid() bool {
+	switch v {
+	case VisibilityShared, VisibilityBusy, VisibilityPrivate:
+		return true
+	default:
+		return false
+	}
+}
+
+// Calendar - именованный календарь пользователя ("Работа", "Личное").
+// События без CalendarID относятся к календарю по умолчанию, который есть у каждого пользователя.
+type Calendar struct {
+	ID     string `json:"id"`
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`
+	// Color - цвет календаря в формате #RRGGBB.
+	Color string `json:"color,omitempty"`
+	// Reminders - напоминания новых событий календаря, у которых напоминания не заданы.
+	Reminders  []Reminder         `json:"reminders,omitempty"`
+	Visibility CalendarVisibility `json:"visibility"`
+	// NoOverlap - события календаря не могут пересекаться (календари переговорных и ресурсов).
+	NoOverlap bool      `json:"no_overlap,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConflictPolicy - что делать, если событие пересекается с другими событиями пользователя.
+type ConflictPolicy string
+
+const (
+	// ConflictAllow - сохранить событие, вернув пересечения как предупреждение.
+	ConflictAllow ConflictPolicy = "allow"
+	// ConflictReject - отказать в сохранении.
+	ConflictReject ConflictPolicy = "reject"
+)
+
+// ErrNotFound - запрошенной записи (события, вхождения серии) нет в хранилище.
+var ErrNotFound = errors.New("запись не найдена")
+
+// ErrInvalid - запрос нарушает правила сервиса: некорректные значения полей, область изменения
+// не для серии и т.п. Ошибки проверки сервисов совпадают с ним в errors.Is (см. Invalid).
+var ErrInvalid = errors.New("некорректный запрос")
+
+// Invalid - ошибка проверки с текстом msg, совпадающая с ErrInvalid в errors.Is.
+func Invalid(msg string) error {
+	return &kindError{msg: msg, kind: ErrInvalid}
+}
+
+// NotFound - ошибка с текстом msg об отсутствующей записи, совпадающая с ErrNotFound в errors.Is.
+func NotFound(msg string) error {
+	return &kindError{msg: msg, kind: ErrNotFound}
+}
+
+// kindError - ошибка со своим текстом, относящаяся к общему виду ошибок kind.
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// ConflictError - событие пересекается с событиями EventIDs, а пересечения запрещены.
+type ConflictError struct {
+	EventIDs []string
+}
+
+func (e *ConflictError) Error() string {
+	return "событие пересекается с другими событиями: " + strings.Join(e.EventIDs, ", ")
+}
+
+// AttendeeStatus - ответ участника на приглашение (PARTSTAT из RFC 5545).
+type AttendeeStatus string
+
+const (
+	AttendeeNeedsAction AttendeeStatus = "needs-action"
+	AttendeeAccepted    AttendeeStatus = "accepted"
+	AttendeeDeclined    AttendeeStatus = "declined"
+	AttendeeTentative   AttendeeStatus = "tentative"
+)
+
+// Valid - известен ли статус.
+func (s AttendeeStatus) Valid() bool {
+	switch s {
+	case AttendeeNeedsAction, AttendeeAccepted, AttendeeDeclined, AttendeeTentative:
+		return true
+	default:
+		return false
+	}
+}
+
+// Attendee - участник встречи. Событие видно участнику в его календаре, пока он не отклонил приглашение.
+type Attendee struct {
+	UserID int64          `json:"user_id"`
+	Status AttendeeStatus `json:"status"`
+}
+
+// Headcount - сколько человек у события: организатор и не отклонившие приглашение участники.
+func (e Event) Headcount() int {
+	people := 1
+	for _, a := range e.Attendees {
+		if a.Status != AttendeeDeclined {
+			people++
+		}
+	}
+
+	return people
+}
+
+// Attendee - участник события с userID или nil, если пользователь не приглашен.
+// Указатель ссылается на элемент Attendees, изменение статуса видно в событии.
+func (e Event) Attendee(userID int64) *Attendee {
+	for i := range e.Attendees {
+		if e.Attendees[i].UserID == userID {
+			return &e.Attendees[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errBadDuration = errors.New("некорректная длительность, ожидается например 90m, 1h30m, 2d или -1d")
+
+// ParseDuration - разбирает длительность в формате time.ParseDuration
+// с дополнительной поддержкой дней ("2d", "-1d", "1d12h").
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errBadDuration
+	}
+
+	sign := time.Duration(1)
+	rest := s
+	switch rest[0] {
+	case '-':
+		sign, rest = -1, rest[1:]
+	case '+':
+		rest = rest[1:]
+	}
+
+	var days time.Duration
+	if idx := strings.IndexByte(rest, 'd'); idx >= 0 {
+		n, err := strconv.Atoi(rest[:idx])
+		if err != nil || n < 0 {
+			return 0, errBadDuration
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		rest = rest[idx+1:]
+	}
+
+	var d time.Duration
+	if rest != "" {
+		var err error
+		d, err = time.ParseDuration(rest)
+		if err != nil || d < 0 {
+			return 0, errBadDuration
+		}
+	}
+
+	return sign * (days + d), nil
+}
+
+// FormatDuration - форматирует длительность в виде, который принимает ParseDuration ("-1d", "1d12h", "15m").
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0m"
+	}
+
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	const day = 24 * time.Hour
+	var b strings.Builder
+	b.WriteString(sign)
+	if days := d / day; days > 0 {
+		b.WriteString(strconv.FormatInt(int64(days), 10) + "d")
+		d -= days * day
+	}
+	if d > 0 {
+		rest := d.String()
+		if strings.HasSuffix(rest, "m0s") {
+			rest = strings.TrimSuffix(rest, "0s")
+		}
+		if strings.HasSuffix(rest, "h0m") {
+			rest = strings.TrimSuffix(rest, "0m")
+		}
+		b.WriteString(rest)
+	}
+
+	return b.String()
+}
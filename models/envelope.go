@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Topic - именованный топик брокера. Каждый подписчик топика получает свою копию сообщения.
+type Topic string
+
+const (
+	// TopicEvents - изменения событий календаря (created/updated/deleted/archived), payload - Event.
+	TopicEvents Topic = "calendar.events"
+	// TopicReminders - отправленные напоминания (reminder.sent), payload - SentReminder.
+	TopicReminders Topic = "calendar.reminders"
+)
+
+// EnvelopeType - тип доменного события в конверте.
+type EnvelopeType string
+
+const (
+	EventCreated  EnvelopeType = "event.created"
+	EventUpdated  EnvelopeType = "event.updated"
+	EventDeleted  EnvelopeType = "event.deleted"
+	EventArchived EnvelopeType = "event.archived"
+	ReminderSent  EnvelopeType = "reminder.sent"
+)
+
+// EnvelopeVersion - текущая версия схемы payload. Увеличивается при несовместимом изменении payload.
+const EnvelopeVersion = 1
+
+// Envelope - конверт доменного события: тип, время, версия схемы и payload в JSON.
+// Для событий календаря payload - Event (для удаленного - состояние перед удалением).
+type Envelope struct {
+	ID         string          `json:"id"`
+	Type       EnvelopeType    `json:"type"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// NewEnvelope - конверт текущей версии с payload, сериализованным в JSON.
+func NewEnvelope(typ EnvelopeType, payload any) (Envelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	return Envelope{
+		ID:         uuid.NewString(),
+		Type:       typ,
+		Version:    EnvelopeVersion,
+		OccurredAt: time.Now(),
+		Payload:    data,
+	}, nil
+}
+
+// Decode - разбирает payload конверта в dst.
+func (e Envelope) Decode(dst any) error {
+	if e.Version > EnvelopeVersion {
+		return fmt.Errorf("неподдерживаемая версия конверта %d", e.Version)
+	}
+
+	return json.Unmarshal(e.Payload, dst)
+}
+
+// SentReminder - payload reminder.sent: срабатывание напоминания, доставленное пользователю.
+type SentReminder struct {
+	EventID    string    `json:"event_id"`
+	UserID     int64     `json:"user_id"`
+	Offset     Offset    `json:"offset"`
+	Occurrence time.Time `json:"occurrence"`
+	SentAt     time.Time `json:"sent_at"`
+	Channels   []Channel `json:"channels,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Interval - промежуток времени [Start; End).
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// FreeBusyQuery - запрос занятости пользователей UserIDs в полуинтервале [From; To).
+// При SlotDuration > 0 дополнительно ищутся общие свободные промежутки не короче SlotDuration
+// в рабочие часы [WorkStart; WorkEnd) (смещения от полуночи в поясе From); выходные
+// учитываются только с Weekends.
+type FreeBusyQuery struct {
+	UserIDs      []int64
+	From         time.Time
+	To           time.Time
+	SlotDuration time.Duration
+	WorkStart    time.Duration
+	WorkEnd      time.Duration
+	Weekends     bool
+}
+
+// UserBusy - объединенные занятые промежутки пользователя, без описания событий.
+type UserBusy struct {
+	UserID int64      `json:"user_id"`
+	Busy   []Interval `json:"busy"`
+}
+
+// FreeBusy - занятость пользователей запроса; Free - общие свободные промежутки
+// (nil, если поиск слотов не запрашивался).
+type FreeBusy struct {
+	Users []UserBusy
+	Free  []Interval
+}
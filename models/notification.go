@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Channel - канал доставки напоминаний.
+type Channel string
+
+const (
+	// ChannelLog - запись в файл или stdout сервера.
+	ChannelLog Channel = "log"
+	// ChannelEmail - письмо на адрес из настроек пользователя.
+	ChannelEmail Channel = "email"
+	// ChannelWebhook - HTTP POST на URL из настроек пользователя.
+	ChannelWebhook Channel = "webhook"
+)
+
+// Valid - известен ли канал.
+func (c Channel) Valid() bool {
+	switch c {
+	case ChannelLog, ChannelEmail, ChannelWebhook:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notification - напоминание, готовое к доставке по конкретному каналу.
+// Date - начало события или вхождения серии, Offset - смещение сработавшего напоминания.
+type Notification struct {
+	Channel Channel   `json:"channel"`
+	UserID  int64     `json:"user_id"`
+	EventID string    `json:"event_id"`
+	Text    string    `json:"event"`
+	Date    time.Time `json:"date"`
+	Offset  Offset    `json:"offset"`
+
+	// Recipient - адрес доставки: email или URL вебхука. Для канала log пустой.
+	Recipient string `json:"-"`
+}
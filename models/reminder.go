@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Reminder - напоминание о событии. Offset отсчитывается от начала события:
+// -15m - за 15 минут до начала, 0m - в момент начала.
+type Reminder struct {
+	Offset Offset `json:"offset"`
+	// SentAt - время последней отправки напоминания.
+	SentAt *time.Time `json:"sent_at,omitempty"`
+	// Occurrence - начало вхождения серии, для которого напоминание отправлено последним.
+	Occurrence *time.Time `json:"occurrence,omitempty"`
+}
+
+// Offset - смещение напоминания относительно начала события. В JSON - строка вида "-1d", "-15m".
+type Offset time.Duration
+
+// Duration - смещение как time.Duration.
+func (o Offset) Duration() time.Duration {
+	return time.Duration(o)
+}
+
+func (o Offset) String() string {
+	return FormatDuration(time.Duration(o))
+}
+
+func (o Offset) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.String())
+}
+
+func (o *Offset) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	d, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*o = Offset(d)
+	return nil
+}
+
+// ReminderPending - есть ли у события неотправленные напоминания.
+// У серии напоминания срабатывают для каждого вхождения, поэтому она всегда в ожидании.
+func (e Event) ReminderPending() bool {
+	if len(e.Reminders) == 0 {
+		return false
+	}
+	if e.RRule != "" {
+		return true
+	}
+
+	for _, r := range e.Reminders {
+		if r.SentAt == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarkReminderSent - отмечает срабатывание напоминания offset для вхождения occurrence отправленным в sentAt.
+// false - у события нет такого напоминания или срабатывание этого либо более позднего вхождения уже отмечено.
+func (e *Event) MarkReminderSent(offset Offset, occurrence, sentAt time.Time) bool {
+	marked := false
+	for i := range e.Reminders {
+		r := &e.Reminders[i]
+		if r.Offset != offset {
+			continue
+		}
+		if r.SentAt != nil && (r.Occurrence == nil || !r.Occurrence.Before(occurrence)) {
+			return false
+		}
+
+		sent, occ := sentAt, occurrence
+		r.SentAt = &sent
+		r.Occurrence = &occ
+		marked = true
+	}
+
+	return marked
+}
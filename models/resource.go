@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ResourceKind - вид бронируемого ресурса.
+type ResourceKind string
+
+const (
+	ResourceRoom      ResourceKind = "room"
+	ResourceEquipment ResourceKind = "equipment"
+)
+
+// Valid - известен ли вид ресурса.
+func (k ResourceKind) Valid() bool {
+	switch k {
+	case ResourceRoom, ResourceEquipment:
+		return true
+	default:
+		return false
+	}
+}
+
+// BookingPolicy - ограничения бронирования ресурса. Нулевые значения не ограничивают.
+type BookingPolicy struct {
+	// MaxDuration - наибольшая длительность брони.
+	MaxDuration Offset `json:"max_duration,omitempty"`
+	// Horizon - насколько вперед от текущего момента можно бронировать.
+	Horizon Offset `json:"horizon,omitempty"`
+}
+
+// Resource - бронируемый ресурс: переговорная или общее оборудование. Ресурс занимает событие,
+// в Resources которого он указан; календарь ресурса - занявшие его события, и они не пересекаются.
+type Resource struct {
+	ID string `json:"id"`
+	// UserID - администратор ресурса; только он изменяет и удаляет ресурс.
+	UserID int64        `json:"user_id"`
+	Name   string       `json:"name"`
+	Kind   ResourceKind `json:"kind"`
+	// Capacity - вместимость: сколько человек (организатор и участники) может быть у события, 0 - без ограничения.
+	Capacity  int           `json:"capacity,omitempty"`
+	Location  string        `json:"location,omitempty"`
+	Policy    BookingPolicy `json:"policy"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// CheckCapacity - *BookingError, если людей у события (см. Event.Headcount) больше вместимости ресурса.
+// prev - сохраненное состояние события или nil для нового: ресурс, который событие уже занимало,
+// проверяется, только если людей стало больше, поэтому уменьшение вместимости не мешает участникам отказываться.
+func (r Resource) CheckCapacity(event, prev *Event) error {
+	people := event.Headcount()
+	if r.Capacity == 0 || people <= r.Capacity {
+		return nil
+	}
+	if prev != nil && slices.Contains(prev.Resources, r.ID) && people <= prev.Headcount() {
+		return nil
+	}
+
+	return &BookingError{ResourceID: r.ID, Reason: fmt.Sprintf("вместимость %d, участников %d", r.Capacity, people)}
+}
+
+// Booking - бронь ресурса событием. Текст события в календаре ресурса не раскрывается.
+type Booking struct {
+	EventID string    `json:"event_id"`
+	UserID  int64     `json:"user_id"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+// BookingError - событие не может занять ресурс ResourceID (пустой - ни один из ресурсов события):
+// ресурс не найден или бронь нарушает его правила.
+type BookingError struct {
+	ResourceID string
+	Reason     string
+}
+
+func (e *BookingError) Error() string {
+	if e.ResourceID == "" {
+		return "нельзя забронировать ресурсы: " + e.Reason
+	}
+
+	return "нельзя забронировать ресурс " + e.ResourceID + ": " + e.Reason
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ShareRole - уровень доступа к чужому календарю.
+type ShareRole string
+
+const (
+	// ShareViewer - чтение событий.
+	ShareViewer ShareRole = "viewer"
+	// ShareEditor - чтение, создание, изменение и удаление событий.
+	ShareEditor ShareRole = "editor"
+	// ShareFreeBusy - только занятость: события отдаются без текста и напоминаний.
+	ShareFreeBusy ShareRole = "free_busy"
+)
+
+// Valid - известна ли роль.
+func (r ShareRole) Valid() bool {
+	switch r {
+	case ShareViewer, ShareEditor, ShareFreeBusy:
+		return true
+	default:
+		return false
+	}
+}
+
+// Share - доступ пользователя GranteeID к календарю OwnerID. У пары владелец-получатель один доступ.
+type Share struct {
+	OwnerID   int64     `json:"owner_id"`
+	GranteeID int64     `json:"grantee_id"`
+	Role      ShareRole `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
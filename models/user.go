@@ -0,0 +1,16 @@
+package models
+
+// UserSettings - пользовательские настройки.
+type UserSettings struct {
+	UserID int64 `json:"user_id"`
+	// TimeZone - IANA часовой пояс пользователя (Europe/Moscow).
+	// Пустое значение означает часовой пояс сервера.
+	TimeZone string `json:"tz"`
+
+	// Channels - каналы напоминаний по умолчанию; пустой список - каналы из конфигурации сервиса.
+	Channels []Channel `json:"channels,omitempty"`
+	// Email - адрес для канала email.
+	Email string `json:"email,omitempty"`
+	// WebhookURL - адрес для канала webhook.
+	WebhookURL string `json:"webhook_url,omitempty"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// WebhookTypes - типы изменений событий, на которые можно подписать вебхук.
+var WebhookTypes = []EnvelopeType{EventCreated, EventUpdated, EventDeleted, EventArchived}
+
+// Webhook - подписка пользователя на изменения его событий.
+type Webhook struct {
+	ID     string `json:"id"`
+	UserID int64  `json:"user_id"`
+	URL    string `json:"url"`
+	// Secret - ключ HMAC-подписи доставок. Не отдается при чтении подписок.
+	Secret string `json:"-"`
+	// Types - типы изменений; пустой список - все WebhookTypes.
+	Types     []EnvelopeType `json:"types,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Accepts - подписан ли вебхук на изменения типа typ.
+func (w Webhook) Accepts(typ EnvelopeType) bool {
+	if len(w.Types) == 0 {
+		return slices.Contains(WebhookTypes, typ)
+	}
+
+	return slices.Contains(w.Types, typ)
+}
+
+// DeliveryStatus - результат попытки доставки вебхука.
+type DeliveryStatus string
+
+const (
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryRetrying - попытка неудачна, запланирован повтор.
+	DeliveryRetrying DeliveryStatus = "retrying"
+	// DeliveryFailed - последняя попытка неудачна, повторов больше не будет.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// WebhookDelivery - запись журнала доставок: одна попытка отправки изменения на вебхук.
+type WebhookDelivery struct {
+	ID         string         `json:"id"`
+	WebhookID  string         `json:"webhook_id"`
+	EnvelopeID string         `json:"envelope_id"`
+	Type       EnvelopeType   `json:"type"`
+	EventID    string         `json:"event_id"`
+	Attempt    int            `json:"attempt"`
+	Status     DeliveryStatus `json:"status"`
+	StatusCode int            `json:"status_code,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
@@ -72,17 +72,17 @@ echo "1.1 Создание событий..."
 measure_time 
 EVENT1=$(curl -s -X POST http://localhost:8080/create_event \
   -H "Content-Type: application/json" \
-  -d "{\"user_id\": 1, \"date\": \"$FUTURE_TIME\", \"event\": \"Meeting 1\", \"reminder\": true}" \
+  -d "{\"user_id\": 1, \"date\": \"$FUTURE_TIME\", \"event\": \"Meeting 1\", \"reminders\": [\"0m\"]}" \
   | jq -r ".result")
 
 EVENT2=$(curl -s -X POST http://localhost:8080/create_event \
   -H "Content-Type: application/json" \
-  -d "{\"user_id\": 1, \"date\": \"$FUTURE_TIME\", \"event\": \"Meeting 2\", \"reminder\": false}" \
+  -d "{\"user_id\": 1, \"date\": \"$FUTURE_TIME\", \"event\": \"Meeting 2\"}" \
   | jq -r ".result")
 
 EVENT3=$(curl -s -X POST http://localhost:8080/create_event \
   -H "Content-Type: application/json" \
-  -d "{\"user_id\": 2, \"date\": \"$FUTURE_TIME\", \"event\": \"Meeting 3\", \"reminder\": true}" \
+  -d "{\"user_id\": 2, \"date\": \"$FUTURE_TIME\", \"event\": \"Meeting 3\", \"reminders\": [\"0m\"]}" \
   | jq -r ".result")
 
 echo "Созданы события: $EVENT1, $EVENT2, $EVENT3"
@@ -97,7 +97,7 @@ measure_time
 for i in {1..50}; do
   curl -s -X POST http://localhost:8080/create_event \
     -H "Content-Type: application/json" \
-    -d "{\"user_id\": $((i % 5 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Mass test $i\", \"reminder\": false}" > /dev/null &
+    -d "{\"user_id\": $((i % 5 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Mass test $i\"}" > /dev/null &
 done
 wait
 echo "50 запросов отправлены одновременно"
@@ -122,7 +122,7 @@ for i in {1..100}; do
       # Создание
       curl -s -X POST http://localhost:8080/create_event \
         -H "Content-Type: application/json" \
-        -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Mixed load $i\", \"reminder\": false}" > /dev/null &
+        -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Mixed load $i\"}" > /dev/null &
       ;;
     1)
       # Получение
@@ -133,14 +133,14 @@ for i in {1..100}; do
       if [[ -n "$EVENT1" ]]; then
 curl -s -X POST http://localhost:8080/update_event \
           -H "Content-Type: application/json" \
-          -d "{\"event_id\": \"$EVENT1\", \"user_id\": 1, \"date\": \"$FUTURE_TIME\", \"event\": \"Updated $i\", \"reminder\": false}" > /dev/null &
+          -d "{\"event_id\": \"$EVENT1\", \"user_id\": 1, \"date\": \"$FUTURE_TIME\", \"event\": \"Updated $i\"}" > /dev/null &
       fi
       ;;
     3)
       # Удаление (создаем и сразу удаляем)
       TEMP_EVENT=$(curl -s -X POST http://localhost:8080/create_event \
         -H "Content-Type: application/json" \
-        -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Temp $i\", \"reminder\": false}" \
+        -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Temp $i\"}" \
         | jq -r ".result")
       if [[ "$TEMP_EVENT" != "null" && -n "$TEMP_EVENT" ]]; then
         curl -s -X POST http://localhost:8080/delete_event \
@@ -163,7 +163,7 @@ measure_time
 for i in {1..10}; do
   curl -s -X POST http://localhost:8080/create_event \
     -H "Content-Type: application/json" \
-    -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$PAST_TIME\", \"event\": \"Past reminder $i\", \"reminder\": true}" > /dev/null &
+    -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$PAST_TIME\", \"event\": \"Past reminder $i\", \"reminders\": [\"0m\"]}" > /dev/null &
 done
 wait
 echo "10 событий с напоминаниями в прошлом созданы"
@@ -175,7 +175,7 @@ measure_time
 for i in {1..5}; do
   curl -s -X POST http://localhost:8080/create_event \
     -H "Content-Type: application/json" \
-    -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Future reminder $i\", \"reminder\": true}" > /dev/null &
+    -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Future reminder $i\", \"reminders\": [\"0m\"]}" > /dev/null &
 done
 wait
 echo "5 событий с напоминаниями в будущем созданы"
@@ -200,15 +200,15 @@ echo "4.2 Тестирование неправильных данных..."
 measure_time
 curl -s -X POST http://localhost:8080/create_event \
   -H "Content-Type: application/json" \
-  -d "{\"user_id\": 0, \"date\": \"$FUTURE_TIME\", \"event\": \"Test\", \"reminder\": false}" > /dev/null &
+  -d "{\"user_id\": 0, \"date\": \"$FUTURE_TIME\", \"event\": \"Test\"}" > /dev/null &
 
 curl -s -X POST http://localhost:8080/create_event \
   -H "Content-Type: application/json" \
-  -d '{"user_id": 1, "date": "invalid-date", "event": "Test", "reminder": false}' > /dev/null &
+  -d '{"user_id": 1, "date": "invalid-date", "event": "Test"}' > /dev/null &
 
 curl -s -X POST http://localhost:8080/create_event \
   -H "Content-Type: application/json" \
-  -d "{\"user_id\": 1, \"date\": \"$FUTURE_TIME\", \"event\": \"\", \"reminder\": false}" > /dev/null &
+  -d "{\"user_id\": 1, \"date\": \"$FUTURE_TIME\", \"event\": \"\"}" > /dev/null &
 
 curl -s "http://localhost:8080/events_for_day?user_id=1&date=invalid-date" > /dev/null &
 
@@ -234,7 +234,7 @@ echo "📅 Время событий: $YESTERDAY_TIME"
 for i in {1..5}; do
   EVENT_ID=$(curl -s -X POST http://localhost:8080/create_event \
     -H "Content-Type: application/json" \
-    -d "{\"user_id\": $i, \"date\": \"$YESTERDAY_TIME\", \"event\": \"Past event $i for archive\", \"reminder\": false}" \
+    -d "{\"user_id\": $i, \"date\": \"$YESTERDAY_TIME\", \"event\": \"Past event $i for archive\"}" \
     | jq -r ".result")
   
   if [ -n "$EVENT_ID" ] && [ "$EVENT_ID" != "null" ]; then
@@ -250,7 +250,7 @@ echo "5.2 Создание событий в будущем для сравне
 for i in {1..3}; do
   EVENT_ID=$(curl -s -X POST http://localhost:8080/create_event \
     -H "Content-Type: application/json" \
-    -d "{\"user_id\": $i, \"date\": \"$FUTURE_TIME\", \"event\": \"Future event $i (not archived)\", \"reminder\": false}" \
+    -d "{\"user_id\": $i, \"date\": \"$FUTURE_TIME\", \"event\": \"Future event $i (not archived)\"}" \
     | jq -r ".result")
   
   if [ -n "$EVENT_ID" ] && [ "$EVENT_ID" != "null" ]; then
@@ -354,7 +354,7 @@ echo "ВНИМАНИЕ: Сейчас будет отправлен SIGTERM, но
 for i in {1..20}; do
   curl -s -X POST http://localhost:8080/create_event \
     -H "Content-Type: application/json" \
-    -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Shutdown test $i\", \"reminder\": false}" > /dev/null &
+    -d "{\"user_id\": $((i % 3 + 1)), \"date\": \"$FUTURE_TIME\", \"event\": \"Shutdown test $i\"}" > /dev/null &
 done
 
 # Небольшая задержка